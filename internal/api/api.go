@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"mime/multipart"
 	"net"
 	"net/http"
@@ -91,9 +92,24 @@ func WrapConnectionError(err error) error {
 	return &ConnectionError{err}
 }
 
+// DefaultRetries is the default number of times a failed request is
+// retried if the error is transient.
+const DefaultRetries = 3
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
 type APIClient struct {
 	Server    string
 	UserAgent string
+	// Retries is the number of times GET requests and bundle uploads
+	// are retried on connection errors and transient server errors
+	Retries uint
+	// Proxy is the URL of the proxy used for all requests. If empty,
+	// the proxy is determined from the environment.
+	Proxy string
 }
 
 var FeaturedProjectsOrganization = "organizations/1"
@@ -107,6 +123,7 @@ func NewClient(server string) *APIClient {
 	return &APIClient{
 		Server:    server,
 		UserAgent: "cifuzz/" + version.Version + " " + runtime.GOOS + "-" + runtime.GOARCH,
+		Retries:   DefaultRetries,
 	}
 }
 
@@ -180,6 +197,47 @@ func (client *APIClient) UploadBundle(path string, projectName string, token str
 		}
 	})
 
+	// Upload the bundle, retrying on transient errors. The upload and
+	// the waiting between the attempts are aborted when the routines
+	// context is cancelled.
+	var body []byte
+	routines.Go(func() error {
+		defer cancelSignalHandler()
+		for attempt := uint(0); ; attempt++ {
+			var err error
+			body, err = client.uploadBundle(routinesCtx, path, projectName, token)
+			if err == nil || attempt >= client.Retries || routinesCtx.Err() != nil || !isRetryableError(err) {
+				return err
+			}
+			err = waitBeforeRetry(routinesCtx, attempt, client.Retries, err)
+			if err != nil {
+				return err
+			}
+		}
+	})
+
+	err := routines.Wait()
+	if err != nil {
+		// Routines.Wait() returns our own errors so it should already have
+		// a stack trace and doesn't need to have one added
+		// nolint: wrapcheck
+		return nil, err
+	}
+
+	artifact := &Artifact{}
+	err = json.Unmarshal(body, artifact)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse response from upload bundle API call")
+	}
+
+	return artifact, nil
+}
+
+// uploadBundle makes a single attempt to upload the bundle and returns
+// the body of the response.
+func (client *APIClient) uploadBundle(ctx context.Context, path string, projectName string, token string) ([]byte, error) {
+	routines, routinesCtx := errgroup.WithContext(ctx)
+
 	// Use a pipe to avoid reading the artifacts into memory at once
 	r, w := io.Pipe()
 	m := multipart.NewWriter(w)
@@ -231,7 +289,6 @@ func (client *APIClient) UploadBundle(path string, projectName string, token str
 				log.Warnf("Failed to close pipe: %v", closeErr)
 			}
 		}()
-		defer cancelSignalHandler()
 		url, err := url.JoinPath(client.Server, "v2", projectName, "artifacts", "import")
 		if err != nil {
 			return errors.WithStack(err)
@@ -245,7 +302,11 @@ func (client *APIClient) UploadBundle(path string, projectName string, token str
 		req.Header.Set("Content-Type", m.FormDataContentType())
 		req.Header.Add("Authorization", "Bearer "+token)
 
-		httpClient := &http.Client{Transport: getCustomTransport()}
+		transport, err := client.transport()
+		if err != nil {
+			return err
+		}
+		httpClient := &http.Client{Transport: transport}
 		resp, err := httpClient.Do(req)
 		if err != nil {
 			return errors.WithStack(err)
@@ -271,14 +332,7 @@ func (client *APIClient) UploadBundle(path string, projectName string, token str
 		// nolint: wrapcheck
 		return nil, err
 	}
-
-	artifact := &Artifact{}
-	err = json.Unmarshal(body, artifact)
-	if err != nil {
-		return nil, errors.Wrap(err, "Failed to parse response from upload bundle API call")
-	}
-
-	return artifact, nil
+	return body, nil
 }
 
 func (client *APIClient) StartRemoteFuzzingRun(artifact *Artifact, token string) (string, error) {
@@ -329,13 +383,71 @@ func (client *APIClient) sendRequest(method string, endpoint string, body []byte
 }
 
 // sendRequestWithTimeout sends a request to the API server with a timeout.
+// GET requests are retried up to client.Retries times on connection errors
+// and responses with a status code which indicates a transient error.
+// The retries are aborted when a termination signal is received.
 func (client *APIClient) sendRequestWithTimeout(method string, endpoint string, body []byte, token string, timeout time.Duration) (*http.Response, error) {
+	ctx, stop := cancelOnTerminationSignal(context.Background())
+	defer stop()
+
+	for attempt := uint(0); ; attempt++ {
+		resp, err := client.sendRequestOnce(ctx, method, endpoint, body, token, timeout)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			// nolint: wrapcheck
+			return nil, context.Cause(ctx)
+		}
+		if method != http.MethodGet || attempt >= client.Retries {
+			return resp, err
+		}
+		if err == nil {
+			if !isRetryableStatusCode(resp.StatusCode) {
+				return resp, nil
+			}
+			err = responseToAPIError(resp)
+			resp.Body.Close()
+		} else if !isRetryableError(err) {
+			return nil, err
+		}
+		err = waitBeforeRetry(ctx, attempt, client.Retries, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// cancelOnTerminationSignal returns a copy of ctx which is cancelled
+// with a SignalError as the cause when a termination signal is received
+// before stop is called. Calling stop doesn't cancel the context, so
+// that the body of a response can still be read afterwards.
+func cancelOnTerminationSignal(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+		case s := <-sigs:
+			log.Warnf("Received %s", s.String())
+			cancel(cmdutils.NewSignalError(s.(syscall.Signal)))
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
+
+func (client *APIClient) sendRequestOnce(ctx context.Context, method string, endpoint string, body []byte, token string, timeout time.Duration) (*http.Response, error) {
 	url, err := url.JoinPath(client.Server, endpoint)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -346,7 +458,11 @@ func (client *APIClient) sendRequestWithTimeout(method string, endpoint string,
 	req.Header.Add("Content-Type", "application/json")
 
 	log.Debugf("Sending HTTP request: %s %s\n%s", method, endpoint, body)
-	httpClient := &http.Client{Transport: getCustomTransport(), Timeout: timeout}
+	transport, err := client.transport()
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: transport, Timeout: timeout}
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, WrapConnectionError(errors.WithStack(err))
@@ -357,6 +473,63 @@ func (client *APIClient) sendRequestWithTimeout(method string, endpoint string,
 	return resp, nil
 }
 
+// retryBackoff returns the time to wait before the next attempt of a
+// request which failed attempt+1 times. The delay grows exponentially
+// and includes a random jitter, so that multiple clients which failed
+// at the same time don't retry at the same time.
+var retryBackoff = func(attempt uint) time.Duration {
+	delay := retryBaseDelay << attempt
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	// Add up to 50% jitter
+	//nolint:gosec // No need for a cryptographically secure random number
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// waitBeforeRetry logs that the request failed with err and waits
+// before the next attempt. It returns the cause of the context's
+// cancellation if the context is done before the next attempt.
+func waitBeforeRetry(ctx context.Context, attempt uint, retries uint, err error) error {
+	delay := retryBackoff(attempt)
+	log.Warnf("Request failed: %v\nRetrying in %s (%d/%d)", err, delay.Round(time.Millisecond), attempt+1, retries)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(context.Cause(ctx))
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isRetryableStatusCode returns true if the status code indicates a
+// transient error of the server or a proxy in front of it.
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
+}
+
+// isRetryableError returns true if the request which failed with err
+// should be retried.
+func isRetryableError(err error) bool {
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return true
+	}
+	// Errors returned by http.Client.Do are always of type *url.Error
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatusCode(apiErr.StatusCode)
+	}
+	return false
+}
+
 // IsTokenValid checks if the token is valid by querying the API server.
 func (client *APIClient) IsTokenValid(token string) (bool, error) {
 	if token == "" {
@@ -379,16 +552,34 @@ func (client *APIClient) IsTokenValid(token string) (bool, error) {
 }
 
 func validateURL(s string) error {
+	return validateURLWithSchemes(s, "http", "https")
+}
+
+func validateURLWithSchemes(s string, schemes ...string) error {
 	u, err := url.Parse(s)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	if u.Scheme != "http" && u.Scheme != "https" {
+	if !stringutil.Contains(schemes, u.Scheme) {
 		return errors.Errorf("unsupported protocol scheme %q", u.Scheme)
 	}
 	return nil
 }
 
+// ValidateProxyURL checks that the given URL can be used as a proxy.
+// Supported are HTTP, HTTPS and SOCKS5 proxies. An empty URL is valid,
+// in that case the proxy is determined from the environment.
+func ValidateProxyURL(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	err := validateURLWithSchemes(proxyURL, "http", "https", "socks5")
+	if err != nil {
+		return errors.WithMessagef(err, "Proxy '%s' is not a valid URL", proxyURL)
+	}
+	return nil
+}
+
 func ValidateAndNormalizeServerURL(server string) (string, error) {
 	// Check if the server option is a valid URL
 	err := validateURL(server)
@@ -411,11 +602,40 @@ func ValidateAndNormalizeServerURL(server string) (string, error) {
 	return url, nil
 }
 
+// transport returns the transport used for the requests of the client,
+// which uses the configured proxy or, if none is configured, the proxy
+// from the environment.
+func (client *APIClient) transport() (*http.Transport, error) {
+	if client.Proxy == "" {
+		return getCustomTransport(), nil
+	}
+
+	err := ValidateProxyURL(client.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL, err := url.Parse(client.Proxy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if proxyURL.Scheme == "socks5" {
+		dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &http.Transport{DialContext: dialContext(dialer)}, nil
+	}
+	return &http.Transport{Proxy: http.ProxyURL(proxyURL)}, nil
+}
+
 func getCustomTransport() *http.Transport {
 	// it is not possible to use the default Proxy Environment because
 	// of https://github.com/golang/go/issues/24135
-	dialer := proxy.FromEnvironment()
-	dialContext := func(ctx context.Context, network, address string) (net.Conn, error) {
+	return &http.Transport{DialContext: dialContext(proxy.FromEnvironment())}
+}
+
+func dialContext(dialer proxy.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
 		conn, err := dialer.Dial(network, address)
 		if err != nil {
 			// This error is being returned to the http package and we
@@ -426,5 +646,4 @@ func getCustomTransport() *http.Transport {
 		}
 		return conn, nil
 	}
-	return &http.Transport{DialContext: dialContext}
 }
@@ -38,8 +38,9 @@ type Campaign struct {
 // CreateCampaignRun creates a new campaign run for the given project and
 // returns the name of the campaign and fuzzing run. The campaign and fuzzing
 // run name is used to identify the campaign run in the API for consecutive
-// calls.
-func (client *APIClient) CreateCampaignRun(project string, token string, fuzzTarget string, buildSystem string, firstMetrics *report.FuzzingMetric, lastMetrics *report.FuzzingMetric) (string, string, error) {
+// calls. If a display name is specified, it's shown for the fuzz target
+// instead of its technical identifier.
+func (client *APIClient) CreateCampaignRun(project string, token string, fuzzTarget string, displayName string, buildSystem string, firstMetrics *report.FuzzingMetric, lastMetrics *report.FuzzingMetric) (string, string, error) {
 	fuzzTargetId := base64.URLEncoding.EncodeToString([]byte(fuzzTarget))
 
 	// generate a short random string to use as the campaign run name
@@ -65,9 +66,12 @@ func (client *APIClient) CreateCampaignRun(project string, token string, fuzzTar
 	apiFuzzTarget := APIFuzzTarget{
 		RelativePath: fuzzTarget,
 	}
+	if displayName == "" {
+		displayName = fuzzTarget
+	}
 	fuzzTargetConfig := &FuzzTargetConfig{
 		Name:        fuzzTargetConfigName,
-		DisplayName: fuzzTarget,
+		DisplayName: displayName,
 	}
 	var engine string
 	switch buildSystem {
@@ -26,6 +26,13 @@ type Finding struct {
 	FuzzTargetDisplayName string       `json:"fuzz_target_display_name,omitempty"`
 }
 
+// UploadedFinding describes how the server stored an uploaded finding.
+type UploadedFinding struct {
+	// Name is the server-assigned identifier of the finding
+	Name        string
+	DisplayName string
+}
+
 type ErrorReport struct {
 	Logs      []string `json:"logs"`
 	Details   string   `json:"details"`
@@ -104,7 +111,9 @@ func (client *APIClient) DownloadRemoteFindings(project string, token string) (F
 	return remoteFindings, nil
 }
 
-func (client *APIClient) UploadFinding(project string, fuzzTarget string, campaignRunName string, fuzzingRunName string, finding *finding.Finding, token string) error {
+// UploadFinding uploads the finding to CI Sense and returns how the
+// server stored it.
+func (client *APIClient) UploadFinding(project string, fuzzTarget string, displayName string, campaignRunName string, fuzzingRunName string, finding *finding.Finding, token string) (*UploadedFinding, error) {
 	project = ConvertProjectNameForUseWithAPIV1V2(project)
 
 	// loop through the stack trace and create a list of breakpoints
@@ -128,6 +137,9 @@ func (client *APIClient) UploadFinding(project string, fuzzTarget string, campai
 				FuzzTarget:  fuzzTarget,
 				FuzzingRun:  fuzzingRunName,
 				CampaignRun: campaignRunName,
+				// Empty if no display name was specified, so that the
+				// server shows the fuzz target
+				FuzzTargetDisplayName: displayName,
 				ErrorReport: &ErrorReport{
 					Logs:      finding.Logs,
 					Details:   finding.Details,
@@ -147,22 +159,52 @@ func (client *APIClient) UploadFinding(project string, fuzzTarget string, campai
 
 	body, err := json.MarshalIndent(findings, "", "  ")
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 
 	url, err := url.JoinPath("/v1", project, "findings")
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 	resp, err := client.sendRequest("POST", url, body, token)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return responseToAPIError(resp)
+		return nil, responseToAPIError(resp)
 	}
 
-	return nil
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseUploadFindingResponse(respBody, &findings.Findings[0]), nil
+}
+
+// parseUploadFindingResponse extracts the server-assigned identifiers
+// of the uploaded finding from the response body. If the server didn't
+// return the finding, the identifiers of the request are used.
+func parseUploadFindingResponse(body []byte, uploaded *Finding) *UploadedFinding {
+	result := &UploadedFinding{
+		Name:        uploaded.Name,
+		DisplayName: uploaded.DisplayName,
+	}
+
+	remoteFindings := Findings{}
+	err := json.Unmarshal(body, &remoteFindings)
+	if err != nil || len(remoteFindings.Findings) == 0 {
+		// Older servers respond with an empty body
+		return result
+	}
+
+	remote := remoteFindings.Findings[0]
+	if remote.Name != "" {
+		result.Name = remote.Name
+	}
+	if remote.DisplayName != "" {
+		result.DisplayName = remote.DisplayName
+	}
+	return result
 }
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// webhookTimeout is kept short because findings are sent to the webhook
+// while the fuzzer is running and we don't want slow endpoints to pile
+// up pending requests.
+const webhookTimeout = 10 * time.Second
+
+// ValidateWebhookURL checks that the given URL can be used as a webhook
+// endpoint.
+func ValidateWebhookURL(webhookURL string) error {
+	err := validateURL(webhookURL)
+	if err != nil {
+		return errors.WithMessagef(err, "Webhook '%s' is not a valid URL", webhookURL)
+	}
+	return nil
+}
+
+// PostToWebhook sends the JSON payload to the given URL via a POST
+// request. The headers are expected in the format "<name>: <value>".
+func (client *APIClient) PostToWebhook(webhookURL string, headers []string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req.Header.Set("User-Agent", client.UserAgent)
+	req.Header.Set("Content-Type", "application/json")
+	for _, header := range headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found {
+			return errors.Errorf("Invalid webhook header %q, expected format '<name>: <value>'", header)
+		}
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	transport, err := client.transport()
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{Transport: transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return WrapConnectionError(errors.WithStack(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return responseToAPIError(resp)
+	}
+
+	return nil
+}
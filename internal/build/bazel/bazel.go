@@ -30,6 +30,16 @@ type BuilderOptions struct {
 	Stderr     io.Writer
 	TempDir    string
 	Verbose    bool
+	// Additional environment variables of the form KEY=VALUE which
+	// are passed to the bazel build
+	BuildEnv []string
+	// The amount of RAM in MB which bazel may use for local build
+	// actions. The actions are executed by the bazel server, which
+	// might already be running, so the memory is limited via bazel's
+	// --local_ram_resources flag instead of build.Command. Note that
+	// this is no hard limit: bazel only doesn't schedule more local
+	// actions in parallel than it estimates to fit into the memory.
+	MemoryLimit uint
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -126,15 +136,23 @@ func (b *Builder) BuildForRun(fuzzTests []string) ([]*build.BuildResult, error)
 	if err != nil {
 		return nil, err
 	}
+	buildEnv, err = build.SetBuildEnv(buildEnv, b.BuildEnv)
+	if err != nil {
+		return nil, err
+	}
 	commonFlags := []string{
 		"--repo_env=CC=" + envutil.Getenv(buildEnv, "CC"),
 		"--repo_env=CXX=" + envutil.Getenv(buildEnv, "CXX"),
 		// Don't use the LLVM from Xcode
 		"--repo_env=BAZEL_USE_CPP_ONLY_TOOLCHAIN=1",
 	}
+	commonFlags = append(commonFlags, build.BazelBuildEnvFlags(b.BuildEnv)...)
 	if b.NumJobs != 0 {
 		commonFlags = append(commonFlags, "--jobs", fmt.Sprint(b.NumJobs))
 	}
+	if b.MemoryLimit != 0 {
+		commonFlags = append(commonFlags, "--local_ram_resources="+fmt.Sprint(b.MemoryLimit))
+	}
 
 	// Flags which should only be used for bazel run because they are
 	// not supported by the other bazel commands we use
@@ -223,6 +241,11 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 		return nil, err
 	}
 
+	env, err = build.SetBuildEnv(env, b.BuildEnv)
+	if err != nil {
+		return nil, err
+	}
+
 	// To avoid part of the loading and/or analysis phase to rerun, we
 	// use the same flags for all bazel commands (except for those which
 	// are not supported by all bazel commands we use).
@@ -238,9 +261,13 @@ func (b *Builder) BuildForBundle(sanitizers []string, fuzzTests []string) ([]*bu
 		// sanitizer is set to "undefined"
 		"--repo_env=SANITIZER=undefined",
 	}
+	commonFlags = append(commonFlags, build.BazelBuildEnvFlags(b.BuildEnv)...)
 	if b.NumJobs != 0 {
 		commonFlags = append(commonFlags, "--jobs", fmt.Sprint(b.NumJobs))
 	}
+	if b.MemoryLimit != 0 {
+		commonFlags = append(commonFlags, "--local_ram_resources="+fmt.Sprint(b.MemoryLimit))
+	}
 
 	// Flags which should only be used for bazel build
 	buildAndCQueryFlags := []string{
@@ -406,7 +433,7 @@ func (b *Builder) setLibFuzzerEnv(env []string) ([]string, error) {
 	var err error
 
 	// Set FUZZING_CFLAGS and FUZZING_CXXFLAGS.
-	cflags := build.LibFuzzerCFlags()
+	cflags := build.LibFuzzerCFlags(build.LibFuzzerSanitizers)
 	env, err = envutil.Setenv(env, "FUZZING_CFLAGS", strings.Join(cflags, " "))
 	if err != nil {
 		return nil, err
@@ -3,10 +3,13 @@ package build
 import (
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/stringutil"
 )
 
 // BuildResult contains fields which are needed to run the fuzz test
@@ -86,6 +89,35 @@ func CommonBuildEnv() ([]string, error) {
 	return env, nil
 }
 
+// SetBuildEnv sets the user-specified build environment variables,
+// which must be of the form KEY=VALUE, in env.
+func SetBuildEnv(env []string, buildEnv []string) ([]string, error) {
+	var err error
+	for _, e := range buildEnv {
+		key, val, found := strings.Cut(e, "=")
+		if !found || key == "" {
+			return nil, errors.Errorf("Invalid build environment variable, must be of the form KEY=VALUE: %s", e)
+		}
+		env, err = envutil.Setenv(env, key, val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+// BazelBuildEnvFlags returns the bazel flags which make the
+// user-specified build environment variables available to repository
+// rules (e.g. the C++ toolchain configuration) and build actions.
+// Bazel doesn't pass the client environment to either of them.
+func BazelBuildEnvFlags(buildEnv []string) []string {
+	var flags []string
+	for _, e := range buildEnv {
+		flags = append(flags, "--repo_env="+e, "--action_env="+e)
+	}
+	return flags
+}
+
 var commonCFlags = []string{
 	// Keep debug symbols
 	"-g",
@@ -100,11 +132,30 @@ var commonCFlags = []string{
 	"-UNDEBUG",
 }
 
-func LibFuzzerCFlags() []string {
+// LibFuzzerSanitizers are the sanitizers which fuzz tests are built
+// with by default. Any subset of them can be used.
+var LibFuzzerSanitizers = []string{"address", "undefined"}
+
+// ValidateSanitizers returns an error if any of the sanitizers is not
+// one of the LibFuzzerSanitizers.
+func ValidateSanitizers(sanitizers []string) error {
+	if len(sanitizers) == 0 {
+		return errors.New("No sanitizers specified")
+	}
+	for _, sanitizer := range sanitizers {
+		if !stringutil.Contains(LibFuzzerSanitizers, sanitizer) {
+			return errors.Errorf("Invalid sanitizer: %q (valid sanitizers: %s)",
+				sanitizer, strings.Join(LibFuzzerSanitizers, ", "))
+		}
+	}
+	return nil
+}
+
+func LibFuzzerCFlags(sanitizers []string) []string {
 	// These flags must not contain spaces, because the environment
 	// variables that are set to these flags are space separated.
 	// Note: Keep in sync with share/cmake/cifuzz-functions.cmake
-	return append(commonCFlags, []string{
+	cflags := append(commonCFlags, []string{
 		// ----- Flags used to build with libFuzzer -----
 		// Compile with edge coverage and compare instrumentation. We
 		// use fuzzer-no-link here instead of -fsanitize=fuzzer because
@@ -112,21 +163,27 @@ func LibFuzzerCFlags() []string {
 		// errors if the build includes tools which have a main function.
 		"-fsanitize=fuzzer-no-link",
 
-		// ----- Flags used to build with ASan -----
-		// Build with instrumentation for ASan and UBSan and link in
+		// ----- Flags used to build with ASan and UBSan -----
+		// Build with instrumentation for the sanitizers and link in
 		// their runtime
-		"-fsanitize=address,undefined",
-		// To support recovering from ASan findings
-		"-fsanitize-recover=address",
-		// Use additional error detectors for use-after-scope bugs
-		// TODO: Evaluate the slow down caused by this flag
-		// TODO: Check if there are other additional error detectors
-		//       which we want to use
-		"-fsanitize-address-use-after-scope",
-		// Disable source fortification, which is currently not supported
-		// in combination with ASan, see https://github.com/google/sanitizers/issues/247
-		"-U_FORTIFY_SOURCE",
+		"-fsanitize=" + strings.Join(sanitizers, ","),
 	}...)
+
+	if stringutil.Contains(sanitizers, "address") {
+		cflags = append(cflags, []string{
+			// To support recovering from ASan findings
+			"-fsanitize-recover=address",
+			// Use additional error detectors for use-after-scope bugs
+			// TODO: Evaluate the slow down caused by this flag
+			// TODO: Check if there are other additional error detectors
+			//       which we want to use
+			"-fsanitize-address-use-after-scope",
+			// Disable source fortification, which is currently not supported
+			// in combination with ASan, see https://github.com/google/sanitizers/issues/247
+			"-U_FORTIFY_SOURCE",
+		}...)
+	}
+	return cflags
 }
 
 func CoverageCFlags(clangVersion *semver.Version) []string {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/util/envutil"
 )
 
@@ -37,3 +38,76 @@ func TestCommonBuildEnv_ClangDontOverwrite(t *testing.T) {
 	assert.Equal(t, "/my/clang", envutil.Getenv(env, "CC"))
 	assert.Equal(t, "/my/clang++", envutil.Getenv(env, "CXX"))
 }
+
+func TestSetBuildEnv(t *testing.T) {
+	env, err := SetBuildEnv([]string{"CC=clang"}, []string{"CC=/my/clang", "CFLAGS=-O1 -g", "EMPTY="})
+	require.NoError(t, err)
+	assert.Equal(t, "/my/clang", envutil.Getenv(env, "CC"))
+	assert.Equal(t, "-O1 -g", envutil.Getenv(env, "CFLAGS"))
+	assert.Contains(t, env, "EMPTY=")
+
+	_, err = SetBuildEnv(nil, []string{"CC"})
+	require.Error(t, err)
+}
+
+func TestValidateSanitizers(t *testing.T) {
+	require.NoError(t, ValidateSanitizers([]string{"address"}))
+	require.NoError(t, ValidateSanitizers([]string{"undefined"}))
+	require.NoError(t, ValidateSanitizers([]string{"address", "undefined"}))
+	require.Error(t, ValidateSanitizers(nil))
+	require.Error(t, ValidateSanitizers([]string{"address", "memory"}))
+}
+
+func TestLibFuzzerCFlags(t *testing.T) {
+	cflags := LibFuzzerCFlags([]string{"undefined"})
+	assert.Contains(t, cflags, "-fsanitize=undefined")
+	assert.NotContains(t, cflags, "-fsanitize-recover=address")
+
+	cflags = LibFuzzerCFlags(LibFuzzerSanitizers)
+	assert.Contains(t, cflags, "-fsanitize=address,undefined")
+	assert.Contains(t, cflags, "-fsanitize-recover=address")
+}
+
+func TestCommand(t *testing.T) {
+	cmd := Command(0, "echo", "foo")
+	assert.Equal(t, []string{"echo", "foo"}, cmd.Args)
+
+	if !MemoryLimitSupported() {
+		t.Skip()
+	}
+
+	cmd = Command(1024, "echo", "foo bar")
+	assert.Equal(t, []string{"/bin/sh", "-c", `ulimit -v 1048576 && exec "$@"`, "sh", "echo", "foo bar"}, cmd.Args)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "foo bar\n", string(out))
+
+	// A program which exceeds the limit fails
+	cmd = Command(1, "/bin/sh", "-c", "true")
+	require.Error(t, cmd.Run())
+}
+
+func TestValidateMemoryLimit(t *testing.T) {
+	limit, err := ValidateMemoryLimit(0, config.BuildSystemNodeJS)
+	require.NoError(t, err)
+	assert.Zero(t, limit)
+
+	_, err = ValidateMemoryLimit(1024, config.BuildSystemNodeJS)
+	require.Error(t, err)
+
+	// The JVM heap and bazel's local RAM resources can be limited on
+	// all platforms
+	for _, buildSystem := range []string{config.BuildSystemMaven, config.BuildSystemGradle, config.BuildSystemBazel} {
+		limit, err = ValidateMemoryLimit(1024, buildSystem)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1024), limit)
+	}
+
+	limit, err = ValidateMemoryLimit(1024, config.BuildSystemCMake)
+	require.NoError(t, err)
+	if MemoryLimitSupported() {
+		assert.Equal(t, uint(1024), limit)
+	} else {
+		assert.Zero(t, limit)
+	}
+}
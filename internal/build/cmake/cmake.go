@@ -51,6 +51,15 @@ type BuilderOptions struct {
 	Stdout     io.Writer
 	Stderr     io.Writer
 	BuildOnly  bool
+	// Additional environment variables of the form KEY=VALUE which
+	// are set when running CMake
+	BuildEnv []string
+	// The directory below which the build directory is created.
+	// Defaults to the project directory.
+	OutputRoot string
+	// The maximum virtual memory in MB of each build process, see
+	// build.Command. No limit is applied if it's 0.
+	MemoryLimit uint
 
 	FindRuntimeDeps bool
 }
@@ -65,6 +74,10 @@ func (opts *BuilderOptions) Validate() error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+
+	if opts.OutputRoot == "" {
+		opts.OutputRoot = opts.ProjectDir
+	}
 	return nil
 }
 
@@ -95,6 +108,10 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 	if err != nil {
 		return nil, err
 	}
+	b.env, err = build.SetBuildEnv(b.env, opts.BuildEnv)
+	if err != nil {
+		return nil, err
+	}
 
 	return b, nil
 }
@@ -138,7 +155,7 @@ func (b *Builder) BuildDir() (string, error) {
 		buildDir = fmt.Sprintf("%s-%s", sanitizersSegment, hashString)
 	}
 
-	buildDir = filepath.Join(b.ProjectDir, ".cifuzz-build", "libfuzzer", buildDir)
+	buildDir = filepath.Join(b.OutputRoot, ".cifuzz-build", "libfuzzer", buildDir)
 
 	return buildDir, nil
 }
@@ -187,7 +204,7 @@ func (b *Builder) Configure() error {
 	args = append(args, b.Args...)
 	args = append(args, b.ProjectDir)
 
-	cmd := exec.Command("cmake", args...)
+	cmd := build.Command(b.MemoryLimit, "cmake", args...)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	cmd.Env = b.env
@@ -221,7 +238,7 @@ func (b *Builder) Build(fuzzTests []string) ([]*build.CBuildResult, error) {
 		}
 	}
 
-	cmd := exec.Command("cmake", flags...)
+	cmd := build.Command(b.MemoryLimit, "cmake", flags...)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	cmd.Env = b.env
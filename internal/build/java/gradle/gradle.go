@@ -49,6 +49,19 @@ type BuilderOptions struct {
 	Parallel   ParallelOptions
 	Stdout     io.Writer
 	Stderr     io.Writer
+	// The maximum heap size in MB of the Gradle daemon, which is passed
+	// via the org.gradle.jvmargs property. No limit is applied if it's 0.
+	MemoryLimit uint
+	// Run Gradle in offline mode, which requires all dependencies to be
+	// available in the Gradle cache
+	Offline bool
+}
+
+// commandOptions are the options of the Gradle invocations which build
+// the project and resolve its dependencies
+type commandOptions struct {
+	memoryLimit uint
+	offline     bool
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -86,7 +99,10 @@ func (b *Builder) Build() (*build.BuildResult, error) {
 	}
 	log.Debugf("Found gradle plugin version: %s", version)
 
-	deps, err := GetDependencies(b.ProjectDir)
+	deps, err := getDependencies(b.ProjectDir, commandOptions{
+		memoryLimit: b.MemoryLimit,
+		offline:     b.Offline,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -101,7 +117,10 @@ func (b *Builder) Build() (*build.BuildResult, error) {
 }
 
 func (b *Builder) GradlePluginVersion() (string, error) {
-	cmd, err := buildGradleCommand(b.ProjectDir, []string{"cifuzzPrintPluginVersion", "-q"})
+	cmd, err := buildGradleCommandWithOptions(b.ProjectDir, []string{"cifuzzPrintPluginVersion", "-q"}, commandOptions{
+		memoryLimit: b.MemoryLimit,
+		offline:     b.Offline,
+	})
 	if err != nil {
 		return "", err
 	}
@@ -128,8 +147,8 @@ func (b *Builder) GradlePluginVersion() (string, error) {
 	return strings.TrimPrefix(string(output), "cifuzz.plugin.version="), nil
 }
 
-func GetDependencies(projectDir string) ([]string, error) {
-	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintTestClasspath", "-q"})
+func getDependencies(projectDir string, cmdOpts commandOptions) ([]string, error) {
+	cmd, err := buildGradleCommandWithOptions(projectDir, []string{"cifuzzPrintTestClasspath", "-q"}, cmdOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -174,20 +193,33 @@ func GetGradleCommand(projectDir string) (string, error) {
 	return gradleCmd, nil
 }
 
-func buildGradleCommand(projectDir string, args []string) (*exec.Cmd, error) {
+func buildGradleCommand(projectDir string, args []string, offline bool) (*exec.Cmd, error) {
+	return buildGradleCommandWithOptions(projectDir, args, commandOptions{offline: offline})
+}
+
+func buildGradleCommandWithOptions(projectDir string, args []string, opts commandOptions) (*exec.Cmd, error) {
 	gradleCmd, err := GetGradleCommand(projectDir)
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.offline {
+		args = append(args, "--offline")
+	}
+	if opts.memoryLimit != 0 {
+		// Limiting the virtual memory via build.Command would break
+		// the JVM, so the heap of the Gradle daemon is limited instead.
+		// This overrides the org.gradle.jvmargs of the project.
+		args = append(args, "-Dorg.gradle.jvmargs="+build.JVMMaxHeapFlag(opts.memoryLimit))
+	}
 	cmd := exec.Command(gradleCmd, args...)
 	cmd.Dir = projectDir
 
 	return cmd, nil
 }
 
-func GetBuildDirectory(projectDir string) (string, error) {
-	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintBuildDir", "-q"})
+func GetBuildDirectory(projectDir string, offline bool) (string, error) {
+	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintBuildDir", "-q"}, offline)
 	if err != nil {
 		return "", nil
 	}
@@ -206,8 +238,8 @@ func GetBuildDirectory(projectDir string) (string, error) {
 	return buildDir, nil
 }
 
-func GetRootDirectory(projectDir string) (string, error) {
-	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintRootDir", "-q"})
+func GetRootDirectory(projectDir string, offline bool) (string, error) {
+	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintRootDir", "-q"}, offline)
 	if err != nil {
 		return "", nil
 	}
@@ -226,8 +258,8 @@ func GetRootDirectory(projectDir string) (string, error) {
 	return rootDir, nil
 }
 
-func GetTestSourceSets(projectDir string) ([]string, error) {
-	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintTestSourceFolders", "-q"})
+func GetTestSourceSets(projectDir string, offline bool) ([]string, error) {
+	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintTestSourceFolders", "-q"}, offline)
 	if err != nil {
 		return nil, err
 	}
@@ -259,8 +291,8 @@ func GetTestSourceSets(projectDir string) ([]string, error) {
 	return sourceSets, nil
 }
 
-func GetMainSourceSets(projectDir string) ([]string, error) {
-	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintMainSourceFolders", "-q"})
+func GetMainSourceSets(projectDir string, offline bool) ([]string, error) {
+	cmd, err := buildGradleCommand(projectDir, []string{"cifuzzPrintMainSourceFolders", "-q"}, offline)
 	if err != nil {
 		return nil, err
 	}
@@ -8,11 +8,13 @@ import (
 	"code-intelligence.com/cifuzz/internal/config"
 )
 
-func SourceDirs(projectDir string, buildSystem string) ([]string, error) {
+// SourceDirs returns the main source directories of the project. The
+// build system is run in offline mode if offline is set.
+func SourceDirs(projectDir string, buildSystem string, offline bool) ([]string, error) {
 	if buildSystem == config.BuildSystemGradle {
-		return gradle.GetMainSourceSets(projectDir)
+		return gradle.GetMainSourceSets(projectDir, offline)
 	} else if buildSystem == config.BuildSystemMaven {
-		sourceDir, err := maven.GetSourceDir(projectDir)
+		sourceDir, err := maven.GetSourceDir(projectDir, offline)
 		if err != nil {
 			return nil, err
 		}
@@ -24,11 +26,13 @@ func SourceDirs(projectDir string, buildSystem string) ([]string, error) {
 	return []string{filepath.Join(projectDir, "src", "main")}, nil
 }
 
-func TestDirs(projectDir string, buildSystem string) ([]string, error) {
+// TestDirs returns the test source directories of the project. The
+// build system is run in offline mode if offline is set.
+func TestDirs(projectDir string, buildSystem string, offline bool) ([]string, error) {
 	if buildSystem == config.BuildSystemGradle {
-		return gradle.GetTestSourceSets(projectDir)
+		return gradle.GetTestSourceSets(projectDir, offline)
 	} else if buildSystem == config.BuildSystemMaven {
-		testDir, err := maven.GetTestDir(projectDir)
+		testDir, err := maven.GetTestDir(projectDir, offline)
 		if err != nil {
 			return nil, err
 		}
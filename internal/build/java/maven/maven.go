@@ -34,6 +34,19 @@ type BuilderOptions struct {
 	Parallel   ParallelOptions
 	Stdout     io.Writer
 	Stderr     io.Writer
+	// The maximum heap size in MB of the Maven JVM, which is passed
+	// via MAVEN_OPTS. No limit is applied if it's 0.
+	MemoryLimit uint
+	// Run Maven in offline mode, which requires all dependencies to be
+	// available in the local repository
+	Offline bool
+}
+
+// commandOptions are the options of the Maven invocations which build
+// the project and resolve its dependencies
+type commandOptions struct {
+	memoryLimit uint
+	offline     bool
 }
 
 func (opts *BuilderOptions) Validate() error {
@@ -65,7 +78,10 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 }
 
 func (b *Builder) Build() (*build.BuildResult, error) {
-	deps, err := GetDependencies(b.ProjectDir, b.Parallel)
+	deps, err := getDependencies(b.ProjectDir, b.Parallel, commandOptions{
+		memoryLimit: b.MemoryLimit,
+		offline:     b.Offline,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +95,7 @@ func (b *Builder) Build() (*build.BuildResult, error) {
 	return result, nil
 }
 
-func GetDependencies(projectDir string, parallel ParallelOptions) ([]string, error) {
+func getDependencies(projectDir string, parallel ParallelOptions, cmdOpts commandOptions) ([]string, error) {
 	var flags []string
 	if parallel.Enabled {
 		flags = append(flags, "-T")
@@ -92,7 +108,7 @@ func GetDependencies(projectDir string, parallel ParallelOptions) ([]string, err
 	}
 
 	args := append(flags, "test-compile", "-DcifuzzPrintTestClasspath")
-	cmd := runMaven(projectDir, args)
+	cmd := runMavenWithOptions(projectDir, args, cmdOpts)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, cmdutils.WrapExecError(errors.WithStack(err), cmd)
@@ -114,11 +130,24 @@ func GetDependencies(projectDir string, parallel ParallelOptions) ([]string, err
 	return deps, nil
 }
 
-func runMaven(projectDir string, args []string) *exec.Cmd {
+func runMaven(projectDir string, args []string, offline bool) *exec.Cmd {
+	return runMavenWithOptions(projectDir, args, commandOptions{offline: offline})
+}
+
+func runMavenWithOptions(projectDir string, args []string, opts commandOptions) *exec.Cmd {
 	// remove color and transfer progress from output
 	args = append(args, "-B", "--no-transfer-progress")
+	if opts.offline {
+		args = append(args, "--offline")
+	}
 	cmd := exec.Command("mvn", args...) // TODO find ./mvnw if available (unify with MavenRunner in coverage.go)
 	cmd.Dir = projectDir
+	if opts.memoryLimit != 0 {
+		// Limiting the virtual memory via build.Command would break
+		// the JVM, so the heap of the Maven JVM is limited instead
+		mavenOpts := strings.TrimSpace(os.Getenv("MAVEN_OPTS") + " " + build.JVMMaxHeapFlag(opts.memoryLimit))
+		cmd.Env = append(os.Environ(), "MAVEN_OPTS="+mavenOpts)
+	}
 
 	log.Debugf("Working directory: %s", cmd.Dir)
 	log.Debugf("Command: %s", cmd.String())
@@ -126,8 +155,8 @@ func runMaven(projectDir string, args []string) *exec.Cmd {
 	return cmd
 }
 
-func GetBuildDirectory(projectDir string) (string, error) {
-	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintBuildDir"})
+func GetBuildDirectory(projectDir string, offline bool) (string, error) {
+	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintBuildDir"}, offline)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
@@ -144,8 +173,8 @@ func GetBuildDirectory(projectDir string) (string, error) {
 
 // GetTestDir returns the value of <testSourceDirectory> for the fuzz project
 // (which may be one of the sub-modules in a multi-project)
-func GetTestDir(projectDir string) (string, error) {
-	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintTestSourceFolders"})
+func GetTestDir(projectDir string, offline bool) (string, error) {
+	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintTestSourceFolders"}, offline)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", cmdutils.WrapExecError(errors.WithStack(err), cmd)
@@ -172,8 +201,8 @@ func GetTestDir(projectDir string) (string, error) {
 
 // GetSourceDir returns the value of <sourceDirectory> for the fuzz project
 // (which may be one of the sub-modules in a multi-project)
-func GetSourceDir(projectDir string) (string, error) {
-	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintMainSourceFolders"})
+func GetSourceDir(projectDir string, offline bool) (string, error) {
+	cmd := runMaven(projectDir, []string{"validate", "-q", "-DcifuzzPrintMainSourceFolders"}, offline)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", errors.WithMessagef(err, "Failed to get source directory of project")
@@ -14,7 +14,7 @@ import (
 func Test_GetTestDir(t *testing.T) {
 	projectDir := shared.CopyTestdataDir(t, "maven")
 
-	testDir, err := GetTestDir(projectDir)
+	testDir, err := GetTestDir(projectDir, false)
 	require.NoError(t, err)
 	assert.Equal(t, filepath.Join(projectDir, "src", "test", "java"), testDir)
 
@@ -26,7 +26,7 @@ func Test_GetTestDir(t *testing.T) {
 		"\t<build>",
 		true,
 	)
-	testDir, err = GetTestDir(projectDir)
+	testDir, err = GetTestDir(projectDir, false)
 	require.NoError(t, err)
 	assert.Equal(t, filepath.Join(projectDir, newTestDir), testDir)
 }
@@ -34,7 +34,7 @@ func Test_GetTestDir(t *testing.T) {
 func Test_GetSourceDir(t *testing.T) {
 	projectDir := shared.CopyTestdataDir(t, "maven")
 
-	sourceDir, err := GetSourceDir(projectDir)
+	sourceDir, err := GetSourceDir(projectDir, false)
 	require.NoError(t, err)
 	assert.Equal(t, filepath.Join(projectDir, "src", "main", "java"), sourceDir)
 
@@ -46,7 +46,26 @@ func Test_GetSourceDir(t *testing.T) {
 		"\t<build>",
 		true,
 	)
-	sourceDir, err = GetSourceDir(projectDir)
+	sourceDir, err = GetSourceDir(projectDir, false)
 	require.NoError(t, err)
 	assert.Equal(t, filepath.Join(projectDir, newSourceDir), sourceDir)
 }
+
+func Test_RunMavenWithOptions(t *testing.T) {
+	cmd := runMavenWithOptions("", []string{"test-compile"}, commandOptions{})
+	assert.NotContains(t, cmd.Args, "--offline")
+
+	cmd = runMavenWithOptions("", []string{"test-compile"}, commandOptions{offline: true})
+	assert.Contains(t, cmd.Args, "--offline")
+
+	// The commands which query the project configuration are run in
+	// offline mode as well
+	cmd = runMaven("", []string{"validate"}, true)
+	assert.Contains(t, cmd.Args, "--offline")
+
+	// The memory is limited via the heap of the Maven JVM
+	t.Setenv("MAVEN_OPTS", "-Dfoo=bar")
+	cmd = runMavenWithOptions("", []string{"test-compile"}, commandOptions{memoryLimit: 1024})
+	assert.Equal(t, "mvn", filepath.Base(cmd.Args[0]))
+	assert.Contains(t, cmd.Env, "MAVEN_OPTS=-Dfoo=bar -Xmx1024m")
+}
@@ -0,0 +1,66 @@
+package build
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// MemoryLimitSupported returns true if the memory of native build
+// processes can be limited via Command on the current platform.
+func MemoryLimitSupported() bool {
+	return runtime.GOOS == "linux"
+}
+
+// ValidateMemoryLimit checks that the memory limit in MB specified via
+// --build-memory-limit can be applied to builds of the build system and
+// returns the limit to use. The memory of native builds can only be
+// limited on Linux, on other platforms a warning is printed and 0 is
+// returned, which means that the memory is not limited.
+func ValidateMemoryLimit(memoryLimitMB uint, buildSystem string) (uint, error) {
+	if memoryLimitMB == 0 {
+		return 0, nil
+	}
+	switch buildSystem {
+	case config.BuildSystemNodeJS:
+		msg := fmt.Sprintf("Flag \"build-memory-limit\" is not supported for build system type %q", buildSystem)
+		return 0, cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	case config.BuildSystemCMake, config.BuildSystemOther:
+		if !MemoryLimitSupported() {
+			log.Warn("Limiting the memory of the build is not supported on this platform, ignoring flag \"build-memory-limit\"")
+			return 0, nil
+		}
+	}
+	return memoryLimitMB, nil
+}
+
+// Command returns a command which runs the specified program with the
+// virtual memory of the process and each of its child processes limited
+// to memoryLimitMB megabytes via "ulimit -v" (RLIMIT_AS), so that a
+// runaway compiler fails instead of exhausting the memory of the host.
+// Note that the limit applies to each process separately. It must only
+// be used for native builds, because the JVM reserves much more virtual
+// memory than it uses, see JVMMaxHeapFlag instead. If memoryLimitMB is 0
+// or limiting the memory is not supported on the current platform, the
+// program is run without a limit.
+func Command(memoryLimitMB uint, name string, args ...string) *exec.Cmd {
+	if memoryLimitMB == 0 || !MemoryLimitSupported() {
+		return exec.Command(name, args...)
+	}
+	script := fmt.Sprintf(`ulimit -v %d && exec "$@"`, uint64(memoryLimitMB)*1024)
+	return exec.Command("/bin/sh", append([]string{"-c", script, "sh", name}, args...)...)
+}
+
+// JVMMaxHeapFlag returns the JVM flag which limits the heap of the JVM
+// to memoryLimitMB megabytes. It's used to limit the memory of Maven
+// and Gradle builds, which is supported on all platforms. The limit
+// doesn't include the memory which the JVM uses in addition to the heap.
+func JVMMaxHeapFlag(memoryLimitMB uint) string {
+	return fmt.Sprintf("-Xmx%dm", memoryLimitMB)
+}
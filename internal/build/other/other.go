@@ -48,11 +48,25 @@ const (
 	EnvFuzzTestLDFlags string = "FUZZ_TEST_LDFLAGS"
 )
 
+// BuildFlagsEnvVars are the environment variables holding the compiler
+// and linker flags which are set by SetLibFuzzerEnv and SetCoverageEnv.
+var BuildFlagsEnvVars = []string{
+	"CFLAGS",
+	"CXXFLAGS",
+	"LDFLAGS",
+	EnvFuzzTestCFlags,
+	EnvFuzzTestCXXFlags,
+	EnvFuzzTestLDFlags,
+}
+
 type BuilderOptions struct {
 	ProjectDir   string
 	BuildCommand string
 	CleanCommand string
 	Sanitizers   []string
+	// The maximum virtual memory in MB of each build process, see
+	// build.Command. No limit is applied if it's 0.
+	MemoryLimit uint
 
 	RunfilesFinder runfiles.RunfilesFinder
 	Stdout         io.Writer
@@ -70,6 +84,16 @@ func (opts *BuilderOptions) Validate() error {
 		return errors.WithStack(err)
 	}
 
+	// Check that the sanitizers are valid
+	if len(opts.Sanitizers) == 0 {
+		opts.Sanitizers = build.LibFuzzerSanitizers
+	} else if !(len(opts.Sanitizers) == 1 && opts.Sanitizers[0] == "coverage") {
+		err = build.ValidateSanitizers(opts.Sanitizers)
+		if err != nil {
+			return err
+		}
+	}
+
 	if opts.RunfilesFinder == nil {
 		opts.RunfilesFinder = runfiles.Finder
 	}
@@ -100,12 +124,7 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 	if len(opts.Sanitizers) == 1 && opts.Sanitizers[0] == "coverage" {
 		b.env, err = SetCoverageEnv(b.env, b.RunfilesFinder)
 	} else {
-		for _, sanitizer := range opts.Sanitizers {
-			if sanitizer != "address" && sanitizer != "undefined" {
-				panic(fmt.Sprintf("Invalid sanitizer: %q", sanitizer))
-			}
-		}
-		b.env, err = SetLibFuzzerEnv(b.env, b.RunfilesFinder)
+		b.env, err = SetLibFuzzerEnv(b.env, b.RunfilesFinder, opts.Sanitizers)
 	}
 	if err != nil {
 		return nil, err
@@ -114,6 +133,16 @@ func NewBuilder(opts *BuilderOptions) (*Builder, error) {
 	return b, nil
 }
 
+// BuildFlags returns the compiler and linker flags which are passed to
+// the build command, in the form "NAME=value".
+func (b *Builder) BuildFlags() []string {
+	var flags []string
+	for _, key := range BuildFlagsEnvVars {
+		flags = append(flags, fmt.Sprintf("%s=%s", key, envutil.Getenv(b.env, key)))
+	}
+	return flags
+}
+
 // Build builds the specified fuzz test via the user-specified build command
 func (b *Builder) Build(fuzzTest string) (*build.CBuildResult, error) {
 	var err error
@@ -124,7 +153,7 @@ func (b *Builder) Build(fuzzTest string) (*build.CBuildResult, error) {
 	}
 
 	// Run the build command
-	cmd := exec.Command("/bin/sh", "-c", b.BuildCommand)
+	cmd := build.Command(b.MemoryLimit, "/bin/sh", "-c", b.BuildCommand)
 	cmd.Stdout = b.Stdout
 	cmd.Stderr = b.Stderr
 	cmd.Env = b.env
@@ -230,7 +259,7 @@ func (b *Builder) setCleanCommandEnv() error {
 	return nil
 }
 
-func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, error) {
+func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder, sanitizers []string) ([]string, error) {
 	var err error
 	env, err = setEnvWithDebugMsg(env, EnvBuildStep, "fuzzing")
 	if err != nil {
@@ -238,7 +267,7 @@ func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, er
 	}
 
 	// Set CFLAGS and CXXFLAGS
-	cflags := build.LibFuzzerCFlags()
+	cflags := build.LibFuzzerCFlags(sanitizers)
 	env, err = setEnvWithDebugMsg(env, "CFLAGS", strings.Join(cflags, " "))
 	if err != nil {
 		return nil, err
@@ -249,9 +278,9 @@ func SetLibFuzzerEnv(env []string, finder runfiles.RunfilesFinder) ([]string, er
 	}
 
 	ldflags := []string{
-		// ----- Flags used to build with ASan -----
-		// Link ASan and UBSan runtime
-		"-fsanitize=address,undefined",
+		// ----- Flags used to build with ASan and UBSan -----
+		// Link the runtime of the sanitizers
+		"-fsanitize=" + strings.Join(sanitizers, ","),
 	}
 	env, err = setEnvWithDebugMsg(env, "LDFLAGS", strings.Join(ldflags, " "))
 	if err != nil {
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/builder"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/mocks"
@@ -88,7 +90,7 @@ func TestNoQuotesOnEnv(t *testing.T) {
 	require.NoError(t, err)
 
 	var env []string
-	env, err = SetLibFuzzerEnv(env, finder)
+	env, err = SetLibFuzzerEnv(env, finder, build.LibFuzzerSanitizers)
 	require.NoError(t, err)
 	assert.NotContains(t, envutil.Getenv(env, EnvFuzzTestCFlags), "'")
 	assert.NotContains(t, envutil.Getenv(env, EnvFuzzTestCXXFlags), "'")
@@ -98,3 +100,21 @@ func TestNoQuotesOnEnv(t *testing.T) {
 	assert.NotContains(t, envutil.Getenv(env, EnvFuzzTestCFlags), "'")
 	assert.NotContains(t, envutil.Getenv(env, EnvFuzzTestCXXFlags), "'")
 }
+
+func TestBuildFlags(t *testing.T) {
+	repoRoot, err := builder.FindProjectDir()
+	require.NoError(t, err)
+	projectDir := filepath.Join(repoRoot, "internal", "build", "other", "testdata")
+
+	b, err := NewBuilder(&BuilderOptions{
+		ProjectDir:     projectDir,
+		BuildCommand:   "true",
+		RunfilesFinder: defaultFinderMock(t, repoRoot),
+	})
+	require.NoError(t, err)
+
+	flags := b.BuildFlags()
+	require.Len(t, flags, len(BuildFlagsEnvVars))
+	assert.Contains(t, flags, "LDFLAGS=-fsanitize="+strings.Join(build.LibFuzzerSanitizers, ","))
+	assert.Contains(t, flags, fmt.Sprintf("%s=-I%s", EnvFuzzTestCFlags, filepath.Join(repoRoot, "include")))
+}
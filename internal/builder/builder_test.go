@@ -28,6 +28,7 @@ func TestIntegration_Version(t *testing.T) {
 		Coverage:  true,
 	})
 	require.NoError(t, err)
+	t.Cleanup(builder.Cleanup)
 
 	err = builder.BuildCIFuzz()
 	require.NoError(t, err)
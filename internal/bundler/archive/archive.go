@@ -2,12 +2,19 @@ package archive
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/pkg/log"
@@ -15,6 +22,28 @@ import (
 	"code-intelligence.com/cifuzz/util/fileutil"
 )
 
+// The supported compression formats of the tar archive
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionNone = "none"
+)
+
+var Compressions = []string{CompressionGzip, CompressionZstd, CompressionNone}
+
+// Extension returns the file extension of a tar archive with the given
+// compression. An empty compression defaults to gzip.
+func Extension(compression string) string {
+	switch compression {
+	case CompressionNone:
+		return ".tar"
+	case CompressionZstd:
+		return ".tar.zst"
+	default:
+		return ".tar.gz"
+	}
+}
+
 type ArchiveWriter interface {
 	Close() error
 	WriteFile(string, string) error
@@ -49,43 +78,134 @@ func (w *NullArchiveWriter) Headers() []*tar.Header {
 	return []*tar.Header{}
 }
 
-// TarArchiveWriter provides functions to create a gzip-compressed tar archive.
+// TarArchiveWriter provides functions to create a compressed tar archive.
+// Its methods can be called from multiple goroutines, the entries are
+// then written in the order in which the calls acquire the writer,
+// unless the reproducible mode is enabled.
 type TarArchiveWriter struct {
-	*tar.Writer
+	tw *tar.Writer
+	// mu serializes the writes to the archive and the accesses to the
+	// manifest and headers
+	mu         sync.Mutex
 	manifest   map[string]string
 	headers    []*tar.Header
-	gzipWriter *gzip.Writer
+	compressor io.WriteCloser
+	splitter   *splitWriter
+
+	// In reproducible mode, the entries are only written on Close, in
+	// sorted order
+	reproducible bool
+	pending      []*pendingEntry
 }
 
+// pendingEntry is an entry of a reproducible archive which is written
+// on Close. sourcePath is empty for directories and hard links.
+type pendingEntry struct {
+	header     *tar.Header
+	sourcePath string
+}
+
+// NewTarArchiveWriter creates a writer for a gzip-compressed tar archive
+// or, if compress is false, for an uncompressed tar archive.
 func NewTarArchiveWriter(w io.Writer, compress bool) *TarArchiveWriter {
-	var gzipWriter *gzip.Writer
-	var writer *tar.Writer
+	if !compress {
+		return newTarArchiveWriter(w, nil)
+	}
+	gzipWriter := gzip.NewWriter(w)
+	return newTarArchiveWriter(gzipWriter, gzipWriter)
+}
 
-	if compress {
-		gzipWriter = gzip.NewWriter(w)
-		writer = tar.NewWriter(gzipWriter)
-	} else {
-		writer = tar.NewWriter(w)
+// NewTarArchiveWriterWithCompression creates a writer for a tar archive
+// with the given compression, which must be one of Compressions. An
+// empty compression defaults to gzip.
+func NewTarArchiveWriterWithCompression(w io.Writer, compression string) (*TarArchiveWriter, error) {
+	switch compression {
+	case "", CompressionGzip:
+		return NewTarArchiveWriter(w, true), nil
+	case CompressionNone:
+		return NewTarArchiveWriter(w, false), nil
+	case CompressionZstd:
+		zstdWriter, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return newTarArchiveWriter(zstdWriter, zstdWriter), nil
+	default:
+		return nil, errors.Errorf("Unknown compression %q", compression)
 	}
+}
+
+// NewSplitTarArchiveWriter creates a writer for a tar archive with the
+// given compression, which is written to path if it's at most splitSize
+// bytes large. Otherwise, it's split into multiple parts of at most
+// splitSize bytes (see PartPath) and a manifest is written to path +
+// SplitManifestSuffix. Archive entries are never spread across parts,
+// so writing an entry which exceeds splitSize after compression returns
+// an error.
+func NewSplitTarArchiveWriter(path, compression string, splitSize int64) (*TarArchiveWriter, error) {
+	if splitSize <= 0 {
+		return nil, errors.Errorf("Invalid split size %d", splitSize)
+	}
+	if compression == "" {
+		compression = CompressionGzip
+	}
+	splitter, err := newSplitWriter(path, compression, splitSize)
+	if err != nil {
+		return nil, err
+	}
+	w := newTarArchiveWriter(splitter, splitter)
+	w.splitter = splitter
+	return w, nil
+}
 
+func newTarArchiveWriter(w io.Writer, compressor io.WriteCloser) *TarArchiveWriter {
 	return &TarArchiveWriter{
-		Writer:     writer,
+		tw:         tar.NewWriter(w),
 		manifest:   make(map[string]string),
-		gzipWriter: gzipWriter,
+		compressor: compressor,
 	}
 }
 
-// Close closes the tar writer and the gzip writer. It does not close
-// the underlying io.Writer.
+// SetReproducible enables or disables the reproducible mode, which must
+// be done before any entry is written. In reproducible mode, archives
+// written from the same files are byte-identical: The modification
+// times and owners of the entries are normalized and the entries are
+// written in sorted order when the writer is closed, so the source files
+// must not be removed before that.
+func (w *TarArchiveWriter) SetReproducible(reproducible bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.reproducible = reproducible
+}
+
+// IsSplit returns true if the archive was split into multiple parts,
+// which is only known after Close was called. In that case, the archive
+// consists of the manifest at path + SplitManifestSuffix and its parts.
+func (w *TarArchiveWriter) IsSplit() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.splitter != nil && w.splitter.split
+}
+
+// Close closes the tar writer and the compressing writer. It does not
+// close the underlying io.Writer.
 func (w *TarArchiveWriter) Close() error {
-	var err error
-	err = w.Writer.Close()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.writePendingEntries()
+	if err == nil {
+		err = w.tw.Close()
+	}
 	if err != nil {
+		if w.splitter != nil {
+			w.splitter.cleanup()
+		}
 		return errors.WithStack(err)
 	}
 
-	if w.gzipWriter != nil {
-		err = w.gzipWriter.Close()
+	if w.compressor != nil {
+		err = w.compressor.Close()
 	}
 
 	if err != nil {
@@ -102,6 +222,8 @@ func (w *TarArchiveWriter) WriteFile(archivePath string, sourcePath string) erro
 	if fileutil.IsDir(sourcePath) {
 		return errors.Errorf("file is a directory: %s", sourcePath)
 	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	return w.writeFileOrEmptyDir(archivePath, sourcePath)
 }
 
@@ -141,32 +263,49 @@ func (w *TarArchiveWriter) writeFileOrEmptyDir(archivePath string, sourcePath st
 		return errors.WithStack(err)
 	}
 	header.Name = archivePath
-	err = w.WriteHeader(header)
+	if w.reproducible {
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return errors.Errorf("not a regular file: %s", sourcePath)
+		}
+		normalizeHeader(header)
+		entry := &pendingEntry{header: header}
+		if !info.IsDir() {
+			entry.sourcePath = sourcePath
+			w.manifest[archivePath] = sourcePath
+		}
+		w.pending = append(w.pending, entry)
+		w.headers = append(w.headers, header)
+		return nil
+	}
+	err = w.tw.WriteHeader(header)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	w.headers = append(w.headers, header)
 
 	if info.IsDir() {
-		return nil
+		return w.endEntry(sourcePath)
 	}
 	if !info.Mode().IsRegular() {
 		return errors.Errorf("not a regular file: %s", sourcePath)
 	}
 
-	_, err = io.Copy(w.Writer, f)
+	_, err = io.Copy(w.tw, f)
 	if err != nil {
 		return errors.Wrapf(err, "failed to add file to archive: %s", sourcePath)
 	}
 
 	w.manifest[archivePath] = sourcePath
-	return nil
+	return w.endEntry(sourcePath)
 }
 
 // WriteHardLink adds a hard link header to the archive. When the
 // archive is extracted, a hard link to target with the name linkname is
 // created.
 func (w *TarArchiveWriter) WriteHardLink(target string, linkname string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	existingAbsPath, conflict := w.manifest[linkname]
 	if conflict {
 		return errors.Errorf("conflict for archive path %q: %q and %q", target, existingAbsPath, linkname)
@@ -177,17 +316,107 @@ func (w *TarArchiveWriter) WriteHardLink(target string, linkname string) error {
 		Name:     linkname,
 		Linkname: target,
 	}
-	err := w.WriteHeader(header)
+	if w.reproducible {
+		normalizeHeader(header)
+		w.pending = append(w.pending, &pendingEntry{header: header})
+		w.manifest[target] = linkname
+		return nil
+	}
+	err := w.tw.WriteHeader(header)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	w.manifest[target] = linkname
+	return w.endEntry(linkname)
+}
+
+// writePendingEntries writes the entries of a reproducible archive
+// sorted by name. Hard links are written last, because the entries they
+// link to must be extracted first.
+func (w *TarArchiveWriter) writePendingEntries() error {
+	slices.SortStableFunc(w.pending, func(a, b *pendingEntry) int {
+		aIsLink := a.header.Typeflag == tar.TypeLink
+		bIsLink := b.header.Typeflag == tar.TypeLink
+		if aIsLink != bIsLink {
+			if aIsLink {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.header.Name, b.header.Name)
+	})
+
+	for _, entry := range w.pending {
+		err := w.writePendingEntry(entry)
+		if err != nil {
+			return err
+		}
+	}
+	w.pending = nil
 	return nil
 }
 
+func (w *TarArchiveWriter) writePendingEntry(entry *pendingEntry) error {
+	err := w.tw.WriteHeader(entry.header)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if entry.sourcePath != "" {
+		f, err := os.Open(entry.sourcePath)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		_, err = io.Copy(w.tw, f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add file to archive: %s", entry.sourcePath)
+		}
+	}
+	return w.endEntry(entry.header.Name)
+}
+
+// normalizeHeader removes the information from the header which differs
+// between otherwise identical files, like the modification time, the
+// owner and the permissions, which depend on the umask. Only whether
+// a file is executable is kept.
+func normalizeHeader(header *tar.Header) {
+	if header.Typeflag == tar.TypeDir || header.Mode&0o111 != 0 {
+		header.Mode = 0o755
+	} else {
+		header.Mode = 0o644
+	}
+	header.ModTime = time.Unix(0, 0)
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid = 0
+	header.Gid = 0
+	header.Uname = ""
+	header.Gname = ""
+	header.PAXRecords = nil
+	header.Format = tar.FormatUnknown
+}
+
+// endEntry marks the end of the entry which was written last, which is
+// where a split archive can be split.
+func (w *TarArchiveWriter) endEntry(name string) error {
+	if w.splitter == nil {
+		return nil
+	}
+	// Write the padding of the entry
+	err := w.tw.Flush()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return w.splitter.endEntry(name)
+}
+
 // WriteDir traverses sourceDir recursively and writes all regular files
-// and symlinks to the archive.
+// and symlinks to the archive. The entries of the directory are not
+// interleaved with entries written concurrently.
 func (w *TarArchiveWriter) WriteDir(archiveBasePath string, sourceDir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return errors.WithStack(err)
@@ -215,28 +444,120 @@ func (w *TarArchiveWriter) WriteDir(archiveBasePath string, sourceDir string) er
 }
 
 func (w *TarArchiveWriter) GetSourcePath(archivePath string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	return w.manifest[archivePath]
 }
 
 func (w *TarArchiveWriter) HasFileEntry(archivePath string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	_, exists := w.manifest[archivePath]
 	return exists
 }
 
+// Headers returns the headers of the entries written so far.
 func (w *TarArchiveWriter) Headers() []*tar.Header {
-	return w.headers
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.headers)
 }
 
-// Extract extracts the gzip-compressed tar archive bundle into dir.
+// The first bytes of every gzip- and zstd-compressed file
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Extract extracts the tar archive bundle into dir. The compression
+// of the archive is detected automatically. Split archives are
+// reassembled if bundle is the path of the manifest or if only the
+// manifest exists next to the path.
 func Extract(bundle, dir string) error {
-	f, err := os.Open(bundle)
+	f, err := openArchive(bundle)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	gr, err := gzip.NewReader(f)
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	compression, err := detectCompression(r)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
+	}
+
+	switch compression {
+	case CompressionGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer gr.Close()
+		return archiveutil.Untar(gr, dir)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer zr.Close()
+		return archiveutil.Untar(zr, dir)
+	default:
+		return archiveutil.Untar(r, dir)
+	}
+}
+
+// DetectCompression returns the compression of the tar archive bundle,
+// which is one of Compressions. Split archives are handled like in
+// Extract.
+func DetectCompression(bundle string) (string, error) {
+	f, err := openArchive(bundle)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return detectCompression(bufio.NewReader(f))
+}
+
+// detectCompression detects the compression of the archive read by r
+// from its first bytes, without consuming them.
+func detectCompression(r *bufio.Reader) (string, error) {
+	magic, err := r.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return "", errors.WithStack(err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return CompressionGzip, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, nil
+	}
+}
+
+// openArchive opens the archive at path or, if it's a split archive,
+// the concatenation of its parts.
+func openArchive(path string) (io.ReadCloser, error) {
+	if strings.HasSuffix(path, SplitManifestSuffix) {
+		return openSplitArchive(path)
+	}
+	exists, err := fileutil.Exists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		manifestExists, err := fileutil.Exists(path + SplitManifestSuffix)
+		if err != nil {
+			return nil, err
+		}
+		if manifestExists {
+			return openSplitArchive(path + SplitManifestSuffix)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
-	defer gr.Close()
-	return archiveutil.Untar(gr, dir)
+	return f, nil
 }
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bufio"
 	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
 	"io/fs"
 	"os"
@@ -11,7 +12,9 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
@@ -235,3 +238,231 @@ func createArchive(t *testing.T, files []fileEntry) *os.File {
 	t.Logf("Created archive at: %s", archiveFile.Name())
 	return archiveFile
 }
+
+func TestExtract_DetectsCompression(t *testing.T) {
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "file.txt")
+			err := os.WriteFile(file, []byte("foobar"), 0o644)
+			require.NoError(t, err)
+
+			archive, err := os.Create(filepath.Join(t.TempDir(), "bundle"))
+			require.NoError(t, err)
+			archiveWriter, err := NewTarArchiveWriterWithCompression(archive, compression)
+			require.NoError(t, err)
+			err = archiveWriter.WriteFile("file.txt", file)
+			require.NoError(t, err)
+			err = archiveWriter.Close()
+			require.NoError(t, err)
+			err = archive.Close()
+			require.NoError(t, err)
+
+			detected, err := DetectCompression(archive.Name())
+			require.NoError(t, err)
+			require.Equal(t, compression, detected)
+
+			out := t.TempDir()
+			err = Extract(archive.Name(), out)
+			require.NoError(t, err)
+			content, err := os.ReadFile(filepath.Join(out, "file.txt"))
+			require.NoError(t, err)
+			require.Equal(t, "foobar", string(content))
+		})
+	}
+}
+
+func TestExtract_RejectsPathTraversal(t *testing.T) {
+	for _, header := range []*tar.Header{
+		{Typeflag: tar.TypeReg, Name: "../evil", Mode: 0o644, Size: 4},
+		{Typeflag: tar.TypeReg, Name: "dir/../../evil", Mode: 0o644, Size: 4},
+		{Typeflag: tar.TypeReg, Name: "/tmp/evil", Mode: 0o644, Size: 4},
+		{Typeflag: tar.TypeDir, Name: "../evil", Mode: 0o755},
+		{Typeflag: tar.TypeLink, Name: "link", Linkname: "../evil"},
+	} {
+		t.Run(header.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			bundle := filepath.Join(dir, "bundle.tar")
+			f, err := os.Create(bundle)
+			require.NoError(t, err)
+			tw := tar.NewWriter(f)
+			err = tw.WriteHeader(header)
+			require.NoError(t, err)
+			if header.Size > 0 {
+				_, err = tw.Write([]byte("evil"))
+				require.NoError(t, err)
+			}
+			err = tw.Close()
+			require.NoError(t, err)
+			err = f.Close()
+			require.NoError(t, err)
+
+			out := filepath.Join(dir, "out")
+			err = Extract(bundle, out)
+			require.Error(t, err)
+			require.NoFileExists(t, filepath.Join(dir, "evil"))
+		})
+	}
+}
+
+func TestTarArchiveWriter_ConcurrentWrites(t *testing.T) {
+	sourceDir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		err := os.WriteFile(filepath.Join(sourceDir, fmt.Sprintf("file%d", i)), []byte(strings.Repeat("x", i*100)), 0o644)
+		require.NoError(t, err)
+	}
+	depsDir := filepath.Join(sourceDir, "deps")
+	for i := 0; i < 5; i++ {
+		err := os.MkdirAll(filepath.Join(depsDir, fmt.Sprintf("dep%d", i)), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(depsDir, fmt.Sprintf("dep%d", i), "lib.so"), []byte(fmt.Sprintf("dep%d", i)), 0o644)
+		require.NoError(t, err)
+	}
+
+	writeArchive := func(reproducible bool) string {
+		archivePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+		f, err := os.Create(archivePath)
+		require.NoError(t, err)
+		defer f.Close()
+		archiveWriter := NewTarArchiveWriter(f, true)
+		archiveWriter.SetReproducible(reproducible)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 25)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				name := fmt.Sprintf("file%d", i)
+				errs <- archiveWriter.WriteFile(filepath.Join("files", name), filepath.Join(sourceDir, name))
+			}(i)
+		}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				name := fmt.Sprintf("dep%d", i)
+				errs <- archiveWriter.WriteDir(filepath.Join("deps", name), filepath.Join(depsDir, name))
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		err = archiveWriter.Close()
+		require.NoError(t, err)
+		return archivePath
+	}
+
+	// The order of the entries depends on the order in which the
+	// goroutines acquire the writer, so only the extracted content is
+	// the same for every archive
+	var extracted []map[string]string
+	for i := 0; i < 2; i++ {
+		out := t.TempDir()
+		err := Extract(writeArchive(false), out)
+		require.NoError(t, err)
+
+		files := make(map[string]string)
+		err = filepath.WalkDir(out, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(out, path)
+			files[filepath.ToSlash(relPath)] = string(content)
+			return err
+		})
+		require.NoError(t, err)
+		require.Len(t, files, 25)
+		extracted = append(extracted, files)
+	}
+	require.Equal(t, extracted[0], extracted[1])
+
+	// In reproducible mode, the entries are sorted, so the archives
+	// are byte-identical
+	first, err := os.ReadFile(writeArchive(true))
+	require.NoError(t, err)
+	second, err := os.ReadFile(writeArchive(true))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+}
+
+func TestTarArchiveWriter_Reproducible(t *testing.T) {
+	names := []string{"b.txt", "a.txt", "lib/c.so"}
+
+	// Writes the files in the given order from a new source directory
+	// with different modification times and permissions, like files
+	// created with a different umask. lib/c.so is executable.
+	writeArchive := func(compression string, order []int, mtime time.Time, perm os.FileMode) string {
+		sourceDir := t.TempDir()
+		for _, name := range names {
+			path := filepath.Join(sourceDir, name)
+			err := os.MkdirAll(filepath.Dir(path), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(path, []byte(name), 0o644)
+			require.NoError(t, err)
+			filePerm := perm
+			if name == "lib/c.so" {
+				filePerm |= 0o111
+			}
+			err = os.Chmod(path, filePerm)
+			require.NoError(t, err)
+			err = os.Chtimes(path, mtime, mtime)
+			require.NoError(t, err)
+		}
+
+		archivePath := filepath.Join(t.TempDir(), "bundle"+Extension(compression))
+		f, err := os.Create(archivePath)
+		require.NoError(t, err)
+		defer f.Close()
+		archiveWriter, err := NewTarArchiveWriterWithCompression(f, compression)
+		require.NoError(t, err)
+		archiveWriter.SetReproducible(true)
+		for _, i := range order {
+			err = archiveWriter.WriteFile(names[i], filepath.Join(sourceDir, names[i]))
+			require.NoError(t, err)
+		}
+		err = archiveWriter.WriteHardLink("a.txt", "0-link.txt")
+		require.NoError(t, err)
+		err = archiveWriter.WriteDir("dir", filepath.Join(sourceDir, "lib"))
+		require.NoError(t, err)
+		err = archiveWriter.Close()
+		require.NoError(t, err)
+		return archivePath
+	}
+
+	checksum := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			first := writeArchive(compression, []int{0, 1, 2}, time.Now().Add(-time.Hour), 0o644)
+			second := writeArchive(compression, []int{2, 1, 0}, time.Now(), 0o664)
+			require.Equal(t, checksum(first), checksum(second))
+
+			// The hard link is written after its target, so that it can be
+			// extracted
+			out := t.TempDir()
+			err := Extract(second, out)
+			require.NoError(t, err)
+			content, err := os.ReadFile(filepath.Join(out, "0-link.txt"))
+			require.NoError(t, err)
+			require.Equal(t, "a.txt", string(content))
+			content, err = os.ReadFile(filepath.Join(out, "dir", "c.so"))
+			require.NoError(t, err)
+			require.Equal(t, "lib/c.so", string(content))
+
+			// Only the executable bit of the permissions is kept
+			info, err := os.Stat(filepath.Join(out, "dir", "c.so"))
+			require.NoError(t, err)
+			require.NotZero(t, info.Mode().Perm()&0o111)
+		})
+	}
+}
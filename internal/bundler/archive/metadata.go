@@ -10,11 +10,18 @@ import (
 // MetadataFileName is the name of the meta information yaml file within an artifact archive.
 const MetadataFileName = "bundle.yaml"
 
+// NoteFileName is the name of the file containing the user-provided
+// note within an artifact archive.
+const NoteFileName = "NOTE.txt"
+
 // Metadata defines meta information for artifacts contained within a fuzzing artifact archive.
 type Metadata struct {
 	*RunEnvironment `yaml:"run_environment"`
 	CodeRevision    *CodeRevision `yaml:"code_revision,omitempty"`
 	Fuzzers         []*Fuzzer     `yaml:"fuzzers"`
+	// Note is a human-readable note provided by the user who created
+	// the bundle, e.g. who built it or a ticket number
+	Note string `yaml:"note,omitempty"`
 }
 
 // Fuzzer specifies the type and locations of fuzzers contained in the archive.
@@ -34,6 +41,9 @@ type Fuzzer struct {
 	RuntimePaths  []string      `yaml:"runtime_paths,omitempty"`
 	EngineOptions EngineOptions `yaml:"engine_options,omitempty"`
 	MaxRunTime    uint          `yaml:"max_run_time,omitempty"`
+	// DisplayName is a human-friendly name of the fuzz test which is
+	// shown instead of its name or target, if set
+	DisplayName string `yaml:"display_name,omitempty"`
 }
 
 // RunEnvironment specifies the environment in which the fuzzers are to be run.
@@ -0,0 +1,337 @@
+package archive
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// SplitManifestSuffix is appended to the path of a split archive to get
+// the path of the manifest which describes how to reassemble it.
+const SplitManifestSuffix = ".parts.json"
+
+// SplitManifest describes the parts of a split archive. Concatenating
+// the parts in order results in the archive.
+type SplitManifest struct {
+	// The file name of the reassembled archive
+	Archive     string       `json:"archive"`
+	Compression string       `json:"compression"`
+	Size        int64        `json:"size"`
+	Parts       []*SplitPart `json:"parts"`
+}
+
+// SplitPart is a part of a split archive. Its name is relative to the
+// directory of the manifest.
+type SplitPart struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// PartPath returns the path of the n-th part (starting at 1) of the
+// split archive.
+func PartPath(archivePath string, n int) string {
+	return fmt.Sprintf("%s.part%d", archivePath, n)
+}
+
+// splitWriter writes an archive into parts of at most maxSize bytes.
+// Parts are only split between archive entries, so that no file of the
+// archive is spread across multiple parts. To achieve that, each entry
+// is compressed separately, which is supported because concatenated
+// gzip members and zstd frames are valid gzip and zstd streams.
+type splitWriter struct {
+	path        string
+	compression string
+	maxSize     int64
+
+	// The uncompressed and compressed content of the current entry
+	entry      *os.File
+	compressed *os.File
+
+	part     *os.File
+	partSize int64
+	partHash hash.Hash
+	manifest *SplitManifest
+
+	// split is set on Close if the archive was split into multiple
+	// parts
+	split bool
+}
+
+func newSplitWriter(path, compression string, maxSize int64) (*splitWriter, error) {
+	entry, err := os.CreateTemp("", "cifuzz-split-entry-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	compressed, err := os.CreateTemp("", "cifuzz-split-compressed-")
+	if err != nil {
+		entry.Close()
+		fileutil.Cleanup(entry.Name())
+		return nil, errors.WithStack(err)
+	}
+	return &splitWriter{
+		path:        path,
+		compression: compression,
+		maxSize:     maxSize,
+		entry:       entry,
+		compressed:  compressed,
+		manifest: &SplitManifest{
+			Archive:     filepath.Base(path),
+			Compression: compression,
+		},
+	}, nil
+}
+
+func (w *splitWriter) Write(p []byte) (int, error) {
+	n, err := w.entry.Write(p)
+	return n, errors.WithStack(err)
+}
+
+// endEntry compresses the data written since the end of the previous
+// entry and appends it to the current part, or to a new part if the
+// current part would exceed the maximum size. The name of the entry is
+// only used in error messages.
+func (w *splitWriter) endEntry(name string) error {
+	size, err := w.compressEntry()
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return nil
+	}
+	if size > w.maxSize {
+		return errors.Errorf("Failed to split the bundle: %s alone has a size of %d bytes after compression, which exceeds the split size of %d bytes",
+			name, size, w.maxSize)
+	}
+
+	if w.part != nil && w.partSize+size > w.maxSize {
+		err = w.closePart()
+		if err != nil {
+			return err
+		}
+	}
+	if w.part == nil {
+		w.part, err = os.Create(PartPath(w.path, len(w.manifest.Parts)+1))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		w.partSize = 0
+		w.partHash = sha256.New()
+	}
+
+	_, err = w.compressed.Seek(0, io.SeekStart)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.Copy(io.MultiWriter(w.part, w.partHash), w.compressed)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w.partSize += size
+	return nil
+}
+
+// compressEntry writes the compressed content of the current entry to
+// w.compressed, resets the current entry and returns the compressed
+// size.
+func (w *splitWriter) compressEntry() (int64, error) {
+	for _, f := range []*os.File{w.entry, w.compressed} {
+		_, err := f.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	err := w.compressed.Truncate(0)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	info, err := w.entry.Stat()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if info.Size() == 0 {
+		return 0, nil
+	}
+
+	var compressor io.WriteCloser
+	switch w.compression {
+	case CompressionNone:
+	case CompressionZstd:
+		compressor, err = zstd.NewWriter(w.compressed)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	default:
+		compressor = gzip.NewWriter(w.compressed)
+	}
+	if compressor == nil {
+		_, err = io.Copy(w.compressed, w.entry)
+	} else {
+		_, err = io.Copy(compressor, w.entry)
+		if err == nil {
+			err = compressor.Close()
+		}
+	}
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	_, err = w.entry.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	err = w.entry.Truncate(0)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	size, err := w.compressed.Seek(0, io.SeekCurrent)
+	return size, errors.WithStack(err)
+}
+
+func (w *splitWriter) closePart() error {
+	err := w.part.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w.manifest.Parts = append(w.manifest.Parts, &SplitPart{
+		Name:   filepath.Base(w.part.Name()),
+		Size:   w.partSize,
+		SHA256: hex.EncodeToString(w.partHash.Sum(nil)),
+	})
+	w.manifest.Size += w.partSize
+	w.part = nil
+	return nil
+}
+
+// Close writes the remaining data to the last part. If the archive
+// fits into a single part, it's renamed to the path of the archive.
+// Otherwise, the manifest is written.
+func (w *splitWriter) Close() error {
+	defer w.cleanup()
+
+	err := w.endEntry("the end of the archive")
+	if err != nil {
+		return err
+	}
+	if w.part != nil {
+		err = w.closePart()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(w.manifest.Parts) == 1 {
+		err = os.Rename(PartPath(w.path, 1), w.path)
+		return errors.WithStack(err)
+	}
+	w.split = true
+	bytes, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.WriteFile(w.path+SplitManifestSuffix, append(bytes, '\n'), 0o644)
+	return errors.WithStack(err)
+}
+
+func (w *splitWriter) cleanup() {
+	w.entry.Close()
+	w.compressed.Close()
+	fileutil.Cleanup(w.entry.Name())
+	fileutil.Cleanup(w.compressed.Name())
+	if w.part != nil {
+		w.part.Close()
+	}
+}
+
+// ReadSplitManifest reads the manifest of a split archive.
+func ReadSplitManifest(path string) (*SplitManifest, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	manifest := &SplitManifest{}
+	err = json.Unmarshal(bytes, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse split archive manifest %s", path)
+	}
+	return manifest, nil
+}
+
+// RemoveSplitArchive removes the manifest and all parts of a split
+// archive.
+func RemoveSplitArchive(archivePath string) {
+	fileutil.Cleanup(archivePath + SplitManifestSuffix)
+	for n := 1; ; n++ {
+		exists, err := fileutil.Exists(PartPath(archivePath, n))
+		if err != nil || !exists {
+			return
+		}
+		fileutil.Cleanup(PartPath(archivePath, n))
+	}
+}
+
+// openSplitArchive verifies the parts of the split archive described
+// by the manifest and returns a reader of their concatenated content.
+func openSplitArchive(manifestPath string) (io.ReadCloser, error) {
+	manifest, err := ReadSplitManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*os.File
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	var readers []io.Reader
+	for _, part := range manifest.Parts {
+		path := filepath.Join(filepath.Dir(manifestPath), filepath.Base(part.Name))
+		f, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return nil, errors.Wrapf(err, "Failed to open part %s of split archive %s", part.Name, manifest.Archive)
+		}
+		files = append(files, f)
+
+		h := sha256.New()
+		size, err := io.Copy(h, f)
+		if err != nil {
+			closeAll()
+			return nil, errors.WithStack(err)
+		}
+		if size != part.Size || hex.EncodeToString(h.Sum(nil)) != part.SHA256 {
+			closeAll()
+			return nil, errors.Errorf("Part %s of split archive %s is corrupted", part.Name, manifest.Archive)
+		}
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			closeAll()
+			return nil, errors.WithStack(err)
+		}
+		readers = append(readers, f)
+	}
+
+	return &multiReadCloser{Reader: io.MultiReader(readers...), close: closeAll}, nil
+}
+
+type multiReadCloser struct {
+	io.Reader
+	close func()
+}
+
+func (r *multiReadCloser) Close() error {
+	r.close()
+	return nil
+}
@@ -0,0 +1,109 @@
+package archive
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitTarArchiveWriter(t *testing.T) {
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			dir := t.TempDir()
+			// Random content can't be compressed, so each file has a
+			// compressed size of more than 3000 bytes
+			contents := make(map[string][]byte)
+			for i := 0; i < 5; i++ {
+				name := fmt.Sprintf("file%d", i)
+				content := make([]byte, 3000)
+				_, err := rand.Read(content)
+				require.NoError(t, err)
+				err = os.WriteFile(filepath.Join(dir, name), content, 0o644)
+				require.NoError(t, err)
+				contents[name] = content
+			}
+
+			archivePath := filepath.Join(dir, "bundle"+Extension(compression))
+			archiveWriter, err := NewSplitTarArchiveWriter(archivePath, compression, 8000)
+			require.NoError(t, err)
+			for name := range contents {
+				err = archiveWriter.WriteFile(name, filepath.Join(dir, name))
+				require.NoError(t, err)
+			}
+			err = archiveWriter.Close()
+			require.NoError(t, err)
+
+			assert.NoFileExists(t, archivePath)
+			manifest, err := ReadSplitManifest(archivePath + SplitManifestSuffix)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Base(archivePath), manifest.Archive)
+			require.Greater(t, len(manifest.Parts), 1)
+			for i, part := range manifest.Parts {
+				assert.Equal(t, filepath.Base(PartPath(archivePath, i+1)), part.Name)
+				assert.LessOrEqual(t, part.Size, int64(8000))
+			}
+
+			// The parts are reassembled via the manifest or the path of
+			// the archive
+			for _, path := range []string{archivePath + SplitManifestSuffix, archivePath} {
+				out := t.TempDir()
+				err = Extract(path, out)
+				require.NoError(t, err)
+				for name, content := range contents {
+					extracted, err := os.ReadFile(filepath.Join(out, name))
+					require.NoError(t, err)
+					assert.Equal(t, content, extracted)
+				}
+			}
+
+			// Corrupted parts are detected
+			err = os.WriteFile(PartPath(archivePath, 1), []byte("corrupted"), 0o644)
+			require.NoError(t, err)
+			err = Extract(archivePath, t.TempDir())
+			require.ErrorContains(t, err, "corrupted")
+		})
+	}
+}
+
+func TestSplitTarArchiveWriter_SinglePart(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "file"), []byte("foobar"), 0o644)
+	require.NoError(t, err)
+
+	// An archive which doesn't exceed the split size isn't split
+	archivePath := filepath.Join(dir, "bundle.tar.gz")
+	archiveWriter, err := NewSplitTarArchiveWriter(archivePath, CompressionGzip, 1024*1024)
+	require.NoError(t, err)
+	err = archiveWriter.WriteFile("file", filepath.Join(dir, "file"))
+	require.NoError(t, err)
+	err = archiveWriter.Close()
+	require.NoError(t, err)
+
+	assert.FileExists(t, archivePath)
+	assert.NoFileExists(t, archivePath+SplitManifestSuffix)
+	assert.NoFileExists(t, PartPath(archivePath, 1))
+	out := t.TempDir()
+	err = Extract(archivePath, out)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(out, "file"))
+}
+
+func TestSplitTarArchiveWriter_EntryExceedsSplitSize(t *testing.T) {
+	dir := t.TempDir()
+	content := make([]byte, 3000)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "libfoo.so"), content, 0o644)
+	require.NoError(t, err)
+
+	archiveWriter, err := NewSplitTarArchiveWriter(filepath.Join(dir, "bundle.tar.gz"), CompressionGzip, 2000)
+	require.NoError(t, err)
+	err = archiveWriter.WriteFile("libfoo.so", filepath.Join(dir, "libfoo.so"))
+	require.ErrorContains(t, err, "exceeds the split size")
+	_ = archiveWriter.Close()
+}
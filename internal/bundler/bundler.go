@@ -24,10 +24,17 @@ const archiveWorkDirPath = "work_dir"
 
 type Bundler struct {
 	opts *Opts
+
+	// bundleFuzzers builds the fuzz tests and writes their artifacts
+	// to the archive. It's replaced in tests to bundle prebuilt fuzz
+	// tests.
+	bundleFuzzers func(archiveWriter *archive.TarArchiveWriter) ([]*archive.Fuzzer, error)
 }
 
 func New(opts *Opts) *Bundler {
-	return &Bundler{opts: opts}
+	b := &Bundler{opts: opts}
+	b.bundleFuzzers = b.buildAndBundleFuzzers
+	return b
 }
 
 func (b *Bundler) Bundle() (string, error) {
@@ -40,36 +47,45 @@ func (b *Bundler) Bundle() (string, error) {
 	}
 	defer fileutil.Cleanup(b.opts.tempDir)
 
-	var bundle *os.File
-	bundle, err = b.createEmptyBundle()
+	b.determineOutputPath()
+
+	// Remove the bundle of a previous run, so that neither a stale
+	// bundle nor a stale split bundle is left next to the new one
+	err = b.removeExistingBundle()
 	if err != nil {
 		return "", err
 	}
+
+	var archiveWriter *archive.TarArchiveWriter
+	var closeBundle func() error
+	var removeBundle func()
+	if b.opts.SplitSize > 0 {
+		archiveWriter, closeBundle, removeBundle, err = b.createSplitBundle()
+	} else {
+		archiveWriter, closeBundle, removeBundle, err = b.createBundle()
+	}
+	if err != nil {
+		return "", err
+	}
+	archiveWriter.SetReproducible(b.opts.Reproducible)
 	// if an error occurs during bundling we should make sure that
 	// the bundle gets removed
 	defer func() {
-		bundle.Close()
 		if err != nil {
-			os.Remove(bundle.Name())
+			removeBundle()
 		}
 	}()
 
-	// Create archive writer
-	bufWriter := bufio.NewWriter(bundle)
-	archiveWriter := archive.NewTarArchiveWriter(bufWriter, true)
-
 	var fuzzers []*archive.Fuzzer
-	switch b.opts.BuildSystem {
-	case config.BuildSystemCMake, config.BuildSystemBazel, config.BuildSystemOther:
-		fuzzers, err = newLibfuzzerBundler(b.opts, archiveWriter).bundle()
-	case config.BuildSystemMaven, config.BuildSystemGradle:
-		fuzzers, err = newJazzerBundler(b.opts, archiveWriter).bundle()
-	default:
-		err = errors.Errorf("Unknown build system for bundler: %s", b.opts.BuildSystem)
-	}
+	fuzzers, err = b.bundleFuzzers(archiveWriter)
 	if err != nil {
 		return "", err
 	}
+	if b.opts.DisplayName != "" {
+		for _, fuzzer := range fuzzers {
+			fuzzer.DisplayName = b.opts.DisplayName
+		}
+	}
 
 	dockerImageUsedInBundle := b.determineDockerImageForBundle()
 	err = b.createMetadataFileInArchive(fuzzers, archiveWriter, dockerImageUsedInBundle)
@@ -87,6 +103,11 @@ func (b *Bundler) Bundle() (string, error) {
 		return "", err
 	}
 
+	err = b.createNoteFileInArchive(archiveWriter)
+	if err != nil {
+		return "", err
+	}
+
 	if b.opts.BundleBuildLogFile != "" {
 		err = archiveWriter.WriteFile("build.log", b.opts.BundleBuildLogFile)
 		if err != nil {
@@ -108,26 +129,43 @@ func (b *Bundler) Bundle() (string, error) {
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
-	log.Debugf("Content of bundle %s:\n%s", bundle.Name(), tableBuf.String())
+	log.Debugf("Content of bundle %s:\n%s", b.opts.OutputPath, tableBuf.String())
 
-	err = archiveWriter.Close()
+	err = closeBundle()
 	if err != nil {
-		return "", errors.WithStack(err)
+		return "", err
 	}
-	err = bufWriter.Flush()
-	if err != nil {
-		return "", errors.WithStack(err)
+
+	if archiveWriter.IsSplit() {
+		return b.opts.OutputPath + archive.SplitManifestSuffix, nil
 	}
-	err = bundle.Close()
-	if err != nil {
-		return "", errors.WithStack(err)
+	return b.opts.OutputPath, nil
+}
+
+func (b *Bundler) buildAndBundleFuzzers(archiveWriter *archive.TarArchiveWriter) ([]*archive.Fuzzer, error) {
+	switch b.opts.BuildSystem {
+	case config.BuildSystemCMake, config.BuildSystemBazel, config.BuildSystemOther:
+		return newLibfuzzerBundler(b.opts, archiveWriter).bundle()
+	case config.BuildSystemMaven, config.BuildSystemGradle:
+		return newJazzerBundler(b.opts, archiveWriter).bundle()
+	default:
+		return nil, errors.Errorf("Unknown build system for bundler: %s", b.opts.BuildSystem)
 	}
+}
 
-	return bundle.Name(), nil
+// removeExistingBundle removes the bundle and the split bundle at the
+// output path, if they exist.
+func (b *Bundler) removeExistingBundle() error {
+	err := os.Remove(b.opts.OutputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	archive.RemoveSplitArchive(b.opts.OutputPath)
+	return nil
 }
 
-func (b *Bundler) createEmptyBundle() (*os.File, error) {
-	archiveExt := ".tar.gz"
+func (b *Bundler) determineOutputPath() {
+	archiveExt := archive.Extension(b.opts.Compression)
 
 	if b.opts.OutputPath != "" {
 		// Check that outpath path makes sense
@@ -142,14 +180,61 @@ func (b *Bundler) createEmptyBundle() (*os.File, error) {
 		b.opts.OutputPath = "fuzz_tests" + archiveExt
 	}
 
+	log.Debugf("Bundle output path: %s", b.opts.OutputPath)
+}
+
+// createBundle creates the archive writer of the bundle and returns it
+// together with functions to finish writing the bundle and to remove
+// it if bundling failed.
+func (b *Bundler) createBundle() (*archive.TarArchiveWriter, func() error, func(), error) {
 	bundle, err := os.Create(b.opts.OutputPath)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create fuzzing artifact archive")
+		return nil, nil, nil, errors.Wrap(err, "failed to create fuzzing artifact archive")
 	}
 
-	log.Debugf("Bundle output path: %s", b.opts.OutputPath)
+	bufWriter := bufio.NewWriter(bundle)
+	archiveWriter, err := archive.NewTarArchiveWriterWithCompression(bufWriter, b.opts.Compression)
+	if err != nil {
+		bundle.Close()
+		os.Remove(bundle.Name())
+		return nil, nil, nil, err
+	}
 
-	return bundle, nil
+	closeBundle := func() error {
+		err := archiveWriter.Close()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		err = bufWriter.Flush()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		err = bundle.Close()
+		return errors.WithStack(err)
+	}
+	removeBundle := func() {
+		bundle.Close()
+		os.Remove(bundle.Name())
+	}
+	return archiveWriter, closeBundle, removeBundle, nil
+}
+
+// createSplitBundle is like createBundle, but the bundle is split into
+// parts of at most the split size.
+func (b *Bundler) createSplitBundle() (*archive.TarArchiveWriter, func() error, func(), error) {
+	archiveWriter, err := archive.NewSplitTarArchiveWriter(b.opts.OutputPath, b.opts.Compression, b.opts.SplitSize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	closeBundle := archiveWriter.Close
+	removeBundle := func() {
+		// Closing the writer removes its temporary files
+		_ = archiveWriter.Close()
+		archive.RemoveSplitArchive(b.opts.OutputPath)
+		os.Remove(b.opts.OutputPath)
+	}
+	return archiveWriter, closeBundle, removeBundle, nil
 }
 
 func (b *Bundler) determineDockerImageForBundle() string {
@@ -178,6 +263,7 @@ func (b *Bundler) createMetadataFileInArchive(fuzzers []*archive.Fuzzer, archive
 			Docker: dockerImageUsedInBundle,
 		},
 		CodeRevision: b.getCodeRevision(),
+		Note:         b.opts.Note,
 	}
 
 	metadataYamlContent, err := metadata.ToYaml()
@@ -200,6 +286,19 @@ func (b *Bundler) createMetadataFileInArchive(fuzzers []*archive.Fuzzer, archive
 	return nil
 }
 
+func (b *Bundler) createNoteFileInArchive(archiveWriter archive.ArchiveWriter) error {
+	if b.opts.Note == "" {
+		return nil
+	}
+
+	notePath := filepath.Join(b.opts.tempDir, archive.NoteFileName)
+	err := os.WriteFile(notePath, []byte(b.opts.Note), 0o644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", archive.NoteFileName)
+	}
+	return archiveWriter.WriteFile(archive.NoteFileName, notePath)
+}
+
 func (b *Bundler) createWorkDirInArchive(archiveWriter archive.ArchiveWriter) error {
 	// The fuzzing artifact archive spec requires this directory even if it is empty.
 	tempWorkDirPath := filepath.Join(b.opts.tempDir, archiveWorkDirPath)
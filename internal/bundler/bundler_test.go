@@ -1,6 +1,8 @@
 package bundler
 
 import (
+	"crypto/rand"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -9,6 +11,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/testutil"
 )
 
@@ -93,3 +97,104 @@ func TestRemoveBundleOnError(t *testing.T) {
 
 	assert.NoFileExists(t, bundlePath)
 }
+
+func TestBundle_Split(t *testing.T) {
+	testDir := t.TempDir()
+	bundlePath := filepath.Join(testDir, "fuzz_tests.tar.gz")
+	manifestPath := bundlePath + archive.SplitManifestSuffix
+
+	// Random data can't be compressed, so each file results in a part
+	// of its own
+	var files []string
+	for _, name := range []string{"a.bin", "b.bin"} {
+		data := make([]byte, 3000)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+		file := filepath.Join(testDir, name)
+		require.NoError(t, os.WriteFile(file, data, 0o644))
+		files = append(files, file)
+	}
+	bundleFiles := func(w *archive.TarArchiveWriter) ([]*archive.Fuzzer, error) {
+		for _, file := range files {
+			err := w.WriteFile(filepath.Base(file), file)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return []*archive.Fuzzer{{Name: "my_fuzz_test"}}, nil
+	}
+
+	// A stale bundle from a previous run is removed
+	require.NoError(t, os.WriteFile(bundlePath, []byte("stale"), 0o644))
+
+	b := New(&Opts{BuildSystem: config.BuildSystemCMake, OutputPath: bundlePath, SplitSize: 4096})
+	b.bundleFuzzers = bundleFiles
+	path, err := b.Bundle()
+	require.NoError(t, err)
+	assert.Equal(t, manifestPath, path)
+	assert.NoFileExists(t, bundlePath)
+	assert.FileExists(t, archive.PartPath(bundlePath, 2))
+
+	out := t.TempDir()
+	require.NoError(t, archive.Extract(path, out))
+	assert.FileExists(t, filepath.Join(out, "a.bin"))
+	assert.FileExists(t, filepath.Join(out, "b.bin"))
+	assert.FileExists(t, filepath.Join(out, archive.MetadataFileName))
+
+	// If the bundle fits into a single part, the split bundle of the
+	// previous run is removed
+	b = New(&Opts{BuildSystem: config.BuildSystemCMake, OutputPath: bundlePath, SplitSize: 1 << 20})
+	b.bundleFuzzers = bundleFiles
+	path, err = b.Bundle()
+	require.NoError(t, err)
+	assert.Equal(t, bundlePath, path)
+	assert.NoFileExists(t, manifestPath)
+	assert.NoFileExists(t, archive.PartPath(bundlePath, 1))
+	assert.NoFileExists(t, archive.PartPath(bundlePath, 2))
+
+	out = t.TempDir()
+	require.NoError(t, archive.Extract(path, out))
+	assert.FileExists(t, filepath.Join(out, "b.bin"))
+}
+
+func TestValidateDisplayName(t *testing.T) {
+	opts := &Opts{
+		BuildSystem: config.BuildSystemMaven,
+		FuzzTests:   []string{"com.example.FuzzTestCase"},
+		DisplayName: "Parser Fuzzer",
+	}
+	require.NoError(t, opts.Validate())
+
+	opts = &Opts{
+		BuildSystem: config.BuildSystemMaven,
+		FuzzTests:   []string{"com.example.FuzzTestCase", "com.example.OtherFuzzTestCase"},
+		DisplayName: "Parser Fuzzer",
+	}
+	require.Error(t, opts.Validate())
+}
+
+func TestValidateNote(t *testing.T) {
+	noteFile := filepath.Join(testutil.MkdirTemp(t, "", "note-*"), "note.txt")
+	err := os.WriteFile(noteFile, []byte("Built by the parser team, see TICKET-42\n"), 0o644)
+	require.NoError(t, err)
+
+	opts := &Opts{
+		BuildSystem: config.BuildSystemMaven,
+		NoteFile:    noteFile,
+	}
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, "Built by the parser team, see TICKET-42\n", opts.Note)
+
+	opts = &Opts{
+		BuildSystem: config.BuildSystemMaven,
+		Note:        "Built by the parser team",
+		NoteFile:    noteFile,
+	}
+	require.Error(t, opts.Validate())
+
+	opts = &Opts{
+		BuildSystem: config.BuildSystemMaven,
+		NoteFile:    filepath.Join(filepath.Dir(noteFile), "does-not-exist"),
+	}
+	require.Error(t, opts.Validate())
+}
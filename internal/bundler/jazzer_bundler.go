@@ -78,11 +78,11 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 	}
 
 	// add source map to archive
-	sourceDirs, err := javaBuild.SourceDirs(b.opts.ProjectDir, b.opts.BuildSystem)
+	sourceDirs, err := javaBuild.SourceDirs(b.opts.ProjectDir, b.opts.BuildSystem, b.opts.Offline)
 	if err != nil {
 		return nil, err
 	}
-	testDirs, err := javaBuild.TestDirs(b.opts.ProjectDir, b.opts.BuildSystem)
+	testDirs, err := javaBuild.TestDirs(b.opts.ProjectDir, b.opts.BuildSystem, b.opts.Offline)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +91,7 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 	// determined by the build system.
 	rootDir := b.opts.ProjectDir
 	if b.opts.BuildSystem == config.BuildSystemGradle {
-		rootDir, err = gradle.GetRootDirectory(b.opts.ProjectDir)
+		rootDir, err = gradle.GetRootDirectory(b.opts.ProjectDir, b.opts.Offline)
 		if err != nil {
 			return nil, err
 		}
@@ -118,6 +118,13 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 		}
 	}
 
+	// Copying the runtime dependencies can take a while for large
+	// projects, so we show the progress
+	var counter ProgressCounter
+	if b.opts.NewProgressCounter != nil && len(runtimeDeps) > 0 {
+		counter = b.opts.NewProgressCounter(int64(len(fuzzTests)*len(runtimeDeps)), "runtime dependencies", "Added runtime dependencies to bundle")
+	}
+
 	// Iterate over build results to fill archive and create fuzzers
 	for i := range fuzzTests {
 		fuzzTestName := fuzzTests[i]
@@ -153,9 +160,20 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 		// this map is used to generate unique artifact names
 		artifactsMap := make(map[string]uint)
 
+		// The runtime dependencies are not sorted because their order
+		// is the order of the class path. The unique artifact names
+		// only depend on that order, and the archive writer sorts the
+		// entries itself in reproducible mode.
 		for _, runtimeDep := range runtimeDeps {
 			log.Debugf("runtime dept: %s", runtimeDep)
 
+			if counter != nil {
+				err = counter.Increment()
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// check if the file exists
 			entry, err := os.Stat(runtimeDep)
 			if os.IsNotExist(err) {
@@ -222,6 +240,14 @@ func (b *jazzerBundler) assembleArtifacts(fuzzTests []string, targetMethods []st
 
 		fuzzers = append(fuzzers, fuzzer)
 	}
+
+	if counter != nil {
+		err = counter.Finish()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return fuzzers, nil
 }
 
@@ -249,7 +275,13 @@ func (b *jazzerBundler) checkDependencies() error {
 	case config.BuildSystemGradle:
 		deps = []dependencies.Key{dependencies.Java, dependencies.Gradle}
 	}
-	err := dependencies.Check(deps, b.opts.ProjectDir)
+	statuses, err := dependencies.Check(deps, b.opts.ProjectDir)
+	if b.opts.DependenciesJSONOutput != nil {
+		jsonErr := dependencies.PrintJSON(b.opts.DependenciesJSONOutput, statuses)
+		if jsonErr != nil {
+			return jsonErr
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -271,8 +303,10 @@ func (b *jazzerBundler) runBuild() (*build.BuildResult, error) {
 				Enabled: viper.IsSet("build-jobs"),
 				NumJobs: b.opts.NumBuildJobs,
 			},
-			Stdout: b.opts.BuildStdout,
-			Stderr: b.opts.BuildStderr,
+			Stdout:      b.opts.BuildStdout,
+			Stderr:      b.opts.BuildStderr,
+			Offline:     b.opts.Offline,
+			MemoryLimit: b.opts.BuildMemoryLimit,
 		})
 		if err != nil {
 			return nil, err
@@ -294,8 +328,10 @@ func (b *jazzerBundler) runBuild() (*build.BuildResult, error) {
 				Enabled: viper.IsSet("build-jobs"),
 				NumJobs: b.opts.NumBuildJobs,
 			},
-			Stdout: b.opts.BuildStdout,
-			Stderr: b.opts.BuildStderr,
+			Stdout:      b.opts.BuildStdout,
+			Stderr:      b.opts.BuildStderr,
+			Offline:     b.opts.Offline,
+			MemoryLimit: b.opts.BuildMemoryLimit,
 		})
 		if err != nil {
 			return nil, err
@@ -391,9 +427,14 @@ func (b *jazzerBundler) fuzzTestIdentifier(runtimeDeps []string) ([]string, []st
 					}
 				}
 				if len(fuzzTestsInTargetClass) == 0 {
-					return nil, nil, cmdutils.WrapIncorrectUsageError(
-						errors.Errorf("No fuzz test could be found for the given class: %s", fuzzTest),
-					)
+					// When bundling multiple classes, skip the ones
+					// without any fuzz test instead of failing the
+					// whole bundle.
+					if len(b.opts.FuzzTests) > 1 {
+						log.Warnf("Skipping class %s because it doesn't contain any fuzz test", fuzzTest)
+						continue
+					}
+					return nil, nil, cmdutils.NewMissingJVMEntrypointError(fuzzTest)
 				}
 
 				for _, test := range fuzzTestsInTargetClass {
@@ -405,6 +446,12 @@ func (b *jazzerBundler) fuzzTestIdentifier(runtimeDeps []string) ([]string, []st
 		}
 	}
 
+	if len(fuzzTests) == 0 {
+		return nil, nil, cmdutils.WrapIncorrectUsageError(
+			errors.Errorf("None of the given classes contain a fuzz test: %s", strings.Join(b.opts.FuzzTests, ", ")),
+		)
+	}
+
 	return fuzzTests, targetMethods, nil
 }
 
@@ -2,12 +2,14 @@ package bundler
 
 import (
 	"bufio"
+	"crypto/sha256"
 	"fmt"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -99,6 +101,42 @@ func TestAssembleArtifactsJava_Fuzzing(t *testing.T) {
 	require.Equal(t, expectedContents, actualContents)
 }
 
+func TestBundleJava_Reproducible(t *testing.T) {
+	projectDir := filepath.Join("testdata", "jazzer", "project")
+	runtimeDeps := []string{
+		filepath.Join(projectDir, "lib", "mylib.jar"),
+		filepath.Join(projectDir, "src", "main"),
+		filepath.Join(projectDir, "src", "test"),
+	}
+
+	// The manifest.jar files are created anew for every bundle, so
+	// their modification times differ and the order of their entries
+	// would be random if they weren't sorted
+	checksums := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		b := New(&Opts{
+			Env:          []string{"FOO=foo"},
+			ProjectDir:   projectDir,
+			OutputPath:   filepath.Join(t.TempDir(), "fuzz_tests.tar.gz"),
+			Reproducible: true,
+		})
+		b.bundleFuzzers = func(archiveWriter *archive.TarArchiveWriter) ([]*archive.Fuzzer, error) {
+			return newJazzerBundler(b.opts, archiveWriter).assembleArtifacts(
+				[]string{"com.example.FuzzTest", "com.example.AnotherFuzzTest"},
+				[]string{"FuzzTestCase", "AnotherFuzzTestCase"},
+				runtimeDeps)
+		}
+		bundlePath, err := b.Bundle()
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(bundlePath)
+		require.NoError(t, err)
+		checksums[fmt.Sprintf("%x", sha256.Sum256(content))] = true
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Len(t, checksums, 1)
+}
+
 func listFilesRecursively(dir string) ([]string, error) {
 	var paths []string
 
@@ -21,9 +21,11 @@ import (
 	"code-intelligence.com/cifuzz/internal/build/cmake"
 	"code-intelligence.com/cifuzz/internal/build/other"
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/afl"
 	"code-intelligence.com/cifuzz/util/envutil"
 	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/sliceutil"
@@ -144,13 +146,14 @@ func (b *libfuzzerBundler) buildAllVariantsBazel(configureVariants []configureVa
 	var allResults []*build.CBuildResult
 	for _, variant := range configureVariants {
 		builder, err := bazel.NewBuilder(&bazel.BuilderOptions{
-			ProjectDir: b.opts.ProjectDir,
-			Args:       b.opts.BuildSystemArgs,
-			NumJobs:    b.opts.NumBuildJobs,
-			Stdout:     b.opts.BuildStdout,
-			Stderr:     b.opts.BuildStderr,
-			TempDir:    b.opts.tempDir,
-			Verbose:    viper.GetBool("verbose"),
+			ProjectDir:  b.opts.ProjectDir,
+			Args:        b.opts.BuildSystemArgs,
+			NumJobs:     b.opts.NumBuildJobs,
+			Stdout:      b.opts.BuildStdout,
+			Stderr:      b.opts.BuildStderr,
+			TempDir:     b.opts.tempDir,
+			Verbose:     viper.GetBool("verbose"),
+			MemoryLimit: b.opts.BuildMemoryLimit,
 		})
 		if err != nil {
 			return nil, err
@@ -190,6 +193,7 @@ func (b *libfuzzerBundler) buildAllVariantsCMake(configureVariants []configureVa
 			Stdout:          b.opts.BuildStdout,
 			Stderr:          b.opts.BuildStderr,
 			FindRuntimeDeps: true,
+			MemoryLimit:     b.opts.BuildMemoryLimit,
 		})
 		if err != nil {
 			return nil, err
@@ -251,6 +255,7 @@ func (b *libfuzzerBundler) buildAllVariantsOther(configureVariants []configureVa
 			Sanitizers:   variant.Sanitizers,
 			Stdout:       b.opts.BuildStdout,
 			Stderr:       b.opts.BuildStderr,
+			MemoryLimit:  b.opts.BuildMemoryLimit,
 		})
 		if err != nil {
 			return nil, err
@@ -279,7 +284,7 @@ func (b *libfuzzerBundler) buildAllVariantsOther(configureVariants []configureVa
 			// To avoid that subsequent builds overwrite the artifacts
 			// from this build, we copy them to a temporary directory
 			// and adjust the paths in the build.CBuildResult struct
-			tempDir := filepath.Join(b.opts.tempDir, fuzzTestPrefix(result))
+			tempDir := filepath.Join(b.opts.tempDir, b.fuzzTestPrefix(result))
 			err = b.copyArtifactsToTempdir(result, tempDir)
 			if err != nil {
 				return nil, err
@@ -314,8 +319,10 @@ func (b *libfuzzerBundler) copyArtifactsToTempdir(buildResult *build.CBuildResul
 	// Try to copy the regular files first before copying the corresponding symlinks.
 	// Failing to do so results in errors that target of the symlink does not exist
 	// in the temp directory.
-	sort.Slice(buildResult.RuntimeDeps, func(i, j int) bool {
-		return !fileutil.IsSymlink(buildResult.RuntimeDeps[i])
+	// The sort is stable to keep the order of the runtime dependencies
+	// deterministic.
+	sort.SliceStable(buildResult.RuntimeDeps, func(i, j int) bool {
+		return !fileutil.IsSymlink(buildResult.RuntimeDeps[i]) && fileutil.IsSymlink(buildResult.RuntimeDeps[j])
 	})
 
 	for i, dep := range buildResult.RuntimeDeps {
@@ -363,7 +370,13 @@ func (b *libfuzzerBundler) checkDependencies() error {
 	case config.BuildSystemOther:
 		deps = []dependencies.Key{dependencies.Clang}
 	}
-	err := dependencies.Check(deps, b.opts.ProjectDir)
+	statuses, err := dependencies.Check(deps, b.opts.ProjectDir)
+	if b.opts.DependenciesJSONOutput != nil {
+		jsonErr := dependencies.PrintJSON(b.opts.DependenciesJSONOutput, statuses)
+		if jsonErr != nil {
+			return jsonErr
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -380,9 +393,25 @@ func (b *libfuzzerBundler) assembleArtifacts(buildResult *build.CBuildResult) (
 
 	fuzzTestExecutableAbsPath := buildResult.Executable
 
+	// afl-fuzz can't run binaries built for libFuzzer. The coverage
+	// build is not run by the fuzzing engine, so it doesn't matter how
+	// it was built.
+	if b.opts.Engine == cmdutils.EngineAFL && !isCoverageBuild(buildResult.Sanitizers) {
+		var isAFLBinary bool
+		isAFLBinary, err = afl.IsAFLBinary(fuzzTestExecutableAbsPath)
+		if err != nil {
+			return
+		}
+		if !isAFLBinary {
+			err = errors.Errorf(`The fuzz test %s was not built with the AFL++ compilers, which is required
+by the engine "afl"`, fuzzTestExecutableAbsPath)
+			return
+		}
+	}
+
 	// Add all build artifacts under a subdirectory of the fuzz test base path so that these files don't clash with
 	// seeds and dictionaries.
-	buildArtifactsPrefix := filepath.Join(fuzzTestPrefix(buildResult), "bin")
+	buildArtifactsPrefix := filepath.Join(b.fuzzTestPrefix(buildResult), "bin")
 
 	// Add the fuzz test executable.
 	ok, err := fileutil.IsBelow(fuzzTestExecutableAbsPath, buildResult.BuildDir)
@@ -399,7 +428,23 @@ func (b *libfuzzerBundler) assembleArtifacts(buildResult *build.CBuildResult) (
 		return
 	}
 	fuzzTestArchivePath := filepath.Join(buildArtifactsPrefix, fuzzTestExecutableRelPath)
-	err = b.archiveWriter.WriteFile(fuzzTestArchivePath, fuzzTestExecutableAbsPath)
+	if b.opts.StripDebug && !isCoverageBuild(buildResult.Sanitizers) {
+		// Bundle the executable without debug sections and add the
+		// debug information as a separate file next to it.
+		strippedDir := filepath.Join(b.opts.tempDir, "stripped", filepath.Dir(fuzzTestArchivePath))
+		var strippedPath, debugPath string
+		strippedPath, debugPath, err = stripDebugInfo(fuzzTestExecutableAbsPath, strippedDir)
+		if err != nil {
+			return
+		}
+		err = b.archiveWriter.WriteFile(fuzzTestArchivePath, strippedPath)
+		if err != nil {
+			return
+		}
+		err = b.archiveWriter.WriteFile(fuzzTestArchivePath+".debug", debugPath)
+	} else {
+		err = b.archiveWriter.WriteFile(fuzzTestArchivePath, fuzzTestExecutableAbsPath)
+	}
 	if err != nil {
 		return
 	}
@@ -494,7 +539,7 @@ depsLoop:
 		// to the library search path in the run environment.
 		// Note: Since all libraries are placed in a single directory, we have to ensure that basenames of external
 		// libraries are unique. If they aren't, we report a conflict.
-		externalLibrariesPrefix = filepath.Join(fuzzTestPrefix(buildResult), "external_libs")
+		externalLibrariesPrefix = filepath.Join(b.fuzzTestPrefix(buildResult), "external_libs")
 		archivePath := filepath.Join(externalLibrariesPrefix, filepath.Base(dep))
 		if b.archiveWriter.HasFileEntry(archivePath) {
 			err = errors.Errorf(
@@ -525,7 +570,7 @@ depsLoop:
 	var archiveDict string
 	if b.opts.Dictionary != "" {
 		log.Debugf("Adding dictionary %s", b.opts.Dictionary)
-		archiveDict = filepath.Join(fuzzTestPrefix(buildResult), "dict")
+		archiveDict = filepath.Join(b.fuzzTestPrefix(buildResult), "dict")
 		err = b.archiveWriter.WriteFile(archiveDict, b.opts.Dictionary)
 		if err != nil {
 			return
@@ -546,7 +591,7 @@ depsLoop:
 	}
 	var archiveSeedsDir string
 	if len(seedCorpusDirs) > 0 {
-		archiveSeedsDir = filepath.Join(fuzzTestPrefix(buildResult), "seeds")
+		archiveSeedsDir = filepath.Join(b.fuzzTestPrefix(buildResult), "seeds")
 
 		err = prepareSeeds(seedCorpusDirs, archiveSeedsDir, b.archiveWriter)
 		if err != nil {
@@ -600,6 +645,9 @@ depsLoop:
 		}
 		fuzzer := baseFuzzerInfo
 		fuzzer.Engine = "LIBFUZZER"
+		if b.opts.Engine == cmdutils.EngineAFL {
+			fuzzer.Engine = "AFL"
+		}
 		fuzzer.Sanitizer = strings.ToUpper(sanitizer)
 		fuzzers = append(fuzzers, &fuzzer)
 	}
@@ -609,12 +657,15 @@ depsLoop:
 
 // fuzzTestPrefix returns the path in the resulting artifact archive under which fuzz test specific files should be
 // added.
-func fuzzTestPrefix(buildResult *build.CBuildResult) string {
+func (b *libfuzzerBundler) fuzzTestPrefix(buildResult *build.CBuildResult) string {
 	sanitizerSegment := strings.Join(buildResult.Sanitizers, "+")
 	if sanitizerSegment == "" {
 		sanitizerSegment = "none"
 	}
-	engine := "libfuzzer"
+	engine := cmdutils.EngineLibFuzzer
+	if b.opts.Engine == cmdutils.EngineAFL {
+		engine = cmdutils.EngineAFL
+	}
 	if isCoverageBuild(buildResult.Sanitizers) {
 		// The backend currently only passes the corpus directory (rather than the files contained in it) as
 		// an argument to the coverage binary if it finds the substring "replayer/coverage" in the fuzz test archive
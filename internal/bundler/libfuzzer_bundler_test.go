@@ -2,6 +2,7 @@ package bundler
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/testutil"
 	"code-intelligence.com/cifuzz/pkg/log"
 )
@@ -141,3 +143,47 @@ func TestAssembleArtifacts_Fuzzing(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedContents, actualContents)
 }
+
+func TestAssembleArtifacts_AFL(t *testing.T) {
+	projectDir := t.TempDir()
+	buildDir := filepath.Join(projectDir, "build")
+	err := os.MkdirAll(buildDir, 0o755)
+	require.NoError(t, err)
+	aflFuzzTest := filepath.Join(buildDir, "afl_fuzz_test")
+	err = os.WriteFile(aflFuzzTest, []byte("\x7fELF\x00__afl_area_ptr\x00"), 0o755)
+	require.NoError(t, err)
+	libfuzzerFuzzTest := filepath.Join(buildDir, "libfuzzer_fuzz_test")
+	err = os.WriteFile(libfuzzerFuzzTest, []byte("\x7fELF\x00LLVMFuzzerTestOneInput\x00"), 0o755)
+	require.NoError(t, err)
+
+	archiveWriter := archive.NewTarArchiveWriter(io.Discard, true)
+	b := newLibfuzzerBundler(&Opts{
+		Engine:  cmdutils.EngineAFL,
+		tempDir: testutil.MkdirTemp(t, "", "bundle-*"),
+	}, archiveWriter)
+
+	buildResult := &build.CBuildResult{
+		Name:       "afl_fuzz_test",
+		Sanitizers: []string{"address"},
+		ProjectDir: projectDir,
+		BuildResult: &build.BuildResult{
+			Executable: aflFuzzTest,
+			BuildDir:   buildDir,
+		},
+	}
+	fuzzers, _, err := b.assembleArtifacts(buildResult)
+	require.NoError(t, err)
+	require.Len(t, fuzzers, 1)
+	assert.Equal(t, "AFL", fuzzers[0].Engine)
+	assert.Equal(t, filepath.Join("afl", "address", "afl_fuzz_test", "bin", "afl_fuzz_test"), fuzzers[0].Path)
+
+	// Fuzz tests which were not built with the AFL++ compilers can't
+	// be bundled for the AFL++ engine
+	buildResult.Name = "libfuzzer_fuzz_test"
+	buildResult.Executable = libfuzzerFuzzTest
+	_, _, err = b.assembleArtifacts(buildResult)
+	require.Error(t, err)
+
+	err = archiveWriter.Close()
+	require.NoError(t, err)
+}
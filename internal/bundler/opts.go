@@ -4,44 +4,73 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"code-intelligence.com/cifuzz/internal/build"
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
+// ProgressCounter shows the progress of processing a known number of
+// items.
+type ProgressCounter interface {
+	// Increment increases the number of processed items by one
+	Increment() error
+	// Finish marks the processing as completed
+	Finish() error
+}
+
 type Opts struct {
-	Branch          string        `mapstructure:"branch"`
-	BuildCommand    string        `mapstructure:"build-command"`
-	CleanCommand    string        `mapstructure:"clean-command"`
-	BuildSystem     string        `mapstructure:"build-system"`
-	NumBuildJobs    uint          `mapstructure:"build-jobs"`
-	Commit          string        `mapstructure:"commit"`
-	Dictionary      string        `mapstructure:"dict"`
-	DockerImage     string        `mapstructure:"docker-image"`
-	EngineArgs      []string      `mapstructure:"engine-args"`
-	Env             []string      `mapstructure:"env"`
-	SeedCorpusDirs  []string      `mapstructure:"seed-corpus-dirs"`
-	Timeout         time.Duration `mapstructure:"timeout"`
-	ProjectDir      string        `mapstructure:"project-dir"`
-	ConfigDir       string        `mapstructure:"config-dir"`
-	AdditionalFiles []string      `mapstructure:"add"`
+	Branch           string        `mapstructure:"branch"`
+	BuildCommand     string        `mapstructure:"build-command"`
+	CleanCommand     string        `mapstructure:"clean-command"`
+	BuildSystem      string        `mapstructure:"build-system"`
+	NumBuildJobs     uint          `mapstructure:"build-jobs"`
+	BuildMemoryLimit uint          `mapstructure:"build-memory-limit"`
+	Commit           string        `mapstructure:"commit"`
+	Dictionary       string        `mapstructure:"dict"`
+	DockerImage      string        `mapstructure:"docker-image"`
+	Engine           string        `mapstructure:"engine"`
+	EngineArgs       []string      `mapstructure:"engine-args"`
+	Env              []string      `mapstructure:"env"`
+	SeedCorpusDirs   []string      `mapstructure:"seed-corpus-dirs"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	ProjectDir       string        `mapstructure:"project-dir"`
+	ConfigDir        string        `mapstructure:"config-dir"`
+	AdditionalFiles  []string      `mapstructure:"add"`
+	Offline          bool          `mapstructure:"offline"`
+	DisplayName      string        `mapstructure:"display-name"`
+	StripDebug       bool          `mapstructure:"strip-debug"`
 
 	// Fields which are not configurable via viper (i.e. via cifuzz.yaml
 	// and CIFUZZ_* environment variables), by setting
 	// mapstructure:"-"
 	FuzzTests       []string  `mapstructure:"-"`
 	OutputPath      string    `mapstructure:"-"`
+	Compression     string    `mapstructure:"-"`
+	SplitSize       int64     `mapstructure:"-"`
+	Reproducible    bool      `mapstructure:"-"`
 	BuildSystemArgs []string  `mapstructure:"-"`
 	ContainerArgs   []string  `mapstructure:"-"`
 	Stdout          io.Writer `mapstructure:"-"`
 	Stderr          io.Writer `mapstructure:"-"`
 	BuildStdout     io.Writer `mapstructure:"-"`
 	BuildStderr     io.Writer `mapstructure:"-"`
+	Note            string    `mapstructure:"-"`
+	NoteFile        string    `mapstructure:"-"`
+	// DependenciesJSONOutput is the writer to which the status of the
+	// checked dependencies is printed as JSON, if set
+	DependenciesJSONOutput io.Writer `mapstructure:"-"`
+	// NewProgressCounter is used to show the progress of steps which
+	// can take a while, e.g. adding the runtime dependencies of JVM
+	// fuzz tests. If nil, no progress is shown.
+	NewProgressCounter func(total int64, itemName, successMessage string) ProgressCounter `mapstructure:"-"`
 
 	tempDir string `mapstructure:"-"`
 
@@ -104,6 +133,65 @@ system type "other"`
 		}
 	}
 
+	if opts.Offline && opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+		msg := "Flag \"offline\" is only applicable for build system types \"maven\" and \"gradle\""
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	opts.BuildMemoryLimit, err = build.ValidateMemoryLimit(opts.BuildMemoryLimit, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	err = cmdutils.ValidateEngine(opts.Engine, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	if opts.StripDebug {
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemBazel &&
+			opts.BuildSystem != config.BuildSystemOther {
+			msg := "Flag \"strip-debug\" is only applicable for build system types \"cmake\", \"bazel\" and \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if runtime.GOOS != "linux" {
+			return cmdutils.WrapIncorrectUsageError(errors.New("Flag \"strip-debug\" is only supported on Linux"))
+		}
+		_, err = findObjcopy()
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.NoteFile != "" {
+		if opts.Note != "" {
+			msg := "Flags \"note\" and \"note-file\" can't be used together"
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		var note []byte
+		note, err = os.ReadFile(opts.NoteFile)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read note file %s", opts.NoteFile)
+		}
+		opts.Note = string(note)
+	}
+
+	if opts.DisplayName != "" && len(opts.FuzzTests) != 1 {
+		msg := "Flag \"display-name\" can only be used when bundling a single fuzz test"
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.Compression != "" && !sliceutil.Contains(archive.Compressions, opts.Compression) {
+		msg := fmt.Sprintf("invalid argument %q for \"--compression\" flag: must be one of %s",
+			opts.Compression, strings.Join(archive.Compressions, ", "))
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.SplitSize < 0 {
+		msg := fmt.Sprintf("invalid argument \"%d\" for \"--split-size\" flag: split size can't be negative", opts.SplitSize)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
 	if opts.Timeout != 0 && opts.Timeout < time.Second {
 		msg := fmt.Sprintf("invalid argument %q for \"--timeout\" flag: timeout can't be less than a second", opts.Timeout)
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
@@ -0,0 +1,67 @@
+package bundler
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// findObjcopy returns the path of the objcopy binary used to split the
+// debug information off the fuzzer executables. llvm-objcopy is
+// preferred because it handles compressed debug sections produced by
+// newer clang versions.
+func findObjcopy() (string, error) {
+	for _, name := range []string{"llvm-objcopy", "objcopy"} {
+		path, err := exec.LookPath(name)
+		if err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("Flag \"strip-debug\" requires llvm-objcopy or objcopy to be installed")
+}
+
+// stripDebugInfo writes a copy of the executable without debug sections
+// and a separate file containing only the debug sections to outDir.
+// The stripped executable contains a .gnu_debuglink section which
+// points to the debug file, so that debuggers and symbolizers find it
+// when both files are placed in the same directory.
+//
+//nolint:nonamedreturns
+func stripDebugInfo(executable, outDir string) (strippedPath, debugPath string, err error) {
+	objcopy, err := findObjcopy()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = os.MkdirAll(outDir, 0o755)
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	strippedPath = filepath.Join(outDir, filepath.Base(executable))
+	debugPath = strippedPath + ".debug"
+
+	err = runObjcopy(objcopy, "--only-keep-debug", executable, debugPath)
+	if err != nil {
+		return "", "", err
+	}
+	err = runObjcopy(objcopy, "--strip-debug", "--add-gnu-debuglink="+debugPath, executable, strippedPath)
+	if err != nil {
+		return "", "", err
+	}
+	return strippedPath, debugPath, nil
+}
+
+func runObjcopy(objcopy string, args ...string) error {
+	cmd := exec.Command(objcopy, args...)
+	log.Debugf("Command: %s", cmd.String())
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "%s failed: %s", cmd.String(), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
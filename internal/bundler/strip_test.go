@@ -0,0 +1,58 @@
+package bundler
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/testutil"
+)
+
+func TestStripDebugInfo(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("stripping debug information is only supported on Linux")
+	}
+	if _, err := findObjcopy(); err != nil {
+		t.Skip(err)
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler found")
+	}
+
+	tempDir := testutil.MkdirTemp(t, "", "strip-*")
+	src := filepath.Join(tempDir, "main.c")
+	err = os.WriteFile(src, []byte("int main(void) { return 0; }\n"), 0o644)
+	require.NoError(t, err)
+	executable := filepath.Join(tempDir, "my_fuzz_test")
+	out, err := exec.Command(cc, "-g", "-o", executable, src).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	strippedPath, debugPath, err := stripDebugInfo(executable, filepath.Join(tempDir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, "out", "my_fuzz_test"), strippedPath)
+	assert.Equal(t, strippedPath+".debug", debugPath)
+
+	stripped, err := elf.Open(strippedPath)
+	require.NoError(t, err)
+	defer stripped.Close()
+	assert.Nil(t, stripped.Section(".debug_info"))
+	assert.NotNil(t, stripped.Section(".gnu_debuglink"))
+
+	debug, err := elf.Open(debugPath)
+	require.NoError(t, err)
+	defer debug.Close()
+	assert.NotNil(t, debug.Section(".debug_info"))
+
+	// The original executable is left untouched
+	original, err := elf.Open(executable)
+	require.NoError(t, err)
+	defer original.Close()
+	assert.NotNil(t, original.Section(".debug_info"))
+}
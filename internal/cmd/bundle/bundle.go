@@ -6,12 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"code-intelligence.com/cifuzz/internal/bundler"
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmd/remoterun/progress"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/cmdutils/logging"
 	"code-intelligence.com/cifuzz/internal/cmdutils/resolve"
@@ -19,10 +23,16 @@ import (
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/version"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/vcs"
 )
 
 type options struct {
 	bundler.Opts `mapstructure:",squash"`
+
+	changedSince string
+	// Set if none of the fuzz tests is affected by the changes since
+	// the changedSince ref, in which case no bundle is created
+	noAffectedFuzzTests bool
 }
 
 func (opts *options) Validate() error {
@@ -116,6 +126,23 @@ on the build system. This can be overridden with a docker-image flag.
 
   If no fuzz tests are specified, all fuzz tests are added to the bundle.
 
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Changed fuzz tests") + `
+  With --changed-since <git ref>, only the fuzz tests affected by the
+  changes since the git ref (including uncommitted changes) are added to
+  the bundle:
+
+    cifuzz bundle --changed-since origin/main
+
+  The changes are determined like by 'git diff <git ref>...HEAD', i.e.
+  since the merge base of the git ref and HEAD.
+
+  For CMake and Bazel, a fuzz test is affected if its fuzz test source
+  changed. For Maven and Gradle, a fuzz test class is affected if it's
+  part of or imports from the package of a changed source, directly or
+  via other packages. If a change can't be mapped to fuzz tests with
+  certainty, e.g. because a build file, another source or any other
+  file changed, all fuzz tests are added to the bundle.
+
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Other build systems") + `
   <fuzz test> is either the path or basename of the fuzz test executable
   created by the build command. If it's the basename, it will be searched
@@ -190,26 +217,51 @@ on the build system. This can be overridden with a docker-image flag.
 			}
 
 			var fuzzTests []string
-			fuzzTests, err = resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir)
+			fuzzTests, err = resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
 			if err != nil {
 				return err
 			}
+			if opts.changedSince != "" {
+				if len(fuzzTests) > 0 {
+					msg := "Flag \"changed-since\" can't be used together with <fuzz test> arguments"
+					return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+				}
+				fuzzTests, err = opts.affectedFuzzTests()
+				if err != nil {
+					return err
+				}
+			}
 			opts.FuzzTests = fuzzTests
 			opts.BuildSystemArgs = argsToPass
 
+			// The options are validated even if no fuzz test is
+			// affected by the changes, so that invalid options are
+			// reported independently of the changes
 			return opts.Validate()
 		},
 		RunE: func(c *cobra.Command, args []string) error {
+			if opts.noAffectedFuzzTests {
+				log.Infof("None of the fuzz tests is affected by the changes since %s, no bundle was created", opts.changedSince)
+				return nil
+			}
+
 			buildPrinter := logging.NewBuildPrinter(os.Stdout, log.BundleInProgressMsg)
 
-			_, err := bundler.New(&opts.Opts).Bundle()
+			if term.IsTerminal(int(os.Stdout.Fd())) {
+				opts.NewProgressCounter = NewProgressCounter
+			}
+			bundlePath, err := bundler.New(&opts.Opts).Bundle()
 			if err != nil {
 				buildPrinter.StopOnError(log.BundleInProgressErrorMsg)
 				return err
 			}
 
 			buildPrinter.StopOnSuccess(log.BundleInProgressSuccessMsg, true)
-			log.Successf("Successfully created bundle: %s", opts.OutputPath)
+			if strings.HasSuffix(bundlePath, archive.SplitManifestSuffix) {
+				log.Successf("Successfully created bundle split into parts: %s", bundlePath)
+				return nil
+			}
+			log.Successf("Successfully created bundle: %s", bundlePath)
 
 			return nil
 		},
@@ -221,21 +273,91 @@ on the build system. This can be overridden with a docker-image flag.
 		cmdutils.AddBuildCommandFlag,
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
+		cmdutils.AddBuildMemoryLimitFlag,
 		cmdutils.AddCommitFlag,
 		cmdutils.AddDictFlag,
+		cmdutils.AddDisplayNameFlag,
 		cmdutils.AddDockerImageFlagForBundleCommand,
+		cmdutils.AddEngineFlag,
 		cmdutils.AddEngineArgFlag,
 		cmdutils.AddEnvFlag,
+		cmdutils.AddOfflineFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddSeedCorpusFlag,
+		cmdutils.AddStripDebugFlag,
 		cmdutils.AddTimeoutFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	)
-	cmd.Flags().StringVarP(&opts.OutputPath, "output", "o", "", "Output path of the bundle (.tar.gz)")
+	cmd.Flags().StringVarP(&opts.OutputPath, "output", "o", "", "Output path of the bundle (.tar.gz, .tar.zst or .tar)")
+	cmd.Flags().StringVar(&opts.Note, "note", "",
+		"A human-readable note which is stored in the bundle, e.g. who built it or a\n"+
+			"ticket number. It is printed when the bundle is executed.")
+	cmd.Flags().StringVar(&opts.NoteFile, "note-file", "",
+		"Read the note which is stored in the bundle from the specified `file`.")
+	cmd.Flags().StringVar(&opts.changedSince, "changed-since", "",
+		"Only bundle the fuzz tests affected by the changes since the specified git `ref`.")
+	cmd.Flags().StringVar(&opts.Compression, "compression", archive.CompressionGzip,
+		"Compression of the bundle (gzip/zstd/none). Bundles which are zstd-compressed\n"+
+			"have the extension .tar.zst, bundles which are not compressed have the\n"+
+			"extension .tar. Bundles which are not gzip-compressed can't be used for\n"+
+			"remote runs.")
+
+	cmd.Flags().Int64Var(&opts.SplitSize, "split-size", 0,
+		"Split the bundle into parts of at most the specified number of `bytes` if it's\n"+
+			"larger. The parts are named <bundle>.partN and a manifest describing how to\n"+
+			"reassemble them is written to <bundle>"+archive.SplitManifestSuffix+". Split bundles\n"+
+			"are reassembled by 'cifuzz execute', but can't be used for remote runs.")
+	cmd.Flags().BoolVar(&opts.Reproducible, "reproducible", false,
+		"Create a byte-identical bundle when bundling the same files again, by sorting\n"+
+			"the entries of the bundle and normalizing their modification times and owners.")
+
+	cmd.AddCommand(newVerifyRunnableCmd())
 
 	return cmd
 }
 
+// affectedFuzzTests returns the fuzz tests affected by the changes
+// since the changedSince ref. If the changes can't be mapped to fuzz
+// tests or if no fuzz test is affected, it returns the arguments which
+// select all fuzz tests (in the latter case, noAffectedFuzzTests is set
+// and no bundle is created).
+func (opts *options) affectedFuzzTests() ([]string, error) {
+	if opts.BuildSystem == config.BuildSystemOther {
+		msg := fmt.Sprintf("Flag \"changed-since\" is not supported for build system type %q", opts.BuildSystem)
+		return nil, cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	var allFuzzTests []string
+	if opts.BuildSystem == config.BuildSystemBazel {
+		// Bazel requires target patterns
+		allFuzzTests = []string{"//..."}
+	}
+
+	// Git reports the changed files relative to the resolved
+	// repository root
+	projectDir, err := filepath.EvalSymlinks(opts.ProjectDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	changedFiles, err := vcs.GitChangedFiles(projectDir, opts.changedSince)
+	if err != nil {
+		return nil, err
+	}
+	fuzzTests, ok, err := resolve.ChangedFuzzTests(changedFiles, opts.BuildSystem, projectDir, opts.Offline)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Infof("The changes since %s can't be mapped to fuzz tests, bundling all fuzz tests", opts.changedSince)
+		return allFuzzTests, nil
+	}
+	if len(fuzzTests) == 0 {
+		opts.noAffectedFuzzTests = true
+		return allFuzzTests, nil
+	}
+	log.Infof("Fuzz tests affected by the changes since %s: %s", opts.changedSince, strings.Join(fuzzTests, ", "))
+	return fuzzTests, nil
+}
+
 // SetUpBundleLogging configures the verbose log and build log file for the bundle command.
 func SetUpBundleLogging(stdout, stderr io.Writer, opts *bundler.Opts) error {
 	var err error
@@ -280,3 +402,8 @@ func SetUpBundleLogging(stdout, stderr io.Writer, opts *bundler.Opts) error {
 	opts.BuildStderr = io.MultiWriter(stderr, log.VerboseSecondaryOutput)
 	return nil
 }
+
+// NewProgressCounter shows the progress of the bundler in the terminal
+func NewProgressCounter(total int64, itemName, successMessage string) bundler.ProgressCounter {
+	return progress.NewCounter(total, itemName, successMessage)
+}
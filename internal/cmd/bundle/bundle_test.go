@@ -3,6 +3,7 @@ package bundle
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"code-intelligence.com/cifuzz/internal/bundler"
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/testutil"
@@ -113,7 +115,7 @@ func TestEnvVarsSetInConfigFile(t *testing.T) {
 
 	t.Setenv("BAR", "bar")
 
-	opts := &options{bundler.Opts{
+	opts := &options{Opts: bundler.Opts{
 		ProjectDir:  projectDir,
 		ConfigDir:   projectDir,
 		BuildSystem: config.BuildSystemCMake,
@@ -125,3 +127,99 @@ func TestEnvVarsSetInConfigFile(t *testing.T) {
 
 	require.Equal(t, []string{"FOO=foo", "BAR=bar"}, opts.Env)
 }
+
+func TestChangedSinceWithoutAffectedFuzzTests(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "bundle-test-")
+	t.Cleanup(func() { fileutil.Cleanup(projectDir) })
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "-A"},
+		{"-c", "user.email=you@example.com", "-c", "user.name=Your Name", "commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = projectDir
+		err := cmd.Run()
+		require.NoError(t, err)
+	}
+
+	// Without changes, no fuzz test is affected
+	opts := &options{Opts: bundler.Opts{
+		ProjectDir:  projectDir,
+		ConfigDir:   projectDir,
+		BuildSystem: config.BuildSystemCMake,
+	}}
+	cmd := newWithOptions(opts)
+	err := cmd.Flags().Set("changed-since", "HEAD")
+	require.NoError(t, err)
+	err = cmd.PreRunE(cmd, nil)
+	require.NoError(t, err)
+	assert.True(t, opts.noAffectedFuzzTests)
+
+	// The options are validated even if no fuzz test is affected
+	opts = &options{Opts: bundler.Opts{
+		ProjectDir:  projectDir,
+		ConfigDir:   projectDir,
+		BuildSystem: config.BuildSystemCMake,
+	}}
+	cmd = newWithOptions(opts)
+	err = cmd.Flags().Set("changed-since", "HEAD")
+	require.NoError(t, err)
+	err = cmd.Flags().Set("split-size", "-1")
+	require.NoError(t, err)
+	err = cmd.PreRunE(cmd, nil)
+	require.Error(t, err)
+
+	// Changes of files which are not sources affect all fuzz tests
+	err = os.WriteFile(filepath.Join(projectDir, "README.md"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	opts = &options{Opts: bundler.Opts{
+		ProjectDir:  projectDir,
+		ConfigDir:   projectDir,
+		BuildSystem: config.BuildSystemCMake,
+	}}
+	cmd = newWithOptions(opts)
+	err = cmd.Flags().Set("changed-since", "HEAD")
+	require.NoError(t, err)
+	err = cmd.PreRunE(cmd, nil)
+	require.NoError(t, err)
+	assert.False(t, opts.noAffectedFuzzTests)
+	assert.Empty(t, opts.FuzzTests)
+
+	// The flag can't be combined with fuzz test arguments
+	opts = &options{Opts: bundler.Opts{
+		ProjectDir:  projectDir,
+		ConfigDir:   projectDir,
+		BuildSystem: config.BuildSystemCMake,
+	}}
+	cmd = newWithOptions(opts)
+	err = cmd.Flags().Set("changed-since", "HEAD")
+	require.NoError(t, err)
+	err = cmd.PreRunE(cmd, []string{"my_fuzz_test"})
+	require.Error(t, err)
+}
+
+func TestPrepareSmokeRun(t *testing.T) {
+	metadata := &archive.Metadata{
+		Fuzzers: []*archive.Fuzzer{
+			{
+				Target:        "my_fuzz_test",
+				Engine:        "LIBFUZZER",
+				EngineOptions: archive.EngineOptions{Flags: []string{"-runs=1000", "-max_total_time=60", "-dict=dict"}},
+			},
+			{
+				Target: "my_fuzz_test",
+				Engine: "LLVM_COV",
+			},
+			{
+				Name:   "com.example.FuzzTestCase::myFuzzTest",
+				Engine: "JAVA_LIBFUZZER",
+			},
+		},
+	}
+
+	fuzzTests := prepareSmokeRun(metadata)
+	assert.Equal(t, []string{"my_fuzz_test", "com.example.FuzzTestCase::myFuzzTest"}, fuzzTests)
+	assert.Equal(t, []string{"-dict=dict", "-runs=1"}, metadata.Fuzzers[0].EngineOptions.Flags)
+	assert.Empty(t, metadata.Fuzzers[1].EngineOptions.Flags)
+	assert.Equal(t, []string{"-runs=1"}, metadata.Fuzzers[2].EngineOptions.Flags)
+}
@@ -0,0 +1,145 @@
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+func newVerifyRunnableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-runnable <bundle>",
+		Short: "Check that the fuzz tests in a bundle can be executed",
+		Long: `This command extracts the given bundle and executes each fuzz test
+in it for a single run, to catch problems like missing runtime
+dependencies, a wrong class path or missing libraries before the
+bundle is uploaded.`,
+		Example: "cifuzz bundle verify-runnable fuzz_tests.tar.gz",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return verifyRunnable(args[0])
+		},
+	}
+	cmdutils.DisableConfigCheck(cmd)
+
+	return cmd
+}
+
+func verifyRunnable(bundlePath string) error {
+	// The fuzz tests are executed via 'cifuzz execute', which is not
+	// supported on Windows
+	if runtime.GOOS == "windows" {
+		return errors.Errorf(config.NotSupportedErrorMessage("bundle verify-runnable", runtime.GOOS))
+	}
+
+	cifuzzExecutable, err := os.Executable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	bundleDir, err := os.MkdirTemp("", "cifuzz-bundle-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(bundleDir)
+
+	err = archive.Extract(bundlePath, bundleDir)
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to extract bundle %s", bundlePath)
+	}
+
+	metadataPath := filepath.Join(bundleDir, archive.MetadataFileName)
+	metadata, err := archive.MetadataFromPath(metadataPath)
+	if err != nil {
+		return err
+	}
+
+	fuzzTests := prepareSmokeRun(metadata)
+	if len(fuzzTests) == 0 {
+		return errors.Errorf("Bundle %s doesn't contain any fuzz tests", bundlePath)
+	}
+
+	metadataYaml, err := metadata.ToYaml()
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(metadataPath, metadataYaml, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var failed []string
+	for i, fuzzTest := range fuzzTests {
+		corpusDir := filepath.Join(bundleDir, ".cifuzz-verify", fmt.Sprint(i))
+		cmd := exec.Command(cifuzzExecutable, "execute", fuzzTest,
+			"--generated-corpus-dir", filepath.Join(corpusDir, "generated"),
+			"--managed-corpus-dir", filepath.Join(corpusDir, "managed"),
+		)
+		cmd.Dir = bundleDir
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		log.Debugf("Command: %s", cmd.String())
+		err = cmd.Run()
+		if viper.GetBool("verbose") {
+			log.Print(output.String())
+		}
+		if err != nil {
+			log.Errorf(err, "%s: Failed to run:\n%s", fuzzTest, strings.TrimSpace(output.String()))
+			failed = append(failed, fuzzTest)
+			continue
+		}
+		log.Successf("%s: OK", fuzzTest)
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("%d of %d fuzz tests in the bundle failed to run: %s",
+			len(failed), len(fuzzTests), strings.Join(failed, ", "))
+	}
+	log.Successf("All %d fuzz tests in the bundle can be executed", len(fuzzTests))
+	return nil
+}
+
+// prepareSmokeRun modifies the metadata of the bundle so that each
+// fuzz test is only executed for a single run and returns the names of
+// the fuzz tests. The coverage binaries are not executed.
+func prepareSmokeRun(metadata *archive.Metadata) []string {
+	var fuzzTests []string
+	for _, fuzzer := range metadata.Fuzzers {
+		if fuzzer.Engine == "LLVM_COV" {
+			continue
+		}
+
+		// Remove flags which limit the run, which would conflict with
+		// the -runs flag
+		var flags []string
+		for _, flag := range fuzzer.EngineOptions.Flags {
+			if strings.HasPrefix(flag, "-runs=") || strings.HasPrefix(flag, "-max_total_time=") {
+				continue
+			}
+			flags = append(flags, flag)
+		}
+		fuzzer.EngineOptions.Flags = append(flags, "-runs=1")
+
+		name := fuzzer.Name
+		if name == "" {
+			name = fuzzer.Target
+		}
+		fuzzTests = append(fuzzTests, name)
+	}
+	return fuzzTests
+}
@@ -24,6 +24,7 @@ type containerRemoteRunOpts struct {
 	bundler.Opts `mapstructure:",squash"`
 	Interactive  bool   `mapstructure:"interactive"`
 	Server       string `mapstructure:"server"` // CI Sense
+	Proxy        string `mapstructure:"proxy"`
 	PrintJSON    bool   `mapstructure:"print-json"`
 	Project      string `mapstructure:"project"` // CI Sense
 	Registry     string `mapstructure:"registry"`
@@ -69,13 +70,18 @@ func newWithOptions(opts *containerRemoteRunOpts) *cobra.Command {
 				return errors.WithStack(err)
 			}
 
-			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir)
+			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
 			if err != nil {
 				return err
 			}
 			opts.FuzzTests = fuzzTests
 			opts.BuildSystemArgs = argsToPass
 
+			err = api.ValidateProxyURL(opts.Proxy)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+
 			return opts.Validate()
 		},
 		RunE: func(c *cobra.Command, args []string) error {
@@ -87,6 +93,7 @@ func newWithOptions(opts *containerRemoteRunOpts) *cobra.Command {
 
 			cmd := &containerRemoteRunCmd{Command: c, opts: opts}
 			cmd.apiClient = api.NewClient(opts.Server)
+			cmd.apiClient.Proxy = opts.Proxy
 			return cmd.run()
 		},
 	}
@@ -105,6 +112,7 @@ func newWithOptions(opts *containerRemoteRunOpts) *cobra.Command {
 		cmdutils.AddPrintJSONFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddProjectFlag,
+		cmdutils.AddProxyFlag,
 		cmdutils.AddRegistryFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
@@ -118,7 +126,7 @@ func newWithOptions(opts *containerRemoteRunOpts) *cobra.Command {
 func (c *containerRemoteRunCmd) run() error {
 	var err error
 
-	token, err := auth.EnsureValidToken(c.opts.Server)
+	token, err := auth.EnsureValidToken(c.apiClient)
 	if err != nil {
 		return err
 	}
@@ -159,6 +167,7 @@ func (c *containerRemoteRunCmd) run() error {
 		// We only want JSON output on stdout, so we print the build
 		// output to stderr.
 		buildOutput = c.ErrOrStderr()
+		c.opts.DependenciesJSONOutput = os.Stdout
 	}
 	buildPrinter := logging.NewBuildPrinter(buildOutput, log.ContainerBuildInProgressMsg)
 	imageID, err := c.buildImage()
@@ -207,5 +216,5 @@ func (c *containerRemoteRunCmd) buildImage() (string, error) {
 		return "", err
 	}
 
-	return container.BuildImageFromBundle(bundlePath)
+	return container.BuildImageFromBundle(bundlePath, &container.ImageBuildOptions{})
 }
@@ -3,6 +3,7 @@ package run
 import (
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -27,9 +28,12 @@ type containerRunOpts struct {
 	PrintJSON     bool     `mapstructure:"print-json"`
 	Interactive   bool     `mapstructure:"interactive"`
 	Server        string   `mapstructure:"server"`
+	Proxy         string   `mapstructure:"proxy"`
 	ContainerPath string   `mapstructure:"container"`
 	BindMounts    []string `mapstructure:"bind-mounts"`
 	BuildOnly     bool     `mapstructure:"build-only"`
+	Dockerfile    string   `mapstructure:"dockerfile"`
+	ContextDir    string   `mapstructure:"context"`
 }
 
 type containerRunCmd struct {
@@ -42,6 +46,33 @@ func New() *cobra.Command {
 }
 
 func (opts *containerRunOpts) Validate() error {
+	err := api.ValidateProxyURL(opts.Proxy)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
+	if opts.Dockerfile != "" {
+		info, err := os.Stat(opts.Dockerfile)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to access Dockerfile %s", opts.Dockerfile)
+		}
+		if info.IsDir() {
+			msg := fmt.Sprintf("Flag \"dockerfile\" must be a file, but %s is a directory", opts.Dockerfile)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	if opts.ContextDir != "" {
+		info, err := os.Stat(opts.ContextDir)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to access build context %s", opts.ContextDir)
+		}
+		if !info.IsDir() {
+			msg := fmt.Sprintf("Flag \"context\" must be a directory, but %s is a file", opts.ContextDir)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
 	return opts.Opts.Validate()
 }
 
@@ -89,7 +120,7 @@ container is built and run locally instead of being pushed to a CI Sense server.
 				return err
 			}
 
-			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir)
+			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
 			if err != nil {
 				return err
 			}
@@ -119,6 +150,7 @@ container is built and run locally instead of being pushed to a CI Sense server.
 		cmdutils.AddPrintJSONFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddProjectFlag,
+		cmdutils.AddProxyFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
 		cmdutils.AddTimeoutFlag,
@@ -128,6 +160,10 @@ container is built and run locally instead of being pushed to a CI Sense server.
 	cmd.Flags().StringArrayVar(&opts.BindMounts, "bind", nil, "Bind mount a directory from the host into the container. "+
 		"Format: --bind <src-path>:<dest-path>")
 	cmd.Flags().BoolVar(&opts.BuildOnly, "build-only", false, "Only build the container image, don't run it.")
+	cmd.Flags().StringVar(&opts.Dockerfile, "dockerfile", "", "Path of a custom `Dockerfile` which is used instead of the default one to build the container image.\n"+
+		"The contents of the bundle are available in the root of the build context.")
+	cmd.Flags().StringVar(&opts.ContextDir, "context", "", "A `directory` whose contents are added to the build context of the container image,\n"+
+		"e.g. to provide additional files to a custom Dockerfile.")
 
 	// For now the --bind flag is only used for tests, so we hide it from the help output.
 	err := cmd.Flags().MarkHidden("bind")
@@ -139,7 +175,9 @@ container is built and run locally instead of being pushed to a CI Sense server.
 }
 
 func (c *containerRunCmd) run() error {
-	authenticated, err := auth.HasValidToken(c.opts.Server)
+	apiClient := api.NewClient(c.opts.Server)
+	apiClient.Proxy = c.opts.Proxy
+	authenticated, err := auth.HasValidToken(apiClient)
 	if err != nil {
 		var connErr *api.ConnectionError
 		if !errors.As(err, &connErr) {
@@ -157,6 +195,7 @@ func (c *containerRunCmd) run() error {
 		// We only want JSON output on stdout, so we print the build
 		// output to stderr.
 		buildOutput = c.ErrOrStderr()
+		c.opts.DependenciesJSONOutput = c.OutOrStdout()
 	}
 	buildPrinter := logging.NewBuildPrinter(buildOutput, log.ContainerBuildInProgressMsg)
 	imageID, err := c.buildContainerImage(buildOutput)
@@ -196,5 +235,8 @@ func (c *containerRunCmd) buildContainerImage(buildOutput io.Writer) (string, er
 		return "", errors.WithMessage(err, "Failed to create bundle")
 	}
 
-	return container.BuildImageFromBundle(bundlePath)
+	return container.BuildImageFromBundle(bundlePath, &container.ImageBuildOptions{
+		Dockerfile: c.opts.Dockerfile,
+		ContextDir: c.opts.ContextDir,
+	})
 }
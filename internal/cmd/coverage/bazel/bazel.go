@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -35,6 +36,21 @@ type CoverageGenerator struct {
 	BuildStdout     io.Writer
 	BuildStderr     io.Writer
 	Verbose         bool
+	FunctionFilter  *regexp.Regexp
+	BuildEnv        []string
+	// MemoryLimit is passed to bazel via --local_ram_resources, so it
+	// only limits how many local actions bazel runs in parallel. No
+	// limit is applied if it's 0.
+	MemoryLimit uint
+
+	summary *coverage.Summary
+}
+
+// Summary returns the coverage summary of the lcov report produced by
+// bazel coverage, or nil if no report was generated yet. Only the
+// matching functions are included if a function filter was specified.
+func (cov *CoverageGenerator) Summary() *coverage.Summary {
+	return cov.summary
 }
 
 // symlinkUserInputsToGeneratedCorpus handles user defined inputs set via
@@ -212,11 +228,25 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 		return "", errors.WithStack(err)
 	}
 	reportReader := strings.NewReader(string(lcovReportContent))
-	summary, err := coverage.ParseLCOVReportIntoSummary(reportReader)
-	if err != nil {
-		return "", err
+	if cov.FunctionFilter != nil {
+		lcovReport, err := coverage.ParseLCOVFileIntoLCOVReport(reportReader)
+		if err != nil {
+			return "", err
+		}
+		coverage.PrintFunctionTable(lcovReport.FilterFunctions(cov.FunctionFilter), cov.FunctionFilter, cov.Stderr)
+		// The written reports and the summary only contain the
+		// coverage of the matching functions
+		filtered := lcovReport.FilterByFunctions(cov.FunctionFilter)
+		cov.summary = filtered.Summary()
+		lcovReportContent = []byte(filtered.String())
+	} else {
+		summary, err := coverage.ParseLCOVReportIntoSummary(reportReader)
+		if err != nil {
+			return "", err
+		}
+		summary.PrintTable(cov.Stderr)
+		cov.summary = summary
 	}
-	summary.PrintTable(cov.Stderr)
 
 	commonFlags, err := cov.getBazelCommandFlags()
 	if err != nil {
@@ -236,10 +266,7 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 		// to 0o644 before umask - copy.Copy just copies the permissions
 		// from the source file, which has permissions 555 like all
 		// files created by bazel.
-		content, err := os.ReadFile(reportPath)
-		if err != nil {
-			return "", errors.WithStack(err)
-		}
+		content := []byte(coverage.AddTestName(string(lcovReportContent), cov.FuzzTest))
 		err = os.WriteFile(cov.OutputPath, content, 0o644)
 		if err != nil {
 			return "", errors.WithStack(err)
@@ -261,6 +288,20 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 		cov.OutputPath = filepath.Join(outputDir, path)
 	}
 
+	if cov.FunctionFilter != nil {
+		// Create the HTML report from the filtered lcov report
+		tmpDir, err := os.MkdirTemp("", "coverage-")
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		defer fileutil.Cleanup(tmpDir)
+		reportPath = filepath.Join(tmpDir, "coverage.lcov")
+		err = os.WriteFile(reportPath, lcovReportContent, 0o644)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+
 	// Create an HTML report via genhtml
 	genHTML, err := runfiles.Finder.GenHTMLPath()
 	if err != nil {
@@ -287,6 +328,10 @@ func (cov *CoverageGenerator) getBazelCommandFlags() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	env, err = build.SetBuildEnv(env, cov.BuildEnv)
+	if err != nil {
+		return nil, err
+	}
 
 	flags := []string{
 		"--repo_env=CC=" + envutil.Getenv(env, "CC"),
@@ -294,9 +339,13 @@ func (cov *CoverageGenerator) getBazelCommandFlags() ([]string, error) {
 		// Don't use the LLVM from Xcode
 		"--repo_env=BAZEL_USE_CPP_ONLY_TOOLCHAIN=1",
 	}
+	flags = append(flags, build.BazelBuildEnvFlags(cov.BuildEnv)...)
 	if cov.NumJobs != 0 {
 		flags = append(flags, "--jobs", fmt.Sprint(cov.NumJobs))
 	}
+	if cov.MemoryLimit != 0 {
+		flags = append(flags, "--local_ram_resources="+fmt.Sprint(cov.MemoryLimit))
+	}
 
 	llvmCov, err := runfiles.Finder.LLVMCovPath()
 	if err != nil {
@@ -5,15 +5,20 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/browser"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
 
+	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/java/gradle"
 	"code-intelligence.com/cifuzz/internal/build/java/maven"
 	bazelCoverage "code-intelligence.com/cifuzz/internal/cmd/coverage/bazel"
@@ -28,6 +33,9 @@ import (
 	"code-intelligence.com/cifuzz/internal/coverage"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
+	parser "code-intelligence.com/cifuzz/pkg/parser/coverage"
+	"code-intelligence.com/cifuzz/pkg/vcs"
+	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/sliceutil"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
@@ -35,24 +43,73 @@ import (
 type Generator interface {
 	BuildFuzzTestForCoverage() error
 	GenerateCoverageReport() (string, error)
+	Summary() *parser.Summary
 }
 
+// MultiFormatGenerator is a Generator which can write reports of
+// multiple formats from the coverage data collected by running the
+// fuzz test once.
+type MultiFormatGenerator interface {
+	Generator
+	GenerateCoverageReports(outputPaths map[string]string) error
+}
+
+// multiFormats are the formats which can be combined via a
+// comma-separated list in the format flag
+var multiFormats = []string{coverage.FormatHTML, coverage.FormatLCOV, coverage.FormatCobertura}
+
+var javaPackageRegex = regexp.MustCompile(`^[\p{L}_$][\p{L}\p{N}_$]*(\.[\p{L}_$][\p{L}\p{N}_$]*)*$`)
+
 type coverageOptions struct {
-	OutputFormat string   `mapstructure:"format"`
-	OutputPath   string   `mapstructure:"output"`
-	BuildSystem  string   `mapstructure:"build-system"`
-	BuildCommand string   `mapstructure:"build-command"`
-	CleanCommand string   `mapstructure:"clean-command"`
-	NumBuildJobs uint     `mapstructure:"build-jobs"`
-	CorpusDirs   []string `mapstructure:"corpus-dirs"`
-	UseSandbox   bool     `mapstructure:"use-sandbox"`
-	EngineArgs   []string `mapstructure:"engine-args"`
+	OutputFormat     string   `mapstructure:"format"`
+	OutputPath       string   `mapstructure:"output"`
+	OutputDir        string   `mapstructure:"output-dir"`
+	BuildSystem      string   `mapstructure:"build-system"`
+	BuildCommand     string   `mapstructure:"build-command"`
+	CleanCommand     string   `mapstructure:"clean-command"`
+	NumBuildJobs     uint     `mapstructure:"build-jobs"`
+	BuildMemoryLimit uint     `mapstructure:"build-memory-limit"`
+	CorpusDirs       []string `mapstructure:"corpus-dirs"`
+	UseSandbox       bool     `mapstructure:"use-sandbox"`
+	EngineArgs       []string `mapstructure:"engine-args"`
+	Packages         []string `mapstructure:"coverage-packages"`
+	StripPaths       bool     `mapstructure:"strip-paths"`
+	StripPathPrefix  string   `mapstructure:"strip-path-prefix"`
+	Symbolizer       string   `mapstructure:"symbolizer"`
+	ExecFile         string   `mapstructure:"exec-file"`
+	ClassFiles       string   `mapstructure:"classfiles"`
+	SkipBuild        bool     `mapstructure:"skip-build"`
+	BuildEnv         []string `mapstructure:"build-env"`
+	OutputRoot       string   `mapstructure:"output-root"`
+	ClassPaths       []string `mapstructure:"classpath"`
+	NativeLibPaths   []string `mapstructure:"native-lib-path"`
+	Offline          bool     `mapstructure:"offline"`
+	Badge            string   `mapstructure:"badge"`
+	BadgeLabel       string   `mapstructure:"badge-label"`
+	Jobs             uint     `mapstructure:"jobs"`
+	Merge            bool     `mapstructure:"merge"`
+	Inputs           []string `mapstructure:"inputs"`
 
+	CoveredFilesOut string `mapstructure:"covered-files-out"`
+	CorpusFromGit   string `mapstructure:"corpus-from-git"`
+
+	FailUnder     float64 `mapstructure:"fail-under"`
+	FailUnderFile float64 `mapstructure:"fail-under-file"`
+
+	IncludeUncoveredFiles bool   `mapstructure:"include-uncovered-files"`
+	PerInput              bool   `mapstructure:"per-input"`
+	PerInputOutput        string `mapstructure:"per-input-output"`
+
+	NoResolveSourcePath   bool `mapstructure:"no-resolve-source-path"`
 	ResolveSourceFilePath bool
 	Preset                string
 	ProjectDir            string
+	Function              string
 
+	fuzzTests       []*fuzzTestTarget
+	outputFormats   []string
 	fuzzTest        string
+	functionFilter  *regexp.Regexp
 	targetMethod    string
 	testNamePattern string
 	argsToPass      []string
@@ -60,14 +117,104 @@ type coverageOptions struct {
 	buildStderr     io.Writer
 }
 
+// fuzzTestTarget is a fuzz test for which coverage is generated,
+// together with the method of a JVM fuzz test or the test name pattern
+// of a Node.js fuzz test specified in its argument
+type fuzzTestTarget struct {
+	fuzzTest        string
+	targetMethod    string
+	testNamePattern string
+}
+
+// validateMerge validates the options for merging existing lcov
+// reports via --merge, for which no fuzz test is built or run.
+func (opts *coverageOptions) validateMerge() error {
+	if len(opts.Inputs) == 0 {
+		msg := `Flag 'input' must be specified at least once when using 'merge'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	for _, input := range opts.Inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			if os.IsNotExist(err) {
+				msg := fmt.Sprintf("lcov report %s passed via 'input' doesn't exist", input)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+			return errors.WithStack(err)
+		}
+		if info.IsDir() {
+			msg := fmt.Sprintf("lcov report %s passed via 'input' must be a file", input)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	validFormats := []string{coverage.FormatLCOV, coverage.FormatCobertura, coverage.FormatSonarQube, coverage.FormatJUnit, coverage.FormatSARIF}
+	if !stringutil.Contains(validFormats, opts.OutputFormat) {
+		msg := fmt.Sprintf("Flag \"format\" must be %s when using 'merge'", strings.Join(validFormats, " or "))
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.Function != "" {
+		msg := `Flag 'function' can't be used with 'merge'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if opts.FailUnder < 0 || opts.FailUnder > 100 || opts.FailUnderFile < 0 || opts.FailUnderFile > 100 {
+		msg := `Flags 'fail-under' and 'fail-under-file' must be a percentage between 0 and 100`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	var err error
+	opts.StripPathPrefix, err = cmdutils.ValidateStripPathPrefix(opts.StripPathPrefix, opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseMergeConfig parses the project config into the options if the
+// command is run in a cifuzz project. Merging existing lcov reports
+// doesn't build anything, so it also works outside of a project, in
+// which case the options are only set from the flags and the project
+// directory defaults to the working directory.
+func parseMergeConfig(opts *coverageOptions) error {
+	err := config.FindAndParseProjectConfig(opts)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err = viper.Unmarshal(opts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if opts.ProjectDir == "" {
+		opts.ProjectDir, err = os.Getwd()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
 func (opts *coverageOptions) validate() error {
 	var err error
 
+	if len(opts.Inputs) > 0 {
+		msg := `Flag 'input' can only be used together with 'merge'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
 	opts.CorpusDirs, err = cmdutils.ValidateCorpusDirs(opts.CorpusDirs)
 	if err != nil {
 		return err
 	}
 
+	if opts.Symbolizer != "" {
+		opts.Symbolizer, err = cmdutils.ValidateSymbolizer(opts.Symbolizer)
+		if err != nil {
+			return err
+		}
+	}
+
 	if opts.BuildSystem == "" {
 		opts.BuildSystem, err = config.DetermineBuildSystem(opts.ProjectDir)
 		if err != nil {
@@ -81,9 +228,20 @@ func (opts *coverageOptions) validate() error {
 	}
 
 	validFormats := coverage.ValidOutputFormats[opts.BuildSystem]
-	if !stringutil.Contains(validFormats, opts.OutputFormat) {
-		msg := fmt.Sprintf("Flag \"format\" must be %s", strings.Join(validFormats, " or "))
-		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	if strings.Contains(opts.OutputFormat, ",") {
+		err = opts.validateMultipleFormats()
+		if err != nil {
+			return err
+		}
+	} else {
+		if !stringutil.Contains(validFormats, opts.OutputFormat) {
+			msg := fmt.Sprintf("Flag \"format\" must be %s", strings.Join(validFormats, " or "))
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.OutputDir != "" && !opts.writesHTMLIndex() {
+			msg := `Flag 'output-dir' can only be used with multiple formats or with the format 'html' and multiple fuzz tests, use 'output' instead`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
 	}
 
 	// To build with other build systems, a build command must be provided
@@ -92,6 +250,208 @@ func (opts *coverageOptions) validate() error {
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 	}
 
+	if opts.Function != "" {
+		opts.functionFilter, err = regexp.Compile(opts.Function)
+		if err != nil {
+			msg := fmt.Sprintf("Flag 'function' must be a valid regular expression: %v", err)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	if len(opts.fuzzTests) > 1 {
+		if opts.OutputFormat == coverage.FormatHTML {
+			err = opts.validateHTMLIndex()
+			if err != nil {
+				return err
+			}
+		} else if !stringutil.Contains([]string{coverage.FormatLCOV, coverage.FormatCobertura, coverage.FormatSonarQube, coverage.FormatJUnit, coverage.FormatSARIF}, opts.OutputFormat) {
+			msg := `Multiple fuzz tests are only supported for the formats 'html', 'lcov', 'cobertura', 'sonarqube', 'junit' and 'sarif'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	// The jacoco.xml and HTML reports of JaCoCo and Jest can't be
+	// restricted to the matching functions
+	if opts.functionFilter != nil &&
+		(opts.OutputFormat == coverage.FormatJacocoXML ||
+			(opts.OutputFormat == coverage.FormatHTML &&
+				(opts.BuildSystem == config.BuildSystemMaven ||
+					opts.BuildSystem == config.BuildSystemGradle ||
+					opts.BuildSystem == config.BuildSystemNodeJS))) {
+		msg := fmt.Sprintf(`Flag 'function' can't be used with the format '%s' for build system type '%s'`, opts.OutputFormat, opts.BuildSystem)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.FailUnder < 0 || opts.FailUnder > 100 || opts.FailUnderFile < 0 || opts.FailUnderFile > 100 {
+		msg := `Flags 'fail-under' and 'fail-under-file' must be a percentage between 0 and 100`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.OutputRoot != "" && opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemOther {
+		msg := `Flag 'output-root' is only applicable for build system types 'CMake' and 'other'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	opts.OutputRoot, err = cmdutils.ValidateOutputRoot(opts.OutputRoot, opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	opts.StripPathPrefix, err = cmdutils.ValidateStripPathPrefix(opts.StripPathPrefix, opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	// Only the LLVM coverage generator can take the seed corpus and
+	// the generated corpus from git, for the other build systems the
+	// flag only applies to the directories specified via --add-corpus
+	if opts.CorpusFromGit != "" && !opts.seedCorpusFromGit() && len(opts.CorpusDirs) == 0 {
+		msg := `Flag 'corpus-from-git' requires at least one directory specified via 'add-corpus' for build system types other than 'CMake' and 'other'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.IncludeUncoveredFiles && opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemOther {
+		msg := `Flag 'include-uncovered-files' is only applicable for build system types 'CMake' and 'other'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.PerInput {
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemOther {
+			msg := `Flag 'per-input' is only applicable for build system types 'CMake' and 'other'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if len(opts.fuzzTests) > 1 {
+			msg := `Flag 'per-input' can't be used with multiple fuzz tests`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	} else if opts.PerInputOutput != "" {
+		msg := `Flag 'per-input-output' can only be used with the flag 'per-input'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if len(opts.Packages) > 0 {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := `Flag 'coverage-packages' is only applicable for build system types 'Maven' and 'Gradle'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		for _, pkg := range opts.Packages {
+			if !javaPackageRegex.MatchString(pkg) {
+				msg := fmt.Sprintf("Flag 'coverage-packages' must be a Java package name, e.g. 'com.example', got %q", pkg)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+		}
+	}
+
+	if len(opts.ClassPaths) > 0 {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := `Flag 'classpath' is only applicable for build system types 'Maven' and 'Gradle'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.ClassPaths, err = cmdutils.ValidateClassPaths(opts.ClassPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(opts.NativeLibPaths) > 0 {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := `Flag 'native-lib-path' is only applicable for build system types 'Maven' and 'Gradle'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.NativeLibPaths, err = cmdutils.ValidateNativeLibPaths(opts.NativeLibPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	opts.BuildMemoryLimit, err = build.ValidateMemoryLimit(opts.BuildMemoryLimit, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	if opts.Offline && opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+		msg := `Flag 'offline' is only applicable for build system types 'Maven' and 'Gradle'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.SkipBuild || opts.ExecFile != "" {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := `Flags 'skip-build' and 'exec-file' are only applicable for build system types 'Maven' and 'Gradle'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if !opts.SkipBuild || opts.ExecFile == "" {
+			msg := `Flags 'skip-build' and 'exec-file' must be used together`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		info, err := os.Stat(opts.ExecFile)
+		if err != nil {
+			if os.IsNotExist(err) {
+				msg := fmt.Sprintf("The exec file '%s' does not exist", opts.ExecFile)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+			return errors.WithStack(err)
+		}
+		if info.IsDir() {
+			msg := fmt.Sprintf("The exec file '%s' is a directory", opts.ExecFile)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		err = javaCoverage.ValidateExecFile(opts.ExecFile)
+		if err != nil {
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+		opts.ExecFile, err = filepath.Abs(opts.ExecFile)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if opts.ClassFiles != "" {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := `Flag 'classfiles' is only applicable for build system types 'Maven' and 'Gradle'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		info, err := os.Stat(opts.ClassFiles)
+		if err != nil {
+			if os.IsNotExist(err) {
+				msg := fmt.Sprintf("The class files path '%s' does not exist", opts.ClassFiles)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+			return errors.WithStack(err)
+		}
+		if !info.IsDir() && filepath.Ext(opts.ClassFiles) != ".jar" {
+			msg := fmt.Sprintf("The class files path '%s' must be a directory or a JAR", opts.ClassFiles)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		err = javaCoverage.ValidateClassFiles(opts.ClassFiles)
+		if err != nil {
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+		opts.ClassFiles, err = filepath.Abs(opts.ClassFiles)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if len(opts.BuildEnv) > 0 {
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemBazel {
+			msg := `Flag 'build-env' is only applicable for build system types 'CMake' and 'Bazel'`
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		err = cmdutils.ValidateBuildEnv(opts.BuildEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Jobs == 0 {
+		opts.Jobs = 1
+	}
+	// Bazel writes the coverage report of all fuzz tests to the same
+	// path in the output base, so they can't be run in parallel
+	if opts.Jobs > 1 && opts.BuildSystem == config.BuildSystemBazel {
+		msg := `Flag 'jobs' is not supported for build system type 'Bazel'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
 	if opts.NumBuildJobs > 0 &&
 		opts.BuildSystem != config.BuildSystemBazel &&
 		opts.BuildSystem != config.BuildSystemCMake &&
@@ -103,9 +463,163 @@ func (opts *coverageOptions) validate() error {
 	return nil
 }
 
+// validateMultipleFormats validates the comma-separated list of formats
+// passed via the format flag, for which the reports are written to the
+// output directory.
+func (opts *coverageOptions) validateMultipleFormats() error {
+	opts.outputFormats = nil
+	for _, format := range strings.Split(opts.OutputFormat, ",") {
+		format = strings.TrimSpace(format)
+		if !stringutil.Contains(multiFormats, format) {
+			msg := fmt.Sprintf("Multiple formats must be a comma-separated list of %s, got %q",
+				strings.Join(multiFormats, ", "), format)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if !stringutil.Contains(opts.outputFormats, format) {
+			opts.outputFormats = append(opts.outputFormats, format)
+		}
+	}
+
+	if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemOther {
+		msg := `Multiple formats are only supported for build system types 'CMake' and 'other'`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if len(opts.fuzzTests) > 1 {
+		msg := `Multiple formats can't be used with multiple fuzz tests`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if opts.OutputDir == "" {
+		msg := `Flag 'output-dir' must be set when using multiple formats`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if opts.OutputPath != "" {
+		msg := `Flag 'output' can't be used with multiple formats, use 'output-dir' instead`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	return nil
+}
+
+// validateHTMLIndex validates the options for creating an HTML report
+// per fuzz test and an index linking them, which is done if the format
+// is "html" and multiple fuzz tests are specified.
+func (opts *coverageOptions) validateHTMLIndex() error {
+	if opts.OutputDir == "" {
+		msg := `Flag 'output-dir' must be set when using the format 'html' with multiple fuzz tests`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if opts.OutputPath != "" {
+		msg := `Flag 'output' can't be used with the format 'html' and multiple fuzz tests, use 'output-dir' instead`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	// The HTML reports are not merged, so there is no total coverage
+	if opts.Badge != "" || opts.CoveredFilesOut != "" || opts.checksThresholds() {
+		msg := `Flags 'badge', 'covered-files-out', 'fail-under' and 'fail-under-file' can't be used with the format 'html' and multiple fuzz tests`
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	return nil
+}
+
+// writesHTMLIndex returns true if an HTML report is created for each of
+// the fuzz tests, together with an index linking them.
+func (opts *coverageOptions) writesHTMLIndex() bool {
+	return len(opts.fuzzTests) > 1 && opts.OutputFormat == coverage.FormatHTML
+}
+
+// setFuzzTest sets the fuzz test for which coverage is generated next
+func (opts *coverageOptions) setFuzzTest(target *fuzzTestTarget) {
+	opts.fuzzTest = target.fuzzTest
+	opts.targetMethod = target.targetMethod
+	opts.testNamePattern = target.testNamePattern
+}
+
+// mergesReports returns true if the lcov reports of the fuzz tests are
+// merged into a single report, which is the case with --merge, when
+// generating coverage for multiple fuzz tests, for SonarQube reports and
+// for Cobertura reports of build systems whose generator doesn't create
+// them itself.
+func (opts *coverageOptions) mergesReports() bool {
+	if opts.Merge || len(opts.fuzzTests) > 1 || opts.OutputFormat == coverage.FormatSonarQube {
+		return true
+	}
+	return opts.OutputFormat == coverage.FormatCobertura &&
+		opts.BuildSystem != config.BuildSystemCMake &&
+		opts.BuildSystem != config.BuildSystemOther
+}
+
+// checksThresholds returns true if the coverage must be compared with
+// the thresholds after generating the report.
+func (opts *coverageOptions) checksThresholds() bool {
+	return opts.FailUnder > 0 || opts.FailUnderFile > 0 || opts.OutputFormat == coverage.FormatJUnit
+}
+
+// writesSummaryReport returns true if the output format is not a
+// coverage report generated by the coverage tool but a report created
+// from the coverage summary, which is the case for JUnit and SARIF.
+func (opts *coverageOptions) writesSummaryReport() bool {
+	return opts.OutputFormat == coverage.FormatJUnit || opts.OutputFormat == coverage.FormatSARIF
+}
+
+// perInputOutputPath returns the path of the per-input coverage file,
+// which is derived from the output path unless it was specified
+// explicitly.
+func (opts *coverageOptions) perInputOutputPath() string {
+	if opts.PerInputOutput != "" {
+		return opts.PerInputOutput
+	}
+	if opts.OutputPath == "" {
+		return "coverage-per-input.json"
+	}
+	outputPath := filepath.Clean(opts.OutputPath)
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".per-input.json"
+}
+
+// validateOutputPath checks that the output path is of the type
+// expected for the output format, i.e. a directory for HTML reports
+// and for all reports of Java and Node.js projects and a file
+// otherwise (including JUnit, SARIF and merged reports), and creates its
+// parent directories.
+func (opts *coverageOptions) validateOutputPath() error {
+	if opts.OutputPath == "" {
+		return nil
+	}
+
+	expectsDir := !opts.writesSummaryReport() && !opts.mergesReports() &&
+		(opts.OutputFormat == coverage.FormatHTML ||
+			opts.BuildSystem == config.BuildSystemMaven ||
+			opts.BuildSystem == config.BuildSystemGradle ||
+			opts.BuildSystem == config.BuildSystemNodeJS)
+
+	info, err := os.Stat(opts.OutputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	if err == nil {
+		if expectsDir && !info.IsDir() {
+			msg := fmt.Sprintf("Flag 'output' must be a directory for %s reports, but %s is a file", opts.OutputFormat, opts.OutputPath)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if !expectsDir && info.IsDir() {
+			msg := fmt.Sprintf("Flag 'output' must be a file for %s reports, but %s is a directory", opts.OutputFormat, opts.OutputPath)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(opts.OutputPath), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 type coverageCmd struct {
 	*cobra.Command
 	opts *coverageOptions
+
+	// buildLock is held while a fuzz test is built, because the builds
+	// of the fuzz tests share the build directory of the project and
+	// can't run in parallel. It's not held while running the fuzz tests
+	// to collect their coverage.
+	buildLock *sync.Mutex
 }
 
 func New() *cobra.Command {
@@ -113,7 +627,7 @@ func New() *cobra.Command {
 	var bindFlags func()
 
 	cmd := &cobra.Command{
-		Use:   "coverage [flags] <fuzz test>",
+		Use:   "coverage [flags] <fuzz test>...",
 		Short: "Generate coverage report for fuzz test",
 		Long: `This command generates a coverage report for a fuzz test.
 
@@ -140,8 +654,91 @@ or a lcov trace file.
 
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("XML (Jacoco Report)") + `
     cifuzz coverage --format=jacocoxml <fuzz test>
+
+For CMake and 'other', the formats 'html', 'lcov' and 'cobertura' can be
+combined as a comma-separated list to create multiple reports from a
+single build and run of the fuzz test. The reports are written to the
+directory specified via 'output-dir'.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Multiple Formats") + `
+    cifuzz coverage --format=html,lcov --output-dir coverage-report <fuzz test>
+
+The flags 'fail-under' and 'fail-under-file' make the command fail if
+the line coverage of the fuzz test or of any file is below the
+specified percentage. With the format 'junit', the results of these
+checks are written as a JUnit XML report instead of a coverage report,
+so that they can be displayed by CI systems.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("JUnit (Coverage Gate)") + `
+    cifuzz coverage --format=junit --fail-under=80 --fail-under-file=50 <fuzz test>
+
+With the format 'sarif', the coverage of each file is written as a
+SARIF 2.1.0 log, which can be ingested by tools that consume SARIF.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("SARIF") + `
+    cifuzz coverage --format=sarif --output coverage.sarif <fuzz test>
+
+With the format 'sonarqube', the line coverage is written in the
+generic test coverage format of SonarQube, which can be imported via
+the property 'sonar.coverageReportPaths'.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("SonarQube") + `
+    cifuzz coverage --format=sonarqube --output coverage.xml <fuzz test>
+
+If multiple fuzz tests are specified, which is supported for the
+formats 'lcov', 'cobertura', 'sonarqube', 'junit' and 'sarif', their
+coverage is merged into a single report. Cobertura reports are supported
+e.g. by GitLab to display the coverage in merge requests. With the flag
+'jobs', the coverage of multiple fuzz tests is generated in parallel.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Cobertura (Merged)") + `
+    cifuzz coverage --format=cobertura --output coverage.xml <fuzz test>...
+
+With the format 'html' and multiple fuzz tests, an HTML report is
+created for each fuzz test in a subdirectory of the directory specified
+via 'output-dir', together with an index.html which lists the coverage
+of each fuzz test and links to its report.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("HTML (Multiple Fuzz Tests)") + `
+    cifuzz coverage --output-dir coverage-reports <fuzz test>...
+
+With the flag 'merge' or the subcommand 'merge', existing lcov reports
+are merged instead of generating the coverage of a fuzz test, e.g. to
+combine the reports of fuzz tests which were run on different CI
+machines. This doesn't require a cifuzz project. The execution counts
+of the same lines are summed up and the functions and branches of all
+reports are combined. If the reports disagree on the lines of a file,
+e.g. because they were created from different revisions, the merged
+report contains all lines seen in any report.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Merge") + `
+    cifuzz coverage --merge --input shard1.lcov --input shard2.lcov --output coverage.lcov
+    cifuzz coverage merge --output coverage.lcov shard1.lcov shard2.lcov
+
+With the flag 'per-input', each input of the corpus is additionally run
+individually to find the lines which only that input covers. The result
+is written to the file specified via 'per-input-output'. By default,
+the file name is derived from the output path, e.g. coverage.lcov
+results in coverage.per-input.json, or coverage-per-input.json if no
+output path is specified. The result can be used to identify redundant
+corpus inputs. This is expensive for large corpora.
+
+With the flag 'corpus-from-git', the corpus is taken from the specified
+git ref instead of the working tree. Comparing the result with the
+coverage of the current corpus shows how the coverage evolved, e.g.
+between releases. For the build system types CMake and other, this
+applies to the seed corpus, the generated corpus and the directories
+specified via 'add-corpus', corpus directories which were not under
+version control at the ref are skipped. For the other build system
+types, it only applies to the directories specified via 'add-corpus'.
+
+` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Corpus From Git") + `
+    cifuzz coverage --corpus-from-git v1.0 <fuzz test>
 `,
 		ValidArgsFunction: completion.ValidFuzzTests,
+		// The command has a subcommand, so cobra would reject any
+		// arguments which don't match it without this
+		Args: cobra.ArbitraryArgs,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			// Bind viper keys to flags. We can't do this in the New
 			// function, because that would re-bind viper keys which
@@ -149,6 +746,36 @@ or a lcov trace file.
 			bindFlags()
 			cmdutils.ViperMustBindPFlag("format", cmd.Flags().Lookup("format"))
 			cmdutils.ViperMustBindPFlag("output", cmd.Flags().Lookup("output"))
+			cmdutils.ViperMustBindPFlag("output-dir", cmd.Flags().Lookup("output-dir"))
+			cmdutils.ViperMustBindPFlag("function", cmd.Flags().Lookup("function"))
+			cmdutils.ViperMustBindPFlag("coverage-packages", cmd.Flags().Lookup("coverage-packages"))
+			cmdutils.ViperMustBindPFlag("exec-file", cmd.Flags().Lookup("exec-file"))
+			cmdutils.ViperMustBindPFlag("classfiles", cmd.Flags().Lookup("classfiles"))
+			cmdutils.ViperMustBindPFlag("skip-build", cmd.Flags().Lookup("skip-build"))
+			cmdutils.ViperMustBindPFlag("include-uncovered-files", cmd.Flags().Lookup("include-uncovered-files"))
+			cmdutils.ViperMustBindPFlag("per-input", cmd.Flags().Lookup("per-input"))
+			cmdutils.ViperMustBindPFlag("per-input-output", cmd.Flags().Lookup("per-input-output"))
+			cmdutils.ViperMustBindPFlag("jobs", cmd.Flags().Lookup("jobs"))
+			cmdutils.ViperMustBindPFlag("merge", cmd.Flags().Lookup("merge"))
+			cmdutils.ViperMustBindPFlag("inputs", cmd.Flags().Lookup("input"))
+			cmdutils.ViperMustBindPFlag("corpus-from-git", cmd.Flags().Lookup("corpus-from-git"))
+
+			if viper.GetBool("merge") {
+				if len(args) > 0 {
+					msg := "No <fuzz test> argument can be provided when using --merge"
+					return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+				}
+				err := parseMergeConfig(opts)
+				if err != nil {
+					return err
+				}
+				// HTML reports can't be created from lcov reports, so
+				// lcov is used by default
+				if !cmd.Flags().Changed("format") && opts.OutputFormat == coverage.FormatHTML {
+					opts.OutputFormat = coverage.FormatLCOV
+				}
+				return opts.validateMerge()
+			}
 
 			var lenFuzzTestArgs int
 			var argsToPass []string
@@ -159,8 +786,8 @@ or a lcov trace file.
 			} else {
 				lenFuzzTestArgs = len(args)
 			}
-			if lenFuzzTestArgs != 1 {
-				msg := fmt.Sprintf("Exactly one <fuzz test> argument must be provided, got %d", lenFuzzTestArgs)
+			if lenFuzzTestArgs < 1 {
+				msg := "At least one <fuzz test> argument must be provided"
 				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 			}
 
@@ -169,42 +796,55 @@ or a lcov trace file.
 				return err
 			}
 
-			if sliceutil.Contains(
-				[]string{config.BuildSystemMaven, config.BuildSystemGradle},
-				opts.BuildSystem,
-			) {
-				// Check if the fuzz test is a method of a class
-				// And remove method from fuzz test argument
-				if strings.Contains(args[0], "::") {
-					split := strings.Split(args[0], "::")
-					args[0], opts.targetMethod = split[0], split[1]
-				}
-			} else if opts.BuildSystem == config.BuildSystemNodeJS {
-				// Check if the fuzz test contains a filter for the test name
-				if strings.Contains(args[0], ":") {
-					split := strings.Split(args[0], ":")
-					args[0], opts.testNamePattern = split[0], strings.ReplaceAll(split[1], "\"", "")
+			targets := make([]*fuzzTestTarget, len(args))
+			for i := range args {
+				targets[i] = &fuzzTestTarget{}
+				if sliceutil.Contains(
+					[]string{config.BuildSystemMaven, config.BuildSystemGradle},
+					opts.BuildSystem,
+				) {
+					// Check if the fuzz test is a method of a class
+					// And remove method from fuzz test argument
+					if strings.Contains(args[i], "::") {
+						split := strings.Split(args[i], "::")
+						args[i], targets[i].targetMethod = split[0], split[1]
+					}
+				} else if opts.BuildSystem == config.BuildSystemNodeJS {
+					// Check if the fuzz test contains a filter for the test name
+					if strings.Contains(args[i], ":") {
+						split := strings.Split(args[i], ":")
+						args[i], targets[i].testNamePattern = split[0], strings.ReplaceAll(split[1], "\"", "")
+					}
 				}
 			}
 
-			fuzzTest, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir)
+			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath && !opts.NoResolveSourcePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
 			if err != nil {
 				return err
 			}
-			opts.fuzzTest = fuzzTest[0]
+			for i, fuzzTest := range fuzzTests {
+				targets[i].fuzzTest = fuzzTest
+			}
+			opts.fuzzTests = targets
+			opts.setFuzzTest(targets[0])
 			opts.argsToPass = argsToPass
 
+			err = opts.validate()
+			if err != nil {
+				return err
+			}
+
 			opts.buildStdout = cmd.OutOrStdout()
 			opts.buildStderr = cmd.OutOrStderr()
 			if logging.ShouldLogBuildToFile() {
-				opts.buildStdout, err = logging.BuildOutputToFile(opts.ProjectDir, []string{opts.fuzzTest})
+				opts.buildStdout, err = logging.BuildOutputToFile(opts.OutputRoot, fuzzTests)
 				if err != nil {
 					return err
 				}
 				opts.buildStderr = opts.buildStdout
 			}
 
-			return opts.validate()
+			return nil
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			cmd := coverageCmd{Command: c, opts: opts}
@@ -215,13 +855,27 @@ or a lcov trace file.
 	// Note: If a flag should be configurable via cifuzz.yaml as well,
 	// bind it to viper in the PreRunE function.
 	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddBadgeFlag,
+		cmdutils.AddBadgeLabelFlag,
 		cmdutils.AddBuildCommandFlag,
+		cmdutils.AddBuildEnvFlag,
 		cmdutils.AddBuildJobsFlag,
+		cmdutils.AddBuildMemoryLimitFlag,
+		cmdutils.AddClassPathFlag,
 		cmdutils.AddCleanCommandFlag,
+		cmdutils.AddCoveredFilesOutFlag,
 		cmdutils.AddEngineArgFlag,
+		cmdutils.AddFailUnderFlag,
+		cmdutils.AddFailUnderFileFlag,
+		cmdutils.AddNativeLibPathFlag,
+		cmdutils.AddNoResolveSourcePathFlag,
+		cmdutils.AddOfflineFlag,
+		cmdutils.AddOutputRootFlag,
 		cmdutils.AddPresetFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddResolveSourceFileFlag,
+		cmdutils.AddStripPathsFlag,
+		cmdutils.AddSymbolizerFlag,
 		cmdutils.AddAdditionalCorpusFlag,
 		cmdutils.AddUseSandboxFlag,
 	)
@@ -230,25 +884,101 @@ or a lcov trace file.
 	if err != nil {
 		panic(err)
 	}
-	cmd.Flags().StringP("format", "f", "html", "Output format of the coverage report (html/lcov).")
+	cmd.Flags().StringP("format", "f", "html",
+		"Output format of the coverage report (html/lcov/cobertura/sonarqube/junit/sarif).\n"+
+			"Multiple formats can be specified as a comma-separated list, e.g. 'html,lcov',\n"+
+			"to create the reports from a single run. This requires --output-dir.")
 	cmd.Flags().StringP("output", "o", "", "Output path of the coverage report.")
+	cmd.Flags().String("output-dir", "",
+		"Output `directory` of the coverage reports when multiple formats are specified\n"+
+			"or when HTML reports of multiple fuzz tests are created.")
+	cmd.Flags().String("function", "",
+		"Only show the coverage of functions whose name matches the `regex`.\n"+
+			"The written reports and the summary are restricted to these functions.\n"+
+			"Not supported for JaCoCo XML reports and the HTML reports of Maven,\n"+
+			"Gradle and Node.js projects.")
+	cmd.Flags().StringArray("coverage-packages", nil,
+		"Only include classes of the Java `package` and its subpackages in the coverage report,\n"+
+			"e.g. 'com.example'. This flag can be used multiple times.\n"+
+			"Only supported for Maven and Gradle projects.")
+	cmd.Flags().String("exec-file", "",
+		"Generate the coverage report from an existing JaCoCo exec `file` instead of\n"+
+			"running the fuzz test. Must be used together with --skip-build.\n"+
+			"Only the file header is checked, not whether the execution data was\n"+
+			"recorded for the class files. JaCoCo reports classes whose execution\n"+
+			"data doesn't match the class files as not covered.\n"+
+			"Only supported for Maven and Gradle projects.")
+	cmd.Flags().String("classfiles", "",
+		"The directory or JAR `path` containing the class files to analyze for the report.\n"+
+			"By default, target/classes or build/classes is used, or the JAR built\n"+
+			"by the project if the project doesn't produce a class files directory.\n"+
+			"Only supported for Maven and Gradle projects.")
+	cmd.Flags().Bool("skip-build", false,
+		"Don't build and run the fuzz test, but generate the coverage report from\n"+
+			"the file specified via --exec-file and the existing class files.\n"+
+			"Only supported for Maven and Gradle projects.")
+	cmd.Flags().Bool("include-uncovered-files", false,
+		"Add the C/C++ source files which were not executed at all to the coverage report\n"+
+			"with zero coverage. Only the directories containing covered source files\n"+
+			"are searched.\n"+
+			"Only supported for CMake projects and build system type 'other'.")
+	cmd.Flags().Bool("per-input", false,
+		"Run each corpus input individually and write the lines which only that input covers\n"+
+			"to a JSON file. This is expensive for large corpora.\n"+
+			"Only supported for CMake projects and build system type 'other'.")
+	cmd.Flags().String("per-input-output", "",
+		"Write the per-input coverage to the specified `file`. Defaults to a file\n"+
+			"next to the output path, e.g. coverage.per-input.json for coverage.lcov.")
+	cmd.Flags().Bool("merge", false,
+		"Merge the existing lcov reports specified via --input into a single report\n"+
+			"instead of generating the coverage of a fuzz test, e.g. to combine the\n"+
+			"reports of fuzz tests which were run on different CI machines.")
+	cmd.Flags().StringArray("input", nil,
+		"An lcov report `file` to merge with --merge. This flag can be used multiple times.")
+	cmd.Flags().String("corpus-from-git", "",
+		"Use the corpus as it was committed at the specified git `ref` instead of the\n"+
+			"current inputs, e.g. to compare the coverage of the corpus between releases.\n"+
+			"For CMake and other, this includes the seed corpus and the generated corpus,\n"+
+			"for the other build systems only the directories specified via --add-corpus.")
+	cmd.Flags().Uint("jobs", 1,
+		"Generate the coverage of up to `n` fuzz tests in parallel when multiple fuzz tests\n"+
+			"are specified. The fuzz tests are still built one after another.\n"+
+			"Not supported for Bazel projects.")
 	err = cmd.RegisterFlagCompletionFunc("format", completion.ValidCoverageOutputFormat)
 	if err != nil {
 		panic(err)
 	}
 
+	cmd.AddCommand(newMergeCmd())
+
 	return cmd
 }
 
 func (c *coverageCmd) run() error {
+	if c.opts.Merge {
+		return c.runMergeInputs()
+	}
+
 	err := c.checkDependencies()
 	if err != nil {
 		return err
 	}
 
-	if c.opts.Preset == "vscode" {
-		var format string
-		var output string
+	if c.opts.CorpusFromGit != "" {
+		tmpDir, err := os.MkdirTemp("", "cifuzz-corpus-from-git-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fileutil.Cleanup(tmpDir)
+		err = c.exportCorpusFromGit(tmpDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.Preset == "vscode" {
+		var format string
+		var output string
 		switch c.opts.BuildSystem {
 		case config.BuildSystemCMake, config.BuildSystemBazel:
 			format = coverage.FormatLCOV
@@ -263,15 +993,525 @@ func (c *coverageCmd) run() error {
 
 		c.opts.OutputFormat = format
 		c.opts.OutputPath = output
+		c.opts.outputFormats = nil
+	}
+
+	if len(c.opts.outputFormats) > 0 {
+		return c.runMultipleFormats()
+	}
+
+	if c.opts.writesHTMLIndex() {
+		return c.runHTMLIndex()
+	}
+
+	err = c.opts.validateOutputPath()
+	if err != nil {
+		return err
+	}
+
+	if c.opts.mergesReports() {
+		return c.runMerged()
+	}
+
+	// The JUnit and SARIF reports are created from the coverage
+	// summary, so the coverage report itself is generated as lcov into
+	// a temporary directory
+	reportFormat := c.opts.OutputFormat
+	reportOutputPath := c.opts.OutputPath
+	if c.opts.writesSummaryReport() {
+		tmpDir, err := os.MkdirTemp("", "cifuzz-coverage-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fileutil.Cleanup(tmpDir)
+
+		reportFormat = coverage.FormatLCOV
+		reportOutputPath = tmpDir
+		if c.opts.BuildSystem == config.BuildSystemCMake ||
+			c.opts.BuildSystem == config.BuildSystemBazel ||
+			c.opts.BuildSystem == config.BuildSystemOther {
+			reportOutputPath = filepath.Join(tmpDir, "report.lcov")
+		}
+	}
+	if c.opts.OutputFormat == coverage.FormatCobertura && reportOutputPath == "" {
+		// Use the same default as for merged Cobertura reports
+		reportOutputPath = "coverage.cobertura.xml"
+	}
+
+	gen, err := c.newGenerator(reportFormat, reportOutputPath)
+	if err != nil {
+		return err
+	}
+
+	reportPath, err := c.generateReport(gen)
+	if err != nil {
+		return err
+	}
+
+	if c.opts.Badge != "" {
+		err = c.writeBadge(gen.Summary())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.handleReport(reportPath)
+	if err != nil {
+		return err
+	}
+
+	if c.opts.OutputFormat == coverage.FormatSARIF {
+		err = c.writeSARIFReport(gen.Summary())
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.CoveredFilesOut != "" {
+		err = c.writeCoveredFiles(gen.Summary())
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.checksThresholds() {
+		return c.checkThresholds(gen.Summary())
+	}
+	return nil
+}
+
+// runMultipleFormats builds and runs the fuzz test once and writes a
+// report of each of the formats to the output directory.
+func (c *coverageCmd) runMultipleFormats() error {
+	gen, err := c.newGenerator(c.opts.outputFormats[0], "")
+	if err != nil {
+		return err
+	}
+	multiGen, ok := gen.(MultiFormatGenerator)
+	if !ok {
+		return errors.Errorf("Multiple formats are not supported for build system \"%s\"", c.opts.BuildSystem)
+	}
+
+	err = c.build(gen)
+	if err != nil {
+		return err
+	}
+
+	outputPaths := make(map[string]string)
+	for _, format := range c.opts.outputFormats {
+		outputPaths[format] = filepath.Join(c.opts.OutputDir, coverage.ReportFileName(format))
+	}
+	err = os.MkdirAll(c.opts.OutputDir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = multiGen.GenerateCoverageReports(outputPaths)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range c.opts.outputFormats {
+		err = c.stripPaths(outputPaths[format])
+		if err != nil {
+			return err
+		}
+		switch format {
+		case coverage.FormatHTML:
+			log.Successf("Created coverage HTML report: %s", outputPaths[format])
+			err = c.printReportURI(filepath.Join(outputPaths[format], "index.html"))
+			if err != nil {
+				return err
+			}
+		case coverage.FormatLCOV:
+			log.Successf("Created coverage lcov report: %s", outputPaths[format])
+		case coverage.FormatCobertura:
+			log.Successf("Created Cobertura coverage report: %s", outputPaths[format])
+		}
+	}
+
+	if c.opts.Badge != "" {
+		err = c.writeBadge(gen.Summary())
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.CoveredFilesOut != "" {
+		err = c.writeCoveredFiles(gen.Summary())
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.checksThresholds() {
+		return c.checkThresholds(gen.Summary())
+	}
+	return nil
+}
+
+// runMergeInputs merges the lcov reports specified via --input and
+// writes the merged report in the output format.
+func (c *coverageCmd) runMergeInputs() error {
+	err := c.opts.validateOutputPath()
+	if err != nil {
+		return err
+	}
+
+	var reports []*parser.LCOVReport
+	for _, input := range c.opts.Inputs {
+		reportFile, err := os.Open(input)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		report, err := parser.ParseLCOVFileIntoLCOVReport(reportFile)
+		reportFile.Close()
+		if err != nil {
+			return errors.WithMessagef(err, "Failed to parse lcov report %s", input)
+		}
+		reports = append(reports, report)
+	}
+	log.Infof("Merging %d lcov reports", len(reports))
+
+	return c.writeMergedReport(reports)
+}
+
+// runMerged generates an lcov report for each of the fuzz tests,
+// merges them and writes the merged report in the output format.
+func (c *coverageCmd) runMerged() error {
+	tmpDir, err := os.MkdirTemp("", "cifuzz-coverage-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tmpDir)
+
+	// The coverage of the fuzz tests is generated by a bounded number
+	// of workers. Each worker uses its own copy of the options and its
+	// own output path, and the generators store their intermediate
+	// files (e.g. the profdata files) in their own temporary directory,
+	// so that they don't collide.
+	reports := make([]*parser.LCOVReport, len(c.opts.fuzzTests))
+	buildLock := &sync.Mutex{}
+	routines := errgroup.Group{}
+	routines.SetLimit(int(c.opts.Jobs))
+	for i, target := range c.opts.fuzzTests {
+		i, target := i, target
+		routines.Go(func() error {
+			opts := *c.opts
+			opts.setFuzzTest(target)
+			worker := &coverageCmd{Command: c.Command, opts: &opts, buildLock: buildLock}
+
+			// The Java and Node.js generators write the lcov report into
+			// a directory, the others to a file
+			reportOutputPath := filepath.Join(tmpDir, fmt.Sprint(i))
+			if opts.BuildSystem == config.BuildSystemCMake ||
+				opts.BuildSystem == config.BuildSystemBazel ||
+				opts.BuildSystem == config.BuildSystemOther {
+				reportOutputPath += ".lcov"
+			}
+
+			var err error
+			reports[i], err = worker.lcovReport(reportOutputPath)
+			return err
+		})
+	}
+	err = routines.Wait()
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	return c.writeMergedReport(reports)
+}
+
+// runHTMLIndex generates an HTML report for each of the fuzz tests in a
+// subdirectory of the output directory and writes an index.html to the
+// output directory which links to the reports.
+func (c *coverageCmd) runHTMLIndex() error {
+	err := os.MkdirAll(c.opts.OutputDir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	entries := make([]*parser.HTMLIndexEntry, len(c.opts.fuzzTests))
+	reportDirs := htmlReportDirs(c.opts.fuzzTests)
+	buildLock := &sync.Mutex{}
+	routines := errgroup.Group{}
+	routines.SetLimit(int(c.opts.Jobs))
+	for i, target := range c.opts.fuzzTests {
+		i, target := i, target
+		routines.Go(func() error {
+			opts := *c.opts
+			opts.setFuzzTest(target)
+			worker := &coverageCmd{Command: c.Command, opts: &opts, buildLock: buildLock}
+
+			outputPath := filepath.Join(c.opts.OutputDir, reportDirs[i])
+			gen, err := worker.newGenerator(coverage.FormatHTML, outputPath)
+			if err != nil {
+				return err
+			}
+			reportPath, err := worker.generateReport(gen)
+			if err != nil {
+				return err
+			}
+			reportDir, err := filepath.Rel(c.opts.OutputDir, reportPath)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			entries[i] = &parser.HTMLIndexEntry{
+				FuzzTest:  fuzzTestTargetName(target),
+				ReportDir: reportDir,
+				Summary:   gen.Summary(),
+			}
+			return nil
+		})
+	}
+	err = routines.Wait()
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	indexPath := filepath.Join(c.opts.OutputDir, "index.html")
+	err = parser.WriteHTMLIndex(indexPath, entries)
+	if err != nil {
+		return err
+	}
+	log.Successf("Created coverage HTML reports of %d fuzz tests: %s", len(entries), c.opts.OutputDir)
+	return c.printReportURI(indexPath)
+}
+
+// fuzzTestTargetName returns the name of the fuzz test target as it was
+// specified as an argument.
+func fuzzTestTargetName(target *fuzzTestTarget) string {
+	switch {
+	case target.targetMethod != "":
+		return target.fuzzTest + "::" + target.targetMethod
+	case target.testNamePattern != "":
+		return target.fuzzTest + ":" + target.testNamePattern
+	default:
+		return target.fuzzTest
+	}
+}
+
+var unsafeDirNameChars = regexp.MustCompile(`[^\w.-]+`)
+
+// htmlReportDirs returns the names of the directories to which the HTML
+// reports of the fuzz tests are written, which are derived from the
+// names of the fuzz tests and made unique.
+func htmlReportDirs(targets []*fuzzTestTarget) []string {
+	dirs := make([]string, len(targets))
+	used := make(map[string]bool)
+	for i, target := range targets {
+		name := strings.Trim(unsafeDirNameChars.ReplaceAllString(fuzzTestTargetName(target), "_"), "_.")
+		if name == "" {
+			name = "fuzz_test"
+		}
+		dir := name
+		for n := 2; used[dir]; n++ {
+			dir = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[dir] = true
+		dirs[i] = dir
+	}
+	return dirs
+}
+
+// writeMergedReport merges the lcov reports and writes the merged
+// report in the output format.
+func (c *coverageCmd) writeMergedReport(reports []*parser.LCOVReport) error {
+	var err error
+	merged := parser.MergeLCOVReports(reports...)
+	summary := merged.Summary()
+	if len(reports) > 1 {
+		log.Infof("Merged the coverage of %d fuzz tests", len(reports))
+		summary.PrintTable(c.OutOrStderr())
+	}
+
+	if c.opts.Badge != "" {
+		err = c.writeBadge(summary)
+		if err != nil {
+			return err
+		}
 	}
 
+	switch c.opts.OutputFormat {
+	case coverage.FormatLCOV:
+		outputPath := c.opts.OutputPath
+		if outputPath == "" {
+			outputPath = "coverage.lcov"
+		}
+		err = merged.WriteLCOVReportToFile(outputPath)
+		if err != nil {
+			return err
+		}
+		err = c.stripPaths(outputPath)
+		if err != nil {
+			return err
+		}
+		log.Successf("Created merged coverage lcov report: %s", outputPath)
+	case coverage.FormatCobertura:
+		outputPath := c.opts.OutputPath
+		if outputPath == "" {
+			outputPath = "coverage.cobertura.xml"
+		}
+		err = merged.WriteCoberturaReport(outputPath, c.opts.ProjectDir)
+		if err != nil {
+			return err
+		}
+		err = c.stripPaths(outputPath)
+		if err != nil {
+			return err
+		}
+		log.Successf("Created Cobertura coverage report: %s", outputPath)
+	case coverage.FormatSonarQube:
+		outputPath := c.opts.OutputPath
+		if outputPath == "" {
+			outputPath = coverage.ReportFileName(coverage.FormatSonarQube)
+		}
+		err = merged.WriteSonarQubeReport(outputPath, c.opts.ProjectDir)
+		if err != nil {
+			return err
+		}
+		err = c.stripPaths(outputPath)
+		if err != nil {
+			return err
+		}
+		log.Successf("Created SonarQube coverage report: %s", outputPath)
+	case coverage.FormatSARIF:
+		err = c.writeSARIFReport(summary)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.CoveredFilesOut != "" {
+		err = c.writeCoveredFiles(summary)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.opts.checksThresholds() {
+		return c.checkThresholds(summary)
+	}
+	return nil
+}
+
+// lcovReport generates the lcov report of the current fuzz test at the
+// output path and parses it.
+func (c *coverageCmd) lcovReport(reportOutputPath string) (*parser.LCOVReport, error) {
+	gen, err := c.newGenerator(coverage.FormatLCOV, reportOutputPath)
+	if err != nil {
+		return nil, err
+	}
+	reportPath, err := c.generateReport(gen)
+	if err != nil {
+		return nil, err
+	}
+
+	reportFile, err := os.Open(reportPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer reportFile.Close()
+	return parser.ParseLCOVFileIntoLCOVReport(reportFile)
+}
+
+// seedCorpusFromGit returns whether the seed corpus and the generated
+// corpus are taken from the git ref specified via --corpus-from-git,
+// which is only supported by the LLVM coverage generator.
+func (opts *coverageOptions) seedCorpusFromGit() bool {
+	return opts.BuildSystem == config.BuildSystemCMake || opts.BuildSystem == config.BuildSystemOther
+}
+
+// exportCorpusFromGit replaces the corpus directories by directories
+// below tmpDir which contain their inputs as they were committed at the
+// git ref specified via --corpus-from-git.
+func (c *coverageCmd) exportCorpusFromGit(tmpDir string) error {
+	for i, dir := range c.opts.CorpusDirs {
+		exportDir := filepath.Join(tmpDir, strconv.Itoa(i))
+		err := os.MkdirAll(exportDir, 0o755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		err = vcs.GitExportDir(dir, c.opts.CorpusFromGit, exportDir)
+		if err != nil {
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+		log.Infof("Using the inputs of %s at git ref %s", fileutil.PrettifyPath(dir), c.opts.CorpusFromGit)
+		c.opts.CorpusDirs[i] = exportDir
+	}
+	return nil
+}
+
+func (c *coverageCmd) lockBuild() {
+	if c.buildLock != nil {
+		c.buildLock.Lock()
+	}
+}
+
+func (c *coverageCmd) unlockBuild() {
+	if c.buildLock != nil {
+		c.buildLock.Unlock()
+	}
+}
+
+// buildJava builds the Maven or Gradle project and returns the runtime
+// dependencies of the fuzz test.
+func (c *coverageCmd) buildJava() ([]string, error) {
+	c.lockBuild()
+	defer c.unlockBuild()
+
+	var buildResult *build.BuildResult
+	if c.opts.BuildSystem == config.BuildSystemGradle {
+		builder, err := gradle.NewBuilder(&gradle.BuilderOptions{
+			ProjectDir:  c.opts.ProjectDir,
+			Stdout:      c.opts.buildStdout,
+			Stderr:      c.opts.buildStderr,
+			MemoryLimit: c.opts.BuildMemoryLimit,
+			Offline:     c.opts.Offline,
+		})
+		if err != nil {
+			return nil, err
+		}
+		buildResult, err = builder.Build()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		builder, err := maven.NewBuilder(&maven.BuilderOptions{
+			ProjectDir: c.opts.ProjectDir,
+			Parallel: maven.ParallelOptions{
+				Enabled: viper.IsSet("build-jobs"),
+				NumJobs: c.opts.NumBuildJobs,
+			},
+			Stdout:      c.opts.buildStdout,
+			Stderr:      c.opts.buildStderr,
+			MemoryLimit: c.opts.BuildMemoryLimit,
+			Offline:     c.opts.Offline,
+		})
+		if err != nil {
+			return nil, err
+		}
+		buildResult, err = builder.Build()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buildResult.RuntimeDeps, nil
+}
+
+// newGenerator returns the coverage generator for the current fuzz test
+// which generates a report of the specified format at the output path.
+func (c *coverageCmd) newGenerator(reportFormat, reportOutputPath string) (Generator, error) {
 	var gen Generator
+	var err error
 	switch c.opts.BuildSystem {
 	case config.BuildSystemBazel:
 		gen = &bazelCoverage.CoverageGenerator{
 			FuzzTest:        c.opts.fuzzTest,
-			OutputFormat:    c.opts.OutputFormat,
-			OutputPath:      c.opts.OutputPath,
+			OutputFormat:    reportFormat,
+			OutputPath:      reportOutputPath,
 			BuildSystemArgs: c.opts.argsToPass,
 			ProjectDir:      c.opts.ProjectDir,
 			Engine:          "libfuzzer",
@@ -282,6 +1522,9 @@ func (c *coverageCmd) run() error {
 			BuildStdout:     c.opts.buildStdout,
 			BuildStderr:     c.opts.buildStderr,
 			Verbose:         viper.GetBool("verbose"),
+			FunctionFilter:  c.opts.functionFilter,
+			BuildEnv:        c.opts.BuildEnv,
+			MemoryLimit:     c.opts.BuildMemoryLimit,
 		}
 	case config.BuildSystemCMake, config.BuildSystemOther:
 		if c.opts.BuildSystem == config.BuildSystemOther {
@@ -291,9 +1534,9 @@ func (c *coverageCmd) run() error {
 			}
 		}
 
-		gen = &llvmCoverage.CoverageGenerator{
-			OutputFormat:    c.opts.OutputFormat,
-			OutputPath:      c.opts.OutputPath,
+		llvmGen := &llvmCoverage.CoverageGenerator{
+			OutputFormat:    reportFormat,
+			OutputPath:      reportOutputPath,
 			BuildSystem:     c.opts.BuildSystem,
 			BuildCommand:    c.opts.BuildCommand,
 			BuildSystemArgs: c.opts.argsToPass,
@@ -306,44 +1549,58 @@ func (c *coverageCmd) run() error {
 			Stderr:          c.OutOrStderr(),
 			BuildStdout:     c.opts.buildStdout,
 			BuildStderr:     c.opts.buildStderr,
+			FunctionFilter:  c.opts.functionFilter,
+			Symbolizer:      c.opts.Symbolizer,
+			BuildEnv:        c.opts.BuildEnv,
+			OutputRoot:      c.opts.OutputRoot,
+			MemoryLimit:     c.opts.BuildMemoryLimit,
+
+			IncludeUncoveredFiles: c.opts.IncludeUncoveredFiles,
+			CorpusFromGit:         c.opts.CorpusFromGit,
 		}
+		if c.opts.PerInput {
+			llvmGen.PerInputOutputPath = c.opts.perInputOutputPath()
+		}
+		gen = llvmGen
 	case config.BuildSystemGradle, config.BuildSystemMaven:
 		if len(c.opts.argsToPass) > 0 {
 			log.Warnf("Passing additional arguments is not supported for Gradle or Maven.\n"+
 				"These arguments are ignored: %s", strings.Join(c.opts.argsToPass, " "))
 		}
 
+		// The dependencies are only needed to run the fuzz test, which
+		// is not done when generating the report from an exec file
 		var deps []string
-		if c.opts.BuildSystem == config.BuildSystemGradle {
-			deps, err = gradle.GetDependencies(c.opts.ProjectDir)
-		} else {
-			deps, err = maven.GetDependencies(c.opts.ProjectDir, maven.ParallelOptions{
-				Enabled: viper.IsSet("build-jobs"),
-				NumJobs: c.opts.NumBuildJobs,
-			})
-		}
-		if err != nil {
-			return err
-		}
+		if !c.opts.SkipBuild {
+			deps, err = c.buildJava()
+			if err != nil {
+				return nil, err
+			}
 
-		err = cmdutils.ValidateJVMFuzzTest(c.opts.fuzzTest, &c.opts.targetMethod, deps)
-		if err != nil {
-			return err
+			err = cmdutils.ValidateJVMFuzzTest(c.opts.fuzzTest, &c.opts.targetMethod, deps)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		gen = &javaCoverage.CoverageGenerator{
-			BuildSystem:  c.opts.BuildSystem,
-			OutputFormat: c.opts.OutputFormat,
-			OutputPath:   c.opts.OutputPath,
-			FuzzTest:     c.opts.fuzzTest,
-			TargetMethod: c.opts.targetMethod,
-			ProjectDir:   c.opts.ProjectDir,
-			Deps:         deps,
-			CorpusDirs:   c.opts.CorpusDirs,
-			EngineArgs:   c.opts.EngineArgs,
-			BuildStdout:  c.opts.buildStdout,
-			BuildStderr:  c.opts.buildStderr,
-			Stderr:       c.OutOrStderr(),
+			BuildSystem:    c.opts.BuildSystem,
+			OutputFormat:   reportFormat,
+			OutputPath:     reportOutputPath,
+			FuzzTest:       c.opts.fuzzTest,
+			TargetMethod:   c.opts.targetMethod,
+			ProjectDir:     c.opts.ProjectDir,
+			Deps:           append(append([]string{}, deps...), c.opts.ClassPaths...),
+			NativeLibPaths: c.opts.NativeLibPaths,
+			CorpusDirs:     c.opts.CorpusDirs,
+			EngineArgs:     c.opts.EngineArgs,
+			FunctionFilter: c.opts.functionFilter,
+			Packages:       c.opts.Packages,
+			ExecFile:       c.opts.ExecFile,
+			ClassFiles:     c.opts.ClassFiles,
+			BuildStdout:    c.opts.buildStdout,
+			BuildStderr:    c.opts.buildStderr,
+			Stderr:         c.OutOrStderr(),
 		}
 	case config.BuildSystemNodeJS:
 		if len(c.opts.argsToPass) > 0 {
@@ -353,41 +1610,76 @@ func (c *coverageCmd) run() error {
 
 		err = cmdutils.ValidateNodeFuzzTest(c.opts.ProjectDir, c.opts.fuzzTest, c.opts.testNamePattern)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		gen = &nodeCoverage.CoverageGenerator{
-			OutputPath:      c.opts.OutputPath,
-			OutputFormat:    c.opts.OutputFormat,
+			OutputPath:      reportOutputPath,
+			OutputFormat:    reportFormat,
 			TestPathPattern: c.opts.fuzzTest,
 			TestNamePattern: c.opts.testNamePattern,
 			ProjectDir:      c.opts.ProjectDir,
 			Stderr:          c.OutOrStderr(),
 			BuildStdout:     c.opts.buildStdout,
 			BuildStderr:     c.opts.buildStderr,
+			FunctionFilter:  c.opts.functionFilter,
 		}
 	default:
-		return errors.Errorf("Unsupported build system \"%s\"", c.opts.BuildSystem)
+		return nil, errors.Errorf("Unsupported build system \"%s\"", c.opts.BuildSystem)
 	}
 
-	if c.opts.BuildSystem != config.BuildSystemNodeJS {
-		buildPrinter := logging.NewBuildPrinter(os.Stdout, log.BuildInProgressMsg)
-		log.Infof("Building %s", pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(c.opts.fuzzTest))
+	return gen, nil
+}
 
-		err = gen.BuildFuzzTestForCoverage()
+// generateReport builds the fuzz test if needed and generates the
+// coverage report, returning its path.
+func (c *coverageCmd) generateReport(gen Generator) (string, error) {
+	if c.opts.BuildSystem != config.BuildSystemNodeJS && !c.opts.SkipBuild {
+		err := c.build(gen)
 		if err != nil {
-			buildPrinter.StopOnError(log.BuildInProgressErrorMsg)
-			return err
+			return "", err
 		}
-
-		buildPrinter.StopOnSuccess(log.BuildInProgressSuccessMsg, true)
 	}
 
 	reportPath, err := gen.GenerateCoverageReport()
 	if err != nil {
-		return err
+		return "", err
+	}
+	return reportPath, c.stripPaths(reportPath)
+}
+
+// stripPaths strips the path prefix from the paths in the report at
+// reportPath if --strip-paths was specified.
+func (c *coverageCmd) stripPaths(reportPath string) error {
+	if !c.opts.StripPaths {
+		return nil
+	}
+	return parser.StripPathsInReport(reportPath, c.opts.StripPathPrefix)
+}
+
+func (c *coverageCmd) build(gen Generator) error {
+	// The Java projects are already built when creating the generator
+	// (see buildJava), the Java generator only runs the fuzz test with
+	// the jacoco agent here, which can be done in parallel
+	if c.opts.BuildSystem != config.BuildSystemMaven && c.opts.BuildSystem != config.BuildSystemGradle {
+		c.lockBuild()
+		defer c.unlockBuild()
 	}
 
+	buildPrinter := logging.NewBuildPrinter(os.Stdout, log.BuildInProgressMsg)
+	log.Infof("Building %s", pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(c.opts.fuzzTest))
+
+	err := gen.BuildFuzzTestForCoverage()
+	if err != nil {
+		buildPrinter.StopOnError(log.BuildInProgressErrorMsg)
+		return cmdutils.WrapCompilationError(err, c.opts.fuzzTest)
+	}
+
+	buildPrinter.StopOnSuccess(log.BuildInProgressSuccessMsg, true)
+	return nil
+}
+
+func (c *coverageCmd) handleReport(reportPath string) error {
 	switch c.opts.OutputFormat {
 	case coverage.FormatHTML:
 		return c.handleHTMLReport(reportPath)
@@ -397,6 +1689,15 @@ func (c *coverageCmd) run() error {
 	case coverage.FormatJacocoXML:
 		log.Successf("Created jacoco.xml coverage report: %s", reportPath)
 		return nil
+	case coverage.FormatCobertura:
+		log.Successf("Created Cobertura coverage report: %s", reportPath)
+		return nil
+	case coverage.FormatJUnit:
+		// The JUnit report is written when checking the thresholds
+		return nil
+	case coverage.FormatSARIF:
+		// The SARIF report is written from the coverage summary
+		return nil
 	default:
 		return errors.Errorf("Unsupported output format")
 	}
@@ -427,6 +1728,109 @@ func (c *coverageCmd) handleHTMLReport(reportPath string) error {
 	return nil
 }
 
+// checkThresholds compares the coverage with the thresholds, writes
+// the JUnit report if the format is "junit" and returns an error if any
+// of the checks failed.
+func (c *coverageCmd) checkThresholds(summary *parser.Summary) error {
+	if summary == nil {
+		return errors.New("Failed to check the coverage thresholds: no coverage summary was computed")
+	}
+	checks := summary.CheckThresholds(c.opts.FailUnder, c.opts.FailUnderFile)
+
+	if c.opts.OutputFormat == coverage.FormatJUnit {
+		outputPath := c.opts.OutputPath
+		if outputPath == "" {
+			outputPath = "coverage-junit.xml"
+		}
+		suiteName := c.opts.fuzzTest
+		if len(c.opts.fuzzTests) != 1 {
+			suiteName = "coverage"
+		}
+		err := parser.WriteJUnitReport(outputPath, suiteName, checks)
+		if err != nil {
+			return err
+		}
+		err = c.stripPaths(outputPath)
+		if err != nil {
+			return err
+		}
+		log.Successf("Created JUnit coverage report: %s", outputPath)
+	}
+
+	var failed []string
+	for _, check := range checks {
+		if !check.Passed() {
+			failed = append(failed, check.String())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("Coverage is below the threshold:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+func (c *coverageCmd) writeSARIFReport(summary *parser.Summary) error {
+	if summary == nil {
+		return errors.New("Failed to write the SARIF report: no coverage summary was computed")
+	}
+	outputPath := c.opts.OutputPath
+	if outputPath == "" {
+		outputPath = "coverage.sarif"
+	}
+	projectDir := c.opts.ProjectDir
+	if c.opts.StripPaths {
+		// Make the paths relative to the prefix, which is omitted
+		// from the report
+		projectDir = c.opts.StripPathPrefix
+	}
+	err := parser.WriteSARIFReport(outputPath, summary, projectDir, c.opts.StripPaths)
+	if err != nil {
+		return err
+	}
+	err = c.stripPaths(outputPath)
+	if err != nil {
+		return err
+	}
+	log.Successf("Created SARIF coverage report: %s", outputPath)
+	return nil
+}
+
+func (c *coverageCmd) writeCoveredFiles(summary *parser.Summary) error {
+	if summary == nil {
+		return errors.New("Failed to write the covered files: no coverage summary was computed")
+	}
+	err := os.MkdirAll(filepath.Dir(c.opts.CoveredFilesOut), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = summary.WriteCoveredFiles(c.opts.CoveredFilesOut)
+	if err != nil {
+		return err
+	}
+	err = c.stripPaths(c.opts.CoveredFilesOut)
+	if err != nil {
+		return err
+	}
+	log.Successf("Created list of covered files: %s", c.opts.CoveredFilesOut)
+	return nil
+}
+
+func (c *coverageCmd) writeBadge(summary *parser.Summary) error {
+	if summary == nil {
+		return errors.New("Failed to write the coverage badge: no coverage summary was computed")
+	}
+	err := os.MkdirAll(filepath.Dir(c.opts.Badge), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = summary.WriteBadge(c.opts.Badge, c.opts.BadgeLabel)
+	if err != nil {
+		return err
+	}
+	log.Successf("Created coverage badge: %s", c.opts.Badge)
+	return nil
+}
+
 func (c *coverageCmd) openReport(reportPath string) error {
 	// ignore output of browser package
 	browser.Stdout = io.Discard
@@ -484,7 +1888,7 @@ func (c *coverageCmd) checkDependencies() error {
 	default:
 		return errors.Errorf("Unsupported build system \"%s\"", c.opts.BuildSystem)
 	}
-	err := dependencies.Check(deps, c.opts.ProjectDir)
+	_, err := dependencies.Check(deps, c.opts.ProjectDir)
 	if err != nil {
 		return err
 	}
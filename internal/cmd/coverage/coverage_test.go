@@ -1,17 +1,21 @@
 package coverage
 
 import (
+	"archive/zip"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/coverage"
 	"code-intelligence.com/cifuzz/internal/testutil"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 )
@@ -104,3 +108,378 @@ func TestNodeMissing(t *testing.T) {
 
 	assert.Contains(t, stdErr, fmt.Sprintf(dependencies.MessageMissing, "node"))
 }
+
+func TestValidateOutputPath(t *testing.T) {
+	testDir := testutil.MkdirTemp(t, "", "coverage-output-test-")
+	file := filepath.Join(testDir, "report.lcov")
+	err := os.WriteFile(file, nil, 0o644)
+	require.NoError(t, err)
+
+	// An HTML report can't be written to an existing file
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, OutputPath: file}
+	require.Error(t, opts.validateOutputPath())
+
+	// An lcov report can't be written to an existing directory
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, OutputPath: testDir}
+	require.Error(t, opts.validateOutputPath())
+
+	// Java reports are always written to a directory
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatLCOV, OutputPath: testDir}
+	require.NoError(t, opts.validateOutputPath())
+
+	// JUnit reports are always written to a file
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatJUnit, OutputPath: testDir}
+	require.Error(t, opts.validateOutputPath())
+
+	// SARIF reports are always written to a file
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatSARIF, OutputPath: testDir}
+	require.Error(t, opts.validateOutputPath())
+
+	// Parent directories are created
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, OutputPath: filepath.Join(testDir, "sub", "report.lcov")}
+	require.NoError(t, opts.validateOutputPath())
+	assert.DirExists(t, filepath.Join(testDir, "sub"))
+}
+
+func TestValidateCoveragePackages(t *testing.T) {
+	opts := &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, Packages: []string{"com.example"}}
+	require.NoError(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, Packages: []string{"com/example"}}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, Packages: []string{"com.example"}}
+	require.Error(t, opts.validate())
+}
+
+func TestValidateExecFile(t *testing.T) {
+	execFile := filepath.Join(t.TempDir(), "jacoco.exec")
+	err := os.WriteFile(execFile, []byte{0x01, 0xc0, 0xc0, 0x10, 0x07}, 0o644)
+	require.NoError(t, err)
+
+	opts := &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, ExecFile: execFile, SkipBuild: true}
+	require.NoError(t, opts.validate())
+
+	// Both flags must be set
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, ExecFile: execFile}
+	require.Error(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, SkipBuild: true}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, ExecFile: execFile + ".missing", SkipBuild: true}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, ExecFile: execFile, SkipBuild: true}
+	require.Error(t, opts.validate())
+
+	// The exec file must be a JaCoCo execution data file
+	err = os.WriteFile(execFile, nil, 0o644)
+	require.NoError(t, err)
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, ExecFile: execFile, SkipBuild: true}
+	require.Error(t, opts.validate())
+	err = os.WriteFile(execFile, []byte("not an exec file"), 0o644)
+	require.NoError(t, err)
+	require.Error(t, opts.validate())
+}
+
+func TestValidateClassFiles(t *testing.T) {
+	dir := t.TempDir()
+	classesDir := filepath.Join(dir, "classes", "com", "example")
+	err := os.MkdirAll(classesDir, 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(classesDir, "App.class"), nil, 0o644)
+	require.NoError(t, err)
+	jar := filepath.Join(dir, "app.jar")
+	writeJar(t, jar, "com/example/App.class")
+	txt := filepath.Join(dir, "app.txt")
+	err = os.WriteFile(txt, nil, 0o644)
+	require.NoError(t, err)
+
+	opts := &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, ClassFiles: jar}
+	require.NoError(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, ClassFiles: filepath.Join(dir, "classes")}
+	require.NoError(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, ClassFiles: txt}
+	require.Error(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, ClassFiles: jar + ".missing"}
+	require.Error(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, ClassFiles: jar}
+	require.Error(t, opts.validate())
+
+	// The directory or JAR must contain class files
+	emptyDir := t.TempDir()
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatHTML, ClassFiles: emptyDir}
+	require.Error(t, opts.validate())
+	emptyJar := filepath.Join(dir, "empty.jar")
+	writeJar(t, emptyJar, "META-INF/MANIFEST.MF")
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, ClassFiles: emptyJar}
+	require.Error(t, opts.validate())
+}
+
+func writeJar(t *testing.T, path string, entries ...string) {
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+	w := zip.NewWriter(file)
+	for _, entry := range entries {
+		_, err = w.Create(entry)
+		require.NoError(t, err)
+	}
+	err = w.Close()
+	require.NoError(t, err)
+}
+
+func TestValidateThresholds(t *testing.T) {
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatJUnit, FailUnder: 80, FailUnderFile: 50}
+	require.NoError(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, FailUnder: 101}
+	require.Error(t, opts.validate())
+
+	// The thresholds are checked against the coverage of the matching
+	// functions
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatJUnit, Function: "foo"}
+	require.NoError(t, opts.validate())
+}
+
+func TestValidateFunction(t *testing.T) {
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, Function: "foo"}
+	require.NoError(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatLCOV, Function: "foo"}
+	require.NoError(t, opts.validate())
+
+	// JaCoCo and Jest reports can't be filtered
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, Function: "foo"}
+	require.Error(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemGradle, OutputFormat: coverage.FormatJacocoXML, Function: "foo"}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, Function: "("}
+	require.Error(t, opts.validate())
+}
+
+func TestValidatePerInput(t *testing.T) {
+	fuzzTests := []*fuzzTestTarget{{fuzzTest: "fuzz_test_1"}, {fuzzTest: "fuzz_test_2"}}
+
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, PerInput: true, fuzzTests: fuzzTests[:1]}
+	require.NoError(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, PerInput: true, fuzzTests: fuzzTests[:1]}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, PerInput: true, fuzzTests: fuzzTests}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, PerInputOutput: "inputs.json", fuzzTests: fuzzTests[:1]}
+	require.Error(t, opts.validate())
+}
+
+func TestPerInputOutputPath(t *testing.T) {
+	opts := &coverageOptions{}
+	assert.Equal(t, "coverage-per-input.json", opts.perInputOutputPath())
+
+	opts = &coverageOptions{OutputPath: filepath.Join("out", "coverage.lcov")}
+	assert.Equal(t, filepath.Join("out", "coverage.per-input.json"), opts.perInputOutputPath())
+
+	opts = &coverageOptions{OutputPath: "report" + string(filepath.Separator)}
+	assert.Equal(t, "report.per-input.json", opts.perInputOutputPath())
+
+	opts = &coverageOptions{OutputPath: "coverage.lcov", PerInputOutput: "inputs.json"}
+	assert.Equal(t, "inputs.json", opts.perInputOutputPath())
+}
+
+func TestValidateMultipleFuzzTests(t *testing.T) {
+	fuzzTests := []*fuzzTestTarget{{fuzzTest: "fuzz_test_1"}, {fuzzTest: "fuzz_test_2"}}
+
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatCobertura, fuzzTests: fuzzTests}
+	require.NoError(t, opts.validate())
+	assert.True(t, opts.mergesReports())
+
+	// HTML reports can't be merged
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatHTML, fuzzTests: fuzzTests}
+	require.Error(t, opts.validate())
+
+	// Cobertura reports are merged even for a single fuzz test
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatCobertura, fuzzTests: fuzzTests[:1]}
+	require.NoError(t, opts.validate())
+	assert.True(t, opts.mergesReports())
+
+	// The LLVM generator creates Cobertura reports itself
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatCobertura, fuzzTests: fuzzTests[:1]}
+	require.NoError(t, opts.validate())
+	assert.False(t, opts.mergesReports())
+
+	// SARIF reports are created from the summary of the LLVM generator
+	// as well
+	for _, buildSystem := range []string{config.BuildSystemCMake, config.BuildSystemBazel} {
+		opts = &coverageOptions{BuildSystem: buildSystem, OutputFormat: coverage.FormatSARIF, fuzzTests: fuzzTests}
+		require.NoError(t, opts.validate())
+	}
+}
+
+func TestValidateMultipleFormats(t *testing.T) {
+	fuzzTests := []*fuzzTestTarget{{fuzzTest: "fuzz_test_1"}, {fuzzTest: "fuzz_test_2"}}
+
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: "html, lcov,html", OutputDir: "coverage", fuzzTests: fuzzTests[:1]}
+	require.NoError(t, opts.validate())
+	assert.Equal(t, []string{coverage.FormatHTML, coverage.FormatLCOV}, opts.outputFormats)
+
+	// The output directory is required
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: "html,lcov", fuzzTests: fuzzTests[:1]}
+	require.Error(t, opts.validate())
+
+	// The output directory is only used for multiple formats
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, OutputDir: "coverage", fuzzTests: fuzzTests[:1]}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: "lcov,junit", OutputDir: "coverage", fuzzTests: fuzzTests[:1]}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: "html,lcov", OutputDir: "coverage", fuzzTests: fuzzTests}
+	require.Error(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: "html,lcov", OutputDir: "coverage", fuzzTests: fuzzTests[:1]}
+	require.Error(t, opts.validate())
+}
+
+func TestValidateJobs(t *testing.T) {
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, Jobs: 4}
+	require.NoError(t, opts.validate())
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV}
+	require.NoError(t, opts.validate())
+	assert.Equal(t, uint(1), opts.Jobs)
+
+	opts = &coverageOptions{BuildSystem: config.BuildSystemBazel, OutputFormat: coverage.FormatLCOV, Jobs: 4}
+	require.Error(t, opts.validate())
+}
+
+func TestRunMergeInputs(t *testing.T) {
+	dir := t.TempDir()
+	shard1 := filepath.Join(dir, "shard1.lcov")
+	err := os.WriteFile(shard1, []byte(`SF:/project/src/parser.cpp
+DA:1,1
+DA:2,0
+end_of_record
+`), 0o644)
+	require.NoError(t, err)
+	// The second report was created from a revision in which the file
+	// has more lines
+	shard2 := filepath.Join(dir, "shard2.lcov")
+	err = os.WriteFile(shard2, []byte(`SF:/project/src/parser.cpp
+DA:1,2
+DA:2,1
+DA:3,0
+end_of_record
+`), 0o644)
+	require.NoError(t, err)
+
+	output := filepath.Join(dir, "merged.lcov")
+	opts := &coverageOptions{Merge: true, Inputs: []string{shard1, shard2}, OutputFormat: coverage.FormatLCOV, OutputPath: output}
+	require.NoError(t, opts.validateMerge())
+	cmd := &coverageCmd{Command: &cobra.Command{}, opts: opts}
+	require.NoError(t, cmd.run())
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "DA:1,3\nDA:2,1\nDA:3,0\n")
+	assert.Contains(t, string(content), "LF:3\nLH:2\n")
+}
+
+func TestMerge_WithoutProject(t *testing.T) {
+	dir := testutil.ChdirToTempDir(t, "coverage-merge-test-")
+	shard1 := filepath.Join(dir, "shard1.lcov")
+	err := os.WriteFile(shard1, []byte("SF:/project/src/parser.cpp\nDA:1,1\nDA:2,0\nend_of_record\n"), 0o644)
+	require.NoError(t, err)
+	shard2 := filepath.Join(dir, "shard2.lcov")
+	err = os.WriteFile(shard2, []byte("SF:/project/src/parser.cpp\nDA:1,2\nDA:2,1\nend_of_record\n"), 0o644)
+	require.NoError(t, err)
+
+	// Via the merge subcommand
+	output := filepath.Join(dir, "merged.lcov")
+	_, _, err = cmdutils.ExecuteCommand(t, New(), os.Stdin, "merge", "--output", output, shard1, shard2)
+	require.NoError(t, err)
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "DA:1,3\nDA:2,1\n")
+
+	// Via the merge flag
+	output = filepath.Join(dir, "merged-flag.lcov")
+	_, _, err = cmdutils.ExecuteCommand(t, New(), os.Stdin, "--merge", "--input", shard1, "--input", shard2, "--output", output)
+	require.NoError(t, err)
+	content, err = os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "DA:1,3\nDA:2,1\n")
+}
+
+func TestValidateMerge(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "report.lcov")
+	err := os.WriteFile(input, nil, 0o644)
+	require.NoError(t, err)
+
+	opts := &coverageOptions{Merge: true, Inputs: []string{input}, OutputFormat: coverage.FormatCobertura}
+	require.NoError(t, opts.validateMerge())
+
+	opts = &coverageOptions{Merge: true, OutputFormat: coverage.FormatLCOV}
+	require.Error(t, opts.validateMerge())
+
+	opts = &coverageOptions{Merge: true, Inputs: []string{input + ".missing"}, OutputFormat: coverage.FormatLCOV}
+	require.Error(t, opts.validateMerge())
+
+	opts = &coverageOptions{Merge: true, Inputs: []string{input}, OutputFormat: coverage.FormatHTML}
+	require.Error(t, opts.validateMerge())
+
+	// Inputs can't be used without --merge
+	opts = &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, Inputs: []string{input}}
+	require.Error(t, opts.validate())
+}
+
+func TestValidateHTMLIndex(t *testing.T) {
+	fuzzTests := []*fuzzTestTarget{{fuzzTest: "com.example.FuzzTestA"}, {fuzzTest: "com.example.FuzzTestB"}}
+	outputDir := t.TempDir()
+
+	opts := &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, OutputDir: outputDir, fuzzTests: fuzzTests}
+	require.NoError(t, opts.validate())
+
+	// The reports are written to the output directory
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, fuzzTests: fuzzTests}
+	require.Error(t, opts.validate())
+
+	// There is no merged report for a badge
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatHTML, OutputDir: outputDir, Badge: "badge.svg", fuzzTests: fuzzTests}
+	require.Error(t, opts.validate())
+}
+
+func TestValidateCorpusFromGit(t *testing.T) {
+	// The seed corpus and the generated corpus are taken from git for
+	// CMake and other, so no additional corpus directory is required
+	opts := &coverageOptions{BuildSystem: config.BuildSystemCMake, OutputFormat: coverage.FormatLCOV, CorpusFromGit: "v1.0"}
+	require.NoError(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemOther, BuildCommand: "make", OutputFormat: coverage.FormatLCOV, CorpusFromGit: "v1.0"}
+	require.NoError(t, opts.validate())
+
+	// For the other build systems, only the additional corpus
+	// directories are taken from git
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatLCOV, CorpusFromGit: "v1.0"}
+	require.Error(t, opts.validate())
+	opts = &coverageOptions{BuildSystem: config.BuildSystemMaven, OutputFormat: coverage.FormatLCOV, CorpusFromGit: "v1.0", CorpusDirs: []string{t.TempDir()}}
+	require.NoError(t, opts.validate())
+}
+
+func TestHTMLReportDirs(t *testing.T) {
+	dirs := htmlReportDirs([]*fuzzTestTarget{
+		{fuzzTest: "com.example.FuzzTest", targetMethod: "fuzzA"},
+		{fuzzTest: "com.example.FuzzTest", targetMethod: "fuzzB"},
+		{fuzzTest: "src/fuzz_test"},
+		{fuzzTest: "src/fuzz-test"},
+		{fuzzTest: "src/fuzz:test"},
+	})
+	assert.Equal(t, []string{
+		"com.example.FuzzTest_fuzzA",
+		"com.example.FuzzTest_fuzzB",
+		"src_fuzz_test",
+		"src_fuzz-test",
+		"src_fuzz_test_2",
+	}, dirs)
+}
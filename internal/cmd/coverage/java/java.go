@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -18,6 +19,7 @@ import (
 	"code-intelligence.com/cifuzz/pkg/options"
 	parser "code-intelligence.com/cifuzz/pkg/parser/coverage"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
+	fuzzer_runner "code-intelligence.com/cifuzz/pkg/runner"
 	"code-intelligence.com/cifuzz/util/envutil"
 	"code-intelligence.com/cifuzz/util/executil"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -35,23 +37,44 @@ type CoverageGenerator struct {
 	Deps       []string
 	CorpusDirs []string
 	EngineArgs []string
+	// NativeLibPaths are the directories which contain native
+	// libraries loaded by the fuzz test via JNI
+	NativeLibPaths []string
+
+	FunctionFilter *regexp.Regexp
+	// Packages restricts the coverage report to the classes of these
+	// Java packages and their subpackages
+	Packages []string
+	// ExecFile is the path to an existing jacoco.exec file which is
+	// used to generate the report. If set, the fuzz test doesn't have
+	// to be run via BuildFuzzTestForCoverage.
+	ExecFile string
+	// ClassFiles is the directory or JAR containing the class files
+	// which are analyzed for the report. If not set, the class files
+	// directory of the build system is used or, if that doesn't exist,
+	// the JAR built by the project.
+	ClassFiles string
 
 	BuildStdout io.Writer
 	BuildStderr io.Writer
 	Stderr      io.Writer
+
+	summary *parser.Summary
+}
+
+// Summary returns the coverage summary parsed from the JaCoCo XML
+// report, or nil if no report was generated yet. If a function filter
+// was specified, the summary is restricted to the matching functions.
+func (cov *CoverageGenerator) Summary() *parser.Summary {
+	return cov.summary
 }
 
 // BuildFuzzTestForCoverage builds the jacoco.exec file for
 // the fuzz test which is used to generate the coverage report.
 func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
-	if cov.OutputPath == "" {
-		cov.OutputPath = filepath.Join(cov.ProjectDir, ".cifuzz-build", "report")
-	}
-	// Make sure that the directories actually exist otherwise
-	// the java command later on will fail
-	err := os.MkdirAll(cov.OutputPath, 0755)
+	err := cov.createOutputDir()
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 
 	// Set the Java agent
@@ -67,19 +90,40 @@ func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
 // jacoco CLI and depending on the output format, also converts
 // it to a html or lcov report.
 func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
+	err := cov.createOutputDir()
+	if err != nil {
+		return "", err
+	}
+
 	cliJar, err := runfiles.Finder.JacocoCLIJarPath()
 	if err != nil {
 		return "", err
 	}
 
-	// Class files are stored differently dependent on build system
-	classFilesDir := filepath.Join(cov.ProjectDir, "target", "classes")
-	if cov.BuildSystem == config.BuildSystemGradle {
-		classFilesDir = filepath.Join(cov.ProjectDir, "build", "classes")
+	classFiles, err := cov.classFiles()
+	if err != nil {
+		return "", err
+	}
+
+	if cov.ExecFile != "" {
+		// The exec file only contains execution data, which can only be
+		// mapped to the source code via the class files it was recorded
+		// for, so those have to exist
+		exists, err := fileutil.Exists(classFiles)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", errors.Errorf("Class files directory %s does not exist, the project must be compiled to generate a report from the exec file %s", classFiles, cov.ExecFile)
+		}
+		err = ValidateClassFiles(classFiles)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	htmlPath := filepath.Join(cov.OutputPath, "html")
-	jacocoXMLPath, err := cov.runJacocoCommand(cliJar, cov.jacocoExecFilePath(), htmlPath, classFilesDir)
+	jacocoXMLPath, err := cov.runJacocoCommand(cliJar, cov.jacocoExecFilePath(), htmlPath, classFiles)
 	if err != nil {
 		return "", err
 	}
@@ -89,7 +133,20 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 		return "", errors.WithStack(err)
 	}
 
-	parser.ParseJacocoXMLIntoSummary(jacocoReport).PrintTable(cov.Stderr)
+	var filteredReport *parser.LCOVReport
+	if cov.FunctionFilter != nil {
+		lcovReport, err := parser.ParseJacocoXMLIntoLCOVReport(jacocoReport)
+		if err != nil {
+			jacocoReport.Close()
+			return "", err
+		}
+		parser.PrintFunctionTable(lcovReport.FilterFunctions(cov.FunctionFilter), cov.FunctionFilter, cov.Stderr)
+		filteredReport = lcovReport.FilterByFunctions(cov.FunctionFilter)
+		cov.summary = filteredReport.Summary()
+	} else {
+		cov.summary = parser.ParseJacocoXMLIntoSummary(jacocoReport)
+		cov.summary.PrintTable(cov.Stderr)
+	}
 	// Close the report here directly, so it can be used
 	// for lcov parsing if needed
 	jacocoReport.Close()
@@ -100,18 +157,25 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 	case coverage.FormatHTML:
 		return htmlPath, nil
 	case coverage.FormatLCOV:
-		// Open report here again otherwise it will be seen as empty
-		// after parsing it into the summary
-		reportFile, err := os.Open(jacocoXMLPath)
-		if err != nil {
-			return "", errors.WithStack(err)
-		}
-
-		lcovReport, err := parser.ParseJacocoXMLIntoLCOVReport(reportFile)
-		if err != nil {
-			return "", err
+		// Write the report restricted to the matching functions if
+		// a function filter was specified
+		lcovReport := filteredReport
+		if lcovReport == nil {
+			// Open report here again otherwise it will be seen as empty
+			// after parsing it into the summary
+			reportFile, err := os.Open(jacocoXMLPath)
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			defer reportFile.Close()
+
+			lcovReport, err = parser.ParseJacocoXMLIntoLCOVReport(reportFile)
+			if err != nil {
+				return "", err
+			}
 		}
 
+		lcovReport.SetTestName(cov.FuzzTest)
 		lcovFilePath := filepath.Join(cov.OutputPath, "report.lcov")
 		err = lcovReport.WriteLCOVReportToFile(lcovFilePath)
 		if err != nil {
@@ -188,6 +252,7 @@ func (cov *CoverageGenerator) GenerateCoverageReportInFuzzContainer(jacocoExecFi
 	}()
 
 	// Write the LCOV report to the specified path.
+	lcovReport.SetTestName(cov.FuzzTest)
 	lcovPath := filepath.Join(cov.OutputPath, "report.lcov")
 	err = lcovReport.WriteLCOVReportToFile(lcovPath)
 	if err != nil {
@@ -221,16 +286,109 @@ func (cov *CoverageGenerator) environment() ([]string, error) {
 		return nil, err
 	}
 
+	if len(cov.NativeLibPaths) > 0 {
+		env, err = fuzzer_runner.SetNativeLibraryPath(env, cov.NativeLibPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return env, nil
 }
 
+// createOutputDir sets the default output path if none was specified
+// and creates the directory.
+func (cov *CoverageGenerator) createOutputDir() error {
+	if cov.OutputPath == "" {
+		cov.OutputPath = filepath.Join(cov.ProjectDir, ".cifuzz-build", "report")
+	}
+	// Make sure that the directories actually exist otherwise
+	// the java command later on will fail
+	err := os.MkdirAll(cov.OutputPath, 0755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 // jacocoExecFilePath returns the path where the jacoco.exec should
 // be generated. Including the fuzz test in the file name ensures
-// that no aggregated reports are created.
+// that no aggregated reports are created. If an existing exec file
+// was specified, that one is used.
 func (cov *CoverageGenerator) jacocoExecFilePath() string {
+	if cov.ExecFile != "" {
+		return cov.ExecFile
+	}
 	return filepath.Join(cov.OutputPath, fmt.Sprintf("jacoco_%s_%s.exec", cov.FuzzTest, cov.TargetMethod))
 }
 
+// classFiles returns the directory or JAR containing the class files of
+// the project. Class files are stored differently dependent on build
+// system. Projects which only produce a JAR don't have a class files
+// directory, in which case the application JAR from the build output is
+// used.
+func (cov *CoverageGenerator) classFiles() (string, error) {
+	if cov.ClassFiles != "" {
+		return cov.ClassFiles, nil
+	}
+
+	classFilesDir := filepath.Join(cov.ProjectDir, "target", "classes")
+	jarDir := filepath.Join(cov.ProjectDir, "target")
+	if cov.BuildSystem == config.BuildSystemGradle {
+		classFilesDir = filepath.Join(cov.ProjectDir, "build", "classes")
+		jarDir = filepath.Join(cov.ProjectDir, "build", "libs")
+	}
+
+	if fileutil.IsDir(classFilesDir) {
+		return classFilesDir, nil
+	}
+	jar, err := applicationJar(jarDir)
+	if err != nil {
+		return "", err
+	}
+	if jar == "" {
+		// Keep the class files directory, so that a missing build is
+		// reported with the expected path
+		return classFilesDir, nil
+	}
+	log.Debugf("Class files directory %s does not exist, using class files from %s", classFilesDir, jar)
+	return jar, nil
+}
+
+// applicationJar returns the most recently built JAR in the directory
+// which contains the classes of the application, i.e. not the sources,
+// javadoc or tests JAR. It returns an empty string if there is none.
+func applicationJar(dir string) (string, error) {
+	jars, err := filepath.Glob(filepath.Join(dir, "*.jar"))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	var jar string
+	var modTime time.Time
+	for _, path := range jars {
+		name := strings.TrimSuffix(filepath.Base(path), ".jar")
+		if strings.HasSuffix(name, "-sources") ||
+			strings.HasSuffix(name, "-javadoc") ||
+			strings.HasSuffix(name, "-tests") ||
+			strings.HasSuffix(name, "-test-sources") {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		if jar == "" || info.ModTime().After(modTime) {
+			jar = path
+			modTime = info.ModTime()
+		}
+	}
+	return jar, nil
+}
+
 func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath, classFilesDir string) (string, error) {
 	jacocoXMLPath := filepath.Join(cov.OutputPath, "jacoco.xml")
 
@@ -238,7 +396,13 @@ func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath,
 		"-jar", cliJar,
 		"report", jacocoExecPath,
 		"--xml", jacocoXMLPath,
-		"--classfiles", classFilesDir,
+	}
+	classFilesDirs, err := cov.packageClassFilesDirs(classFilesDir)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range classFilesDirs {
+		args = append(args, "--classfiles", dir)
 	}
 	// Set html output path if needed
 	if cov.OutputFormat == coverage.FormatHTML {
@@ -250,7 +414,7 @@ func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath,
 	cmd.Stderr = cov.BuildStderr
 	cmd.Stdout = cov.BuildStdout
 	log.Debugf("Command: %s", strings.Join(stringutil.QuotedStrings(cmd.Args), " "))
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
@@ -258,6 +422,53 @@ func (cov *CoverageGenerator) runJacocoCommand(cliJar, jacocoExecPath, htmlPath,
 	return jacocoXMLPath, nil
 }
 
+// packageClassFilesDirs returns the directories containing the class
+// files which should be analyzed for the coverage report. If no
+// packages were specified, that's the whole class files directory,
+// else only the directories of the specified packages. A JAR is always
+// analyzed as a whole.
+func (cov *CoverageGenerator) packageClassFilesDirs(classFilesDir string) ([]string, error) {
+	if len(cov.Packages) == 0 {
+		return []string{classFilesDir}, nil
+	}
+	if strings.HasSuffix(classFilesDir, ".jar") {
+		log.Warnf("The class files in %s can't be restricted to the specified packages, "+
+			"the coverage report includes all classes of the JAR", classFilesDir)
+		return []string{classFilesDir}, nil
+	}
+
+	roots := []string{classFilesDir}
+	if cov.BuildSystem == config.BuildSystemGradle {
+		// Gradle stores the class files in
+		// build/classes/<language>/<source set>
+		var err error
+		roots, err = filepath.Glob(filepath.Join(classFilesDir, "*", "*"))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var dirs []string
+	for _, pkg := range cov.Packages {
+		found := false
+		for _, root := range roots {
+			dir := filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(pkg, ".", "/")))
+			if fileutil.IsDir(dir) {
+				dirs = append(dirs, dir)
+				found = true
+			}
+		}
+		if !found {
+			log.Warnf("No class files found for package %s in %s", pkg, classFilesDir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, errors.Errorf("No class files found for the packages specified via --coverage-packages: %s",
+			strings.Join(cov.Packages, ", "))
+	}
+	return dirs, nil
+}
+
 func (cov *CoverageGenerator) produceJacocoExec(agentJarPath, jacocoExecFilePath string) error {
 	javaBin, err := runfiles.Finder.JavaPath()
 	if err != nil {
@@ -268,7 +479,17 @@ func (cov *CoverageGenerator) produceJacocoExec(agentJarPath, jacocoExecFilePath
 	if err != nil {
 		return err
 	}
-	args = append(args, fmt.Sprintf("-javaagent:%s=destfile=%s", agentJarPath, jacocoExecFilePath))
+	agentOptions := fmt.Sprintf("destfile=%s", jacocoExecFilePath)
+	if len(cov.Packages) > 0 {
+		// Only collect execution data for the classes of the specified
+		// packages
+		var includes []string
+		for _, pkg := range cov.Packages {
+			includes = append(includes, pkg+".*")
+		}
+		agentOptions += ",includes=" + strings.Join(includes, ":")
+	}
+	args = append(args, fmt.Sprintf("-javaagent:%s=%s", agentJarPath, agentOptions))
 
 	// Get class path from dependencies
 	classPath := strings.Join(cov.Deps, string(os.PathListSeparator))
@@ -290,6 +511,10 @@ func (cov *CoverageGenerator) produceJacocoExec(agentJarPath, jacocoExecFilePath
 		"-XX:+EnableDynamicAgentLoading",
 	)
 
+	if len(cov.NativeLibPaths) > 0 {
+		args = append(args, options.JavaLibraryPathFlag(cov.NativeLibPaths))
+	}
+
 	// Jazzer main class
 	args = append(args, options.JazzerMainClass)
 
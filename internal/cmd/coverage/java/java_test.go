@@ -0,0 +1,51 @@
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/config"
+)
+
+func TestClassFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	cov := &CoverageGenerator{BuildSystem: config.BuildSystemGradle, ProjectDir: projectDir}
+
+	// Without any build output, the default class files directory is used
+	classFiles, err := cov.classFiles()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(projectDir, "build", "classes"), classFiles)
+
+	// Fall back to the application JAR
+	libsDir := filepath.Join(projectDir, "build", "libs")
+	require.NoError(t, os.MkdirAll(libsDir, 0o755))
+	old := time.Now().Add(-time.Hour)
+	for _, name := range []string{"app-0.1.jar", "app-0.1-sources.jar", "app-0.1-javadoc.jar"} {
+		path := filepath.Join(libsDir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+	appJar := filepath.Join(libsDir, "app-0.2.jar")
+	require.NoError(t, os.WriteFile(appJar, nil, 0o644))
+	classFiles, err = cov.classFiles()
+	require.NoError(t, err)
+	assert.Equal(t, appJar, classFiles)
+
+	// The class files directory is preferred over the JAR
+	classesDir := filepath.Join(projectDir, "build", "classes")
+	require.NoError(t, os.MkdirAll(classesDir, 0o755))
+	classFiles, err = cov.classFiles()
+	require.NoError(t, err)
+	assert.Equal(t, classesDir, classFiles)
+
+	// The class files specified by the user take precedence
+	cov.ClassFiles = appJar
+	classFiles, err = cov.classFiles()
+	require.NoError(t, err)
+	assert.Equal(t, appJar, classFiles)
+}
@@ -0,0 +1,86 @@
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// execFileHeader is the start of every file written by the JaCoCo agent:
+// the header block type followed by the magic number 0xC0C0
+var execFileHeader = []byte{0x01, 0xc0, 0xc0}
+
+// ValidateExecFile returns an error if the file doesn't start with the
+// header of a JaCoCo execution data file. It doesn't check whether the
+// execution data matches any class files.
+func ValidateExecFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	header := make([]byte, len(execFileHeader))
+	_, err = io.ReadFull(file, header)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errors.Errorf("The exec file %s is empty", path)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !bytes.Equal(header, execFileHeader) {
+		return errors.Errorf("The exec file %s is not a JaCoCo execution data file", path)
+	}
+	return nil
+}
+
+// ValidateClassFiles returns an error if the directory or JAR doesn't
+// contain any class files. The class files are not compared to the
+// class IDs in an exec file.
+func ValidateClassFiles(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var found bool
+	if info.IsDir() {
+		errFound := errors.New("found class file")
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(p) == ".class" {
+				return errFound
+			}
+			return nil
+		})
+		found = errors.Is(err, errFound)
+		if err != nil && !found {
+			return errors.WithStack(err)
+		}
+	} else {
+		jar, err := zip.OpenReader(path)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to open JAR %s", path)
+		}
+		defer jar.Close()
+		for _, f := range jar.File {
+			if strings.HasSuffix(f.Name, ".class") {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return errors.Errorf("%s doesn't contain any class files", path)
+	}
+	return nil
+}
@@ -6,16 +6,20 @@ import (
 	"debug/macho"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/spf13/viper"
+	"golang.org/x/exp/maps"
 
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/cmake"
@@ -28,12 +32,18 @@ import (
 	"code-intelligence.com/cifuzz/pkg/parser/coverage"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
 	fuzzer_runner "code-intelligence.com/cifuzz/pkg/runner"
+	"code-intelligence.com/cifuzz/pkg/vcs"
 	"code-intelligence.com/cifuzz/util/envutil"
 	"code-intelligence.com/cifuzz/util/executil"
 	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
 
+// sourceFileExtensions are the extensions of the C/C++ source files
+// which are added to the report if IncludeUncoveredFiles is set
+var sourceFileExtensions = []string{".c", ".cc", ".cpp", ".cxx", ".c++"}
+
 type CoverageGenerator struct {
 	OutputFormat    string
 	OutputPath      string
@@ -49,6 +59,26 @@ type CoverageGenerator struct {
 	Stderr          io.Writer
 	BuildStdout     io.Writer
 	BuildStderr     io.Writer
+	FunctionFilter  *regexp.Regexp
+	Symbolizer      string
+	BuildEnv        []string
+	OutputRoot      string
+	// MemoryLimit is the limit in MB of the virtual memory of each
+	// build process. No limit is applied if it's 0.
+	MemoryLimit uint
+	// IncludeUncoveredFiles adds the source files in the project
+	// directory which were not executed at all to the report
+	IncludeUncoveredFiles bool
+	// PerInputOutputPath is the path of the JSON file to which the
+	// lines uniquely covered by each corpus input are written. If set,
+	// each input is run individually after generating the report, which
+	// is expensive for large corpora.
+	PerInputOutputPath string
+	// CorpusFromGit is a git ref. If set, the seed corpus and the
+	// generated corpus are taken from that ref instead of the working
+	// tree. They are skipped if they were not under version control
+	// at the ref.
+	CorpusFromGit string
 
 	coverageBinary string
 	libraryDirs    []string
@@ -56,6 +86,14 @@ type CoverageGenerator struct {
 	tmpDir         string
 	outputDir      string
 	runfilesFinder runfiles.RunfilesFinder
+	summary        *coverage.Summary
+}
+
+// Summary returns the coverage summary computed from the profile data
+// while generating the report, or nil if no report was generated yet.
+// With a function filter, it only covers the matching functions.
+func (cov *CoverageGenerator) Summary() *coverage.Summary {
+	return cov.summary
 }
 
 func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
@@ -84,26 +122,97 @@ func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
 }
 
 func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
-	log.Infof("Running %s on corpus", pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(cov.FuzzTest))
-	log.Debugf("Executable: %s", cov.coverageBinary)
+	ctx := context.Background()
+	defer fileutil.Cleanup(cov.tmpDir)
 
+	err := cov.collect(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	reportPath, err := cov.report(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if cov.PerInputOutputPath != "" {
+		err = cov.perInputCoverage(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+	return reportPath, nil
+}
+
+// GenerateCoverageReports runs the fuzz test on the corpus once and
+// writes a report of each format to the output path specified for it
+// in outputPaths.
+func (cov *CoverageGenerator) GenerateCoverageReports(outputPaths map[string]string) error {
 	ctx := context.Background()
 	defer fileutil.Cleanup(cov.tmpDir)
 
+	err := cov.collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	formats := maps.Keys(outputPaths)
+	sort.Strings(formats)
+	for _, format := range formats {
+		cov.OutputFormat = format
+		cov.OutputPath = outputPaths[format]
+		_, err = cov.report(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cov.PerInputOutputPath != "" {
+		err = cov.perInputCoverage(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect runs the fuzz test on the corpus, indexes the raw profiles
+// and prints the coverage summary (or the coverage of the functions
+// matching the function filter).
+func (cov *CoverageGenerator) collect(ctx context.Context) error {
+	log.Infof("Running %s on corpus", pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(cov.FuzzTest))
+	log.Debugf("Executable: %s", cov.coverageBinary)
+
 	err := cov.run(ctx)
 	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) && cov.UseSandbox {
-			return "", cmdutils.WrapCouldBeSandboxError(err)
+			return cmdutils.WrapCouldBeSandboxError(err)
 		}
-		return "", err
+		return err
 	}
 
-	reportPath, err := cov.report(ctx)
+	err = cov.indexRawProfile(ctx)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return reportPath, nil
+
+	if cov.FunctionFilter != nil {
+		return cov.printFunctionCoverage(ctx)
+	}
+
+	lcovReportSummary, err := cov.lcovReportSummary(ctx)
+	if err != nil {
+		return err
+	}
+	reportReader := strings.NewReader(lcovReportSummary)
+	summary, err := coverage.ParseLCOVReportIntoSummary(reportReader)
+	if err != nil {
+		return err
+	}
+	summary.PrintTable(cov.Stderr)
+	cov.summary = summary
+	return nil
 }
 
 func (cov *CoverageGenerator) GenerateCoverageReportInFuzzContainer(ctx context.Context, coverageBinary string,
@@ -193,11 +302,14 @@ func (cov *CoverageGenerator) build() error {
 				Enabled: viper.IsSet("build-jobs"),
 				NumJobs: uint(cov.NumBuildJobs),
 			},
-			Stdout: cov.BuildStdout,
-			Stderr: cov.BuildStderr,
+			Stdout:     cov.BuildStdout,
+			Stderr:     cov.BuildStderr,
+			BuildEnv:   cov.BuildEnv,
+			OutputRoot: cov.OutputRoot,
 			// We want the runtime deps in the build result because we
 			// pass them to the llvm-cov command.
 			FindRuntimeDeps: true,
+			MemoryLimit:     cov.MemoryLimit,
 		})
 		if err != nil {
 			return err
@@ -223,6 +335,7 @@ func (cov *CoverageGenerator) build() error {
 			RunfilesFinder: cov.runfilesFinder,
 			Stdout:         cov.BuildStdout,
 			Stderr:         cov.BuildStderr,
+			MemoryLimit:    cov.MemoryLimit,
 		})
 		if err != nil {
 			return err
@@ -243,6 +356,15 @@ func (cov *CoverageGenerator) build() error {
 	cov.coverageBinary = buildResult.Executable
 	cov.runtimeDeps = buildResult.RuntimeDeps
 
+	// The generated corpus is stored below the output root by cifuzz run
+	if cov.OutputRoot != "" {
+		buildResult.GeneratedCorpus = cmdutils.RebaseOnOutputRoot(buildResult.GeneratedCorpus, cov.ProjectDir, cov.OutputRoot)
+	}
+
+	if cov.CorpusFromGit != "" {
+		return cov.addCorpusDirsFromGit(buildResult.SeedCorpus, buildResult.GeneratedCorpus)
+	}
+
 	// Use the seed corpus directory and generated corpus directory if
 	// they exist.
 	for _, path := range []string{buildResult.SeedCorpus, buildResult.GeneratedCorpus} {
@@ -258,6 +380,34 @@ func (cov *CoverageGenerator) build() error {
 	return nil
 }
 
+// addCorpusDirsFromGit exports the specified corpus directories as they
+// were committed at the git ref specified via CorpusFromGit and adds
+// the exported directories to the corpus directories.
+func (cov *CoverageGenerator) addCorpusDirsFromGit(dirs ...string) error {
+	for i, dir := range dirs {
+		exists, err := vcs.GitDirExists(dir, cov.CorpusFromGit)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			log.Debugf("Skipping %s, it's not under version control at git ref %s", dir, cov.CorpusFromGit)
+			continue
+		}
+		exportDir := filepath.Join(cov.tmpDir, "corpus-from-git", strconv.Itoa(i))
+		err = os.MkdirAll(exportDir, 0o755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		err = vcs.GitExportDir(dir, cov.CorpusFromGit, exportDir)
+		if err != nil {
+			return err
+		}
+		log.Infof("Using the inputs of %s at git ref %s", fileutil.PrettifyPath(dir), cov.CorpusFromGit)
+		cov.CorpusDirs = append(cov.CorpusDirs, exportDir)
+	}
+	return nil
+}
+
 func (cov *CoverageGenerator) run(ctx context.Context) error {
 	var err error
 
@@ -273,21 +423,10 @@ func (cov *CoverageGenerator) run(ctx context.Context) error {
 	}
 
 	conModeSupport := binary.SupportsLlvmProfileContinuousMode(cov.coverageBinary)
-	var env []string
-	env, err = envutil.Setenv(env, "LLVM_PROFILE_FILE", cov.rawProfilePattern(conModeSupport))
+	env, err := cov.fuzzerEnv(cov.rawProfilePattern(cov.outputDir, conModeSupport))
 	if err != nil {
 		return err
 	}
-	env, err = envutil.Setenv(env, "NO_CIFUZZ", "1")
-	if err != nil {
-		return err
-	}
-	if len(cov.libraryDirs) > 0 {
-		env, err = fuzzer_runner.SetLDLibraryPath(env, cov.libraryDirs)
-		if err != nil {
-			return err
-		}
-	}
 
 	dirWithEmptyFile := filepath.Join(cov.outputDir, "empty-file-corpus")
 	err = os.Mkdir(dirWithEmptyFile, 0o755)
@@ -329,6 +468,35 @@ func (cov *CoverageGenerator) run(ctx context.Context) error {
 	return cov.runFuzzer(ctx, append(args, "-merge=1"), append([]string{emptyDir}, corpusDirs...), env)
 }
 
+// fuzzerEnv returns the environment for running the coverage binary
+// which writes its raw profiles to the specified pattern.
+func (cov *CoverageGenerator) fuzzerEnv(rawProfilePattern string) ([]string, error) {
+	var env []string
+	env, err := envutil.Setenv(env, "LLVM_PROFILE_FILE", rawProfilePattern)
+	if err != nil {
+		return nil, err
+	}
+	env, err = envutil.Setenv(env, "NO_CIFUZZ", "1")
+	if err != nil {
+		return nil, err
+	}
+	if len(cov.libraryDirs) > 0 {
+		env, err = fuzzer_runner.SetLDLibraryPath(env, cov.libraryDirs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cov.Symbolizer != "" {
+		// Use the user-specified llvm-symbolizer for stack traces of
+		// inputs which crash the fuzz test
+		env, err = envutil.Setenv(env, "ASAN_SYMBOLIZER_PATH", cov.Symbolizer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
 func (cov *CoverageGenerator) runFuzzer(ctx context.Context, preCorpusArgs []string,
 	corpusDirs []string, env []string) error {
 
@@ -391,23 +559,10 @@ func (cov *CoverageGenerator) runFuzzer(ctx context.Context, preCorpusArgs []str
 	return err
 }
 
+// report writes the report of the output format from the indexed
+// profile created by collect.
 func (cov *CoverageGenerator) report(ctx context.Context) (string, error) {
-	err := cov.indexRawProfile(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	lcovReportSummary, err := cov.lcovReportSummary(ctx)
-	if err != nil {
-		return "", err
-	}
-	reportReader := strings.NewReader(lcovReportSummary)
-	summary, err := coverage.ParseLCOVReportIntoSummary(reportReader)
-	if err != nil {
-		return "", err
-	}
-	summary.PrintTable(cov.Stderr)
-
+	var err error
 	reportPath := ""
 	switch cov.OutputFormat {
 	case "html":
@@ -421,20 +576,32 @@ func (cov *CoverageGenerator) report(ctx context.Context) (string, error) {
 		if err != nil {
 			return "", err
 		}
+
+	case "cobertura":
+		reportPath, err = cov.generateCoberturaReport(ctx)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	return reportPath, nil
 }
 
 func (cov *CoverageGenerator) indexRawProfile(ctx context.Context) error {
-	rawProfileFiles, err := cov.rawProfileFiles()
+	return cov.mergeRawProfiles(ctx, cov.outputDir, cov.indexedProfilePath())
+}
+
+// mergeRawProfiles merges the raw profiles in the directory into the
+// indexed profile at the specified path.
+func (cov *CoverageGenerator) mergeRawProfiles(ctx context.Context, dir, indexedProfilePath string) error {
+	rawProfileFiles, err := cov.rawProfileFiles(dir)
 	if err != nil {
 		return err
 	}
 	if len(rawProfileFiles) == 0 {
 		// The rawProfilePattern parameter only governs whether we add "%c",
 		// which doesn't affect the actual raw profile location.
-		return errors.Errorf("%s did not generate .profraw files at %s", cov.coverageBinary, cov.rawProfilePattern(false))
+		return errors.Errorf("%s did not generate .profraw files at %s", cov.coverageBinary, cov.rawProfilePattern(dir, false))
 	}
 
 	llvmProfData, err := cov.runfilesFinder.LLVMProfDataPath()
@@ -442,7 +609,7 @@ func (cov *CoverageGenerator) indexRawProfile(ctx context.Context) error {
 		return err
 	}
 
-	args := append([]string{"merge", "-sparse", "-o", cov.indexedProfilePath()}, rawProfileFiles...)
+	args := append([]string{"merge", "-sparse", "-o", indexedProfilePath}, rawProfileFiles...)
 	cmd := exec.CommandContext(ctx, llvmProfData, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -454,7 +621,7 @@ func (cov *CoverageGenerator) indexRawProfile(ctx context.Context) error {
 	return nil
 }
 
-func (cov *CoverageGenerator) rawProfilePattern(supportsContinuousMode bool) string {
+func (cov *CoverageGenerator) rawProfilePattern(dir string, supportsContinuousMode bool) string {
 	// Use "%m" instead of a fixed path to support coverage of shared
 	// libraries: Each executable or library generates its own profile
 	// file, all of which we have to merge in the end. By using "%m",
@@ -468,7 +635,7 @@ func (cov *CoverageGenerator) rawProfilePattern(supportsContinuousMode bool) str
 	if supportsContinuousMode {
 		basePattern = "%c" + basePattern
 	}
-	return filepath.Join(cov.outputDir, basePattern)
+	return filepath.Join(dir, basePattern)
 }
 
 func (cov *CoverageGenerator) generateHTMLReport(ctx context.Context) (string, error) {
@@ -482,6 +649,14 @@ func (cov *CoverageGenerator) generateHTMLReport(ctx context.Context) (string, e
 	if err != nil {
 		return "", err
 	}
+	report, err = cov.addUncoveredFiles(report)
+	if err != nil {
+		return "", err
+	}
+	report, err = cov.filterFunctions(report)
+	if err != nil {
+		return "", err
+	}
 	// Write lcov report to temp dir
 	reportDir, err := os.MkdirTemp("", "coverage-")
 	if err != nil {
@@ -536,6 +711,10 @@ func (cov *CoverageGenerator) generateHTMLReport(ctx context.Context) (string, e
 }
 
 func (cov *CoverageGenerator) runLlvmCov(ctx context.Context, args []string) (string, error) {
+	return cov.runLlvmCovWithProfile(ctx, args, cov.indexedProfilePath())
+}
+
+func (cov *CoverageGenerator) runLlvmCovWithProfile(ctx context.Context, args []string, indexedProfilePath string) (string, error) {
 	llvmCov, err := cov.runfilesFinder.LLVMCovPath()
 	if err != nil {
 		return "", err
@@ -543,7 +722,7 @@ func (cov *CoverageGenerator) runLlvmCov(ctx context.Context, args []string) (st
 
 	// Add all runtime dependencies of the fuzz test to the binaries
 	// processed by llvm-cov to include them in the coverage report
-	args = append(args, "-instr-profile="+cov.indexedProfilePath())
+	args = append(args, "-instr-profile="+indexedProfilePath)
 	args = append(args, cov.coverageBinary)
 	if archArg, err := cov.archFlagIfNeeded(cov.coverageBinary); err != nil {
 		return "", err
@@ -569,7 +748,8 @@ func (cov *CoverageGenerator) runLlvmCov(ctx context.Context, args []string) (st
 	return string(output), nil
 }
 
-func (cov *CoverageGenerator) generateLcovReport(ctx context.Context) (string, error) {
+// lcovReport exports the coverage as lcov trace data
+func (cov *CoverageGenerator) lcovReport(ctx context.Context) (string, error) {
 	args := []string{"export", "-format=lcov"}
 	ignoreCIFuzzIncludesArgs, err := cov.getIgnoreCIFuzzIncludesArgs()
 	if err != nil {
@@ -580,6 +760,29 @@ func (cov *CoverageGenerator) generateLcovReport(ctx context.Context) (string, e
 	if err != nil {
 		return "", err
 	}
+	report, err = cov.addUncoveredFiles(report)
+	if err != nil {
+		return "", err
+	}
+
+	report = coverage.AddTestName(report, cov.FuzzTest)
+	return cov.filterFunctions(report)
+}
+
+// filterFunctions restricts the lcov report to the functions matching
+// the function filter, if one was specified.
+func (cov *CoverageGenerator) filterFunctions(report string) (string, error) {
+	if cov.FunctionFilter == nil {
+		return report, nil
+	}
+	return coverage.FilterLCOVByFunctions(report, cov.FunctionFilter)
+}
+
+func (cov *CoverageGenerator) generateLcovReport(ctx context.Context) (string, error) {
+	report, err := cov.lcovReport(ctx)
+	if err != nil {
+		return "", err
+	}
 
 	outputPath := cov.OutputPath
 	if cov.OutputPath == "" {
@@ -600,6 +803,37 @@ func (cov *CoverageGenerator) generateLcovReport(ctx context.Context) (string, e
 	return outputPath, nil
 }
 
+// generateCoberturaReport converts the lcov trace data into a Cobertura
+// XML report, which groups the source files into packages by their
+// directory relative to the project directory.
+func (cov *CoverageGenerator) generateCoberturaReport(ctx context.Context) (string, error) {
+	report, err := cov.lcovReport(ctx)
+	if err != nil {
+		return "", err
+	}
+	lcovReport, err := coverage.ParseLCOVFileIntoLCOVReport(strings.NewReader(report))
+	if err != nil {
+		return "", err
+	}
+
+	outputPath := cov.OutputPath
+	if cov.OutputPath == "" {
+		// Like the lcov report, the report is created in the current
+		// working directory if no output path is specified
+		outputPath = cov.executableName() + ".coverage.cobertura.xml"
+	}
+
+	// Merging ensures that each source file is contained in a single
+	// section, as expected by the Cobertura conversion
+	err = coverage.MergeLCOVReports(lcovReport).WriteCoberturaReport(outputPath, cov.ProjectDir)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Created Cobertura report: %s", outputPath)
+	return outputPath, nil
+}
+
 func (cov *CoverageGenerator) lcovReportSummary(ctx context.Context) (string, error) {
 	args := []string{"export", "-format=lcov", "-summary-only"}
 	ignoreCIFuzzIncludesArgs, err := cov.getIgnoreCIFuzzIncludesArgs()
@@ -612,7 +846,176 @@ func (cov *CoverageGenerator) lcovReportSummary(ctx context.Context) (string, er
 		return "", err
 	}
 
-	return output, nil
+	return cov.addUncoveredFiles(output)
+}
+
+// addUncoveredFiles adds the C/C++ source files which are not contained
+// in the lcov report, because none of their code was executed, to the
+// report with zero coverage. Only the directories in the project
+// directory which contain source files of the report are searched, so
+// that sources which are not part of the fuzz test, like those of other
+// targets or of the build directory, are not added.
+func (cov *CoverageGenerator) addUncoveredFiles(report string) (string, error) {
+	if !cov.IncludeUncoveredFiles {
+		return report, nil
+	}
+
+	var sourceDirs []string
+	for _, sourceFile := range coverage.SourceFilesInReport(report) {
+		dir := filepath.Dir(sourceFile)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cov.ProjectDir, dir)
+		}
+		isBelow, err := fileutil.IsBelow(dir, cov.ProjectDir)
+		if err != nil {
+			return "", err
+		}
+		if isBelow && !stringutil.Contains(sourceDirs, dir) {
+			sourceDirs = append(sourceDirs, dir)
+		}
+	}
+
+	var sourceFiles []string
+	for _, dir := range sourceDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && stringutil.Contains(sourceFileExtensions, filepath.Ext(entry.Name())) {
+				sourceFiles = append(sourceFiles, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	return coverage.AddUncoveredFiles(report, sourceFiles)
+}
+
+// printFunctionCoverage prints the coverage of the functions matching
+// the function filter and computes the summary of their coverage. The
+// summary-only export doesn't include function records, so this needs
+// the full lcov export.
+func (cov *CoverageGenerator) printFunctionCoverage(ctx context.Context) error {
+	args := []string{"export", "-format=lcov"}
+	ignoreCIFuzzIncludesArgs, err := cov.getIgnoreCIFuzzIncludesArgs()
+	if err != nil {
+		return err
+	}
+	args = append(args, ignoreCIFuzzIncludesArgs...)
+	output, err := cov.runLlvmCov(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	lcovReport, err := coverage.ParseLCOVFileIntoLCOVReport(strings.NewReader(output))
+	if err != nil {
+		return err
+	}
+	coverage.PrintFunctionTable(lcovReport.FilterFunctions(cov.FunctionFilter), cov.FunctionFilter, cov.Stderr)
+	cov.summary = lcovReport.FilterByFunctions(cov.FunctionFilter).Summary()
+	return nil
+}
+
+// perInputCoverage runs each input of the corpus individually and writes
+// the lines which are covered by only that input to PerInputOutputPath.
+// Inputs which don't cover any lines uniquely are redundant.
+func (cov *CoverageGenerator) perInputCoverage(ctx context.Context) error {
+	inputs, err := cov.corpusInputs()
+	if err != nil {
+		return err
+	}
+	log.Infof("Running %d corpus inputs individually, this may take a while", len(inputs))
+
+	ignoreCIFuzzIncludesArgs, err := cov.getIgnoreCIFuzzIncludesArgs()
+	if err != nil {
+		return err
+	}
+	artifactsDir := filepath.Join(cov.outputDir, "per-input-artifacts")
+	err = os.Mkdir(artifactsDir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	conModeSupport := binary.SupportsLlvmProfileContinuousMode(cov.coverageBinary)
+	var names []string
+	var reports []*coverage.LCOVReport
+	for i, input := range inputs {
+		profileDir := filepath.Join(cov.outputDir, "per-input", strconv.Itoa(i))
+		err = os.MkdirAll(profileDir, 0o755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		env, err := cov.fuzzerEnv(cov.rawProfilePattern(profileDir, conModeSupport))
+		if err != nil {
+			return err
+		}
+
+		// Inputs which crash the fuzz test still produce coverage,
+		// like in the merge run of the whole corpus
+		err = cov.runFuzzer(ctx, []string{"-artifact_prefix=" + artifactsDir + "/"}, []string{input}, env)
+		if err != nil {
+			log.Debugf("Running input %s failed: %v", input, err)
+		}
+
+		indexedProfilePath := filepath.Join(profileDir, "input.profdata")
+		err = cov.mergeRawProfiles(ctx, profileDir, indexedProfilePath)
+		if err != nil {
+			return err
+		}
+		args := append([]string{"export", "-format=lcov"}, ignoreCIFuzzIncludesArgs...)
+		output, err := cov.runLlvmCovWithProfile(ctx, args, indexedProfilePath)
+		if err != nil {
+			return err
+		}
+		report, err := coverage.ParseLCOVFileIntoLCOVReport(strings.NewReader(output))
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+		names = append(names, fileutil.PrettifyPath(input))
+
+		// The profiles of large projects take up a lot of space, so
+		// they are removed as soon as they are no longer needed
+		fileutil.Cleanup(profileDir)
+	}
+
+	inputCoverage := coverage.PerInputCoverage(names, reports)
+	err = coverage.WritePerInputCoverage(cov.PerInputOutputPath, inputCoverage)
+	if err != nil {
+		return err
+	}
+
+	var numRedundant int
+	for _, c := range inputCoverage {
+		if c.Redundant() {
+			numRedundant++
+		}
+	}
+	log.Infof("%d of %d inputs don't cover any lines which aren't covered by other inputs", numRedundant, len(inputs))
+	log.Successf("Created per-input coverage report: %s", cov.PerInputOutputPath)
+	return nil
+}
+
+// corpusInputs returns the inputs in the corpus directories, in the
+// same order in each run.
+func (cov *CoverageGenerator) corpusInputs() ([]string, error) {
+	var inputs []string
+	for _, dir := range cov.CorpusDirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				inputs = append(inputs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	sort.Strings(inputs)
+	return sliceutil.RemoveDuplicates(inputs), nil
 }
 
 func (cov *CoverageGenerator) getIgnoreCIFuzzIncludesArgs() ([]string, error) {
@@ -623,8 +1026,8 @@ func (cov *CoverageGenerator) getIgnoreCIFuzzIncludesArgs() ([]string, error) {
 	return []string{"-ignore-filename-regex=" + regexp.QuoteMeta(cifuzzIncludePath) + "/.*"}, nil
 }
 
-func (cov *CoverageGenerator) rawProfileFiles() ([]string, error) {
-	files, err := filepath.Glob(filepath.Join(cov.outputDir, "*.profraw"))
+func (cov *CoverageGenerator) rawProfileFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.profraw"))
 	return files, errors.WithStack(err)
 }
 
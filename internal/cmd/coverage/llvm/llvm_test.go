@@ -101,3 +101,30 @@ func TestIntegration_LLVM(t *testing.T) {
 		})
 	}
 }
+
+func TestAddUncoveredFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	srcDir := filepath.Join(projectDir, "src")
+	otherDir := filepath.Join(projectDir, "other")
+	for _, path := range []string{
+		filepath.Join(srcDir, "covered.cpp"),
+		filepath.Join(srcDir, "uncovered.cpp"),
+		filepath.Join(srcDir, "README.md"),
+		filepath.Join(otherDir, "other.cpp"),
+	} {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte("int foo() {\n  return 1;\n}\n"), 0o644)
+		require.NoError(t, err)
+	}
+
+	cov := &CoverageGenerator{ProjectDir: projectDir, IncludeUncoveredFiles: true}
+	report := "SF:" + filepath.Join(srcDir, "covered.cpp") + "\nDA:1,3\nLF:1\nLH:1\nend_of_record\n"
+	actualReport, err := cov.addUncoveredFiles(report)
+	require.NoError(t, err)
+
+	// Only the directory of the covered source file is searched
+	expectedReport := report +
+		"SF:" + filepath.Join(srcDir, "uncovered.cpp") + "\nDA:1,0\nDA:2,0\nLF:2\nLH:0\nend_of_record\n"
+	assert.Equal(t, expectedReport, actualReport)
+}
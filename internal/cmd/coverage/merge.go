@@ -0,0 +1,73 @@
+package coverage
+
+import (
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/coverage"
+)
+
+func newMergeCmd() *cobra.Command {
+	opts := &coverageOptions{}
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "merge [flags] <lcov report>...",
+		Short: "Merge existing lcov reports into a single report",
+		Long: `This command merges existing lcov reports into a single report, e.g.
+to combine the reports of fuzz tests which were run on different CI
+machines. The execution counts of the same lines are summed up and the
+functions and branches of all reports are combined. If the reports
+disagree on the lines of a file, e.g. because they were created from
+different revisions, the merged report contains all lines seen in any
+report.
+
+The merged report can be written in the formats 'lcov', 'cobertura',
+'sonarqube', 'junit' and 'sarif'. This command doesn't require a cifuzz
+project.`,
+		Example: "cifuzz coverage merge --output coverage.lcov shard1.lcov shard2.lcov",
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Bind viper keys to flags. We can't do this in the
+			// newMergeCmd function, because that would re-bind viper
+			// keys which were bound to the flags of other commands
+			// before.
+			bindFlags()
+			cmdutils.ViperMustBindPFlag("format", cmd.Flags().Lookup("format"))
+			cmdutils.ViperMustBindPFlag("output", cmd.Flags().Lookup("output"))
+
+			err := parseMergeConfig(opts)
+			if err != nil {
+				return err
+			}
+			opts.Merge = true
+			opts.Inputs = args
+			// The format configured in cifuzz.yaml is meant for the
+			// coverage of fuzz tests and might not be supported for
+			// merged reports, so only the flag is respected
+			if !cmd.Flags().Changed("format") {
+				opts.OutputFormat = coverage.FormatLCOV
+			}
+			return opts.validateMerge()
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := coverageCmd{Command: c, opts: opts}
+			return cmd.runMergeInputs()
+		},
+	}
+
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddBadgeFlag,
+		cmdutils.AddBadgeLabelFlag,
+		cmdutils.AddCoveredFilesOutFlag,
+		cmdutils.AddFailUnderFlag,
+		cmdutils.AddFailUnderFileFlag,
+		cmdutils.AddProjectDirFlag,
+		cmdutils.AddStripPathsFlag,
+	)
+	cmd.Flags().StringP("format", "f", coverage.FormatLCOV,
+		"Output format of the merged report (lcov/cobertura/sonarqube/junit/sarif).")
+	cmd.Flags().StringP("output", "o", "", "Output path of the merged report.")
+
+	return cmd
+}
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"syscall"
 
@@ -26,10 +27,20 @@ type CoverageGenerator struct {
 	TestPathPattern string
 	TestNamePattern string
 	ProjectDir      string
+	FunctionFilter  *regexp.Regexp
 
 	Stderr      io.Writer
 	BuildStdout io.Writer
 	BuildStderr io.Writer
+
+	summary *parser.Summary
+}
+
+// Summary returns the coverage summary of the lcov report written by
+// Jest, restricted to the matching functions if a function filter was
+// specified. It is nil until GenerateCoverageReport was called.
+func (cov *CoverageGenerator) Summary() *parser.Summary {
+	return cov.summary
 }
 
 func (cov *CoverageGenerator) BuildFuzzTestForCoverage() error {
@@ -65,18 +76,42 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 		return "", err
 	}
 
-	// generate the summary table
 	reportPath := filepath.Join(cov.OutputPath, "lcov.info")
+	if cov.OutputFormat == coverage.FormatLCOV {
+		err = cov.addTestNameToReport(reportPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// generate the summary table
 	reportFile, err := os.Open(reportPath)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
 	defer reportFile.Close()
-	summary, err := parser.ParseLCOVReportIntoSummary(reportFile)
-	if err != nil {
-		return "", err
+	if cov.FunctionFilter != nil {
+		lcovReport, err := parser.ParseLCOVFileIntoLCOVReport(reportFile)
+		if err != nil {
+			return "", err
+		}
+		parser.PrintFunctionTable(lcovReport.FilterFunctions(cov.FunctionFilter), cov.FunctionFilter, cov.Stderr)
+		// The lcov report and the summary only contain the coverage
+		// of the matching functions
+		filtered := lcovReport.FilterByFunctions(cov.FunctionFilter)
+		cov.summary = filtered.Summary()
+		err = os.WriteFile(reportPath, []byte(filtered.String()), 0o644)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	} else {
+		summary, err := parser.ParseLCOVReportIntoSummary(reportFile)
+		if err != nil {
+			return "", err
+		}
+		summary.PrintTable(cov.Stderr)
+		cov.summary = summary
 	}
-	summary.PrintTable(cov.Stderr)
 
 	// the index.html file is located in the subfolder lcov-report
 	if cov.OutputFormat == "html" {
@@ -86,6 +121,18 @@ func (cov *CoverageGenerator) GenerateCoverageReport() (string, error) {
 	return reportPath, nil
 }
 
+// addTestNameToReport adds the fuzz test as test name to the lcov report
+// created by jest.
+func (cov *CoverageGenerator) addTestNameToReport(reportPath string) error {
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	report := parser.AddTestName(string(content), cov.TestPathPattern)
+	err = os.WriteFile(reportPath, []byte(report), 0o644)
+	return errors.WithStack(err)
+}
+
 func (cov *CoverageGenerator) validateFuzzTest() error {
 	// list all fuzz tests with the specified path and name patterns
 	args := []string{"jest", "--listTests"}
@@ -4,9 +4,11 @@ package execute
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -26,6 +28,7 @@ import (
 	"code-intelligence.com/cifuzz/internal/coverage"
 	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/afl"
 	"code-intelligence.com/cifuzz/pkg/runner/jazzer"
 	"code-intelligence.com/cifuzz/pkg/runner/libfuzzer"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -37,7 +40,10 @@ type executeOpts struct {
 	SingleFuzzTest      bool   `mapstructure:"single-fuzz-test"`
 	PrintBundleMetadata bool   `mapstructure:"print-bundle-metadata"`
 	JSONOutputFilePath  string `mapstructure:"json-output-file"`
+	JSONFlush           bool   `mapstructure:"json-flush"`
+	JSONLines           bool   `mapstructure:"json-lines"`
 	GeneratedCorpusDir  string `mapstructure:"generated-corpus-dir"`
+	ManagedCorpusDir    string `mapstructure:"managed-corpus-dir"`
 	CoverageOutputPath  string `mapstructure:"coverage-output-path"`
 
 	name string
@@ -59,6 +65,11 @@ func New() *cobra.Command {
 It can be used as an experimental alternative to cifuzz_runner.
 It is currently only intended for use with the 'cifuzz container' subcommand.
 
+It must be run in a folder with an unpacked bundle. If the folder
+instead contains a bundle which was split via 'cifuzz bundle --split-size',
+i.e. its <bundle>.parts.json manifest and its parts, the bundle is
+reassembled and unpacked into the folder first.
+
 `,
 		Example: "cifuzz execute [fuzz test]",
 		Args:    cobra.MaximumNArgs(1),
@@ -72,13 +83,18 @@ It is currently only intended for use with the 'cifuzz container' subcommand.
 			cmdutils.ViperMustBindPFlag("coverage-output-path", cmd.Flags().Lookup("coverage-output-path"))
 			cmdutils.ViperMustBindPFlag("stop-signal-file", cmd.Flags().Lookup("stop-signal-file"))
 			cmdutils.ViperMustBindPFlag("json-output-file", cmd.Flags().Lookup("json-output-file"))
+			cmdutils.ViperMustBindPFlag("json-lines", cmd.Flags().Lookup("json-lines"))
 			cmdutils.ViperMustBindPFlag("generated-corpus-dir", cmd.Flags().Lookup("generated-corpus-dir"))
+			cmdutils.ViperMustBindPFlag("managed-corpus-dir", cmd.Flags().Lookup("managed-corpus-dir"))
 			opts.SingleFuzzTest = viper.GetBool("single-fuzz-test")
 			opts.PrintBundleMetadata = viper.GetBool("print-bundle-metadata")
 			opts.CoverageOutputPath = viper.GetString("coverage-output-path")
 			opts.PrintJSON = viper.GetBool("print-json")
 			opts.JSONOutputFilePath = viper.GetString("json-output-file")
+			opts.JSONFlush = viper.GetBool("json-flush")
+			opts.JSONLines = viper.GetBool("json-lines")
 			opts.GeneratedCorpusDir = viper.GetString("generated-corpus-dir")
+			opts.ManagedCorpusDir = viper.GetString("managed-corpus-dir")
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			if signalFile := viper.GetString("stop-signal-file"); signalFile != "" {
@@ -121,12 +137,15 @@ It is currently only intended for use with the 'cifuzz container' subcommand.
 	cmd.Flags().String("coverage-output-path", "", "Produce an LCOV coverage report at the specified path after running the fuzz test.")
 	cmd.Flags().String("stop-signal-file", "", "CI Fuzz will create a file 'cifuzz-execution-finished' upon exit")
 	cmd.Flags().String("json-output-file", "", "Print output as JSON to the specified file (implies --json)")
+	cmd.Flags().Bool("json-lines", false, "Print each report as compact JSON on a single line, so that the output can be parsed line by line (implies --json)")
 	cmd.Flags().String("generated-corpus-dir", "/tmp/generated-corpus", "The directory where inputs which increased the coverage are stored. The user running the container must have write access to this directory.")
+	cmd.Flags().String("managed-corpus-dir", container.ManagedSeedCorpusDir, "The directory where crashing inputs are stored. The user running the container must have write access to this directory.")
 
 	// Note: If a flag should be configurable via viper as well (i.e.
 	//       via cifuzz.yaml and CIFUZZ_* environment variables), bind
 	//       it to viper in the PreRun function.
 	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddJSONFlushFlag,
 		cmdutils.AddPrintJSONFlag,
 	)
 
@@ -136,6 +155,11 @@ It is currently only intended for use with the 'cifuzz container' subcommand.
 func (c *executeCmd) run(metadata *archive.Metadata) error {
 	var jsonOutput, printerOutput io.Writer
 
+	// --json-lines implies --json
+	if c.opts.JSONLines {
+		c.opts.PrintJSON = true
+	}
+
 	// Set the output streams depending on the flags.
 	if c.opts.JSONOutputFilePath != "" {
 		// --json-output-file implies --json
@@ -167,7 +191,7 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		} else {
 			metadataOutput = os.Stdout
 		}
-		err := printMetadata(metadata, metadataOutput)
+		err := printMetadata(metadata, metadataOutput, c.opts.JSONLines)
 		if err != nil {
 			return err
 		}
@@ -178,7 +202,7 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		return err
 	}
 
-	err = os.MkdirAll(container.ManagedSeedCorpusDir, 0o755)
+	err = os.MkdirAll(c.opts.ManagedCorpusDir, 0o755)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -191,7 +215,7 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		getFuzzerName(fuzzer),
 		&reporthandler.ReportHandlerOptions{
 			ProjectDir:           fuzzer.ProjectDir,
-			ManagedSeedCorpusDir: container.ManagedSeedCorpusDir,
+			ManagedSeedCorpusDir: c.opts.ManagedCorpusDir,
 			// Saving findings is currently broken when the container is run
 			// as a non-root user and the build system is bazel. This is a
 			// quick workaround to avoid breaking the container when it's run
@@ -199,6 +223,8 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 			SkipSavingFinding: true,
 			PrinterOutput:     printerOutput,
 			JSONOutput:        jsonOutput,
+			SyncJSONOutput:    c.opts.JSONFlush,
+			JSONLines:         c.opts.JSONLines,
 		})
 	if err != nil {
 		return err
@@ -301,7 +327,27 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 			runnerOpts.SeedCorpusDirs = append(runnerOpts.SeedCorpusDirs, seedCorpusDir)
 		}
 
-		runner = libfuzzer.NewRunner(runnerOpts)
+		if fuzzer.Engine == "AFL" {
+			engineArgs := runnerOpts.EngineArgs
+			if runnerOpts.Dictionary != "" {
+				engineArgs = append([]string{"-x", runnerOpts.Dictionary}, engineArgs...)
+			}
+			runner = afl.NewRunner(&afl.RunnerOptions{
+				EngineArgs:         engineArgs,
+				EnvVars:            runnerOpts.EnvVars,
+				FuzzTarget:         runnerOpts.FuzzTarget,
+				GeneratedCorpusDir: runnerOpts.GeneratedCorpusDir,
+				KeepColor:          runnerOpts.KeepColor,
+				LibraryDirs:        runnerOpts.LibraryDirs,
+				ProjectDir:         runnerOpts.ProjectDir,
+				ReportHandler:      reportHandler,
+				SeedCorpusDirs:     runnerOpts.SeedCorpusDirs,
+				Timeout:            runnerOpts.Timeout,
+				Verbose:            runnerOpts.Verbose,
+			})
+		} else {
+			runner = libfuzzer.NewRunner(runnerOpts)
+		}
 	}
 
 	err = adapter.ExecuteFuzzerRunner(runner)
@@ -353,7 +399,7 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 		if err != nil {
 			return err
 		}
-		seedCorpusDirs := append(runnerOpts.SeedCorpusDirs, runnerOpts.GeneratedCorpusDir, container.ManagedSeedCorpusDir)
+		seedCorpusDirs := append(runnerOpts.SeedCorpusDirs, runnerOpts.GeneratedCorpusDir, c.opts.ManagedCorpusDir)
 		gen := &llvmCoverage.CoverageGenerator{
 			OutputFormat: coverage.FormatLCOV,
 			CorpusDirs:   seedCorpusDirs,
@@ -365,11 +411,19 @@ func (c *executeCmd) run(metadata *archive.Metadata) error {
 }
 
 // getMetadata returns the bundle metadata from the bundle.yaml file.
+// If the working directory contains a split bundle instead of an
+// unpacked bundle, the split bundle is extracted first.
 func getMetadata() (*archive.Metadata, error) {
 	exists, err := fileutil.Exists(archive.MetadataFileName)
 	if err != nil {
 		return nil, err
 	}
+	if !exists {
+		exists, err = extractSplitBundle(".")
+		if err != nil {
+			return nil, err
+		}
+	}
 	if !exists {
 		return nil, errors.Errorf("bundle metadata file '%s' does not exist. Execute command should be run in a folder with an unpacked cifuzz bundle.", archive.MetadataFileName)
 	}
@@ -388,10 +442,44 @@ func getMetadata() (*archive.Metadata, error) {
 	return metadata, nil
 }
 
-func printMetadata(metadata *archive.Metadata, output io.Writer) error {
-	metadataJSON, err := stringutil.ToJSONString(metadata)
+// extractSplitBundle reassembles and extracts the split bundle in dir,
+// if dir contains the manifest of exactly one split bundle. It returns
+// true if a bundle was extracted.
+func extractSplitBundle(dir string) (bool, error) {
+	manifests, err := filepath.Glob(filepath.Join(dir, "*"+archive.SplitManifestSuffix))
 	if err != nil {
-		return err
+		return false, errors.WithStack(err)
+	}
+	if len(manifests) == 0 {
+		return false, nil
+	}
+	if len(manifests) > 1 {
+		return false, errors.Errorf("Found the manifests of multiple split bundles: %s. Execute command should be run in a folder with a single split bundle.",
+			strings.Join(manifests, ", "))
+	}
+
+	log.Infof("Extracting split bundle %s", manifests[0])
+	err = archive.Extract(manifests[0], dir)
+	if err != nil {
+		return false, errors.WithMessagef(err, "Failed to extract split bundle %s", manifests[0])
+	}
+	return true, nil
+}
+
+func printMetadata(metadata *archive.Metadata, output io.Writer, compact bool) error {
+	var metadataJSON string
+	var err error
+	if compact {
+		bytes, err := json.Marshal(metadata)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		metadataJSON = string(bytes)
+	} else {
+		metadataJSON, err = stringutil.ToJSONString(metadata)
+		if err != nil {
+			return err
+		}
 	}
 	_, err = fmt.Fprintln(output, metadataJSON)
 	if err != nil {
@@ -409,6 +497,9 @@ func printNotice(metadata *archive.Metadata) error {
 
 	fmt.Println("")
 	fmt.Printf("This container is based on: %s\n", metadata.RunEnvironment.Docker)
+	if note := strings.TrimSpace(metadata.Note); note != "" {
+		fmt.Printf("Note:\n%s\n", note)
+	}
 	fmt.Println("")
 
 	fmt.Printf("Available fuzzers:\n")
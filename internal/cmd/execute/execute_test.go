@@ -3,6 +3,7 @@
 package execute
 
 import (
+	"crypto/rand"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -203,3 +204,37 @@ func TestStopSignalFile(t *testing.T) {
 	cmdutils.ExecuteCommand(t, New(), os.Stdin, "my_fuzz_test", "--stop-signal-file=test")
 	assert.FileExists(t, filepath.Join(dir, "test"), "--stop-signal-file flag did not create the file 'cifuzz-execution-finished'on exit")
 }
+
+func TestExtractSplitBundle(t *testing.T) {
+	bundleDir := t.TempDir()
+
+	// No split bundle
+	extracted, err := extractSplitBundle(bundleDir)
+	require.NoError(t, err)
+	assert.False(t, extracted)
+
+	metadataFile := filepath.Join(t.TempDir(), archive.MetadataFileName)
+	err = os.WriteFile(metadataFile, []byte("fuzzers: []\n"), 0o644)
+	require.NoError(t, err)
+	// Random data can't be compressed, so it doesn't fit into the same
+	// part as the metadata
+	dataFile := filepath.Join(t.TempDir(), "data.bin")
+	data := make([]byte, 1000)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+	err = os.WriteFile(dataFile, data, 0o644)
+	require.NoError(t, err)
+
+	w, err := archive.NewSplitTarArchiveWriter(filepath.Join(bundleDir, "fuzz_tests.tar.gz"), archive.CompressionGzip, 2048)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteFile(archive.MetadataFileName, metadataFile))
+	require.NoError(t, w.WriteFile("data.bin", dataFile))
+	require.NoError(t, w.WriteFile("data2.bin", dataFile))
+	require.NoError(t, w.Close())
+	require.True(t, w.IsSplit())
+
+	extracted, err = extractSplitBundle(bundleDir)
+	require.NoError(t, err)
+	assert.True(t, extracted)
+	assert.FileExists(t, filepath.Join(bundleDir, archive.MetadataFileName))
+}
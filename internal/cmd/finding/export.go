@@ -0,0 +1,90 @@
+package finding
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+type exportOptions struct {
+	ProjectDir string `mapstructure:"project-dir"`
+	ConfigDir  string `mapstructure:"config-dir"`
+	OutputPath string
+}
+
+func newExportCmd(opts *exportOptions) *cobra.Command {
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all local findings to a single archive",
+		Long: `This command packs all local findings of the project into a
+single gzip-compressed tar archive, which can be imported into another
+checkout of the project via 'cifuzz finding import'.`,
+		Example: "cifuzz finding export --output findings.tar.gz",
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			bindFlags()
+			err := config.FindAndParseProjectConfig(opts)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			count, err := exportFindings(opts.ProjectDir, opts.OutputPath)
+			if err != nil {
+				return err
+			}
+			log.Successf("Exported %d finding(s) to %s", count, opts.OutputPath)
+			return nil
+		},
+	}
+
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddProjectDirFlag,
+	)
+	cmd.Flags().StringVarP(&opts.OutputPath, "output", "o", "findings.tar.gz", "Output path of the archive (.tar.gz)")
+
+	return cmd
+}
+
+// exportFindings writes all local findings of the project to a
+// gzip-compressed tar archive at outputPath. If that fails, the
+// partially written archive is removed.
+func exportFindings(projectDir, outputPath string) (int, error) {
+	archiveFile, err := os.Create(outputPath)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	writer := archive.NewTarArchiveWriter(archiveFile, true)
+	removeArchive := func() {
+		_ = writer.Close()
+		_ = archiveFile.Close()
+		_ = os.Remove(outputPath)
+	}
+
+	count, err := finding.Export(projectDir, writer)
+	if err != nil {
+		removeArchive()
+		return 0, err
+	}
+	err = writer.Close()
+	if err != nil {
+		removeArchive()
+		return 0, err
+	}
+	err = archiveFile.Close()
+	if err != nil {
+		removeArchive()
+		return 0, errors.WithStack(err)
+	}
+	return count, nil
+}
@@ -3,6 +3,8 @@ package finding
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -22,16 +24,48 @@ import (
 	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+	"code-intelligence.com/cifuzz/pkg/vcs"
+	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
 
+// The keys by which the listed findings can be sorted via --sort
+const (
+	sortByDate     = "date"
+	sortByName     = "name"
+	sortBySeverity = "severity"
+)
+
+// The formats in which the listed findings can be written via --format
+const formatJUnit = "junit"
+
 type options struct {
 	PrintJSON   bool   `mapstructure:"print-json"`
 	ProjectDir  string `mapstructure:"project-dir"`
 	ConfigDir   string `mapstructure:"config-dir"`
 	Interactive bool   `mapstructure:"interactive"`
 	Server      string `mapstructure:"server"`
+	Proxy       string `mapstructure:"proxy"`
 	Project     string `mapstructure:"project"`
+	BuildSystem string `mapstructure:"build-system"`
+
+	RefreshErrorDetails bool              `mapstructure:"refresh-error-details"`
+	SeverityOverrides   map[string]string `mapstructure:"severity-overrides"`
+
+	EmitJUnitSeed   bool
+	FindingsDir     string
+	MinSeverity     string
+	IncludeUnscored bool
+	Sort            string
+	Reverse         bool
+	ScanDirs        []string
+	HTMLPath        string
+	Format          string
+	OutputPath      string
+	GitBlame        bool
+
+	severityOverrides map[string]*finding.Severity
+	minSeverity       finding.SeverityLevel
 }
 
 type findingCmd struct {
@@ -57,11 +91,24 @@ func newWithOptions(opts *options) *cobra.Command {
 			// function, because that would re-bind viper keys which
 			// were bound to the flags of other commands before.
 			bindFlags()
-			err := config.FindAndParseProjectConfig(opts)
+			// When scanning for projects, the findings of multiple
+			// projects are listed, so we don't need a project config
+			if len(opts.ScanDirs) > 0 {
+				err := viper.Unmarshal(opts)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+			} else {
+				err := config.FindAndParseProjectConfig(opts)
+				if err != nil {
+					return err
+				}
+			}
+			err := api.ValidateProxyURL(opts.Proxy)
 			if err != nil {
-				return err
+				return cmdutils.WrapIncorrectUsageError(err)
 			}
-			return nil
+			return opts.validateFormat(args)
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			opts.Interactive = viper.GetBool("interactive")
@@ -72,7 +119,77 @@ func newWithOptions(opts *options) *cobra.Command {
 			}
 			opts.Server = viper.GetString("server")
 
+			if opts.EmitJUnitSeed {
+				if len(args) != 1 {
+					err := errors.New("Flag 'emit-junit-seed' requires a finding name")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+				if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+					err := errors.New("Flag 'emit-junit-seed' is only supported for Maven and Gradle projects")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			}
+
+			if opts.HTMLPath != "" {
+				if len(args) != 1 {
+					err := errors.New("Flag 'html' requires a finding name")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+				if opts.EmitJUnitSeed {
+					err := errors.New("Flags 'html' and 'emit-junit-seed' can't be used together")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			}
+
+			if opts.GitBlame && len(args) != 1 {
+				err := errors.New("Flag 'git-blame' requires a finding name")
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+
 			var err error
+			if opts.MinSeverity != "" {
+				if len(args) != 0 {
+					err := errors.New("Flag 'min-severity' can only be used when listing findings")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+				opts.minSeverity, err = finding.ParseSeverityLevel(opts.MinSeverity)
+				if err != nil {
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			} else if opts.IncludeUnscored {
+				err := errors.New("Flag 'include-unscored' requires 'min-severity'")
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+
+			if len(opts.ScanDirs) > 0 {
+				if len(args) != 0 {
+					err := errors.New("Flag 'scan' can only be used when listing findings")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+				if opts.FindingsDir != "" {
+					err := errors.New("Flags 'scan' and 'findings-dir' can't be used together")
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			}
+
+			if !stringutil.Contains([]string{sortByDate, sortByName, sortBySeverity}, opts.Sort) {
+				msg := fmt.Sprintf("invalid argument %q for \"--sort\" flag: must be %q, %q or %q",
+					opts.Sort, sortByDate, sortByName, sortBySeverity)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+
+			if opts.FindingsDir != "" {
+				opts.FindingsDir, err = finding.ValidateFindingsDir(opts.FindingsDir)
+				if err != nil {
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			}
+
+			opts.severityOverrides, err = finding.ParseSeverityOverrides(opts.SeverityOverrides)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+
 			opts.Server, err = api.ValidateAndNormalizeServerURL(opts.Server)
 			if err != nil {
 				return err
@@ -91,24 +208,104 @@ func newWithOptions(opts *options) *cobra.Command {
 		cmdutils.AddInteractiveFlag,
 		cmdutils.AddServerFlag,
 		cmdutils.AddProjectFlag,
+		cmdutils.AddProxyFlag,
+		cmdutils.AddRefreshErrorDetailsFlag,
 	)
+	cmd.Flags().BoolVar(&opts.EmitJUnitSeed, "emit-junit-seed", false,
+		"Write the crashing input of the finding to the inputs directory of the fuzz test\n"+
+			"(src/test/resources/.../<fuzz test>Inputs), so that it is used as a regression\n"+
+			"test input by JUnit. Only supported for Maven and Gradle projects.")
+	addFindingsDirFlag(cmd, &opts.FindingsDir)
+	cmd.Flags().StringVar(&opts.HTMLPath, "html", "",
+		"Write a self-contained HTML report of the finding to the specified `file`,\n"+
+			"which contains its description, severity, stack trace, more extensive\n"+
+			"error details and its crashing input.")
+	cmd.Flags().BoolVar(&opts.GitBlame, "git-blame", false,
+		"Show the commit which last changed the line of the source code at which the\n"+
+			"finding occurred, via git blame. This helps to route the finding to the\n"+
+			"right owner.")
+	cmd.Flags().StringVar(&opts.Format, "format", "",
+		"Write the listed findings in the specified `format` instead of printing them.\n"+
+			"Supported formats: junit, which writes a JUnit XML report with a failed test\n"+
+			"case for each finding, grouped by fuzz test.")
+	cmd.Flags().StringVarP(&opts.OutputPath, "output", "o", "",
+		"Output `path` of the report written via --format (default \"findings-junit.xml\").")
+	cmd.Flags().StringVar(&opts.MinSeverity, "min-severity", "",
+		"Only list findings with a severity of at least the specified `level`\n"+
+			"(low, medium, high or critical). Findings without a severity are\n"+
+			"not listed, unless --include-unscored is specified.")
+	cmd.Flags().BoolVar(&opts.IncludeUnscored, "include-unscored", false,
+		"List findings without a severity when --min-severity is specified.")
+	cmd.Flags().StringVar(&opts.Sort, "sort", sortByDate,
+		"Sort the listed findings by the specified `key`: \"date\" (newest first),\n"+
+			"\"name\" or \"severity\" (highest first, findings without a severity last).")
+	cmd.Flags().BoolVar(&opts.Reverse, "reverse", false,
+		"Reverse the order of the listed findings.")
+	cmd.Flags().StringArrayVar(&opts.ScanDirs, "scan", nil,
+		"List the local findings of all cifuzz projects below the specified `directory`,\n"+
+			"annotated with the project they belong to. This flag can be used multiple times.")
+
+	cmd.AddCommand(newExportCmd(&exportOptions{}))
+	cmd.AddCommand(newImportCmd(&importOptions{}))
+	cmd.AddCommand(newPruneCmd(&pruneOptions{}))
+	cmd.AddCommand(newTriageCmd(&triageOptions{}))
 
 	return cmd
 }
 
+// addFindingsDirFlag adds the --findings-dir flag, which is shared by
+// the finding command and its triage subcommand.
+func addFindingsDirFlag(cmd *cobra.Command, findingsDir *string) {
+	cmd.Flags().StringVar(findingsDir, "findings-dir", "",
+		"Read the local findings from the specified `directory` instead of the\n"+
+			".cifuzz-findings directory of the project, e.g. findings downloaded\n"+
+			"as an artifact of a CI job.")
+}
+
+func (opts *options) validateFormat(args []string) error {
+	if opts.Format == "" {
+		if opts.OutputPath != "" {
+			err := errors.New("Flag 'output' requires 'format'")
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+		return nil
+	}
+	if opts.Format != formatJUnit {
+		msg := fmt.Sprintf("invalid argument %q for \"--format\" flag: must be %q", opts.Format, formatJUnit)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if len(args) != 0 {
+		err := errors.New("Flag 'format' can only be used when listing findings")
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+	if opts.PrintJSON {
+		err := errors.New("Flags 'format' and 'json' can't be used together")
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+	if opts.OutputPath == "" {
+		opts.OutputPath = "findings-junit.xml"
+	}
+	return nil
+}
+
 func (cmd *findingCmd) run(args []string) error {
-	errorDetails, token, err := auth.TryGetErrorDetailsAndToken(cmd.opts.Server)
+	apiClient := api.NewClient(cmd.opts.Server)
+	apiClient.Proxy = cmd.opts.Proxy
+
+	errorDetails, token, err := auth.TryGetErrorDetailsAndToken(apiClient, cmd.opts.RefreshErrorDetails)
 	if err != nil {
 		return err
 	}
 
+	if len(cmd.opts.ScanDirs) > 0 {
+		return cmd.listScannedFindings(errorDetails)
+	}
+
 	var remoteAPIFindings api.Findings
 
 	if token != "" {
 		log.Info("Note that findings that have already been uploaded to CI Sense will only be shown if you are authenticated and 'project' is set.")
 
-		apiClient := api.NewClient(cmd.opts.Server)
-
 		// get remote findings if project is set and user is authenticated
 		if cmd.opts.Project != "" {
 			remoteAPIFindings, err = apiClient.DownloadRemoteFindings(cmd.opts.Project, token)
@@ -141,7 +338,7 @@ Skipping remote findings because running in non-interactive mode.`)
 		}
 	}
 
-	localFindings, err := finding.LocalFindings(cmd.opts.ProjectDir, errorDetails)
+	localFindings, err := cmd.localFindings(errorDetails)
 	if err != nil {
 		return err
 	}
@@ -185,6 +382,17 @@ Skipping remote findings because running in non-interactive mode.`)
 		// If called without arguments, `cifuzz findings` lists short
 		// descriptions of all findings
 		allFindings := append(localFindings, remoteFindings...)
+		for _, f := range allFindings {
+			f.ApplySeverityOverrides(cmd.opts.severityOverrides)
+		}
+		if cmd.opts.minSeverity != "" {
+			allFindings = cmd.filterBySeverity(allFindings)
+		}
+		sortFindings(allFindings, cmd.opts.Sort, cmd.opts.Reverse)
+
+		if cmd.opts.Format == formatJUnit {
+			return cmd.writeJUnitReport(allFindings)
+		}
 
 		if cmd.opts.PrintJSON {
 			s, err := stringutil.ToJSONString(allFindings)
@@ -196,42 +404,20 @@ Skipping remote findings because running in non-interactive mode.`)
 		}
 
 		if len(allFindings) == 0 {
+			if cmd.opts.minSeverity != "" {
+				log.Printf("This project doesn't have any findings with a severity of at least %s",
+					strings.ToLower(string(cmd.opts.minSeverity)))
+				return nil
+			}
 			log.Print("This project doesn't have any findings yet")
 			return nil
 		}
 
 		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 1, ' ', 0)
 
-		data := [][]string{
-			{"Origin", "Severity", "Name", "Description", "Fuzz Test", "Location"},
-		}
-
-		for _, f := range allFindings {
-			score := "n/a"
-			locationInfo := f.SourceLocation()
-			// check if MoreDetails exists to avoid nil pointer errors
-			if f.MoreDetails != nil {
-				// check if we have a severity and if we have a severity score
-				if f.MoreDetails.Severity != nil {
-					colorFunc := getColorFunctionForSeverity(f.MoreDetails.Severity.Score)
-					score = colorFunc(fmt.Sprintf("%.1f", f.MoreDetails.Severity.Score))
-				}
-			}
-			data = append(data, []string{
-				f.Origin,
-				score,
-				f.Name,
-				// FIXME: replace f.ShortDescriptionColumns()[0] with
-				// f.MoreDetails.Name once we cover all bugs with our
-				// error-details.json
-				f.ShortDescriptionColumns()[0],
-				f.FuzzTest,
-				locationInfo,
-			})
-		}
-		err = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+		err = printFindingsTable(allFindings)
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
 
 		err = w.Flush()
@@ -249,21 +435,312 @@ Skipping remote findings because running in non-interactive mode.`)
 	for i := range remoteFindings {
 		f := remoteFindings[i]
 		if strings.TrimPrefix(f.Name, fmt.Sprintf("projects/%s/findings/", cmd.opts.Project)) == findingName {
+			f.ApplySeverityOverrides(cmd.opts.severityOverrides)
+			if cmd.opts.EmitJUnitSeed {
+				return cmd.emitJUnitSeed(f)
+			}
+			if cmd.opts.HTMLPath != "" {
+				return cmd.writeHTMLReport(f)
+			}
 			return cmd.printFinding(f)
 		}
 	}
 
 	// ...if the finding is not a remote finding, check if it is a local finding
-	f, err := finding.LoadFinding(cmd.opts.ProjectDir, findingName, errorDetails)
+	f, err := cmd.loadFinding(findingName, errorDetails)
 	if finding.IsNotExistError(err) {
 		return errors.WithMessagef(err, "Finding %s does not exist", findingName)
 	}
 	if err != nil {
 		return err
 	}
+	f.ApplySeverityOverrides(cmd.opts.severityOverrides)
+	if cmd.opts.EmitJUnitSeed {
+		return cmd.emitJUnitSeed(f)
+	}
+	if cmd.opts.HTMLPath != "" {
+		return cmd.writeHTMLReport(f)
+	}
 	return cmd.printFinding(f)
 }
 
+// printFindingsTable prints the table of findings which is listed when
+// no finding name is specified.
+func printFindingsTable(findings []*finding.Finding) error {
+	data := [][]string{
+		{"Origin", "Severity", "Name", "Description", "Fuzz Test", "Location"},
+	}
+	for _, f := range findings {
+		data = append(data, findingTableRow(f))
+	}
+	err := pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	return errors.WithStack(err)
+}
+
+// findingTableRow returns the columns which are listed for the finding
+// in the findings table.
+func findingTableRow(f *finding.Finding) []string {
+	score := "n/a"
+	// check if MoreDetails exists to avoid nil pointer errors
+	if f.MoreDetails != nil {
+		// check if we have a severity and if we have a severity score
+		if f.MoreDetails.Severity != nil {
+			colorFunc := getColorFunctionForSeverity(f.MoreDetails.Severity.Score)
+			score = colorFunc(fmt.Sprintf("%.1f", f.MoreDetails.Severity.Score))
+		}
+	}
+	return []string{
+		f.Origin,
+		score,
+		f.Name,
+		// FIXME: replace f.ShortDescriptionColumns()[0] with
+		// f.MoreDetails.Name once we cover all bugs with our
+		// error-details.json
+		f.ShortDescriptionColumns()[0],
+		f.FuzzTest,
+		f.SourceLocation(),
+	}
+}
+
+// projectFinding is a finding annotated with the directory of the
+// project it belongs to, as listed with --scan.
+type projectFinding struct {
+	Project string `json:"project"`
+	*finding.Finding
+}
+
+// listScannedFindings lists the local findings of all projects below
+// the directories specified via --scan.
+func (cmd *findingCmd) listScannedFindings(errorDetails []*finding.ErrorDetails) error {
+	var allFindings []*finding.Finding
+	projects := make(map[*finding.Finding]string)
+	seen := make(map[string]bool)
+	for _, root := range cmd.opts.ScanDirs {
+		projectDirs, err := config.FindProjectDirs(root)
+		if err != nil {
+			return errors.WithMessagef(err, "Failed to scan %s for cifuzz projects", root)
+		}
+		for _, projectDir := range projectDirs {
+			// The same project can be found below multiple roots
+			if seen[projectDir] {
+				continue
+			}
+			seen[projectDir] = true
+
+			findings, err := finding.LocalFindings(projectDir, errorDetails)
+			if err != nil {
+				return err
+			}
+			for _, f := range findings {
+				f.ApplySeverityOverrides(cmd.opts.severityOverrides)
+				projects[f] = projectDir
+			}
+			allFindings = append(allFindings, findings...)
+		}
+	}
+
+	if cmd.opts.minSeverity != "" {
+		allFindings = cmd.filterBySeverity(allFindings)
+	}
+	sortFindings(allFindings, cmd.opts.Sort, cmd.opts.Reverse)
+
+	if cmd.opts.Format == formatJUnit {
+		return cmd.writeJUnitReport(allFindings)
+	}
+
+	if cmd.opts.PrintJSON {
+		// Always print a list, also if there are no findings
+		res := []*projectFinding{}
+		for _, f := range allFindings {
+			res = append(res, &projectFinding{Project: projects[f], Finding: f})
+		}
+		s, err := stringutil.ToJSONString(res)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), s)
+		return nil
+	}
+
+	if len(seen) == 0 {
+		log.Printf("No cifuzz projects found in %s", strings.Join(cmd.opts.ScanDirs, ", "))
+		return nil
+	}
+	if len(allFindings) == 0 {
+		log.Printf("None of the %d projects has any findings", len(seen))
+		return nil
+	}
+
+	data := [][]string{
+		{"Project", "Origin", "Severity", "Name", "Description", "Fuzz Test", "Location"},
+	}
+	for _, f := range allFindings {
+		data = append(data, append([]string{fileutil.PrettifyPath(projects[f])}, findingTableRow(f)...))
+	}
+	err := pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// filterBySeverity returns the findings which have a severity of at
+// least the level specified via --min-severity. Findings without a
+// severity are only kept if --include-unscored is specified.
+func (cmd *findingCmd) filterBySeverity(findings []*finding.Finding) []*finding.Finding {
+	// Always return a non-nil slice, so that the JSON output is "[]"
+	res := []*finding.Finding{}
+	for _, f := range findings {
+		if !hasSeverity(f) {
+			if cmd.opts.IncludeUnscored {
+				res = append(res, f)
+			}
+			continue
+		}
+		if f.MoreDetails.Severity.AtLeast(cmd.opts.minSeverity) {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func (cmd *findingCmd) writeJUnitReport(findings []*finding.Finding) error {
+	err := os.MkdirAll(filepath.Dir(cmd.opts.OutputPath), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = finding.WriteJUnitReport(cmd.opts.OutputPath, findings)
+	if err != nil {
+		return err
+	}
+	log.Successf("Wrote JUnit report of %d finding(s) to %s", len(findings), fileutil.PrettifyPath(cmd.opts.OutputPath))
+	return nil
+}
+
+// sortFindings sorts the findings by the specified key. Findings
+// without a severity are always sorted last when sorting by severity,
+// also if the order is reversed.
+func sortFindings(findings []*finding.Finding, key string, reverse bool) {
+	less := func(a, b *finding.Finding) bool {
+		switch key {
+		case sortByName:
+			return a.Name < b.Name
+		case sortBySeverity:
+			return a.MoreDetails.Severity.Score > b.MoreDetails.Severity.Score
+		default:
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if key == sortBySeverity {
+			aScored, bScored := hasSeverity(a), hasSeverity(b)
+			if !aScored || !bScored {
+				return aScored && !bScored
+			}
+		}
+		if reverse {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+func hasSeverity(f *finding.Finding) bool {
+	return f.MoreDetails != nil && f.MoreDetails.Severity != nil
+}
+
+func (cmd *findingCmd) localFindings(errorDetails []*finding.ErrorDetails) ([]*finding.Finding, error) {
+	if cmd.opts.FindingsDir != "" {
+		return finding.LocalFindingsFromDir(cmd.opts.FindingsDir, errorDetails)
+	}
+	return finding.LocalFindings(cmd.opts.ProjectDir, errorDetails)
+}
+
+func (cmd *findingCmd) loadFinding(name string, errorDetails []*finding.ErrorDetails) (*finding.Finding, error) {
+	if cmd.opts.FindingsDir != "" {
+		return finding.LoadFindingFromDir(cmd.opts.FindingsDir, name, errorDetails)
+	}
+	return finding.LoadFinding(cmd.opts.ProjectDir, name, errorDetails)
+}
+
+// emitJUnitSeed writes the crashing input of the finding to the inputs
+// directory of its fuzz test, from which the Jazzer JUnit integration
+// picks up regression test inputs.
+func (cmd *findingCmd) emitJUnitSeed(f *finding.Finding) error {
+	if f.FuzzTest == "" {
+		return errors.Errorf("Finding %s doesn't specify the fuzz test which produced it", f.Name)
+	}
+
+	input, err := cmd.crashingInput(f)
+	if err != nil {
+		return err
+	}
+	if input == nil {
+		return errors.Errorf("Finding %s doesn't have a crashing input", f.Name)
+	}
+
+	// The fuzz test can include the target method, which isn't part of
+	// the inputs directory
+	targetClass, _, _ := strings.Cut(f.FuzzTest, "::")
+	seedCorpusDir := cmdutils.JazzerSeedCorpus(targetClass, cmd.opts.ProjectDir)
+	err = os.MkdirAll(seedCorpusDir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	seedPath := filepath.Join(seedCorpusDir, f.Name)
+	err = os.WriteFile(seedPath, input, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Successf("Wrote crashing input of finding %s to %s", f.Name, fileutil.PrettifyPath(seedPath))
+	return nil
+}
+
+// crashingInput returns the crashing input of the finding, which is
+// either stored in the input file or, for remote findings, in the
+// finding itself. It returns nil if the finding has no crashing input.
+func (cmd *findingCmd) crashingInput(f *finding.Finding) ([]byte, error) {
+	if f.InputFile == "" {
+		return f.InputData, nil
+	}
+	inputPath := f.InputFile
+	if !filepath.IsAbs(inputPath) {
+		// The input file is stored relative to the project directory
+		inputPath = filepath.Join(cmd.opts.ProjectDir, inputPath)
+	}
+	input, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return input, nil
+}
+
+func (cmd *findingCmd) writeHTMLReport(f *finding.Finding) error {
+	input, err := cmd.crashingInput(f)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(cmd.opts.HTMLPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer out.Close()
+	err = writeHTMLReport(out, f, input, cmd.opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	err = out.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Successf("Wrote HTML report of finding %s to %s", f.Name, fileutil.PrettifyPath(cmd.opts.HTMLPath))
+	return nil
+}
+
 func (cmd *findingCmd) printFinding(f *finding.Finding) error {
 	if cmd.opts.PrintJSON {
 		s, err := stringutil.ToJSONString(f)
@@ -277,16 +754,72 @@ func (cmd *findingCmd) printFinding(f *finding.Finding) error {
 	} else {
 		s := pterm.Style{pterm.Reset, pterm.Bold}.Sprint(f.ShortDescriptionWithName())
 		s += fmt.Sprintf("\nDate: %s\n", f.CreatedAt)
+		if f.ProvenanceInput != "" {
+			s += fmt.Sprintf("Mutated from corpus entry: %s\n", f.ProvenanceInput)
+		}
 		s += fmt.Sprintf("\n  %s\n", strings.Join(f.Logs, "\n  "))
+		if len(f.TargetOutput) > 0 {
+			s += pterm.Style{pterm.Reset, pterm.Bold}.Sprint("\nOutput of the fuzz test before the crash:")
+			s += fmt.Sprintf("\n  %s\n", strings.Join(f.TargetOutput, "\n  "))
+		}
 		_, err := fmt.Fprint(cmd.OutOrStdout(), s)
 		if err != nil {
 			return errors.WithStack(err)
 		}
 		PrintMoreDetails(f)
 	}
+	if cmd.opts.GitBlame {
+		cmd.printBlame(f)
+	}
 	return nil
 }
 
+// printBlame prints the commit which last changed the line of the top
+// stack frame of the finding. It's skipped if the source file can't be
+// found or isn't contained in a Git repository.
+func (cmd *findingCmd) printBlame(f *finding.Finding) {
+	if len(f.StackTrace) == 0 || f.StackTrace[0].SourceFile == "" || f.StackTrace[0].Line == 0 {
+		log.Infof("\nSkipping git blame: The finding doesn't have a source location")
+		return
+	}
+	frame := f.StackTrace[0]
+	sourceFile := frame.SourceFile
+	if !filepath.IsAbs(sourceFile) {
+		// Source files are reported relative to the project directory
+		sourceFile = filepath.Join(cmd.opts.ProjectDir, sourceFile)
+	}
+	exists, err := fileutil.Exists(sourceFile)
+	if err != nil || !exists {
+		log.Infof("\nSkipping git blame: Source file %s not found", frame.SourceFile)
+		return
+	}
+
+	info, err := vcs.GitBlame(sourceFile, frame.Line)
+	if err != nil {
+		log.Debugf("git blame failed: %+v", err)
+		log.Infof("\nSkipping git blame: %s is not contained in a Git repository", frame.SourceFile)
+		return
+	}
+
+	log.Infof("\nLast change of %s:%d:", frame.SourceFile, frame.Line)
+	if !info.IsCommitted() {
+		log.Print("The line has uncommitted changes")
+		return
+	}
+	data := [][]string{
+		{"Commit", info.Commit},
+		{"Author", fmt.Sprintf("%s <%s>", info.Author, info.AuthorMail)},
+		{"Date", info.Date.Format(time.RFC1123Z)},
+		{"Summary", info.Summary},
+	}
+	tableString, err := pterm.DefaultTable.WithData(data).WithBoxed().Srender()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	log.Print(tableString)
+}
+
 func PrintMoreDetails(f *finding.Finding) {
 	if f.MoreDetails == nil {
 		return
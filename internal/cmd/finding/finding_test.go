@@ -1,18 +1,24 @@
 package finding
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"code-intelligence.com/cifuzz/integration-tests/shared/mockserver"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/testutil"
 	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/stringutil"
 )
 
@@ -66,6 +72,175 @@ func TestListFindings(t *testing.T) {
 	require.Equal(t, jsonString, stdOut)
 }
 
+func TestListFindings_MinSeverity(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-list-findings-")
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+
+	// Create findings with different severities, the newest first
+	now := time.Now()
+	findings := []*finding.Finding{
+		{
+			Name:        "critical_finding",
+			Origin:      "Local",
+			CreatedAt:   now,
+			MoreDetails: &finding.ErrorDetails{ID: "critical", Severity: &finding.Severity{Level: "Critical", Score: 9.8}},
+		},
+		{
+			Name:        "medium_finding",
+			Origin:      "Local",
+			CreatedAt:   now.Add(-time.Minute),
+			MoreDetails: &finding.ErrorDetails{ID: "medium", Severity: &finding.Severity{Level: "Medium", Score: 5.0}},
+		},
+		{
+			Name:        "unscored_finding",
+			Origin:      "Local",
+			CreatedAt:   now.Add(-2 * time.Minute),
+			MoreDetails: &finding.ErrorDetails{ID: "unscored"},
+		},
+	}
+	for _, f := range findings {
+		err := f.Save(projectDir)
+		require.NoError(t, err)
+	}
+
+	listFindings := func(args ...string) []string {
+		return listFindingNames(t, projectDir, args...)
+	}
+
+	assert.Equal(t, []string{"critical_finding", "medium_finding", "unscored_finding"}, listFindings())
+	assert.Equal(t, []string{"critical_finding", "medium_finding"}, listFindings("--min-severity", "medium"))
+	assert.Equal(t, []string{"critical_finding"}, listFindings("--min-severity", "HIGH"))
+	assert.Equal(t, []string{"critical_finding", "unscored_finding"},
+		listFindings("--min-severity", "high", "--include-unscored"))
+	assert.Equal(t, []string{"critical_finding"}, listFindings("--min-severity", "critical"))
+
+	// Check that invalid levels are rejected
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--min-severity", "urgent", "--interactive=false")
+	require.Error(t, err)
+	assert.Contains(t, stdErr, "invalid severity level")
+}
+
+func TestListFindings_Sort(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-list-findings-")
+
+	now := time.Now()
+	findings := []*finding.Finding{
+		{
+			Name:        "b_finding",
+			Origin:      "Local",
+			CreatedAt:   now,
+			MoreDetails: &finding.ErrorDetails{ID: "medium", Severity: &finding.Severity{Level: "Medium", Score: 5.0}},
+		},
+		{
+			Name:        "c_finding",
+			Origin:      "Local",
+			CreatedAt:   now.Add(-time.Minute),
+			MoreDetails: &finding.ErrorDetails{ID: "unscored"},
+		},
+		{
+			Name:        "a_finding",
+			Origin:      "Local",
+			CreatedAt:   now.Add(-2 * time.Minute),
+			MoreDetails: &finding.ErrorDetails{ID: "critical", Severity: &finding.Severity{Level: "Critical", Score: 9.8}},
+		},
+	}
+	for _, f := range findings {
+		err := f.Save(projectDir)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"b_finding", "c_finding", "a_finding"}, listFindingNames(t, projectDir))
+	assert.Equal(t, []string{"a_finding", "c_finding", "b_finding"}, listFindingNames(t, projectDir, "--reverse"))
+	assert.Equal(t, []string{"a_finding", "b_finding", "c_finding"}, listFindingNames(t, projectDir, "--sort", "name"))
+	assert.Equal(t, []string{"c_finding", "b_finding", "a_finding"}, listFindingNames(t, projectDir, "--sort", "name", "--reverse"))
+	// Findings without a severity are sorted last, also when reversed
+	assert.Equal(t, []string{"a_finding", "b_finding", "c_finding"}, listFindingNames(t, projectDir, "--sort", "severity"))
+	assert.Equal(t, []string{"b_finding", "a_finding", "c_finding"}, listFindingNames(t, projectDir, "--sort", "severity", "--reverse"))
+
+	// Check that invalid keys are rejected
+	opts := &options{ProjectDir: projectDir, ConfigDir: projectDir}
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--sort", "size", "--interactive=false")
+	require.Error(t, err)
+	assert.Contains(t, stdErr, `invalid argument "size" for "--sort" flag`)
+}
+
+// listFindingNames lists the findings of the project via the JSON
+// output of the command and returns their names in the listed order.
+func listFindingNames(t *testing.T, projectDir string, args ...string) []string {
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+	args = append(args, "--json", "--interactive=false")
+	stdOut, _, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, args...)
+	require.NoError(t, err)
+	var listed []*finding.Finding
+	err = json.Unmarshal([]byte(stdOut), &listed)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range listed {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestListFindings_Scan(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"project_a", "project_b"} {
+		projectDir := filepath.Join(root, name)
+		err := os.MkdirAll(projectDir, 0o755)
+		require.NoError(t, err)
+		_, err = config.CreateProjectConfig(projectDir, "", "", "")
+		require.NoError(t, err)
+		f := &finding.Finding{
+			Name:      name + "_finding",
+			Origin:    "Local",
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}
+		if name == "project_a" {
+			f.Type = finding.ErrorTypeWarning
+		}
+		err = f.Save(projectDir)
+		require.NoError(t, err)
+	}
+	// A directory without a cifuzz.yaml is not a project
+	err := os.MkdirAll(filepath.Join(root, "no_project"), 0o755)
+	require.NoError(t, err)
+
+	// The scan doesn't require the current directory to be a project
+	opts := &options{}
+	stdOut, _, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--scan", root, "--json", "--interactive=false")
+	require.NoError(t, err)
+	var listed []*projectFinding
+	err = json.Unmarshal([]byte(stdOut), &listed)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, filepath.Join(root, "project_b"), listed[0].Project)
+	assert.Equal(t, "project_b_finding", listed[0].Name)
+	assert.Equal(t, filepath.Join(root, "project_a"), listed[1].Project)
+	assert.Equal(t, "project_a_finding", listed[1].Name)
+
+	// Check that the severity overrides are applied to the scanned
+	// findings before filtering by severity
+	opts = &options{SeverityOverrides: map[string]string{string(finding.ErrorTypeWarning): "high"}}
+	stdOut, _, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--scan", root, "--min-severity", "high", "--json", "--interactive=false")
+	require.NoError(t, err)
+	listed = nil
+	err = json.Unmarshal([]byte(stdOut), &listed)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "project_a_finding", listed[0].Name)
+
+	// Check that the flag can't be combined with a finding name
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(&options{}), os.Stdin, "--scan", root, "project_a_finding")
+	require.Error(t, err)
+	assert.Contains(t, stdErr, "Flag 'scan' can only be used when listing findings")
+}
+
 func TestListFindings_Authenticated(t *testing.T) {
 	t.Setenv("CIFUZZ_API_TOKEN", "token")
 	server := mockserver.New(t)
@@ -281,3 +456,323 @@ func TestPrintRemoteFinding_Authenticated(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, stdErr, "cifuzz found more extensive information about this finding:")
 }
+
+func TestEmitJUnitSeed(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-emit-junit-seed-")
+	opts := &options{
+		ProjectDir:  projectDir,
+		ConfigDir:   projectDir,
+		BuildSystem: config.BuildSystemMaven,
+	}
+
+	f := &finding.Finding{
+		Origin:    "Local",
+		Name:      "test_finding",
+		InputData: []byte("crashing input"),
+		FuzzTest:  "com.example.FuzzTestCase",
+	}
+	err := f.Save(projectDir)
+	require.NoError(t, err)
+
+	_, _, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--emit-junit-seed", "--interactive=false")
+	require.NoError(t, err)
+
+	seedPath := filepath.Join(projectDir, "src", "test", "resources", "com", "example", "FuzzTestCaseInputs", f.Name)
+	content, err := os.ReadFile(seedPath)
+	require.NoError(t, err)
+	assert.Equal(t, "crashing input", string(content))
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-html-report-")
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+
+	f := &finding.Finding{
+		Origin:    "Local",
+		Name:      "test_finding",
+		Type:      finding.ErrorTypeCrash,
+		Details:   "heap-buffer-overflow",
+		InputData: []byte("<script>\x00"),
+		Logs:      []string{"==1==ERROR: AddressSanitizer: heap-buffer-overflow"},
+		MoreDetails: &finding.ErrorDetails{
+			ID:         "heap_buffer_overflow",
+			Name:       "Heap Buffer Overflow",
+			Severity:   &finding.Severity{Level: finding.SeverityLevelHigh, Score: 8.0},
+			Mitigation: "Check the bounds of the buffer.",
+			CweDetails: &finding.ExternalDetail{ID: 122, Name: "Heap-based Buffer Overflow"},
+		},
+		StackTrace: []*stacktrace.StackFrame{
+			{Function: "parse", SourceFile: "src/parser.cpp", Line: 12, Column: 3},
+		},
+	}
+	err := f.Save(projectDir)
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(t.TempDir(), "report.html")
+	_, _, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--html", reportPath, "--interactive=false")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	report := string(content)
+	assert.Contains(t, report, "Heap Buffer Overflow")
+	assert.Contains(t, report, `<td class="severity-high">HIGH (8.0)</td>`)
+	assert.Contains(t, report, "Check the bounds of the buffer.")
+	assert.Contains(t, report, "CWE: Heap-based Buffer Overflow")
+	assert.Contains(t, report, fmt.Sprintf(`<a href="file://%s">src/parser.cpp:12:3</a>`, filepath.ToSlash(filepath.Join(projectDir, "src", "parser.cpp"))))
+	assert.Contains(t, report, "AddressSanitizer: heap-buffer-overflow")
+	// The input contains a null byte, so it's only shown as hex, which
+	// is escaped
+	assert.Contains(t, report, "3c 73 63 72 69 70 74 3e")
+	assert.NotContains(t, report, "<script>")
+
+	// Check that the flag requires a finding name
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--html", reportPath, "--interactive=false")
+	require.Error(t, err)
+	assert.Contains(t, stdErr, "Flag 'html' requires a finding name")
+}
+
+func TestListFindings_JUnit(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-list-findings-")
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+
+	f := &finding.Finding{
+		Origin:   "Local",
+		Name:     "test_finding",
+		Type:     finding.ErrorTypeCrash,
+		Details:  "heap-buffer-overflow",
+		FuzzTest: "my_fuzz_test",
+	}
+	err := f.Save(projectDir)
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(t.TempDir(), "findings.xml")
+	_, _, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--format", "junit", "--output", reportPath, "--interactive=false")
+	require.NoError(t, err)
+	content, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `<testsuite name="my_fuzz_test" tests="1" failures="1">`)
+	assert.Contains(t, string(content), `<testcase name="test_finding" classname="my_fuzz_test">`)
+
+	// Check that invalid formats and combinations are rejected
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--format", "xml", "--interactive=false")
+	require.Error(t, err)
+	assert.Contains(t, stdErr, `invalid argument "xml" for "--format" flag`)
+	_, stdErr, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, "--format", "junit", "--json", "--interactive=false")
+	require.Error(t, err)
+	assert.Contains(t, stdErr, "Flags 'format' and 'json' can't be used together")
+}
+
+func TestPrintFinding_GitBlame(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-git-blame-")
+	opts := &options{
+		ProjectDir: projectDir,
+		ConfigDir:  projectDir,
+	}
+
+	err := os.WriteFile(filepath.Join(projectDir, "parser.cpp"), []byte("int a;\nint b;\n"), 0o644)
+	require.NoError(t, err)
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "you@example.com"},
+		{"config", "user.name", "Your Name"},
+		{"add", "parser.cpp"},
+		{"commit", "-m", "Add parser"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = projectDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	f := &finding.Finding{
+		Origin: "Local",
+		Name:   "test_finding",
+		StackTrace: []*stacktrace.StackFrame{
+			{Function: "parse", SourceFile: "parser.cpp", Line: 2},
+		},
+	}
+	err = f.Save(projectDir)
+	require.NoError(t, err)
+
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--git-blame", "--interactive=false")
+	require.NoError(t, err)
+	assert.Contains(t, stdErr, "Last change of parser.cpp:2:")
+	assert.Contains(t, stdErr, "Your Name <you@example.com>")
+	assert.Contains(t, stdErr, "Add parser")
+
+	// Check that the blame is skipped if the source file doesn't exist
+	f.StackTrace[0].SourceFile = "does_not_exist.cpp"
+	err = f.Save(projectDir)
+	require.NoError(t, err)
+	_, stdErr, err = cmdutils.ExecuteCommand(t, newWithOptions(opts), os.Stdin, f.Name, "--git-blame", "--interactive=false")
+	require.NoError(t, err)
+	assert.Contains(t, stdErr, "Skipping git blame: Source file does_not_exist.cpp not found")
+}
+
+func TestPruneFindings(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-prune-findings-")
+
+	oldFinding := &finding.Finding{
+		Origin:    "Local",
+		Name:      "old_finding",
+		CreatedAt: time.Now().Add(-40 * 24 * time.Hour),
+	}
+	newFinding := &finding.Finding{
+		Origin:    "Local",
+		Name:      "new_finding",
+		CreatedAt: time.Now(),
+	}
+	for _, f := range []*finding.Finding{oldFinding, newFinding} {
+		err := f.Save(projectDir)
+		require.NoError(t, err)
+	}
+
+	opts := &pruneOptions{ProjectDir: projectDir, ConfigDir: projectDir}
+	_, _, err := cmdutils.ExecuteCommand(t, newPruneCmd(opts), os.Stdin, "--older-than", "30d", "--dry-run")
+	require.NoError(t, err)
+	findings, err := finding.LocalFindings(projectDir, nil)
+	require.NoError(t, err)
+	assert.Len(t, findings, 2)
+
+	opts = &pruneOptions{ProjectDir: projectDir, ConfigDir: projectDir}
+	_, _, err = cmdutils.ExecuteCommand(t, newPruneCmd(opts), os.Stdin, "--older-than", "30d")
+	require.NoError(t, err)
+	findings, err = finding.LocalFindings(projectDir, nil)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, newFinding.Name, findings[0].Name)
+}
+
+func TestExportImportFindings(t *testing.T) {
+	sourceDir := testutil.BootstrapEmptyProject(t, "test-export-findings-")
+	targetDir := testutil.BootstrapEmptyProject(t, "test-import-findings-")
+
+	f := &finding.Finding{Origin: "Local", Name: "test_finding", Logs: []string{"Oops"}}
+	err := f.Save(sourceDir)
+	require.NoError(t, err)
+
+	archivePath := filepath.Join(t.TempDir(), "findings.tar.gz")
+	count, err := exportFindings(sourceDir, archivePath)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	imported, err := finding.Import(targetDir, archivePath)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+	assert.Equal(t, f.Name, imported[0].Name)
+
+	// Importing the same findings again skips them
+	imported, err = finding.Import(targetDir, archivePath)
+	require.NoError(t, err)
+	assert.Empty(t, imported)
+}
+
+func TestExportFindings_RemovesArchiveOnError(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-export-findings-")
+
+	f := &finding.Finding{Origin: "Local", Name: "test_finding"}
+	err := f.Save(projectDir)
+	require.NoError(t, err)
+	// A dangling symlink can't be added to the archive
+	err = os.Symlink("does-not-exist", filepath.Join(projectDir, ".cifuzz-findings", f.Name, "dangling"))
+	require.NoError(t, err)
+
+	archivePath := filepath.Join(t.TempDir(), "findings.tar.gz")
+	_, err = exportFindings(projectDir, archivePath)
+	require.Error(t, err)
+	assert.NoFileExists(t, archivePath)
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for s, expected := range testCases {
+		d, err := parseAge(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, d)
+	}
+
+	for _, s := range []string{"", "d", "-1d", "-5h", "30x"} {
+		_, err := parseAge(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestTriageFindings_NonInteractive(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-triage-findings-")
+	f := &finding.Finding{
+		Origin:    "Local",
+		Name:      "my_finding",
+		CreatedAt: time.Now(),
+	}
+	err := f.Save(projectDir)
+	require.NoError(t, err)
+
+	// Without a terminal, the findings are listed instead
+	opts := &triageOptions{ProjectDir: projectDir, ConfigDir: projectDir}
+	_, stdErr, err := cmdutils.ExecuteCommand(t, newTriageCmd(opts), os.Stdin, "--interactive=false")
+	require.NoError(t, err)
+	assert.Contains(t, stdErr, "listing the findings instead")
+	findings, err := finding.LocalFindings(projectDir, nil)
+	require.NoError(t, err)
+	assert.Len(t, findings, 1)
+}
+
+func TestTriageFindings_FindingsDir(t *testing.T) {
+	projectDir := testutil.BootstrapEmptyProject(t, "test-triage-findings-")
+	err := os.WriteFile(filepath.Join(projectDir, "src.c"), []byte("int main() {\n  return 0;\n}\n"), 0o644)
+	require.NoError(t, err)
+	findingsDir := t.TempDir()
+	f := &finding.Finding{
+		Origin:    "Local",
+		Name:      "my_finding",
+		Type:      finding.ErrorTypeWarning,
+		CreatedAt: time.Now(),
+		StackTrace: []*stacktrace.StackFrame{
+			{SourceFile: "src.c", Line: 2},
+		},
+	}
+	err = f.SaveToDirWithIndent(findingsDir, finding.JSONIndentTwoSpace)
+	require.NoError(t, err)
+
+	overrides, err := finding.ParseSeverityOverrides(map[string]string{string(finding.ErrorTypeWarning): "high"})
+	require.NoError(t, err)
+	cmd := &triageCmd{
+		Command: newTriageCmd(&triageOptions{}),
+		opts: &triageOptions{
+			ProjectDir:        projectDir,
+			FindingsDir:       findingsDir,
+			severityOverrides: overrides,
+		},
+	}
+
+	// The findings are read from the findings dir with the severity
+	// overrides applied
+	findings, err := cmd.localFindings()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.NotNil(t, findings[0].MoreDetails)
+	assert.True(t, findings[0].MoreDetails.Severity.AtLeast(finding.SeverityLevelHigh))
+
+	// Suppressing the finding updates it in the findings dir without
+	// storing the overridden severity
+	err = cmd.suppress(findings[0], suppressionReasonAccepted)
+	require.NoError(t, err)
+	stored, err := finding.LoadFindingFromDir(findingsDir, "my_finding", nil)
+	require.NoError(t, err)
+	assert.True(t, stored.Suppressed)
+	assert.Nil(t, stored.MoreDetails)
+	exists, err := fileutil.Exists(filepath.Join(projectDir, ".cifuzz-findings", "my_finding"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
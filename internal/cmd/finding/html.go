@@ -0,0 +1,101 @@
+package finding
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+//go:embed finding.html.tmpl
+var htmlReportTemplate string
+
+type htmlReport struct {
+	Finding     *finding.Finding
+	Description string
+	StackTrace  []*htmlStackFrame
+	Input       []byte
+	InputText   string
+	InputHex    string
+}
+
+type htmlStackFrame struct {
+	Function string
+	Location string
+	// A file URL of the source file, if its path is known. We build
+	// it ourselves, so it's safe to use it as a link target.
+	URL template.URL
+}
+
+// writeHTMLReport renders the finding, including its error details and
+// its crashing input, as a self-contained HTML page.
+func writeHTMLReport(w io.Writer, f *finding.Finding, input []byte, projectDir string) error {
+	tmpl, err := template.New("finding").Funcs(template.FuncMap{
+		"lower": func(level finding.SeverityLevel) string { return strings.ToLower(string(level)) },
+	}).Parse(htmlReportTemplate)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	report := &htmlReport{
+		Finding:     f,
+		Description: f.ShortDescriptionWithName(),
+		Input:       input,
+		InputHex:    hex.Dump(input),
+	}
+	if isPrintable(input) {
+		report.InputText = string(input)
+	}
+	for _, frame := range f.StackTrace {
+		report.StackTrace = append(report.StackTrace, newHTMLStackFrame(frame.Function, frame.SourceFile, frame.Line, frame.Column, projectDir))
+	}
+
+	err = tmpl.Execute(w, report)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func newHTMLStackFrame(function, sourceFile string, line, column uint32, projectDir string) *htmlStackFrame {
+	frame := &htmlStackFrame{Function: function}
+	if sourceFile == "" {
+		return frame
+	}
+	frame.Location = fmt.Sprintf("%s:%d:%d", sourceFile, line, column)
+
+	// Source files are reported relative to the project directory
+	path := sourceFile
+	if !filepath.IsAbs(path) {
+		if projectDir == "" {
+			return frame
+		}
+		path = filepath.Join(projectDir, path)
+	}
+	frame.URL = template.URL((&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()) //nolint:gosec
+	return frame
+}
+
+// isPrintable returns true if the input is valid UTF-8 which only
+// contains printable characters and whitespace.
+func isPrintable(input []byte) bool {
+	if !utf8.Valid(input) {
+		return false
+	}
+	for _, r := range string(input) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
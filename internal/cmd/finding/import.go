@@ -0,0 +1,51 @@
+package finding
+
+import (
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+type importOptions struct {
+	ProjectDir string `mapstructure:"project-dir"`
+	ConfigDir  string `mapstructure:"config-dir"`
+}
+
+func newImportCmd(opts *importOptions) *cobra.Command {
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "import <archive>",
+		Short: "Import findings from an archive",
+		Long: `This command imports the findings from an archive created via
+'cifuzz finding export' into the project. Each finding is validated
+before it is imported, findings which already exist in the project
+are skipped.`,
+		Example: "cifuzz finding import findings.tar.gz",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			bindFlags()
+			return config.FindAndParseProjectConfig(opts)
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			findings, err := finding.Import(opts.ProjectDir, args[0])
+			if err != nil {
+				return err
+			}
+			for _, f := range findings {
+				log.Infof("Imported finding %s", f.ShortDescriptionWithName())
+			}
+			log.Successf("Imported %d finding(s) from %s", len(findings), args[0])
+			return nil
+		},
+	}
+
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddProjectDirFlag,
+	)
+
+	return cmd
+}
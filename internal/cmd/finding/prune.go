@@ -0,0 +1,198 @@
+package finding
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/replay"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+type pruneOptions struct {
+	ProjectDir string `mapstructure:"project-dir"`
+	ConfigDir  string `mapstructure:"config-dir"`
+
+	OlderThan       string
+	DryRun          bool
+	KeepReproducing bool
+
+	maxAge  time.Duration
+	runOpts *adapter.RunOptions
+}
+
+func newPruneCmd(opts *pruneOptions) *cobra.Command {
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "prune --older-than <duration>",
+		Short: "Remove local findings which are older than a given age",
+		Long: `This command removes the local findings of the project which were
+created before the specified duration, e.g. "30d" for 30 days, "2w" for
+two weeks or "12h" for 12 hours.
+
+With --keep-reproducing, the fuzz tests of the old findings are built
+and executed with the crashing inputs, and findings which still
+reproduce are kept. Each fuzz test is only built once. Findings whose
+crashing input doesn't exist are kept as well. This is only supported
+for CMake, Bazel and build system type 'other'.`,
+		Example: "cifuzz finding prune --older-than 30d --dry-run",
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			bindFlags()
+			err := config.FindAndParseProjectConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if opts.OlderThan == "" {
+				return cmdutils.WrapIncorrectUsageError(errors.New("Flag 'older-than' must be set"))
+			}
+			opts.maxAge, err = parseAge(opts.OlderThan)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+
+			if opts.KeepReproducing {
+				opts.runOpts = &adapter.RunOptions{}
+				err = config.FindAndParseProjectConfig(opts.runOpts)
+				if err != nil {
+					return err
+				}
+				err = replay.CheckBuildSystem(opts.runOpts.BuildSystem)
+				if err != nil {
+					return err
+				}
+				opts.runOpts.BuildStdout = cmd.OutOrStdout()
+				opts.runOpts.BuildStderr = cmd.OutOrStderr()
+				opts.runOpts.Stdout = cmd.OutOrStdout()
+				opts.runOpts.Stderr = cmd.OutOrStderr()
+				err = opts.runOpts.Validate()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			return prune(opts)
+		},
+	}
+
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddProjectDirFlag,
+	)
+	cmd.Flags().StringVar(&opts.OlderThan, "older-than", "",
+		"Remove findings which were created more than the specified `duration` ago,\n"+
+			"e.g. \"30d\", \"2w\" or \"12h\".")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false,
+		"Only print the findings which would be removed.")
+	cmd.Flags().BoolVar(&opts.KeepReproducing, "keep-reproducing", false,
+		"Keep findings whose crashing input still reproduces.\n"+
+			"Only supported for CMake, Bazel and build system type 'other'.")
+
+	return cmd
+}
+
+func prune(opts *pruneOptions) error {
+	findings, err := finding.LocalFindings(opts.ProjectDir, nil)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-opts.maxAge)
+	var old []*finding.Finding
+	for _, f := range findings {
+		// Findings without a creation time can't be pruned by age
+		if !f.CreatedAt.IsZero() && f.CreatedAt.Before(cutoff) {
+			old = append(old, f)
+		}
+	}
+	if len(old) == 0 {
+		log.Infof("There are no findings older than %s", opts.OlderThan)
+		return nil
+	}
+
+	if opts.KeepReproducing {
+		old, err = withoutReproducing(opts.runOpts, old)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range old {
+		if opts.DryRun {
+			log.Infof("Would remove finding %s (created %s)", f.Name, f.CreatedAt.Format(time.DateOnly))
+			continue
+		}
+		err = f.Remove(opts.ProjectDir)
+		if err != nil {
+			return err
+		}
+		log.Infof("Removed finding %s (created %s)", f.Name, f.CreatedAt.Format(time.DateOnly))
+	}
+
+	if opts.DryRun {
+		log.Successf("%d finding(s) would be removed", len(old))
+	} else {
+		log.Successf("Removed %d finding(s)", len(old))
+	}
+	return nil
+}
+
+// withoutReproducing returns the findings whose crashing input doesn't
+// trigger a finding anymore. Findings whose crashing input doesn't
+// exist are kept, because it can't be checked whether they reproduce.
+func withoutReproducing(runOpts *adapter.RunOptions, findings []*finding.Finding) ([]*finding.Finding, error) {
+	replayer, err := replay.NewReplayer(runOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer replayer.Cleanup()
+
+	var res []*finding.Finding
+	for _, f := range findings {
+		reproduces, err := replayer.Reproduces(f)
+		if errors.Is(err, replay.ErrMissingInput) {
+			log.Warnf("Keeping finding %s, its crashing input %s does not exist", f.Name, f.InputFile)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reproduces {
+			log.Infof("Keeping finding %s, which still reproduces", f.Name)
+			continue
+		}
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+// parseAge parses a duration which, in addition to the units supported
+// by time.ParseDuration, can be specified in days ("d") or weeks ("w").
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(s, suffix), 10, 32)
+		if err != nil {
+			return 0, errors.Errorf("Invalid duration %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.Errorf("Invalid duration %q", s)
+	}
+	return d, nil
+}
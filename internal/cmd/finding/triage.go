@@ -0,0 +1,375 @@
+package finding
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/browser"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"code-intelligence.com/cifuzz/internal/api"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/cmdutils/auth"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/dialog"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// The actions which can be applied to a finding during triage
+const (
+	triageActionDetails       = "View details"
+	triageActionFalsePositive = "Mark as false positive"
+	triageActionAccepted      = "Mark as accepted"
+	triageActionOpenSource    = "Open source location"
+	triageActionDelete        = "Delete"
+	triageActionBack          = "Back"
+)
+
+// The suppression reasons written when marking a finding
+const (
+	suppressionReasonFalsePositive = "false positive"
+	suppressionReasonAccepted      = "accepted"
+)
+
+const triageQuit = "<Quit>"
+
+type triageOptions struct {
+	ProjectDir          string            `mapstructure:"project-dir"`
+	ConfigDir           string            `mapstructure:"config-dir"`
+	Interactive         bool              `mapstructure:"interactive"`
+	Server              string            `mapstructure:"server"`
+	Proxy               string            `mapstructure:"proxy"`
+	RefreshErrorDetails bool              `mapstructure:"refresh-error-details"`
+	SeverityOverrides   map[string]string `mapstructure:"severity-overrides"`
+	FindingJSONIndent   string            `mapstructure:"finding-json-indent"`
+
+	FindingsDir string
+
+	severityOverrides map[string]*finding.Severity
+}
+
+func newTriageCmd(opts *triageOptions) *cobra.Command {
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "triage",
+		Short: "Interactively triage the local findings",
+		Long: `This command lists the local findings of the project in an interactive
+terminal UI. After selecting a finding, you can:
+
+  * view its details
+  * mark it as a false positive or as accepted, which appends a
+    suppression comment ("/* ` + finding.SuppressionMarker + ` <reason> */") to the
+    source line of the finding
+  * open its source location in $VISUAL or $EDITOR, or in the default
+    application if neither is set
+  * delete it
+
+If stdin or stdout is not a terminal, or with --interactive=false, the
+findings are listed non-interactively instead.`,
+		Example: "cifuzz finding triage",
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			bindFlags()
+			err := config.FindAndParseProjectConfig(opts)
+			if err != nil {
+				return err
+			}
+			_, err = finding.ParseJSONIndent(opts.FindingJSONIndent)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+			if opts.FindingsDir != "" {
+				opts.FindingsDir, err = finding.ValidateFindingsDir(opts.FindingsDir)
+				if err != nil {
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			}
+			opts.severityOverrides, err = finding.ParseSeverityOverrides(opts.SeverityOverrides)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+			err = api.ValidateProxyURL(opts.Proxy)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			opts.Interactive = viper.GetBool("interactive")
+			if opts.Interactive {
+				opts.Interactive = term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+			}
+			var err error
+			opts.Server, err = api.ValidateAndNormalizeServerURL(viper.GetString("server"))
+			if err != nil {
+				return err
+			}
+			cmd := &triageCmd{Command: c, opts: opts}
+			return cmd.run()
+		},
+	}
+
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddInteractiveFlag,
+		cmdutils.AddProjectDirFlag,
+		cmdutils.AddProxyFlag,
+		cmdutils.AddRefreshErrorDetailsFlag,
+		cmdutils.AddServerFlag,
+	)
+	addFindingsDirFlag(cmd, &opts.FindingsDir)
+
+	return cmd
+}
+
+type triageCmd struct {
+	*cobra.Command
+	opts *triageOptions
+
+	errorDetails []*finding.ErrorDetails
+}
+
+func (cmd *triageCmd) run() error {
+	apiClient := api.NewClient(cmd.opts.Server)
+	apiClient.Proxy = cmd.opts.Proxy
+	var err error
+	cmd.errorDetails, _, err = auth.TryGetErrorDetailsAndToken(apiClient, cmd.opts.RefreshErrorDetails)
+	if err != nil {
+		return err
+	}
+
+	findings, err := cmd.localFindings()
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		log.Print("This project doesn't have any findings yet")
+		return nil
+	}
+
+	if !cmd.opts.Interactive {
+		log.Info("Not running in an interactive terminal, listing the findings instead")
+		sortFindings(findings, sortByDate, false)
+		return printFindingsTable(findings)
+	}
+
+	for {
+		// Reload the findings in each iteration to reflect the changes
+		// of the previous action
+		findings, err = cmd.localFindings()
+		if err != nil {
+			return err
+		}
+		if len(findings) == 0 {
+			log.Success("All findings were triaged")
+			return nil
+		}
+		sortFindings(findings, sortByDate, false)
+
+		f, err := selectFinding(findings)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return nil
+		}
+		err = cmd.triageFinding(f)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// selectFinding lets the user select one of the findings. It returns
+// nil if the user chose to quit.
+func selectFinding(findings []*finding.Finding) (*finding.Finding, error) {
+	findingsByOption := make(map[string]*finding.Finding)
+	var options []string
+	for _, f := range findings {
+		option := f.ShortDescriptionWithName()
+		if f.Suppressed {
+			option += " (suppressed)"
+		}
+		findingsByOption[option] = f
+		options = append(options, option)
+	}
+	options = append(options, triageQuit)
+
+	prompt := pterm.DefaultInteractiveSelect.WithMaxHeight(dialog.MaxListEntries).WithOptions(options)
+	prompt.DefaultText = fmt.Sprintf("Select one of %d findings to triage:", len(findings))
+	option, err := prompt.Show()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return findingsByOption[option], nil
+}
+
+// triageFinding lets the user apply actions to the finding until it was
+// deleted or the user chose to go back to the list of findings.
+func (cmd *triageCmd) triageFinding(f *finding.Finding) error {
+	for {
+		actions := []string{triageActionDetails}
+		if !f.Suppressed && hasSourceLocation(f) {
+			actions = append(actions, triageActionFalsePositive, triageActionAccepted)
+		}
+		if hasSourceLocation(f) {
+			actions = append(actions, triageActionOpenSource)
+		}
+		actions = append(actions, triageActionDelete, triageActionBack)
+
+		prompt := pterm.DefaultInteractiveSelect.WithOptions(actions)
+		prompt.DefaultText = f.ShortDescriptionWithName()
+		action, err := prompt.Show()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		switch action {
+		case triageActionDetails:
+			err = cmd.findingCmd().printFinding(f)
+		case triageActionFalsePositive:
+			err = cmd.suppress(f, suppressionReasonFalsePositive)
+		case triageActionAccepted:
+			err = cmd.suppress(f, suppressionReasonAccepted)
+		case triageActionOpenSource:
+			err = cmd.openSourceLocation(f)
+		case triageActionDelete:
+			var deleted bool
+			deleted, err = cmd.delete(f)
+			if err == nil && deleted {
+				return nil
+			}
+		case triageActionBack:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// findingCmd returns a finding command with the options of the triage
+// command, which is used to load the findings and to print their
+// details the same way as `cifuzz finding <name>`.
+func (cmd *triageCmd) findingCmd() *findingCmd {
+	return &findingCmd{
+		Command: cmd.Command,
+		opts: &options{
+			ProjectDir:        cmd.opts.ProjectDir,
+			ConfigDir:         cmd.opts.ConfigDir,
+			Server:            cmd.opts.Server,
+			Proxy:             cmd.opts.Proxy,
+			FindingsDir:       cmd.opts.FindingsDir,
+			severityOverrides: cmd.opts.severityOverrides,
+		},
+	}
+}
+
+// localFindings returns the local findings with the error details and
+// the severity overrides applied.
+func (cmd *triageCmd) localFindings() ([]*finding.Finding, error) {
+	findings, err := cmd.findingCmd().localFindings(cmd.errorDetails)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range findings {
+		f.ApplySeverityOverrides(cmd.opts.severityOverrides)
+	}
+	return findings, nil
+}
+
+// findingsDir returns the directory from which the findings are read.
+func (cmd *triageCmd) findingsDir() string {
+	if cmd.opts.FindingsDir != "" {
+		return cmd.opts.FindingsDir
+	}
+	return finding.FindingsDir(cmd.opts.ProjectDir)
+}
+
+// suppress appends a suppression comment with the given reason to the
+// source line of the finding and stores that the finding is suppressed.
+// The stored finding is loaded without the error details and severity
+// overrides, so that only the suppression is added to it.
+func (cmd *triageCmd) suppress(f *finding.Finding, reason string) error {
+	stored, err := finding.LoadFindingFromDir(cmd.findingsDir(), f.Name, nil)
+	if err != nil {
+		return err
+	}
+	err = stored.AddSuppression(cmd.opts.ProjectDir, reason)
+	if err != nil {
+		return err
+	}
+	indent, err := finding.ParseJSONIndent(cmd.opts.FindingJSONIndent)
+	if err != nil {
+		return err
+	}
+	err = stored.SaveToDirWithIndent(cmd.findingsDir(), indent)
+	if err != nil {
+		return err
+	}
+	f.Suppressed = stored.Suppressed
+	f.SuppressionReason = stored.SuppressionReason
+	log.Successf("Marked finding %s as %s in %s", f.Name, reason, f.SourceLocation())
+	return nil
+}
+
+// openSourceLocation opens the source file of the finding at the
+// source line in the editor specified via $VISUAL or $EDITOR, or in the
+// default application if neither is set.
+func (cmd *triageCmd) openSourceLocation(f *finding.Finding) error {
+	frame := f.StackTrace[0]
+	path := frame.SourceFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cmd.opts.ProjectDir, path)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		err := browser.OpenFile(path)
+		return errors.WithStack(err)
+	}
+
+	// Most editors support jumping to a line via "+<line>"
+	args := strings.Fields(editor)
+	args = append(args, fmt.Sprintf("+%d", frame.Line), path)
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	log.Debugf("Command: %s", c.String())
+	err := c.Run()
+	if err != nil {
+		return cmdutils.WrapExecError(errors.WithStack(err), c)
+	}
+	return nil
+}
+
+// delete removes the finding after asking the user for confirmation.
+// It returns true if the finding was removed.
+func (cmd *triageCmd) delete(f *finding.Finding) (bool, error) {
+	confirmed, err := dialog.Confirm(fmt.Sprintf("Do you want to delete finding %s?", f.Name), false)
+	if err != nil || !confirmed {
+		return false, err
+	}
+	err = f.RemoveFromDir(cmd.findingsDir())
+	if err != nil {
+		return false, err
+	}
+	log.Successf("Deleted finding %s", f.Name)
+	return true, nil
+}
+
+func hasSourceLocation(f *finding.Finding) bool {
+	return len(f.StackTrace) > 0 && f.StackTrace[0].SourceFile != "" && f.StackTrace[0].Line != 0
+}
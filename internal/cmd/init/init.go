@@ -31,6 +31,9 @@ type options struct {
 	Server      string `mapstructure:"server"`
 	Project     string `mapstructure:"project"`
 	testLang    string
+	// forcedBuildSystem is the build system specified via
+	// --build-system, which overrides the detected build system
+	forcedBuildSystem string
 }
 
 func New() *cobra.Command {
@@ -60,8 +63,18 @@ func New() *cobra.Command {
 				opts.testLang = args[0]
 			}
 
-			// Override detected build system if test language is specified.
-			if opts.testLang != "" {
+			if opts.forcedBuildSystem != "" {
+				err = config.ValidateBuildSystem(opts.forcedBuildSystem)
+				if err != nil {
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+				if opts.testLang != "" && supportedInitTestTypesMap[opts.testLang] != opts.forcedBuildSystem {
+					err := errors.Errorf("Test type %q can't be used with build system %q", opts.testLang, opts.forcedBuildSystem)
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+				opts.BuildSystem = opts.forcedBuildSystem
+			} else if opts.testLang != "" {
+				// Override detected build system if test language is specified.
 				// cobra checks for us that opts.testLang is in supportedInitTestTypes
 				// because we set ValidArgs below.
 				opts.BuildSystem = supportedInitTestTypesMap[opts.testLang]
@@ -113,6 +126,11 @@ func New() *cobra.Command {
 		cmdutils.AddProjectFlag,
 		cmdutils.AddServerFlag,
 	)
+	cmd.Flags().StringVar(&opts.forcedBuildSystem, "build-system", "",
+		"The build system of the project, which is written to the cifuzz.yaml.\n"+
+			"Use this if the build system is not detected correctly, e.g. in\n"+
+			"directories which contain files of multiple build systems.\n"+
+			"Valid values: \"bazel\", \"cmake\", \"maven\", \"gradle\", \"nodejs\", \"other\".")
 
 	return cmd
 }
@@ -121,7 +139,7 @@ func run(opts *options) error {
 	setUpAndMentionBuildSystemIntegrations(opts.Dir, opts.BuildSystem, opts.testLang)
 	log.Debugf("Creating config file in directory: %s", opts.Dir)
 
-	configpath, err := config.CreateProjectConfig(opts.Dir, opts.Server, opts.Project)
+	configpath, err := config.CreateProjectConfig(opts.Dir, opts.Server, opts.Project, opts.forcedBuildSystem)
 	if err != nil {
 		// explicitly inform the user about an existing config file
 		if errors.Is(err, os.ErrExist) && configpath != "" {
@@ -66,6 +66,26 @@ func TestInitCmdForNodeWithTSLanguageArg(t *testing.T) {
 	assert.FileExists(t, filepath.Join(testDir, "cifuzz.yaml"))
 }
 
+func TestInitCmdWithBuildSystemFlag(t *testing.T) {
+	testDir := testutil.BootstrapExampleProjectForTest(t, "init-cmd-test", config.BuildSystemCMake)
+
+	// remove cifuzz.yaml from example project
+	err := os.Remove(filepath.Join(testDir, "cifuzz.yaml"))
+	require.NoError(t, err)
+
+	// An invalid build system is rejected
+	_, _, err = cmdutils.ExecuteCommand(t, New(), os.Stdin, "--build-system", "foo")
+	require.Error(t, err)
+
+	// The build system overrides the detected one and is written to
+	// the cifuzz.yaml
+	_, _, err = cmdutils.ExecuteCommand(t, New(), os.Stdin, "--build-system", config.BuildSystemMaven)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(testDir, "cifuzz.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "\nbuild-system: maven\n")
+}
+
 func TestSupportedInitTestTypes(t *testing.T) {
 	// Test that the supportedInitTestTypesMap and supportedInitTestTypes are in sync.
 	initTestTypes := supportedInitTestTypes
@@ -20,6 +20,7 @@ import (
 type loginOpts struct {
 	Interactive bool   `mapstructure:"interactive"`
 	Server      string `mapstructure:"server"`
+	Proxy       string `mapstructure:"proxy"`
 }
 
 type loginCmd struct {
@@ -29,6 +30,7 @@ type loginCmd struct {
 }
 
 func New() *cobra.Command {
+	opts := &loginOpts{}
 	var bindFlags func()
 
 	cmd := &cobra.Command{
@@ -42,14 +44,17 @@ To learn more, visit https://www.code-intelligence.com.`,
 			// function, because that would re-bind viper keys which
 			// were bound to the flags of other commands before.
 			bindFlags()
+
+			opts.Interactive = viper.GetBool("interactive")
+			opts.Server = viper.GetString("server")
+			opts.Proxy = viper.GetString("proxy")
+			err := api.ValidateProxyURL(opts.Proxy)
+			if err != nil {
+				return cmdutils.WrapIncorrectUsageError(err)
+			}
 			return nil
 		},
 		RunE: func(c *cobra.Command, args []string) error {
-			opts := &loginOpts{
-				Interactive: viper.GetBool("interactive"),
-				Server:      viper.GetString("server"),
-			}
-
 			var err error
 			opts.Server, err = api.ValidateAndNormalizeServerURL(opts.Server)
 			if err != nil {
@@ -58,11 +63,13 @@ To learn more, visit https://www.code-intelligence.com.`,
 
 			cmd := loginCmd{Command: c, opts: opts}
 			cmd.apiClient = api.NewClient(opts.Server)
+			cmd.apiClient.Proxy = opts.Proxy
 			return cmd.run()
 		},
 	}
 	bindFlags = cmdutils.AddFlags(cmd,
 		cmdutils.AddInteractiveFlag,
+		cmdutils.AddProxyFlag,
 		cmdutils.AddServerFlag,
 	)
 
@@ -86,7 +93,7 @@ func (c *loginCmd) run() error {
 		}
 		token = strings.TrimSpace(string(b))
 
-		isValid, err := auth.IsValidToken(c.opts.Server, token)
+		isValid, err := auth.IsValidToken(c.apiClient, token)
 		if err != nil {
 			return err
 		}
@@ -104,7 +111,7 @@ in interactive mode. You can generate a token here:
 		return cmdutils.WrapIncorrectUsageError(err)
 	}
 
-	_, err = auth.EnsureValidToken(c.opts.Server)
+	_, err = auth.EnsureValidToken(c.apiClient)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,348 @@
+package minimize
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/cmdutils/logging"
+	"code-intelligence.com/cifuzz/internal/cmdutils/resolve"
+	"code-intelligence.com/cifuzz/internal/completion"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+type minimizeCmd struct {
+	*cobra.Command
+	opts      *adapter.RunOptions
+	outputDir string
+	inPlace   bool
+}
+
+func New() *cobra.Command {
+	opts := &adapter.RunOptions{}
+	var outputDir string
+	var inPlace bool
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "minimize [flags] <fuzz test> [--] [<build system arg>...]",
+		Short: "Minimize the seed corpus of a fuzz test",
+		Long: `This command builds the fuzz test and uses libFuzzer's -merge=1 mode to
+merge the inputs of the seed corpus of the fuzz test and the directories
+specified via --seed-corpus into a minimal set of inputs which covers
+the same features. It reports how many inputs were removed.
+
+The <fuzz test> argument is resolved the same way as by 'cifuzz run'.
+
+The minimized set of inputs is written to the directory specified via
+--output. To replace the seed corpus of the fuzz test (e.g.
+<fuzz test>_inputs) by the minimized set of inputs instead, use
+--in-place. The seed corpus is only replaced after the minimization
+succeeded.
+
+Inputs which crash the fuzz test are not part of the minimized set of
+inputs created by libFuzzer, so they are added to it to not lose them.
+
+Only supported for CMake, Bazel, Maven, Gradle and build system type 'other'.`,
+		Example:           "cifuzz minimize --in-place my_fuzz_test",
+		ValidArgsFunction: completion.ValidFuzzTests,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Bind viper keys to flags. We can't do this in the New
+			// function, because that would re-bind viper keys which
+			// were bound to the flags of other commands before.
+			bindFlags()
+
+			var lenFuzzTestArgs int
+			var argsToPass []string
+			if cmd.ArgsLenAtDash() != -1 {
+				lenFuzzTestArgs = cmd.ArgsLenAtDash()
+				argsToPass = args[cmd.ArgsLenAtDash():]
+				args = args[:cmd.ArgsLenAtDash()]
+			} else {
+				lenFuzzTestArgs = len(args)
+			}
+			if lenFuzzTestArgs != 1 {
+				msg := fmt.Sprintf("Exactly one <fuzz test> argument must be provided, got %d", lenFuzzTestArgs)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+
+			err := validateOutput(outputDir, inPlace)
+			if err != nil {
+				return err
+			}
+
+			err = config.FindAndParseProjectConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath && !opts.NoResolveSourcePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
+			if err != nil {
+				return err
+			}
+			opts.FuzzTest = fuzzTests[0]
+			opts.ArgsToPass = argsToPass
+
+			opts.BuildStdout = cmd.OutOrStdout()
+			opts.BuildStderr = cmd.OutOrStderr()
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.OutOrStderr()
+
+			err = opts.Validate()
+			if err != nil {
+				return err
+			}
+
+			err = checkBuildSystem(opts.BuildSystem)
+			if err != nil {
+				return err
+			}
+			if opts.Engine == cmdutils.EngineAFL {
+				msg := "Minimizing the corpus is not supported for the engine \"afl\""
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+
+			if logging.ShouldLogBuildToFile() {
+				opts.BuildStdout, err = logging.BuildOutputToFile(opts.OutputRoot, []string{opts.FuzzTest})
+				if err != nil {
+					return err
+				}
+				opts.BuildStderr = opts.BuildStdout
+			}
+
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := minimizeCmd{Command: c, opts: opts, outputDir: outputDir, inPlace: inPlace}
+			return cmd.run()
+		},
+	}
+
+	// Note: If a flag should be configurable via cifuzz.yaml as well,
+	// bind it to viper in the PreRunE function.
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddBuildCommandFlag,
+		cmdutils.AddBuildEnvFlag,
+		cmdutils.AddBuildJobsFlag,
+		cmdutils.AddCleanCommandFlag,
+		cmdutils.AddEngineArgFlag,
+		cmdutils.AddNoResolveSourcePathFlag,
+		cmdutils.AddProjectDirFlag,
+		cmdutils.AddResolveSourceFileFlag,
+		cmdutils.AddSanitizersFlag,
+		cmdutils.AddSeedCorpusFlag,
+		cmdutils.AddUseSandboxFlag,
+	)
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "",
+		"Directory to which the minimized inputs are written.")
+	cmd.Flags().BoolVar(&inPlace, "in-place", false,
+		"Replace the seed corpus of the fuzz test by the minimized inputs.")
+
+	return cmd
+}
+
+func (c *minimizeCmd) run() error {
+	runAdapter, err := adapter.NewAdapter(c.opts)
+	if err != nil {
+		return err
+	}
+	defer runAdapter.Cleanup()
+
+	_, err = runAdapter.CheckDependencies(c.opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "cifuzz-minimize-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tmpDir)
+
+	opts := *c.opts
+	opts.MergeCorpusDir = filepath.Join(tmpDir, "corpus")
+	opts.Timeout = 0
+
+	reportHandler, err := runAdapter.Run(&opts)
+	if err != nil {
+		return err
+	}
+
+	// The adapter adds the seed corpus of the fuzz test to the seed
+	// corpus dirs, so these are all inputs which were merged
+	numInputs, err := countInputs(opts.SeedCorpusDirs)
+	if err != nil {
+		return err
+	}
+
+	// libFuzzer skips the inputs which crash the fuzz test when
+	// merging, so we add them to the minimized inputs
+	numCrashing, err := addCrashingInputs(opts.MergeCorpusDir, reportHandler.Findings)
+	if err != nil {
+		return err
+	}
+	if numCrashing > 0 {
+		log.Warnf("Kept %d inputs which crash the fuzz test", numCrashing)
+	}
+
+	outputDir := c.outputDir
+	var numMinimized int
+	if c.inPlace {
+		outputDir = reportHandler.ManagedSeedCorpusDir
+		if outputDir == "" {
+			return errors.New("Failed to determine the seed corpus of the fuzz test, please specify --output")
+		}
+		numMinimized, err = replaceCorpus(opts.MergeCorpusDir, outputDir)
+	} else {
+		numMinimized, err = cmdutils.ExportCorpus(opts.MergeCorpusDir, outputDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	numRemoved := 0
+	if numInputs > numMinimized {
+		numRemoved = numInputs - numMinimized
+	}
+	log.Successf("Minimized the corpus from %d to %d inputs (removed %d) in %s",
+		numInputs, numMinimized, numRemoved, fileutil.PrettifyPath(outputDir))
+	return nil
+}
+
+// validateOutput returns an error unless exactly one of the output
+// directory and in-place mode was specified.
+func validateOutput(outputDir string, inPlace bool) error {
+	if outputDir == "" && !inPlace {
+		msg := "Either --output or --in-place must be specified"
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	if outputDir != "" && inPlace {
+		msg := "Flags --output and --in-place can't be used together"
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	return nil
+}
+
+// addCrashingInputs writes the inputs of the findings to the corpus
+// directory, named by the SHA1 of their content like libFuzzer names
+// the inputs. It returns the number of inputs which were added.
+func addCrashingInputs(corpusDir string, findings []*finding.Finding) (int, error) {
+	var numAdded int
+	for _, f := range findings {
+		if len(f.InputData) == 0 {
+			continue
+		}
+		path := filepath.Join(corpusDir, fmt.Sprintf("%x", sha1.Sum(f.InputData)))
+		exists, err := fileutil.Exists(path)
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			continue
+		}
+		err = os.WriteFile(path, f.InputData, 0o644)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		numAdded++
+	}
+	return numAdded, nil
+}
+
+// replaceCorpus replaces the inputs of the seed corpus directory by the
+// inputs of the corpus directory. The inputs are first exported to a
+// new directory next to the seed corpus, which then replaces it, so that
+// the seed corpus is kept if exporting the inputs fails. It returns the
+// number of inputs of the new seed corpus.
+func replaceCorpus(corpusDir string, seedCorpusDir string) (int, error) {
+	parentDir := filepath.Dir(seedCorpusDir)
+	err := os.MkdirAll(parentDir, 0o755)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	tmpDir, err := os.MkdirTemp(parentDir, ".cifuzz-minimize-")
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tmpDir)
+
+	newDir := filepath.Join(tmpDir, "new")
+	numInputs, err := cmdutils.ExportCorpus(corpusDir, newDir)
+	if err != nil {
+		return 0, err
+	}
+
+	exists, err := fileutil.Exists(seedCorpusDir)
+	if err != nil {
+		return 0, err
+	}
+	oldDir := filepath.Join(tmpDir, "old")
+	if exists {
+		// Move the old seed corpus into the temporary directory, which
+		// is removed afterwards
+		err = os.Rename(seedCorpusDir, oldDir)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	err = os.Rename(newDir, seedCorpusDir)
+	if err != nil {
+		if exists {
+			// Restore the old seed corpus
+			_ = os.Rename(oldDir, seedCorpusDir)
+		}
+		return 0, errors.WithStack(err)
+	}
+	return numInputs, nil
+}
+
+// checkBuildSystem returns an error if minimizing the corpus is not
+// supported for the build system.
+func checkBuildSystem(buildSystem string) error {
+	if buildSystem == config.BuildSystemNodeJS {
+		msg := fmt.Sprintf("Minimizing the corpus is not supported for build system type \"%s\"", buildSystem)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	return nil
+}
+
+// countInputs returns the number of non-empty inputs in the corpus
+// directories, because libFuzzer ignores empty inputs.
+func countInputs(dirs []string) (int, error) {
+	var numInputs int
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Size() != 0 {
+				numInputs++
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	return numInputs, nil
+}
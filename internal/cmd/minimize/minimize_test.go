@@ -0,0 +1,79 @@
+package minimize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+func TestValidateOutput(t *testing.T) {
+	require.Error(t, validateOutput("", false))
+	require.Error(t, validateOutput("minimized", true))
+	require.NoError(t, validateOutput("minimized", false))
+	require.NoError(t, validateOutput("", true))
+}
+
+func TestAddCrashingInputs(t *testing.T) {
+	corpusDir := t.TempDir()
+	findings := []*finding.Finding{
+		{Name: "crash", InputData: []byte("crash")},
+		{Name: "same_input", InputData: []byte("crash")},
+		{Name: "no_input"},
+	}
+
+	numAdded, err := addCrashingInputs(corpusDir, findings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, numAdded)
+
+	// The input is named by the SHA1 of its content
+	content, err := os.ReadFile(filepath.Join(corpusDir, "2fc7f1452374b6e341d67717f032abbe0da0f4a6"))
+	require.NoError(t, err)
+	assert.Equal(t, "crash", string(content))
+}
+
+func TestReplaceCorpus(t *testing.T) {
+	testDir := t.TempDir()
+	corpusDir := filepath.Join(testDir, "merged")
+	require.NoError(t, os.MkdirAll(corpusDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(corpusDir, "minimized"), []byte("minimized"), 0o644))
+
+	seedCorpusDir := filepath.Join(testDir, "my_fuzz_test_inputs")
+	require.NoError(t, os.MkdirAll(seedCorpusDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "seed1"), []byte("seed1"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "seed2"), []byte("seed2"), 0o644))
+
+	numInputs, err := replaceCorpus(corpusDir, seedCorpusDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, numInputs)
+
+	entries, err := os.ReadDir(seedCorpusDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "minimized", entries[0].Name())
+
+	// The temporary directory is removed
+	entries, err = os.ReadDir(testDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestReplaceCorpus_KeepsSeedCorpusOnError(t *testing.T) {
+	testDir := t.TempDir()
+	seedCorpusDir := filepath.Join(testDir, "my_fuzz_test_inputs")
+	require.NoError(t, os.MkdirAll(seedCorpusDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(seedCorpusDir, "seed"), []byte("seed"), 0o644))
+
+	// The corpus can't be read, because it's a file
+	corpusFile := filepath.Join(testDir, "merged")
+	require.NoError(t, os.WriteFile(corpusFile, nil, 0o000))
+	corpusDir := filepath.Join(corpusFile, "sub")
+
+	_, err := replaceCorpus(corpusDir, seedCorpusDir)
+	require.Error(t, err)
+	assert.FileExists(t, filepath.Join(seedCorpusDir, "seed"))
+}
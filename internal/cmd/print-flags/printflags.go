@@ -6,23 +6,15 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/other"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
 	"code-intelligence.com/cifuzz/util/envutil"
 )
 
 func New() []*cobra.Command {
-	buildFlags := []string{
-		"CFLAGS",
-		"CXXFLAGS",
-		"LDFLAGS",
-		other.EnvFuzzTestCFlags,
-		other.EnvFuzzTestCXXFlags,
-		other.EnvFuzzTestLDFlags,
-	}
-
 	var commands []*cobra.Command
-	for _, flags := range buildFlags {
+	for _, flags := range other.BuildFlagsEnvVars {
 		commands = append(commands, addCommand(flags))
 	}
 
@@ -44,7 +36,7 @@ func addCommand(flags string) *cobra.Command {
 					return err
 				}
 			} else {
-				env, err = other.SetLibFuzzerEnv(env, runfiles.Finder)
+				env, err = other.SetLibFuzzerEnv(env, runfiles.Finder, build.LibFuzzerSanitizers)
 				if err != nil {
 					return err
 				}
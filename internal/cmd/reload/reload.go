@@ -111,7 +111,7 @@ func (c *reloadCmd) checkDependencies() error {
 			deps = append(deps, dependencies.VisualStudio)
 		}
 	}
-	err := dependencies.Check(deps, c.opts.ProjectDir)
+	_, err := dependencies.Check(deps, c.opts.ProjectDir)
 	if err != nil {
 		return err
 	}
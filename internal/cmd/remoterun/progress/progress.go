@@ -9,6 +9,8 @@ import (
 
 	"github.com/mitchellh/ioprogress"
 	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/log"
 )
 
 func NewReader(reader io.Reader, size int64, successMessage string) *ioprogress.Reader {
@@ -43,3 +45,60 @@ func DrawProgressBar(w io.Writer, drawFormatBar ioprogress.DrawTextFormatFunc, s
 		return errors.WithStack(err)
 	}
 }
+
+// Counter shows the progress of processing a known number of items,
+// e.g. files which are copied.
+type Counter struct {
+	total    int64
+	count    int64
+	draw     ioprogress.DrawFunc
+	lastDraw time.Time
+}
+
+// NewCounter returns a Counter which draws a progress bar with the
+// number of processed items. If a spinner is currently shown, the
+// progress is shown in the spinner text instead, so that the two don't
+// overwrite each other.
+func NewCounter(total int64, itemName string, successMessage string) *Counter {
+	drawFormatBar := ioprogress.DrawTextFormatBar(40)
+	drawFormat := func(progress, total int64) string {
+		return fmt.Sprintf("%s %d/%d %s", drawFormatBar(progress, total), progress, total, itemName)
+	}
+
+	var draw ioprogress.DrawFunc
+	if log.SpinnerPrinterActive() {
+		draw = drawSpinnerText(drawFormat, successMessage)
+	} else {
+		draw = DrawProgressBar(os.Stdout, drawFormat, successMessage)
+	}
+	return &Counter{total: total, draw: draw}
+}
+
+// Increment increases the number of processed items by one and redraws
+// the progress bar if the last draw is long enough ago.
+func (c *Counter) Increment() error {
+	c.count++
+	if c.count < c.total && time.Since(c.lastDraw) < 100*time.Millisecond {
+		return nil
+	}
+	c.lastDraw = time.Now()
+	return c.draw(c.count, c.total)
+}
+
+// Finish clears the progress bar and prints the success message.
+func (c *Counter) Finish() error {
+	return c.draw(-1, -1)
+}
+
+func drawSpinnerText(drawFormat ioprogress.DrawTextFormatFunc, successMessage string) ioprogress.DrawFunc {
+	prefix := log.SpinnerPrinterText()
+	return func(progress, total int64) error {
+		if progress == -1 && total == -1 {
+			log.UpdateCurrentSpinnerPrinter(prefix)
+			log.Info(successMessage)
+			return nil
+		}
+		log.UpdateCurrentSpinnerPrinter(prefix + " " + drawFormat(progress, total))
+		return nil
+	}
+}
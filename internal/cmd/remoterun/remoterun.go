@@ -13,6 +13,7 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/api"
 	"code-intelligence.com/cifuzz/internal/bundler"
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	"code-intelligence.com/cifuzz/internal/cmd/bundle"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/cmdutils/auth"
@@ -32,6 +33,11 @@ type remoteRunOpts struct {
 	PrintJSON    bool   `mapstructure:"print-json"`
 	ProjectName  string `mapstructure:"project"`
 	Server       string `mapstructure:"server"`
+	Proxy        string `mapstructure:"proxy"`
+
+	// The number of times the bundle upload is retried on connection
+	// errors and transient server errors
+	UploadRetries uint `mapstructure:"upload-retries"`
 
 	// Fields which are not configurable via viper (i.e. via cifuzz.yaml
 	// and CIFUZZ_* environment variables), by setting
@@ -46,6 +52,11 @@ func (opts *remoteRunOpts) Validate() error {
 		return err
 	}
 
+	err = api.ValidateProxyURL(opts.Proxy)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
 	if opts.BuildSystem == config.BuildSystemNodeJS && !config.AllowUnsupportedPlatforms() {
 		return errors.Errorf(config.NotSupportedErrorMessage("remote run", opts.BuildSystem))
 	}
@@ -57,6 +68,23 @@ func (opts *remoteRunOpts) Validate() error {
 		if err != nil {
 			return err
 		}
+	} else {
+		if strings.HasSuffix(opts.BundlePath, archive.SplitManifestSuffix) {
+			msg := "Split bundles can't be used for remote runs, create the bundle without --split-size"
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+
+		// CI Sense only supports gzip-compressed bundles, so we fail
+		// before uploading a bundle which it can't extract
+		compression, err := archive.DetectCompression(opts.BundlePath)
+		if err != nil {
+			return err
+		}
+		if compression != archive.CompressionGzip {
+			msg := fmt.Sprintf("Bundle %s is not gzip-compressed (compression: %s). Only gzip-compressed bundles\n"+
+				"can be used for remote runs, create the bundle with --compression=gzip.", opts.BundlePath, compression)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
 	}
 
 	if opts.Interactive {
@@ -124,6 +152,7 @@ variable or by running 'cifuzz login' first.
 			}
 
 			cmdutils.ViperMustBindPFlag("bundle", cmd.Flags().Lookup("bundle"))
+			cmdutils.ViperMustBindPFlag("upload-retries", cmd.Flags().Lookup("upload-retries"))
 			err = config.FindAndParseProjectConfig(opts)
 			if err != nil {
 				return err
@@ -136,7 +165,7 @@ variable or by running 'cifuzz login' first.
 			}
 
 			var fuzzTests []string
-			fuzzTests, err = resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir)
+			fuzzTests, err = resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
 			if err != nil {
 				return err
 			}
@@ -175,6 +204,8 @@ variable or by running 'cifuzz login' first.
 		RunE: func(c *cobra.Command, args []string) error {
 			cmd := runRemoteCmd{Command: c, opts: opts}
 			cmd.apiClient = api.NewClient(opts.Server)
+			cmd.apiClient.Retries = opts.UploadRetries
+			cmd.apiClient.Proxy = opts.Proxy
 			return cmd.run()
 		},
 	}
@@ -193,18 +224,22 @@ variable or by running 'cifuzz login' first.
 		cmdutils.AddPrintJSONFlag,
 		cmdutils.AddProjectDirFlag,
 		cmdutils.AddProjectFlag,
+		cmdutils.AddProxyFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
 		cmdutils.AddTimeoutFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	)
 	cmd.Flags().StringVar(&opts.BundlePath, "bundle", "", "Path of an existing bundle to start a remote run with.")
+	cmd.Flags().Uint("upload-retries", api.DefaultRetries,
+		"Number of times the upload of the bundle and other idempotent API requests are\n"+
+			"retried on connection errors and transient server errors (502, 503, 504).")
 
 	return cmd
 }
 
 func (c *runRemoteCmd) run() error {
-	token, err := auth.EnsureValidToken(c.opts.Server)
+	token, err := auth.EnsureValidToken(c.apiClient)
 	if err != nil {
 		return err
 	}
@@ -263,9 +298,13 @@ func (c *runRemoteCmd) run() error {
 		buildPrinterOutput := os.Stdout
 		if c.opts.PrintJSON {
 			buildPrinterOutput = os.Stderr
+			c.opts.DependenciesJSONOutput = os.Stdout
 		}
 		buildPrinter := logging.NewBuildPrinter(buildPrinterOutput, log.BundleInProgressMsg)
 
+		if !c.opts.PrintJSON && term.IsTerminal(int(os.Stdout.Fd())) {
+			c.opts.NewProgressCounter = bundle.NewProgressCounter
+		}
 		b := bundler.New(&c.opts.Opts)
 		_, err = b.Bundle()
 		if err != nil {
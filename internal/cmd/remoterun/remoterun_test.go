@@ -0,0 +1,35 @@
+package remoterun
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/bundler/archive"
+	"code-intelligence.com/cifuzz/internal/config"
+)
+
+func TestValidate_BundleCompression(t *testing.T) {
+	for _, compression := range archive.Compressions {
+		t.Run(compression, func(t *testing.T) {
+			bundlePath := filepath.Join(t.TempDir(), "bundle"+archive.Extension(compression))
+			f, err := os.Create(bundlePath)
+			require.NoError(t, err)
+			w, err := archive.NewTarArchiveWriterWithCompression(f, compression)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			require.NoError(t, f.Close())
+
+			opts := &remoteRunOpts{BundlePath: bundlePath}
+			opts.BuildSystem = config.BuildSystemCMake
+			err = opts.Validate()
+			if compression == archive.CompressionGzip {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, "not gzip-compressed")
+			}
+		})
+	}
+}
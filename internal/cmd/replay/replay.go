@@ -0,0 +1,199 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/cmdutils/logging"
+	"code-intelligence.com/cifuzz/internal/cmdutils/resolve"
+	"code-intelligence.com/cifuzz/internal/completion"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/replay"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+type replayCmd struct {
+	*cobra.Command
+	opts *adapter.RunOptions
+}
+
+// replayResult is the result of replaying the crashing input of a
+// single finding
+type replayResult struct {
+	finding    *finding.Finding
+	reproduces bool
+}
+
+func New() *cobra.Command {
+	opts := &adapter.RunOptions{}
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "replay [flags] <fuzz test> [--] [<build system arg>...]",
+		Short: "Check if the findings of a fuzz test still reproduce",
+		Long: `This command builds the fuzz test once and executes it with the crashing
+input of each finding of the fuzz test stored in .cifuzz-findings. It
+reports for each finding whether it still reproduces and exits with a
+non-zero exit code if any of them does.
+
+In contrast to running the fuzz test on its whole corpus, only the
+inputs of the recorded findings are executed, which makes this a quick
+check that known bugs are still fixed.
+
+Only supported for CMake, Bazel and build system type 'other'.`,
+		Example:           "cifuzz replay my_fuzz_test",
+		ValidArgsFunction: completion.ValidFuzzTests,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Bind viper keys to flags. We can't do this in the New
+			// function, because that would re-bind viper keys which
+			// were bound to the flags of other commands before.
+			bindFlags()
+
+			var lenFuzzTestArgs int
+			var argsToPass []string
+			if cmd.ArgsLenAtDash() != -1 {
+				lenFuzzTestArgs = cmd.ArgsLenAtDash()
+				argsToPass = args[cmd.ArgsLenAtDash():]
+				args = args[:cmd.ArgsLenAtDash()]
+			} else {
+				lenFuzzTestArgs = len(args)
+			}
+			if lenFuzzTestArgs != 1 {
+				msg := fmt.Sprintf("Exactly one <fuzz test> argument must be provided, got %d", lenFuzzTestArgs)
+				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+			}
+
+			err := config.FindAndParseProjectConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath && !opts.NoResolveSourcePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
+			if err != nil {
+				return err
+			}
+			opts.FuzzTest = fuzzTests[0]
+			opts.ArgsToPass = argsToPass
+
+			opts.BuildStdout = cmd.OutOrStdout()
+			opts.BuildStderr = cmd.OutOrStderr()
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.OutOrStderr()
+
+			err = opts.Validate()
+			if err != nil {
+				return err
+			}
+
+			err = replay.CheckBuildSystem(opts.BuildSystem)
+			if err != nil {
+				return err
+			}
+
+			if logging.ShouldLogBuildToFile() {
+				opts.BuildStdout, err = logging.BuildOutputToFile(opts.OutputRoot, []string{opts.FuzzTest})
+				if err != nil {
+					return err
+				}
+				opts.BuildStderr = opts.BuildStdout
+			}
+
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := replayCmd{Command: c, opts: opts}
+			return cmd.run()
+		},
+	}
+
+	// Note: If a flag should be configurable via cifuzz.yaml as well,
+	// bind it to viper in the PreRunE function.
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddBuildCommandFlag,
+		cmdutils.AddBuildEnvFlag,
+		cmdutils.AddBuildJobsFlag,
+		cmdutils.AddCleanCommandFlag,
+		cmdutils.AddNoResolveSourcePathFlag,
+		cmdutils.AddProjectDirFlag,
+		cmdutils.AddResolveSourceFileFlag,
+		cmdutils.AddSanitizersFlag,
+		cmdutils.AddSymbolizerFlag,
+		cmdutils.AddUseSandboxFlag,
+	)
+
+	return cmd
+}
+
+func (c *replayCmd) run() error {
+	allFindings, err := finding.LocalFindings(c.opts.ProjectDir, nil)
+	if err != nil {
+		return err
+	}
+	var findings []*finding.Finding
+	for _, f := range allFindings {
+		if f.FuzzTest == c.opts.FuzzTest {
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) == 0 {
+		log.Infof("There are no findings of %s to replay", c.opts.FuzzTest)
+		return nil
+	}
+
+	replayer, err := replay.NewReplayer(c.opts)
+	if err != nil {
+		return err
+	}
+	defer replayer.Cleanup()
+
+	var results []*replayResult
+	for _, f := range findings {
+		log.Infof("Replaying finding %s", pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(f.Name))
+		reproduces, err := replayer.Reproduces(f)
+		if errors.Is(err, replay.ErrMissingInput) {
+			log.Warnf("Skipping finding %s, its crashing input %s does not exist", f.Name, f.InputFile)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		results = append(results, &replayResult{finding: f, reproduces: reproduces})
+	}
+	if len(results) == 0 {
+		log.Warnf("None of the findings of %s could be replayed", c.opts.FuzzTest)
+		return nil
+	}
+
+	return printResults(results)
+}
+
+func printResults(results []*replayResult) error {
+	data := [][]string{{"Finding", "Description", "Status"}}
+	var reproduced []string
+	for _, r := range results {
+		status := pterm.Green("fixed")
+		if r.reproduces {
+			status = pterm.Red("reproduces")
+			reproduced = append(reproduced, r.finding.Name)
+		}
+		data = append(data, []string{r.finding.Name, r.finding.ShortDescriptionColumns()[0], status})
+	}
+	err := pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(reproduced) > 0 {
+		return errors.Errorf("%d of %d findings still reproduce: %s",
+			len(reproduced), len(results), strings.Join(reproduced, ", "))
+	}
+	log.Successf("None of the %d findings reproduce", len(results))
+	return nil
+}
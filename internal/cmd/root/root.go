@@ -22,10 +22,13 @@ import (
 	initCmd "code-intelligence.com/cifuzz/internal/cmd/init"
 	integrateCmd "code-intelligence.com/cifuzz/internal/cmd/integrate"
 	loginCmd "code-intelligence.com/cifuzz/internal/cmd/login"
+	minimizeCmd "code-intelligence.com/cifuzz/internal/cmd/minimize"
 	printflagsCmds "code-intelligence.com/cifuzz/internal/cmd/print-flags"
 	reloadCmd "code-intelligence.com/cifuzz/internal/cmd/reload"
 	remoteRunCmd "code-intelligence.com/cifuzz/internal/cmd/remoterun"
+	replayCmd "code-intelligence.com/cifuzz/internal/cmd/replay"
 	runCmd "code-intelligence.com/cifuzz/internal/cmd/run"
+	sourceMapCmd "code-intelligence.com/cifuzz/internal/cmd/source-map"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/version"
@@ -51,6 +54,11 @@ func New() (*cobra.Command, error) {
 				return err
 			}
 
+			err = cmdutils.SetTempDir()
+			if err != nil {
+				return err
+			}
+
 			if cmdutils.NeedsConfig(cmd) {
 				_, err = config.FindConfigDir()
 				if errors.Is(err, os.ErrNotExist) {
@@ -78,6 +86,17 @@ func New() (*cobra.Command, error) {
 		return nil, errors.WithStack(err)
 	}
 
+	rootCmd.PersistentFlags().String("temp-dir", "",
+		"Directory in which temporary files are created, e.g. by the fuzzer and the bundler.\n"+
+			"Can also be set via the CIFUZZ_TMPDIR environment variable.\n"+
+			"Defaults to the system temp directory.")
+	if err := viper.BindPFlag("temp-dir", rootCmd.PersistentFlags().Lookup("temp-dir")); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := viper.BindEnv("temp-dir", "CIFUZZ_TMPDIR"); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	rootCmd.PersistentFlags().Bool("no-notifications", false,
 		"Turn off desktop notifications")
 	if err := viper.BindPFlag("no-notifications", rootCmd.PersistentFlags().Lookup("no-notifications")); err != nil {
@@ -103,12 +122,15 @@ func New() (*cobra.Command, error) {
 	rootCmd.AddCommand(containerCmd.New())
 	rootCmd.AddCommand(createCmd.New())
 	rootCmd.AddCommand(runCmd.New())
+	rootCmd.AddCommand(replayCmd.New())
+	rootCmd.AddCommand(minimizeCmd.New())
 	rootCmd.AddCommand(remoteRunCmd.New())
 	rootCmd.AddCommand(reloadCmd.New())
 	rootCmd.AddCommand(bundleCmd.New())
 	rootCmd.AddCommand(coverageCmd.New())
 	rootCmd.AddCommand(findingCmd.New())
 	rootCmd.AddCommand(integrateCmd.New())
+	rootCmd.AddCommand(sourceMapCmd.New())
 
 	for _, cmd := range printflagsCmds.New() {
 		rootCmd.AddCommand(cmd)
@@ -138,6 +160,7 @@ func Execute() {
 		var couldBeSandboxError *cmdutils.CouldBeSandboxError
 		var signalErr *cmdutils.SignalError
 		var silentErr *cmdutils.SilentError
+		var compilationErr *cmdutils.CompilationError
 
 		if errors.As(err, &usageErr) ||
 			strings.HasPrefix(err.Error(), "unknown command") ||
@@ -166,9 +189,9 @@ func Execute() {
 		if !errors.As(err, &silentErr) {
 			// For any other errors that are not silent (= not expected)
 			// we want to print the error and their stack trace in
-			// verbose mode (except IncorrectUsageError which should only
-			// print the message)
-			if errors.As(err, &usageErr) {
+			// verbose mode (except IncorrectUsageError and
+			// CompilationError which should only print the message)
+			if errors.As(err, &usageErr) || errors.As(err, &compilationErr) {
 				log.ErrorMsg(err.Error())
 			} else {
 				log.Error(err)
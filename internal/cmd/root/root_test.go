@@ -73,3 +73,34 @@ func TestChangingToExistingDirectory(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, filepath.Join(origWorkDir, "foo"), workDir)
 }
+
+func TestSettingNonExistingTempDir(t *testing.T) {
+	testutil.ChdirToTempDir(t, "root-cmd-test-")
+	err := fileutil.Touch("CMakeLists.txt")
+	require.NoError(t, err)
+
+	cmd, err := New()
+	require.NoError(t, err)
+	_, _, err = cmdutils.ExecuteCommand(t, cmd, os.Stdin, "--temp-dir", "foo", "init")
+	require.Error(t, err)
+}
+
+func TestSettingTempDir(t *testing.T) {
+	testDir := testutil.ChdirToTempDir(t, "root-cmd-test-")
+	err := fileutil.Touch("CMakeLists.txt")
+	require.NoError(t, err)
+	tempDir := filepath.Join(testDir, "tmp")
+	err = os.Mkdir(tempDir, 0o700)
+	require.NoError(t, err)
+	t.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	t.Setenv("TMP", os.Getenv("TMP"))
+	t.Setenv("TEMP", os.Getenv("TEMP"))
+
+	cmd, err := New()
+	require.NoError(t, err)
+	_, _, err = cmdutils.ExecuteCommand(t, cmd, os.Stdin, "--temp-dir", tempDir, "init")
+	require.NoError(t, err)
+
+	// Check that temporary files are now created in the temp dir
+	require.Equal(t, tempDir, os.TempDir())
+}
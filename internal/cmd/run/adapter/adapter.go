@@ -5,10 +5,11 @@ import (
 
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
 )
 
 type Adapter interface {
-	CheckDependencies(string) error
+	CheckDependencies(string) ([]*dependencies.Status, error)
 	Run(*RunOptions) (*reporthandler.ReportHandler, error)
 	Cleanup()
 }
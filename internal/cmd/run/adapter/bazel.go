@@ -17,9 +17,10 @@ import (
 
 type BazelAdapter struct {
 	tempDir string
+	builds  buildCache[build.BuildResult]
 }
 
-func (r *BazelAdapter) CheckDependencies(projectDir string) error {
+func (r *BazelAdapter) CheckDependencies(projectDir string) ([]*dependencies.Status, error) {
 	// All dependencies are managed via bazel but it should be checked
 	// that the correct bazel version is installed
 	return dependencies.Check([]dependencies.Key{
@@ -29,14 +30,17 @@ func (r *BazelAdapter) CheckDependencies(projectDir string) error {
 
 func (r *BazelAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
 	// Create a temporary directory which the builder can use to create
-	// temporary files
+	// temporary files. It's shared by all runs of the adapter, because
+	// it contains the scripts of the cached builds (see build).
 	var err error
-	r.tempDir, err = os.MkdirTemp("", "cifuzz-run-")
-	if err != nil {
-		return nil, errors.WithStack(err)
+	if r.tempDir == "" {
+		r.tempDir, err = os.MkdirTemp("", "cifuzz-run-")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
 	}
 
-	buildResult, err := wrapBuild[build.BuildResult](opts, r.build)
+	buildResult, err := wrapCachedBuild(&r.builds, opts, r.build)
 	if err != nil {
 		return nil, err
 	}
@@ -85,15 +89,24 @@ func (r *BazelAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 		opts.FuzzTest += "_bin"
 	}
 
+	// Each build uses its own temporary directory, because the builder
+	// creates the script which runs the fuzz test there
+	tempDir, err := os.MkdirTemp(r.tempDir, "build-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	var builder *bazel.Builder
 	builder, err = bazel.NewBuilder(&bazel.BuilderOptions{
-		ProjectDir: opts.ProjectDir,
-		Args:       opts.ArgsToPass,
-		NumJobs:    opts.NumBuildJobs,
-		Stdout:     opts.BuildStdout,
-		Stderr:     opts.BuildStderr,
-		TempDir:    r.tempDir,
-		Verbose:    viper.GetBool("verbose"),
+		ProjectDir:  opts.ProjectDir,
+		Args:        opts.ArgsToPass,
+		NumJobs:     opts.NumBuildJobs,
+		Stdout:      opts.BuildStdout,
+		Stderr:      opts.BuildStderr,
+		TempDir:     tempDir,
+		Verbose:     viper.GetBool("verbose"),
+		BuildEnv:    opts.BuildEnv,
+		MemoryLimit: opts.BuildMemoryLimit,
 	})
 	if err != nil {
 		return nil, err
@@ -8,13 +8,15 @@ import (
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/cmake"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 )
 
 type CMakeAdapter struct {
+	builds buildCache[build.CBuildResult]
 }
 
-func (r *CMakeAdapter) CheckDependencies(projectDir string) error {
+func (r *CMakeAdapter) CheckDependencies(projectDir string) ([]*dependencies.Status, error) {
 	var deps []dependencies.Key
 	deps = []dependencies.Key{
 		dependencies.CMake,
@@ -31,7 +33,7 @@ func (r *CMakeAdapter) CheckDependencies(projectDir string) error {
 }
 
 func (r *CMakeAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
-	cBuildResult, err := wrapBuild[build.CBuildResult](opts, r.build)
+	cBuildResult, err := wrapCachedBuild(&r.builds, opts, r.build)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +52,11 @@ func (r *CMakeAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 		return nil, err
 	}
 
-	err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	if opts.Engine == cmdutils.EngineAFL {
+		err = runAFL(opts, cBuildResult.BuildResult, reportHandler)
+	} else {
+		err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -59,20 +65,21 @@ func (r *CMakeAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 }
 
 func (r *CMakeAdapter) build(opts *RunOptions) (*build.CBuildResult, error) {
-	sanitizers := []string{"address", "undefined"}
-
 	var builder *cmake.Builder
 	builder, err := cmake.NewBuilder(&cmake.BuilderOptions{
 		ProjectDir: opts.ProjectDir,
 		Args:       opts.ArgsToPass,
-		Sanitizers: sanitizers,
+		Sanitizers: opts.Sanitizers,
 		Parallel: cmake.ParallelOptions{
 			Enabled: viper.IsSet("build-jobs"),
 			NumJobs: opts.NumBuildJobs,
 		},
-		Stdout:    opts.BuildStdout,
-		Stderr:    opts.BuildStderr,
-		BuildOnly: opts.BuildOnly,
+		Stdout:      opts.BuildStdout,
+		Stderr:      opts.BuildStderr,
+		BuildOnly:   opts.BuildOnly,
+		BuildEnv:    opts.BuildEnv,
+		OutputRoot:  opts.OutputRoot,
+		MemoryLimit: opts.BuildMemoryLimit,
 	})
 	if err != nil {
 		return nil, err
@@ -18,7 +18,7 @@ import (
 type GradleAdapter struct {
 }
 
-func (r *GradleAdapter) CheckDependencies(projectDir string) error {
+func (r *GradleAdapter) CheckDependencies(projectDir string) ([]*dependencies.Status, error) {
 	return dependencies.Check([]dependencies.Key{
 		dependencies.Java,
 		dependencies.Gradle,
@@ -82,8 +82,10 @@ func (r *GradleAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 			Enabled: viper.IsSet("build-jobs"),
 			NumJobs: opts.NumBuildJobs,
 		},
-		Stdout: opts.BuildStdout,
-		Stderr: opts.BuildStderr,
+		Stdout:      opts.BuildStdout,
+		Stderr:      opts.BuildStderr,
+		MemoryLimit: opts.BuildMemoryLimit,
+		Offline:     opts.Offline,
 	})
 	if err != nil {
 		return nil, err
@@ -16,7 +16,7 @@ import (
 type MavenAdapter struct {
 }
 
-func (r *MavenAdapter) CheckDependencies(projectDir string) error {
+func (r *MavenAdapter) CheckDependencies(projectDir string) ([]*dependencies.Status, error) {
 	return dependencies.Check([]dependencies.Key{
 		dependencies.Java,
 		dependencies.Maven,
@@ -71,8 +71,10 @@ func (r *MavenAdapter) build(opts *RunOptions) (*build.BuildResult, error) {
 			Enabled: viper.IsSet("build-jobs"),
 			NumJobs: opts.NumBuildJobs,
 		},
-		Stdout: opts.BuildStdout,
-		Stderr: opts.BuildStderr,
+		Stdout:      opts.BuildStdout,
+		Stderr:      opts.BuildStderr,
+		MemoryLimit: opts.BuildMemoryLimit,
+		Offline:     opts.Offline,
 	})
 	if err != nil {
 		return nil, err
@@ -16,7 +16,7 @@ import (
 type NodeJSAdapter struct {
 }
 
-func (r *NodeJSAdapter) CheckDependencies(projectDir string) error {
+func (r *NodeJSAdapter) CheckDependencies(projectDir string) ([]*dependencies.Status, error) {
 	return dependencies.Check([]dependencies.Key{
 		dependencies.Node,
 	}, projectDir)
@@ -53,7 +53,9 @@ func (r *NodeJSAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, err
 			Verbose:        viper.GetBool("verbose"),
 		},
 	}
-	err = ExecuteFuzzerRunner(jazzerjs.NewRunner(runnerOpts))
+	err = executeWithInfraRetries(opts, reportHandler, func() FuzzerRunner {
+		return jazzerjs.NewRunner(runnerOpts)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -4,12 +4,30 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 
+	"code-intelligence.com/cifuzz/internal/api"
+	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/names"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/options"
+	fuzzer_runner "code-intelligence.com/cifuzz/pkg/runner"
+	"code-intelligence.com/cifuzz/util/stringutil"
+)
+
+// The defaults of the CI output preset, which are used unless the
+// corresponding flags are set explicitly
+const (
+	CISummaryFile     = "cifuzz-summary.json"
+	CIMetricsInterval = 30 * time.Second
 )
 
 type RunOptions struct {
@@ -17,24 +35,112 @@ type RunOptions struct {
 	BuildCommand          string        `mapstructure:"build-command"`
 	CleanCommand          string        `mapstructure:"clean-command"`
 	NumBuildJobs          uint          `mapstructure:"build-jobs"`
+	BuildMemoryLimit      uint          `mapstructure:"build-memory-limit"`
+	Offline               bool          `mapstructure:"offline"`
 	Dictionary            string        `mapstructure:"dict"`
+	NoDefaultDict         bool          `mapstructure:"no-default-dict"`
 	EngineArgs            []string      `mapstructure:"engine-args"`
 	SeedCorpusDirs        []string      `mapstructure:"seed-corpus-dirs"`
 	Timeout               time.Duration `mapstructure:"timeout"`
+	MaxTotalTime          time.Duration `mapstructure:"max-total-time"`
+	MaxRuns               uint          `mapstructure:"max-runs"`
+	UnitTimeout           time.Duration `mapstructure:"unit-timeout"`
+	RetryOnInfraFailure   uint          `mapstructure:"retry-on-infra-failure"`
 	Interactive           bool          `mapstructure:"interactive"`
 	Server                string        `mapstructure:"server"`
+	Proxy                 string        `mapstructure:"proxy"`
 	Project               string        `mapstructure:"project"`
 	UseSandbox            bool          `mapstructure:"use-sandbox"`
 	PrintJSON             bool          `mapstructure:"print-json"`
+	DisplayName           string        `mapstructure:"display-name"`
+	JSONFlush             bool          `mapstructure:"json-flush"`
 	BuildOnly             bool          `mapstructure:"build-only"`
+	FindingWebhook        string        `mapstructure:"finding-webhook"`
+	WebhookHeaders        []string      `mapstructure:"webhook-headers"`
+	NoResolveSourcePath   bool          `mapstructure:"no-resolve-source-path"`
+	StripPaths            bool          `mapstructure:"strip-paths"`
+	StripPathPrefix       string        `mapstructure:"strip-path-prefix"`
+	Symbolizer            string        `mapstructure:"symbolizer"`
+	BuildEnv              []string      `mapstructure:"build-env"`
+	PrintCommand          bool          `mapstructure:"print-command"`
+	PrintFlags            bool          `mapstructure:"print-flags"`
+	RefreshErrorDetails   bool          `mapstructure:"refresh-error-details"`
+	Sanitizers            []string      `mapstructure:"sanitizers"`
+	OutputRoot            string        `mapstructure:"output-root"`
+	ClassPaths            []string      `mapstructure:"classpath"`
+	NativeLibPaths        []string      `mapstructure:"native-lib-path"`
+	RequireSeeds          bool          `mapstructure:"require-seeds"`
+	WarnOversizedSeeds    bool          `mapstructure:"warn-oversized-seeds"`
 	ResolveSourceFilePath bool
 
+	SeverityOverrides map[string]string `mapstructure:"severity-overrides"`
+	FindingNameStyle  string            `mapstructure:"finding-name-style"`
+	FindingJSONIndent string            `mapstructure:"finding-json-indent"`
+	ASanODRViolation  string            `mapstructure:"asan-odr-violation"`
+	FailOn            []string          `mapstructure:"fail-on"`
+	Engine            string            `mapstructure:"engine"`
+	RunnerBinary      string            `mapstructure:"runner-binary"`
+
+	// If set, the fuzz test is executed once with this input file
+	// instead of fuzzing
+	Reproduce string `mapstructure:"reproduce"`
+
+	// If set, the final metrics of the run are appended as a JSON line
+	// to this file
+	MetricsHistoryFile string `mapstructure:"metrics-history-file"`
+
+	// If set, each metric reported during the run is appended as a
+	// JSON line to this file
+	MetricsFile string `mapstructure:"metrics-file"`
+
+	// If set, the final metrics of the run are written to this file in
+	// the Prometheus text exposition format
+	PrometheusOutput string `mapstructure:"prometheus-output"`
+
+	// If set, the fuzzer is stopped when no new coverage was found for
+	// this duration
+	StopOnPlateau time.Duration `mapstructure:"stop-on-plateau"`
+
+	// If true, the coverage metadata of the entries added to the
+	// generated corpus is written to the corpus index file
+	CorpusIndex bool `mapstructure:"corpus-index"`
+
+	// If true, all fuzz tests of the JVM project are run one after
+	// another
+	All bool `mapstructure:"all"`
+
+	// CI enables the CI output preset, see ApplyCIPreset
+	CI              bool          `mapstructure:"ci"`
+	SummaryFile     string        `mapstructure:"summary-file"`
+	MetricsInterval time.Duration `mapstructure:"metrics-interval"`
+
+	// If true, the corpus inputs are staged into a temporary directory
+	// with names which enforce a stable order before fuzzing
+	DeterministicCorpusOrder bool `mapstructure:"deterministic-corpus-order"`
+
+	// If set, the inputs of the generated corpus are copied to this
+	// directory after the fuzzing run finished
+	CorpusOutput string `mapstructure:"corpus-output"`
+
 	ProjectDir      string
 	FuzzTest        string
 	TargetMethod    string
 	TestNamePattern string
 	ArgsToPass      []string
 
+	// Replay is set by 'cifuzz replay' to execute the inputs in the
+	// seed corpus dirs once instead of fuzzing. The default seed corpus
+	// is not used, findings are not saved and the generated corpus is
+	// stored in ReplayCorpusDir.
+	Replay          bool
+	ReplayCorpusDir string
+
+	// MergeCorpusDir is set by 'cifuzz minimize' to merge the inputs of
+	// the seed corpus dirs and the default seed corpus into this
+	// directory via libFuzzer's -merge=1 instead of fuzzing. Findings
+	// are not saved.
+	MergeCorpusDir string
+
 	BuildStdout io.Writer
 	BuildStderr io.Writer
 
@@ -58,6 +164,18 @@ func (opts *RunOptions) Validate() error {
 		}
 	}
 
+	if opts.Symbolizer != "" {
+		opts.Symbolizer, err = cmdutils.ValidateSymbolizer(opts.Symbolizer)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = api.ValidateProxyURL(opts.Proxy)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
 	if opts.BuildSystem == "" {
 		opts.BuildSystem, err = config.DetermineBuildSystem(opts.ProjectDir)
 		if err != nil {
@@ -76,10 +194,333 @@ func (opts *RunOptions) Validate() error {
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 	}
 
+	if opts.MaxTotalTime != 0 {
+		if opts.Timeout != 0 {
+			msg := "Flags \"timeout\" and \"max-total-time\" can't be used together"
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.MaxTotalTime < time.Second {
+			msg := fmt.Sprintf("invalid argument %q for \"--max-total-time\" flag: time can't be less than a second", opts.MaxTotalTime)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.Timeout = opts.MaxTotalTime
+	}
+
 	if opts.Timeout != 0 && opts.Timeout < time.Second {
 		msg := fmt.Sprintf("invalid argument %q for \"--timeout\" flag: timeout can't be less than a second", opts.Timeout)
 		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 	}
 
+	if opts.PrintFlags && opts.BuildSystem != config.BuildSystemOther {
+		msg := "Flag \"print-flags\" is only applicable for build system type \"other\""
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if len(opts.BuildEnv) > 0 {
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemBazel {
+			msg := "Flag \"build-env\" is only applicable for build system types \"cmake\" and \"bazel\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		err = cmdutils.ValidateBuildEnv(opts.BuildEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(opts.Sanitizers) > 0 {
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemOther {
+			msg := "Flag \"sanitizers\" is only applicable for build system types \"cmake\" and \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		err = build.ValidateSanitizers(opts.Sanitizers)
+		if err != nil {
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+	} else {
+		opts.Sanitizers = build.LibFuzzerSanitizers
+	}
+
+	if opts.OutputRoot != "" {
+		if opts.BuildSystem != config.BuildSystemCMake && opts.BuildSystem != config.BuildSystemBazel &&
+			opts.BuildSystem != config.BuildSystemOther {
+			msg := "Flag \"output-root\" is only applicable for build system types \"cmake\", \"bazel\" and \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+	opts.OutputRoot, err = cmdutils.ValidateOutputRoot(opts.OutputRoot, opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	opts.StripPathPrefix, err = cmdutils.ValidateStripPathPrefix(opts.StripPathPrefix, opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.ClassPaths) > 0 {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := "Flag \"classpath\" is only applicable for build system types \"maven\" and \"gradle\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.ClassPaths, err = cmdutils.ValidateClassPaths(opts.ClassPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(opts.NativeLibPaths) > 0 {
+		if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+			msg := "Flag \"native-lib-path\" is only applicable for build system types \"maven\" and \"gradle\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.NativeLibPaths, err = cmdutils.ValidateNativeLibPaths(opts.NativeLibPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	opts.BuildMemoryLimit, err = build.ValidateMemoryLimit(opts.BuildMemoryLimit, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	if opts.Offline && opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+		msg := "Flag \"offline\" is only applicable for build system types \"maven\" and \"gradle\""
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.ASanODRViolation != "" {
+		if opts.BuildSystem != config.BuildSystemCMake &&
+			opts.BuildSystem != config.BuildSystemBazel &&
+			opts.BuildSystem != config.BuildSystemOther {
+			msg := "Flag \"asan-odr-violation\" is only applicable for build system types \"cmake\", \"bazel\" and \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if !stringutil.Contains([]string{"0", "1", "2"}, opts.ASanODRViolation) {
+			msg := fmt.Sprintf("invalid argument %q for \"--asan-odr-violation\" flag: must be 0, 1 or 2", opts.ASanODRViolation)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	err = cmdutils.ValidateEngine(opts.Engine, opts.BuildSystem)
+	if err != nil {
+		return err
+	}
+
+	if opts.RunnerBinary != "" {
+		if opts.BuildSystem != config.BuildSystemCMake &&
+			opts.BuildSystem != config.BuildSystemOther {
+			msg := "Flag \"runner-binary\" is only supported for build system types \"cmake\" and \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.Engine == cmdutils.EngineAFL {
+			msg := "Flag \"runner-binary\" can't be used with the engine \"afl\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.RunnerBinary, err = cmdutils.ValidateRunnerBinary(opts.RunnerBinary)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.DeterministicCorpusOrder {
+		if opts.BuildSystem != config.BuildSystemCMake &&
+			opts.BuildSystem != config.BuildSystemBazel &&
+			opts.BuildSystem != config.BuildSystemOther {
+			msg := "Flag \"deterministic-corpus-order\" is only supported for build system types \"cmake\", \"bazel\" and \"other\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.Engine == cmdutils.EngineAFL {
+			msg := "Flag \"deterministic-corpus-order\" can't be used with the engine \"afl\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	if opts.MaxRuns != 0 {
+		if opts.BuildSystem == config.BuildSystemNodeJS {
+			msg := "Flag \"max-runs\" is not supported for build system type \"nodejs\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.Engine == cmdutils.EngineAFL {
+			msg := "Flag \"max-runs\" can't be used with the engine \"afl\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	if opts.CorpusIndex {
+		if opts.BuildSystem == config.BuildSystemNodeJS {
+			msg := "Flag \"corpus-index\" is not supported for build system type \"nodejs\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.Engine == cmdutils.EngineAFL {
+			msg := "Flag \"corpus-index\" can't be used with the engine \"afl\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	if opts.UnitTimeout != 0 {
+		if opts.BuildSystem == config.BuildSystemNodeJS {
+			msg := "Flag \"unit-timeout\" is not supported for build system type \"nodejs\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.Engine == cmdutils.EngineAFL {
+			msg := "Flag \"unit-timeout\" can't be used with the engine \"afl\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		// libFuzzer only supports timeouts in whole seconds
+		if opts.UnitTimeout < time.Second {
+			msg := fmt.Sprintf("invalid argument %q for \"--unit-timeout\" flag: timeout must be at least 1s", opts.UnitTimeout)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
+	// The runner needs the exit codes to tell findings apart from
+	// unexpected exits of the fuzzer
+	for _, flag := range []string{options.LibFuzzerErrorExitCode, options.LibFuzzerTimeoutExitCode} {
+		_, err = options.ParseLibFuzzerExitCode(opts.EngineArgs, flag, 0)
+		if err != nil {
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+	}
+
+	if opts.Reproduce != "" {
+		if opts.BuildSystem == config.BuildSystemNodeJS {
+			msg := "Flag \"reproduce\" is not supported for build system type \"nodejs\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.Engine == cmdutils.EngineAFL {
+			msg := "Flag \"reproduce\" can't be used with the engine \"afl\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		if opts.DeterministicCorpusOrder {
+			msg := "Flags \"reproduce\" and \"deterministic-corpus-order\" can't be used together"
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		opts.Reproduce, err = cmdutils.ValidateReproduceInput(opts.Reproduce)
+		if err != nil {
+			return err
+		}
+		// The input is executed once, so a timeout is not needed
+		opts.Timeout = 0
+	}
+
+	if opts.JSONFlush && !opts.PrintJSON {
+		msg := "Flag \"json-flush\" can only be used together with \"json\""
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.RequireSeeds && opts.BuildSystem == config.BuildSystemNodeJS {
+		msg := "Flag \"require-seeds\" is not supported for build system type \"nodejs\""
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.WarnOversizedSeeds {
+		if opts.BuildSystem == config.BuildSystemNodeJS {
+			msg := "Flag \"warn-oversized-seeds\" is not supported for build system type \"nodejs\""
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+		_, err = options.ParseLibFuzzerMaxLen(opts.EngineArgs)
+		if err != nil {
+			return cmdutils.WrapIncorrectUsageError(err)
+		}
+	}
+
+	if opts.MetricsInterval < 0 {
+		msg := fmt.Sprintf("invalid argument %q for \"--metrics-interval\" flag: interval can't be negative", opts.MetricsInterval)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	if opts.StopOnPlateau < 0 {
+		msg := fmt.Sprintf("invalid argument %q for \"--stop-on-plateau\" flag: duration can't be negative", opts.StopOnPlateau)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+
+	_, err = finding.ParseSeverityOverrides(opts.SeverityOverrides)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
+	_, err = names.ParseStyle(opts.FindingNameStyle)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
+	_, err = finding.ParseJSONIndent(opts.FindingJSONIndent)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
+	err = finding.ValidateFindingKinds(opts.FailOn)
+	if err != nil {
+		return cmdutils.WrapIncorrectUsageError(err)
+	}
+
+	for _, header := range opts.WebhookHeaders {
+		if !strings.Contains(header, ":") {
+			msg := fmt.Sprintf("invalid argument %q for \"--webhook-header\" flag: expected format \"<name>: <value>\"", header)
+			return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+
 	return nil
 }
+
+// ApplyCIPreset enables the CI output preset if --ci was specified or,
+// unless --ci=false was specified, if the CI environment variable is
+// "true". The preset disables colors and the updating printer, prints
+// the metrics periodically and writes a JSON summary of the run. Flags
+// which were set explicitly take precedence over the preset.
+func (opts *RunOptions) ApplyCIPreset() {
+	if !viper.IsSet("ci") && os.Getenv("CI") == "true" {
+		opts.CI = true
+	}
+	if !opts.CI {
+		return
+	}
+
+	if !viper.IsSet("style") && !viper.IsSet("plain") {
+		viper.Set("plain", true)
+	}
+	if !viper.IsSet("summary-file") {
+		opts.SummaryFile = filepath.Join(opts.OutputRoot, CISummaryFile)
+	}
+	if !viper.IsSet("metrics-interval") {
+		opts.MetricsInterval = CIMetricsInterval
+	}
+}
+
+// libFuzzerEngineArgs returns the engine args passed to libFuzzer and
+// Jazzer, which include the -timeout and -timeout_exitcode flags if
+// --unit-timeout was specified, the -runs flag if --max-runs or
+// --reproduce was specified, the -merge flag if the corpus should be
+// merged instead of fuzzed and the -verbosity flag if --corpus-index
+// was specified.
+func (opts *RunOptions) libFuzzerEngineArgs() []string {
+	// The flags are added before the user-specified engine args, so
+	// that flags passed via --engine-arg take precedence
+	var args []string
+	if opts.UnitTimeout != 0 {
+		// Round up to whole seconds, because libFuzzer doesn't support
+		// fractions of seconds
+		seconds := int64((opts.UnitTimeout + time.Second - 1) / time.Second)
+		args = append(args, options.LibFuzzerTimeoutFlag(strconv.FormatInt(seconds, 10)))
+		// Set the exit code explicitly, because its default differs
+		// between libFuzzer versions. The runner also accepts an exit
+		// code passed via --engine-arg.
+		args = append(args, options.LibFuzzerTimeoutExitCodeFlag(strconv.Itoa(fuzzer_runner.LibFuzzerTimeoutExitCode)))
+	}
+	switch {
+	case opts.Reproduce != "":
+		args = append(args, options.LibFuzzerRunsFlag("0"))
+	case opts.MergeCorpusDir != "":
+		args = append(args, options.LibFuzzerMergeFlag("1"))
+	case opts.MaxRuns != 0:
+		args = append(args, options.LibFuzzerRunsFlag(strconv.FormatUint(uint64(opts.MaxRuns), 10)))
+	}
+	if opts.CorpusIndex {
+		// libFuzzer only reports the paths of the entries it adds to
+		// the generated corpus with verbosity 2
+		args = append(args, options.LibFuzzerVerbosityFlag("2"))
+	}
+	return append(args, opts.EngineArgs...)
+}
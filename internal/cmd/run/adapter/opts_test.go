@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibFuzzerEngineArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *RunOptions
+		want []string
+	}{
+		{
+			name: "no flags",
+			opts: &RunOptions{},
+			want: nil,
+		},
+		{
+			name: "engine args only",
+			opts: &RunOptions{EngineArgs: []string{"-seed=1"}},
+			want: []string{"-seed=1"},
+		},
+		{
+			name: "max runs",
+			opts: &RunOptions{MaxRuns: 100},
+			want: []string{"-runs=100"},
+		},
+		{
+			// The -runs flag is added before the engine args, so that
+			// a -runs flag passed via --engine-arg takes precedence
+			name: "max runs with engine args",
+			opts: &RunOptions{MaxRuns: 100, EngineArgs: []string{"-runs=5"}},
+			want: []string{"-runs=100", "-runs=5"},
+		},
+		{
+			// Reproducing only runs the input, regardless of --max-runs
+			name: "reproduce",
+			opts: &RunOptions{Reproduce: "crash-123", MaxRuns: 100},
+			want: []string{"-runs=0"},
+		},
+		{
+			name: "merge",
+			opts: &RunOptions{MergeCorpusDir: "corpus", MaxRuns: 100},
+			want: []string{"-merge=1"},
+		},
+		{
+			// The timeout is rounded up to whole seconds and the exit
+			// code is set explicitly
+			name: "unit timeout",
+			opts: &RunOptions{UnitTimeout: 1500 * time.Millisecond},
+			want: []string{"-timeout=2", "-timeout_exitcode=70"},
+		},
+		{
+			name: "unit timeout with exit code in engine args",
+			opts: &RunOptions{UnitTimeout: 10 * time.Second, MaxRuns: 100, EngineArgs: []string{"-timeout_exitcode=3"}},
+			want: []string{"-timeout=10", "-timeout_exitcode=70", "-runs=100", "-timeout_exitcode=3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.libFuzzerEngineArgs())
+		})
+	}
+}
@@ -7,14 +7,16 @@ import (
 	"code-intelligence.com/cifuzz/internal/build"
 	"code-intelligence.com/cifuzz/internal/build/other"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/log"
 )
 
 type OtherAdapter struct {
+	builds buildCache[build.CBuildResult]
 }
 
-func (r *OtherAdapter) CheckDependencies(projectDir string) error {
+func (r *OtherAdapter) CheckDependencies(projectDir string) ([]*dependencies.Status, error) {
 
 	var deps []dependencies.Key
 	switch runtime.GOOS {
@@ -32,7 +34,7 @@ func (r *OtherAdapter) CheckDependencies(projectDir string) error {
 }
 
 func (r *OtherAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, error) {
-	cBuildResult, err := wrapBuild[build.CBuildResult](opts, r.build)
+	cBuildResult, err := wrapCachedBuild(&r.builds, opts, r.build)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +53,11 @@ func (r *OtherAdapter) Run(opts *RunOptions) (*reporthandler.ReportHandler, erro
 		return nil, err
 	}
 
-	err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	if opts.Engine == cmdutils.EngineAFL {
+		err = runAFL(opts, cBuildResult.BuildResult, reportHandler)
+	} else {
+		err = runLibfuzzer(opts, cBuildResult.BuildResult, reportHandler)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -65,21 +71,27 @@ func (r *OtherAdapter) build(opts *RunOptions) (*build.CBuildResult, error) {
 			"These arguments are ignored: %s", strings.Join(opts.ArgsToPass, " "))
 	}
 
-	sanitizers := []string{"address", "undefined"}
-
 	var builder *other.Builder
 	builder, err := other.NewBuilder(&other.BuilderOptions{
 		ProjectDir:   opts.ProjectDir,
 		BuildCommand: opts.BuildCommand,
 		CleanCommand: opts.CleanCommand,
-		Sanitizers:   sanitizers,
+		Sanitizers:   opts.Sanitizers,
 		Stdout:       opts.BuildStdout,
 		Stderr:       opts.BuildStderr,
+		MemoryLimit:  opts.BuildMemoryLimit,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	buildFlags := strings.Join(builder.BuildFlags(), "\n")
+	if opts.PrintFlags {
+		log.Infof("Build flags:\n%s", buildFlags)
+	} else {
+		log.Debugf("Build flags:\n%s", buildFlags)
+	}
+
 	err = builder.Clean()
 	if err != nil {
 		return nil, err
@@ -21,6 +21,7 @@ import (
 	"code-intelligence.com/cifuzz/internal/ldd"
 	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/runner/afl"
 	"code-intelligence.com/cifuzz/pkg/runner/jazzer"
 	"code-intelligence.com/cifuzz/pkg/runner/libfuzzer"
 	"code-intelligence.com/cifuzz/util/fileutil"
@@ -32,6 +33,13 @@ type FuzzerRunner interface {
 }
 
 func ExecuteFuzzerRunner(runner FuzzerRunner) error {
+	return executeFuzzerRunnerUntil(runner, nil)
+}
+
+// executeFuzzerRunnerUntil executes the fuzzer runner like
+// ExecuteFuzzerRunner, but stops the fuzzer gracefully when the stop
+// channel is closed.
+func executeFuzzerRunnerUntil(runner FuzzerRunner, stop <-chan struct{}) error {
 	// Handle cleanup (terminating the fuzzer process) when receiving
 	// termination signals
 	signalHandlerCtx, cancelSignalHandler := context.WithCancel(context.Background())
@@ -40,10 +48,15 @@ func ExecuteFuzzerRunner(runner FuzzerRunner) error {
 	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	defer signal.Stop(sigs)
 	var signalErr error
+	var stopped bool
 	routines.Go(func() error {
 		select {
 		case <-routinesCtx.Done():
 			return nil
+		case <-stop:
+			stopped = true
+			runner.Cleanup(routinesCtx)
+			return nil
 		case s := <-sigs:
 			log.Warnf("Received %s", s.String())
 			signalErr = cmdutils.NewSignalError(s.(syscall.Signal))
@@ -69,6 +82,11 @@ func ExecuteFuzzerRunner(runner FuzzerRunner) error {
 	}
 
 	var execErr *cmdutils.ExecError
+	if stopped && errors.As(err, &execErr) {
+		// The fuzzer was terminated by us, so a non-zero exit code is
+		// expected
+		return nil
+	}
 	if errors.As(err, &execErr) {
 		// If the error is expected because libFuzzer might fail due to user
 		// configuration, we return the execErr directly
@@ -81,6 +99,48 @@ func ExecuteFuzzerRunner(runner FuzzerRunner) error {
 	return err
 }
 
+// executeWithInfraRetries executes the fuzzer runner created by
+// newRunner and executes it again, up to opts.RetryOnInfraFailure times,
+// if the fuzzer exited unexpectedly without reporting a finding. Such
+// failures are usually caused by the host (e.g. the OOM killer or
+// transient mount issues) and not by the fuzz test. A fresh runner is
+// created for each attempt.
+func executeWithInfraRetries(opts *RunOptions, reportHandler *reporthandler.ReportHandler, newRunner func() FuzzerRunner) (err error) {
+	defer func() {
+		closeErr := reportHandler.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	for attempt := uint(1); ; attempt++ {
+		numFindings := len(reportHandler.Findings)
+		err = executeFuzzerRunnerUntil(newRunner(), reportHandler.PlateauReached())
+		if err == nil || reportHandler.ReachedPlateau() || attempt > opts.RetryOnInfraFailure || !isInfraFailure(err) {
+			return err
+		}
+		if len(reportHandler.Findings) > numFindings {
+			// The fuzzer reported a finding before it failed, so this
+			// is not an infrastructure failure
+			return err
+		}
+		log.Warnf("The fuzzer exited unexpectedly without reporting a finding: %v\nRetrying (%d/%d)",
+			err, attempt, opts.RetryOnInfraFailure)
+	}
+}
+
+// isInfraFailure returns true if the error was caused by an unexpected
+// exit of the fuzzer process. Errors caused by signals received by
+// cifuzz itself are not considered infrastructure failures.
+func isInfraFailure(err error) bool {
+	var signalErr *cmdutils.SignalError
+	if errors.As(err, &signalErr) {
+		return false
+	}
+	var execErr *cmdutils.ExecError
+	return errors.As(err, &execErr)
+}
+
 func runLibfuzzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *reporthandler.ReportHandler) error {
 	var err error
 
@@ -103,13 +163,14 @@ func runLibfuzzer(opts *RunOptions, buildResult *build.BuildResult, reportHandle
 	if err != nil {
 		return err
 	}
-	if exists {
+	if exists && !opts.Replay {
 		opts.SeedCorpusDirs = append(opts.SeedCorpusDirs, buildResult.SeedCorpus)
 	}
 
 	// If user-specified dictionary is not set, use
-	// implicit dictionary from buildResult (if it exists).
-	if opts.Dictionary == "" {
+	// implicit dictionary from buildResult (if it exists), unless
+	// the user opted out of it.
+	if opts.Dictionary == "" && !opts.NoDefaultDict {
 		exists, err := fileutil.Exists(buildResult.Dictionary)
 		if err != nil {
 			return err
@@ -119,25 +180,121 @@ func runLibfuzzer(opts *RunOptions, buildResult *build.BuildResult, reportHandle
 		}
 	}
 
+	generatedCorpus := buildResult.GeneratedCorpus
+	seedCorpusDirs := opts.SeedCorpusDirs
+	var stagingDir string
+	var staged map[string]bool
+	if opts.DeterministicCorpusOrder {
+		// Stage all corpus inputs in a single directory, which libFuzzer
+		// also writes the new inputs to. These are copied to the
+		// generated corpus after the run.
+		stagingDir, err = os.MkdirTemp("", "cifuzz-corpus-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer fileutil.Cleanup(stagingDir)
+		staged, err = cmdutils.StageCorpus(append([]string{generatedCorpus}, seedCorpusDirs...), stagingDir)
+		if err != nil {
+			return err
+		}
+		log.Infof("Staged %d corpus inputs in a deterministic order", len(staged))
+		generatedCorpus = stagingDir
+		seedCorpusDirs = nil
+	}
+	if opts.Reproduce != "" {
+		// Pass the input as the only positional argument, so that
+		// libFuzzer executes it once instead of fuzzing
+		generatedCorpus = opts.Reproduce
+		seedCorpusDirs = nil
+	}
+
 	runnerOpts := &libfuzzer.RunnerOptions{
 		Dictionary:         opts.Dictionary,
-		EngineArgs:         opts.EngineArgs,
+		EngineArgs:         opts.libFuzzerEngineArgs(),
 		EnvVars:            []string{"NO_CIFUZZ=1"},
 		FuzzTarget:         buildResult.Executable,
 		LibraryDirs:        libraryPaths,
-		GeneratedCorpusDir: buildResult.GeneratedCorpus,
+		GeneratedCorpusDir: generatedCorpus,
 		KeepColor:          !opts.PrintJSON && !log.PlainStyle(),
 		ProjectDir:         opts.ProjectDir,
 		ReadOnlyBindings:   []string{buildResult.BuildDir},
 		ReportHandler:      reportHandler,
-		SeedCorpusDirs:     opts.SeedCorpusDirs,
+		SeedCorpusDirs:     seedCorpusDirs,
 		Timeout:            opts.Timeout,
 		UseMinijail:        opts.UseSandbox,
 		Verbose:            viper.GetBool("verbose"),
+		Symbolizer:         opts.Symbolizer,
+		PrintCommand:       opts.PrintCommand,
+		DetectODRViolation: opts.ASanODRViolation,
+		RunnerBinary:       opts.RunnerBinary,
 	}
 
 	// TODO: Only set ReadOnlyBindings if buildResult.BuildDir != ""
-	return ExecuteFuzzerRunner(libfuzzer.NewRunner(runnerOpts))
+	err = executeWithInfraRetries(opts, reportHandler, func() FuzzerRunner {
+		return libfuzzer.NewRunner(runnerOpts)
+	})
+	if stagingDir != "" {
+		// Also keep the new inputs if the fuzzer failed
+		copyErr := cmdutils.CopyNewInputs(stagingDir, staged, buildResult.GeneratedCorpus)
+		if err == nil {
+			err = copyErr
+		}
+	}
+	// nolint: wrapcheck
+	return err
+}
+
+func runAFL(opts *RunOptions, buildResult *build.BuildResult, reportHandler *reporthandler.ReportHandler) error {
+	// Check that the fuzz test was built with the AFL++ compilers,
+	// because afl-fuzz can't run binaries built for libFuzzer
+	isAFLBinary, err := afl.IsAFLBinary(buildResult.Executable)
+	if err != nil {
+		return err
+	}
+	if !isAFLBinary {
+		return errors.Errorf(`The fuzz test %s was not built with the AFL++ compilers, which is required
+by the engine "afl". Build it with afl-clang-fast or afl-clang-lto, for example
+by setting CC and CXX in the build command or via --build-env.`, buildResult.Executable)
+	}
+
+	style := pterm.Style{pterm.Reset, pterm.FgLightBlue}
+	log.Infof("Running %s with AFL++", style.Sprintf(opts.FuzzTest))
+	log.Debugf("Executable: %s", buildResult.Executable)
+
+	libraryPaths, err := ldd.LibraryPaths(buildResult.Executable)
+	if err != nil {
+		return err
+	}
+
+	// Use user-specified seed corpus dirs (if any) and the default seed
+	// corpus (if it exists).
+	exists, err := fileutil.Exists(buildResult.SeedCorpus)
+	if err != nil {
+		return err
+	}
+	if exists {
+		opts.SeedCorpusDirs = append(opts.SeedCorpusDirs, buildResult.SeedCorpus)
+	}
+
+	runnerOpts := &afl.RunnerOptions{
+		EngineArgs:         opts.EngineArgs,
+		EnvVars:            []string{"NO_CIFUZZ=1"},
+		FuzzTarget:         buildResult.Executable,
+		LibraryDirs:        libraryPaths,
+		GeneratedCorpusDir: buildResult.GeneratedCorpus,
+		KeepColor:          !opts.PrintJSON && !log.PlainStyle(),
+		ProjectDir:         opts.ProjectDir,
+		ReportHandler:      reportHandler,
+		SeedCorpusDirs:     opts.SeedCorpusDirs,
+		Timeout:            opts.Timeout,
+		Verbose:            viper.GetBool("verbose"),
+		Symbolizer:         opts.Symbolizer,
+		PrintCommand:       opts.PrintCommand,
+	}
+
+	return executeWithInfraRetries(opts, reportHandler, func() FuzzerRunner {
+		return afl.NewRunner(runnerOpts)
+	})
 }
 
 func runJazzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *reporthandler.ReportHandler) error {
@@ -155,11 +312,11 @@ func runJazzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *
 	}
 
 	// Create source map
-	sourceDirs, err := java.SourceDirs(opts.ProjectDir, opts.BuildSystem)
+	sourceDirs, err := java.SourceDirs(opts.ProjectDir, opts.BuildSystem, opts.Offline)
 	if err != nil {
 		return err
 	}
-	testDirs, err := java.TestDirs(opts.ProjectDir, opts.BuildSystem)
+	testDirs, err := java.TestDirs(opts.ProjectDir, opts.BuildSystem, opts.Offline)
 	if err != nil {
 		return err
 	}
@@ -168,7 +325,7 @@ func runJazzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *
 	// determined by the build system.
 	rootDir := opts.ProjectDir
 	if opts.BuildSystem == config.BuildSystemGradle {
-		rootDir, err = gradle.GetRootDirectory(opts.ProjectDir)
+		rootDir, err = gradle.GetRootDirectory(opts.ProjectDir, opts.Offline)
 		if err != nil {
 			return err
 		}
@@ -178,30 +335,44 @@ func runJazzer(opts *RunOptions, buildResult *build.BuildResult, reportHandler *
 		return err
 	}
 
-	var fuzzerRunner FuzzerRunner
+	// The class path entries specified by the user are appended, so
+	// that the order of the runtime dependencies is preserved
+	classPaths := append(append([]string{}, buildResult.RuntimeDeps...), opts.ClassPaths...)
+
+	generatedCorpus := buildResult.GeneratedCorpus
+	seedCorpusDirs := opts.SeedCorpusDirs
+	if opts.Reproduce != "" {
+		// Pass the input as the only positional argument, so that
+		// Jazzer executes it once instead of fuzzing
+		generatedCorpus = opts.Reproduce
+		seedCorpusDirs = nil
+	}
 
 	runnerOpts := &jazzer.RunnerOptions{
-		TargetClass:  opts.FuzzTest,
-		TargetMethod: opts.TargetMethod,
-		ClassPaths:   buildResult.RuntimeDeps,
+		TargetClass:    opts.FuzzTest,
+		TargetMethod:   opts.TargetMethod,
+		ClassPaths:     classPaths,
+		NativeLibPaths: opts.NativeLibPaths,
 		LibfuzzerOptions: &libfuzzer.RunnerOptions{
 			Dictionary:         opts.Dictionary,
-			EngineArgs:         opts.EngineArgs,
+			EngineArgs:         opts.libFuzzerEngineArgs(),
 			EnvVars:            []string{"NO_CIFUZZ=1"},
 			FuzzTarget:         buildResult.Executable,
-			GeneratedCorpusDir: buildResult.GeneratedCorpus,
+			GeneratedCorpusDir: generatedCorpus,
 			KeepColor:          !opts.PrintJSON && !log.PlainStyle(),
 			ProjectDir:         opts.ProjectDir,
 			SourceMap:          sourceMap,
 			ReadOnlyBindings:   []string{buildResult.BuildDir},
 			ReportHandler:      reportHandler,
-			SeedCorpusDirs:     opts.SeedCorpusDirs,
+			SeedCorpusDirs:     seedCorpusDirs,
 			Timeout:            opts.Timeout,
 			UseMinijail:        opts.UseSandbox,
 			Verbose:            viper.GetBool("verbose"),
+			PrintCommand:       opts.PrintCommand,
 		},
 	}
 
-	fuzzerRunner = jazzer.NewRunner(runnerOpts)
-	return ExecuteFuzzerRunner(fuzzerRunner)
+	return executeWithInfraRetries(opts, reportHandler, func() FuzzerRunner {
+		return jazzer.NewRunner(runnerOpts)
+	})
 }
@@ -0,0 +1,137 @@
+package adapter
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+// fakeRunner returns the errors in errs, one per run, and reports a
+// finding before returning if reportFinding is set
+type fakeRunner struct {
+	errs          []error
+	runs          *int
+	reportHandler *reporthandler.ReportHandler
+	reportFinding bool
+}
+
+func (r *fakeRunner) Run(context.Context) error {
+	*r.runs++
+	if r.reportFinding {
+		err := r.reportHandler.Handle(&report.Report{
+			Status:  report.RunStatusRunning,
+			Finding: &finding.Finding{Name: "test_finding"},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if *r.runs > len(r.errs) {
+		return nil
+	}
+	return r.errs[*r.runs-1]
+}
+
+func (r *fakeRunner) Cleanup(context.Context) {}
+
+func infraErr() error {
+	return cmdutils.WrapExecError(errors.New("signal: killed"), exec.Command("fuzzer"))
+}
+
+func TestExecuteWithInfraRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		retries       uint
+		errs          []error
+		reportFinding bool
+		expectedRuns  int
+		expectErr     bool
+	}{
+		{
+			name:         "no retries",
+			retries:      0,
+			errs:         []error{infraErr()},
+			expectedRuns: 1,
+			expectErr:    true,
+		},
+		{
+			name:         "succeeds after retry",
+			retries:      2,
+			errs:         []error{infraErr()},
+			expectedRuns: 2,
+		},
+		{
+			name:         "retries exhausted",
+			retries:      2,
+			errs:         []error{infraErr(), infraErr(), infraErr(), infraErr()},
+			expectedRuns: 3,
+			expectErr:    true,
+		},
+		{
+			name:         "no retry for other errors",
+			retries:      2,
+			errs:         []error{errors.New("build failed")},
+			expectedRuns: 1,
+			expectErr:    true,
+		},
+		{
+			name:         "no retry for signals",
+			retries:      2,
+			errs:         []error{cmdutils.NewSignalError(syscall.SIGTERM)},
+			expectedRuns: 1,
+			expectErr:    true,
+		},
+		{
+			name:          "no retry after finding",
+			retries:       2,
+			errs:          []error{infraErr()},
+			reportFinding: true,
+			expectedRuns:  1,
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reportHandler, err := reporthandler.NewReportHandler("", &reporthandler.ReportHandlerOptions{
+				ProjectDir:        t.TempDir(),
+				SkipSavingFinding: true,
+			})
+			require.NoError(t, err)
+
+			var runs int
+			opts := &RunOptions{RetryOnInfraFailure: tc.retries}
+			err = executeWithInfraRetries(opts, reportHandler, func() FuzzerRunner {
+				return &fakeRunner{
+					errs:          tc.errs,
+					runs:          &runs,
+					reportHandler: reportHandler,
+					reportFinding: tc.reportFinding,
+				}
+			})
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRuns, runs)
+		})
+	}
+}
+
+func TestIsInfraFailure(t *testing.T) {
+	assert.True(t, isInfraFailure(infraErr()))
+	assert.True(t, isInfraFailure(errors.WithMessage(infraErr(), "fuzzing failed")))
+	assert.False(t, isInfraFailure(errors.New("build failed")))
+	assert.False(t, isInfraFailure(cmdutils.NewSignalError(syscall.SIGTERM)))
+}
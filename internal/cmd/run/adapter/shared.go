@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -12,7 +13,10 @@ import (
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/internal/cmdutils/logging"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/names"
+	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/options"
 	"code-intelligence.com/cifuzz/util/fileutil"
 )
 
@@ -36,21 +40,70 @@ func wrapBuild[BR BuildResultType](opts *RunOptions, build func(*RunOptions) (*B
 	cBuildResult, err := build(opts)
 	if err != nil {
 		buildPrinter.StopOnError(log.BuildInProgressErrorMsg)
+		err = cmdutils.WrapCompilationError(err, opts.FuzzTest)
 	} else {
 		buildPrinter.StopOnSuccess(log.BuildInProgressSuccessMsg, true)
 	}
 	return cBuildResult, err
 }
 
+// buildCache stores the build results of an adapter, so that each
+// fuzz test is only built once when the adapter is used to replay the
+// inputs of multiple findings (see RunOptions.Replay).
+type buildCache[BR BuildResultType] map[string]*cachedBuild[BR]
+
+type cachedBuild[BR BuildResultType] struct {
+	// The fuzz test as modified by the build function
+	fuzzTest string
+	result   *BR
+}
+
+// wrapCachedBuild is like wrapBuild, but when replaying inputs, it
+// only builds the fuzz test if it was not built before via the cache.
+func wrapCachedBuild[BR BuildResultType](cache *buildCache[BR], opts *RunOptions, build func(*RunOptions) (*BR, error)) (*BR, error) {
+	if !opts.Replay {
+		return wrapBuild(opts, build)
+	}
+
+	if cached, ok := (*cache)[opts.FuzzTest]; ok {
+		log.Debugf("Using the existing build of %s", opts.FuzzTest)
+		opts.FuzzTest = cached.fuzzTest
+		return cached.result, nil
+	}
+
+	fuzzTest := opts.FuzzTest
+	result, err := wrapBuild(opts, build)
+	if err != nil {
+		return nil, err
+	}
+	if *cache == nil {
+		*cache = buildCache[BR]{}
+	}
+	(*cache)[fuzzTest] = &cachedBuild[BR]{fuzzTest: opts.FuzzTest, result: result}
+	return result, nil
+}
+
 func prepareCorpusDir(opts *RunOptions, buildResult *build.BuildResult) error {
 	switch opts.BuildSystem {
 	case config.BuildSystemCMake, config.BuildSystemBazel, config.BuildSystemOther:
+		// Store the generated corpus below the output root, which is
+		// the project dir if no output root was specified
+		buildResult.GeneratedCorpus = cmdutils.RebaseOnOutputRoot(buildResult.GeneratedCorpus, opts.ProjectDir, opts.OutputRoot)
+		if opts.Replay {
+			buildResult.GeneratedCorpus = opts.ReplayCorpusDir
+		}
+		if opts.MergeCorpusDir != "" {
+			buildResult.GeneratedCorpus = opts.MergeCorpusDir
+		}
+
 		// The generated corpus dir has to be created before starting the fuzzing run.
 		err := os.MkdirAll(buildResult.GeneratedCorpus, 0o755)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		log.Infof("Storing generated corpus in %s", fileutil.PrettifyPath(buildResult.GeneratedCorpus))
+		if !opts.Replay && opts.MergeCorpusDir == "" {
+			log.Infof("Storing generated corpus in %s", fileutil.PrettifyPath(buildResult.GeneratedCorpus))
+		}
 
 		// Ensure that symlinks are resolved to be able to add minijail
 		// bindings for the corpus dirs.
@@ -84,11 +137,96 @@ func prepareCorpusDir(opts *RunOptions, buildResult *build.BuildResult) error {
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		// Jazzer merges the inputs into the first corpus directory
+		if opts.MergeCorpusDir != "" {
+			buildResult.GeneratedCorpus = opts.MergeCorpusDir
+		}
+	}
+
+	if opts.RequireSeeds {
+		err := checkSeeds(opts, buildResult)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.WarnOversizedSeeds {
+		err := warnOversizedSeeds(opts, buildResult)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// corpusDirs returns the directories whose inputs the fuzz test is
+// started with.
+func corpusDirs(opts *RunOptions, buildResult *build.BuildResult) []string {
+	dirs := append([]string{buildResult.SeedCorpus, buildResult.GeneratedCorpus}, opts.SeedCorpusDirs...)
+	if opts.BuildSystem == config.BuildSystemMaven || opts.BuildSystem == config.BuildSystemGradle {
+		dirs = append(dirs, cmdutils.JazzerSeedCorpus(opts.FuzzTest, opts.ProjectDir))
+	}
+	return dirs
+}
+
+// checkSeeds returns an error if none of the corpus directories of the
+// fuzz test contain a non-empty input.
+func checkSeeds(opts *RunOptions, buildResult *build.BuildResult) error {
+	dirs := corpusDirs(opts, buildResult)
+
+	found, err := cmdutils.ContainsNonEmptyInput(dirs)
+	if err != nil {
+		return err
+	}
+	if !found {
+		var prettyDirs []string
+		for _, dir := range dirs {
+			if dir != "" {
+				prettyDirs = append(prettyDirs, fileutil.PrettifyPath(dir))
+			}
+		}
+		return errors.Errorf(`No seeds found for %s, but --require-seeds was specified.
+None of these directories contain a non-empty input:
+  %s
+Check that the inputs directory is correct or add seeds via --seed-corpus.`,
+			opts.FuzzTest, strings.Join(prettyDirs, "\n  "))
+	}
+	return nil
+}
+
+// warnOversizedSeeds prints a warning for each input of the corpus
+// directories which is larger than the -max_len passed to libFuzzer,
+// because libFuzzer truncates these inputs.
+func warnOversizedSeeds(opts *RunOptions, buildResult *build.BuildResult) error {
+	maxLen, err := options.ParseLibFuzzerMaxLen(opts.EngineArgs)
+	if err != nil {
+		return err
+	}
+	if maxLen == 0 {
+		// Without -max_len, libFuzzer uses the size of the largest
+		// input of the corpus, so no input is truncated
+		log.Debugf("Not checking for oversized seeds because %s is not set", options.LibFuzzerMaxLen)
+		return nil
+	}
+
+	paths, err := cmdutils.OversizedInputs(corpusDirs(opts, buildResult), maxLen)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	var prettyPaths []string
+	for _, path := range paths {
+		prettyPaths = append(prettyPaths, fileutil.PrettifyPath(path))
+	}
+	log.Warnf(`%d inputs of the corpus are larger than %s=%d and will be truncated by libFuzzer,
+which loses the coverage of the truncated part:
+  %s`, len(paths), options.LibFuzzerMaxLen, maxLen, strings.Join(prettyPaths, "\n  "))
+	return nil
+}
+
 func createReportHandler(opts *RunOptions, buildResult *build.BuildResult) (*reporthandler.ReportHandler, error) {
 	printerOutput := os.Stdout
 	jsonOutput := io.Discard
@@ -104,11 +242,29 @@ func createReportHandler(opts *RunOptions, buildResult *build.BuildResult) (*rep
 		opts.FuzzTest,
 		&reporthandler.ReportHandlerOptions{
 			ProjectDir:           opts.ProjectDir,
+			OutputRoot:           opts.OutputRoot,
 			UserSeedCorpusDirs:   opts.SeedCorpusDirs,
 			ManagedSeedCorpusDir: buildResult.SeedCorpus,
 			GeneratedCorpusDir:   buildResult.GeneratedCorpus,
 			PrinterOutput:        printerOutput,
 			JSONOutput:           jsonOutput,
+			SyncJSONOutput:       opts.JSONFlush,
+			FindingWebhook:       opts.FindingWebhook,
+			WebhookHeaders:       opts.WebhookHeaders,
+			Proxy:                opts.Proxy,
+			StripPaths:           opts.StripPaths,
+			StripPathPrefix:      opts.StripPathPrefix,
+			SkipSavingFinding:    opts.Replay || opts.MergeCorpusDir != "",
+			NameStyle:            names.Style(opts.FindingNameStyle),
+			FindingJSONIndent:    finding.JSONIndent(opts.FindingJSONIndent),
+			BuildSystem:          opts.BuildSystem,
+			MetricsHistoryFile:   opts.MetricsHistoryFile,
+			SummaryFile:          opts.SummaryFile,
+			MetricsFile:          opts.MetricsFile,
+			PrometheusOutput:     opts.PrometheusOutput,
+			MetricsInterval:      opts.MetricsInterval,
+			StopOnPlateau:        opts.StopOnPlateau,
+			CorpusIndex:          opts.CorpusIndex,
 		},
 	)
 }
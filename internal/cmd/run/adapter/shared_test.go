@@ -0,0 +1,45 @@
+package adapter
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/build"
+)
+
+func TestWrapCachedBuild(t *testing.T) {
+	var builds []string
+	buildFunc := func(opts *RunOptions) (*build.BuildResult, error) {
+		builds = append(builds, opts.FuzzTest)
+		// Like the bazel adapter, modify the fuzz test
+		opts.FuzzTest += "_bin"
+		return &build.BuildResult{Executable: opts.FuzzTest}, nil
+	}
+	newOpts := func(fuzzTest string, replay bool) *RunOptions {
+		return &RunOptions{FuzzTest: fuzzTest, Replay: replay, Stdout: io.Discard, Stderr: io.Discard}
+	}
+
+	var cache buildCache[build.BuildResult]
+
+	// When replaying inputs, each fuzz test is only built once
+	for i := 0; i < 2; i++ {
+		opts := newOpts("foo", true)
+		result, err := wrapCachedBuild(&cache, opts, buildFunc)
+		require.NoError(t, err)
+		assert.Equal(t, "foo_bin", result.Executable)
+		assert.Equal(t, "foo_bin", opts.FuzzTest)
+	}
+	assert.Equal(t, []string{"foo"}, builds)
+
+	_, err := wrapCachedBuild(&cache, newOpts("bar", true), buildFunc)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, builds)
+
+	// Otherwise, the fuzz test is always built
+	_, err = wrapCachedBuild(&cache, newOpts("foo", false), buildFunc)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar", "foo"}, builds)
+}
@@ -0,0 +1,128 @@
+package reporthandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+// CorpusIndexSuffix is appended to the path of the generated corpus
+// directory to get the path of its index file. The index is stored next
+// to the corpus inputs instead of between them, because the fuzzer
+// would otherwise use it as an input.
+const CorpusIndexSuffix = ".index.json"
+
+// CorpusIndex records the coverage which the entries of a generated
+// corpus contributed when they were added by the fuzzer.
+type CorpusIndex struct {
+	FuzzTest string                       `json:"fuzz_test"`
+	Entries  map[string]*CorpusIndexEntry `json:"entries"`
+}
+
+// CorpusIndexEntry is the coverage metadata of a single corpus entry,
+// as reported by libFuzzer when it added the entry.
+type CorpusIndexEntry struct {
+	Size    int64     `json:"size"`
+	AddedAt time.Time `json:"added_at"`
+	// Whether the entry replaced a larger entry with the same features
+	// (REDUCE) instead of adding new features (NEW)
+	Reduced bool `json:"reduced,omitempty"`
+	// The edges and features which were new when the entry was added
+	NewEdges    int32 `json:"new_edges"`
+	NewFeatures int32 `json:"new_features"`
+	// The total edges and features after the entry was added
+	TotalEdges    int32 `json:"total_edges"`
+	TotalFeatures int32 `json:"total_features"`
+}
+
+// recordCorpusEntry records an entry which the fuzzer added to the
+// generated corpus. Entries are identified by their file name, which
+// libFuzzer derives from their content.
+func (h *ReportHandler) recordCorpusEntry(entry *report.CorpusEntry) {
+	if h.corpusEntries == nil {
+		h.corpusEntries = make(map[string]*CorpusIndexEntry)
+	}
+	indexEntry := &CorpusIndexEntry{
+		Size:        entry.Size,
+		Reduced:     entry.Reduced,
+		NewEdges:    entry.NewEdges,
+		NewFeatures: entry.NewFeatures,
+	}
+	if entry.Metric != nil {
+		indexEntry.AddedAt = entry.Metric.Timestamp
+		indexEntry.TotalEdges = entry.Metric.Edges
+		indexEntry.TotalFeatures = entry.Metric.Features
+	}
+	h.corpusEntries[filepath.Base(entry.Path)] = indexEntry
+}
+
+// WriteCorpusIndex updates the index file of the generated corpus with
+// the coverage metadata of the entries added during this run. Entries
+// of previous runs are kept as long as they still exist in the corpus,
+// entries which libFuzzer removed when reducing the corpus are dropped.
+// It returns the path of the index file.
+func (h *ReportHandler) WriteCorpusIndex() (string, error) {
+	if h.GeneratedCorpusDir == "" {
+		return "", errors.New("the location of the generated corpus is unknown")
+	}
+	path := filepath.Clean(h.GeneratedCorpusDir) + CorpusIndexSuffix
+
+	index, err := ReadCorpusIndex(path)
+	if err != nil {
+		return "", err
+	}
+	if index == nil {
+		index = &CorpusIndex{}
+	}
+	index.FuzzTest = h.FuzzTest
+	oldEntries := index.Entries
+	index.Entries = make(map[string]*CorpusIndexEntry)
+
+	dirEntries, err := os.ReadDir(h.GeneratedCorpusDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.WithStack(err)
+	}
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() {
+			continue
+		}
+		if entry, ok := h.corpusEntries[dirEntry.Name()]; ok {
+			index.Entries[dirEntry.Name()] = entry
+		} else if entry, ok := oldEntries[dirEntry.Name()]; ok {
+			index.Entries[dirEntry.Name()] = entry
+		}
+	}
+
+	bytes, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	err = os.WriteFile(path, append(bytes, '\n'), 0o644)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// ReadCorpusIndex reads the corpus index file at path. It returns nil
+// if the file doesn't exist.
+func ReadCorpusIndex(path string) (*CorpusIndex, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	index := &CorpusIndex{}
+	err = json.Unmarshal(bytes, index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse corpus index %s", path)
+	}
+	return index, nil
+}
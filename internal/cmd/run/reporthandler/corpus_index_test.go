@@ -0,0 +1,78 @@
+package reporthandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+func TestWriteCorpusIndex(t *testing.T) {
+	corpusDir := filepath.Join(t.TempDir(), "my_fuzz_test")
+	require.NoError(t, os.MkdirAll(corpusDir, 0o755))
+
+	h, err := NewReportHandler("my_fuzz_test", &ReportHandlerOptions{CorpusIndex: true, GeneratedCorpusDir: corpusDir})
+	require.NoError(t, err)
+	h.initFinished = true
+
+	writeInput := func(name string) {
+		require.NoError(t, os.WriteFile(filepath.Join(corpusDir, name), []byte(name), 0o644))
+	}
+	handleEntry := func(name string, reduced bool, newEdges, newFeatures, edges, features int32) {
+		err := h.Handle(&report.Report{
+			CorpusEntry: &report.CorpusEntry{
+				Path:        filepath.Join(corpusDir, name),
+				Size:        int64(len(name)),
+				Reduced:     reduced,
+				NewEdges:    newEdges,
+				NewFeatures: newFeatures,
+				Metric:      &report.FuzzingMetric{Timestamp: time.Now(), Edges: edges, Features: features},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// An input which existed before the run and isn't in the index
+	writeInput("old")
+	writeInput("first")
+	handleEntry("first", false, 2, 5, 12, 25)
+	writeInput("second")
+	handleEntry("second", false, 1, 0, 13, 25)
+	// An entry which libFuzzer removed again when reducing the corpus
+	handleEntry("removed", false, 1, 1, 14, 26)
+	writeInput("reduced")
+	handleEntry("reduced", true, 0, 0, 14, 26)
+
+	path, err := h.WriteCorpusIndex()
+	require.NoError(t, err)
+	assert.Equal(t, corpusDir+CorpusIndexSuffix, path)
+
+	index, err := ReadCorpusIndex(path)
+	require.NoError(t, err)
+	assert.Equal(t, "my_fuzz_test", index.FuzzTest)
+	require.Len(t, index.Entries, 3)
+	assert.Equal(t, int64(5), index.Entries["first"].Size)
+	assert.Equal(t, int32(2), index.Entries["first"].NewEdges)
+	assert.Equal(t, int32(5), index.Entries["first"].NewFeatures)
+	assert.Equal(t, int32(12), index.Entries["first"].TotalEdges)
+	assert.Equal(t, int32(1), index.Entries["second"].NewEdges)
+	assert.Equal(t, int32(0), index.Entries["second"].NewFeatures)
+	assert.True(t, index.Entries["reduced"].Reduced)
+
+	// Entries of previous runs are kept if they still exist
+	require.NoError(t, os.Remove(filepath.Join(corpusDir, "second")))
+	h, err = NewReportHandler("my_fuzz_test", &ReportHandlerOptions{CorpusIndex: true, GeneratedCorpusDir: corpusDir})
+	require.NoError(t, err)
+	_, err = h.WriteCorpusIndex()
+	require.NoError(t, err)
+	index, err = ReadCorpusIndex(path)
+	require.NoError(t, err)
+	require.Len(t, index.Entries, 2)
+	assert.Contains(t, index.Entries, "first")
+	assert.Contains(t, index.Entries, "reduced")
+}
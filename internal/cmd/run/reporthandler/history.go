@@ -0,0 +1,71 @@
+package reporthandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxMetricsHistorySize is the size above which the metrics history
+// file is rotated before appending a new entry. The previous entries
+// are kept in a file with the suffix ".1", which is overwritten on the
+// next rotation.
+const maxMetricsHistorySize = 10 * 1024 * 1024
+
+// MetricsHistoryEntry is the JSON line which is appended to the
+// metrics history file after each run
+type MetricsHistoryEntry struct {
+	Timestamp          time.Time `json:"timestamp"`
+	FuzzTest           string    `json:"fuzz_test"`
+	DurationSeconds    float64   `json:"duration_seconds"`
+	TotalExecutions    uint64    `json:"total_executions"`
+	AverageExecsPerSec uint64    `json:"average_execs_per_second"`
+	CorpusEntries      uint      `json:"corpus_entries"`
+	NewCorpusEntries   uint      `json:"new_corpus_entries"`
+	NumFindings        int       `json:"findings"`
+}
+
+// AppendMetricsHistory appends the entry as a single JSON line to the
+// file at path, which is created if it doesn't exist yet.
+func AppendMetricsHistory(path string, entry *MetricsHistoryEntry) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	info, err := os.Stat(path)
+	if err == nil && info.Size() > maxMetricsHistorySize {
+		err = os.Rename(path, path+".1")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+
+	return appendJSONLine(path, entry)
+}
+
+// appendJSONLine appends v as a single JSON line to the file at path,
+// which is created if it doesn't exist yet.
+func appendJSONLine(path string, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(file.Close())
+}
@@ -0,0 +1,54 @@
+package reporthandler
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendMetricsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "metrics.jsonl")
+
+	err := AppendMetricsHistory(path, &MetricsHistoryEntry{FuzzTest: "my_fuzz_test", CorpusEntries: 1})
+	require.NoError(t, err)
+	err = AppendMetricsHistory(path, &MetricsHistoryEntry{FuzzTest: "my_fuzz_test", CorpusEntries: 2, NumFindings: 1})
+	require.NoError(t, err)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var entries []*MetricsHistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := &MetricsHistoryEntry{}
+		err = json.Unmarshal(scanner.Bytes(), entry)
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint(1), entries[0].CorpusEntries)
+	assert.Equal(t, uint(2), entries[1].CorpusEntries)
+	assert.Equal(t, 1, entries[1].NumFindings)
+}
+
+func TestAppendMetricsHistory_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.jsonl")
+	err := os.WriteFile(path, make([]byte, maxMetricsHistorySize+1), 0o644)
+	require.NoError(t, err)
+
+	err = AppendMetricsHistory(path, &MetricsHistoryEntry{FuzzTest: "my_fuzz_test"})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxMetricsHistorySize+1), info.Size())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"fuzz_test":"my_fuzz_test"`)
+}
@@ -23,12 +23,22 @@ func NewLinePrinter(output io.Writer) *LinePrinter {
 type LinePrinter struct {
 	*pterm.BasicTextPrinter
 	startedAt time.Time
+
+	// If set, metrics are printed at most once per interval, the
+	// metrics received in between are dropped
+	Interval      time.Duration
+	lastPrintedAt time.Time
 }
 
 func (p *LinePrinter) Start() {
 }
 
 func (p *LinePrinter) PrintMetrics(metrics *report.FuzzingMetric) {
+	if p.Interval > 0 && !p.lastPrintedAt.IsZero() && time.Since(p.lastPrintedAt) < p.Interval {
+		return
+	}
+	p.lastPrintedAt = time.Now()
+
 	s := fmt.Sprint(
 		MetricsToString(metrics),
 		DelimString(" ("),
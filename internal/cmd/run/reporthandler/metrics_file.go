@@ -0,0 +1,71 @@
+package reporthandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+// MetricsFileEntry is the JSON line which is appended to the metrics
+// file for each metric reported by the fuzzer during the run
+type MetricsFileEntry struct {
+	Timestamp           time.Time `json:"timestamp"`
+	FuzzTest            string    `json:"fuzz_test"`
+	ExecutionsPerSecond int32     `json:"executions_per_second"`
+	TotalExecutions     uint64    `json:"total_executions"`
+	CorpusSize          int32     `json:"corpus_size"`
+	Edges               int32     `json:"edges"`
+	Features            int32     `json:"features"`
+	NumFindings         int       `json:"findings"`
+}
+
+// writeMetricsFileEntry appends the metric to the metrics file. The
+// file is opened on the first metric and kept open until Close is
+// called, because the fuzzer reports metrics every few seconds.
+func (h *ReportHandler) writeMetricsFileEntry(metric *report.FuzzingMetric) error {
+	if h.metricsFile == nil {
+		err := os.MkdirAll(filepath.Dir(h.MetricsFile), 0o755)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		h.metricsFile, err = os.OpenFile(h.MetricsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	timestamp := metric.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	line, err := json.Marshal(&MetricsFileEntry{
+		Timestamp:           timestamp,
+		FuzzTest:            h.FuzzTest,
+		ExecutionsPerSecond: metric.ExecutionsPerSecond,
+		TotalExecutions:     metric.TotalExecutions,
+		CorpusSize:          metric.CorpusSize,
+		Edges:               metric.Edges,
+		Features:            metric.Features,
+		NumFindings:         len(h.UnsuppressedFindings()),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = h.metricsFile.Write(append(line, '\n'))
+	return errors.WithStack(err)
+}
+
+// closeMetricsFile closes the metrics file if it was opened.
+func (h *ReportHandler) closeMetricsFile() error {
+	if h.metricsFile == nil {
+		return nil
+	}
+	err := h.metricsFile.Close()
+	h.metricsFile = nil
+	return errors.WithStack(err)
+}
@@ -0,0 +1,55 @@
+package reporthandler
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+func TestReportHandler_MetricsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics", "metrics.jsonl")
+	h, err := NewReportHandler("my_fuzz_test", &ReportHandlerOptions{MetricsFile: path})
+	require.NoError(t, err)
+
+	for _, corpusSize := range []int32{1, 2} {
+		err = h.Handle(&report.Report{
+			Status: report.RunStatusRunning,
+			Metric: &report.FuzzingMetric{CorpusSize: corpusSize, TotalExecutions: 100, ExecutionsPerSecond: 10},
+		})
+		require.NoError(t, err)
+	}
+	// Reports without a metric don't add an entry
+	err = h.Handle(&report.Report{Status: report.RunStatusRunning})
+	require.NoError(t, err)
+	// The metrics file is kept open until the handler is closed
+	require.NotNil(t, h.metricsFile)
+	require.NoError(t, h.Close())
+	require.Nil(t, h.metricsFile)
+	require.NoError(t, h.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var entries []*MetricsFileEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := &MetricsFileEntry{}
+		err = json.Unmarshal(scanner.Bytes(), entry)
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, entries, 2)
+	assert.Equal(t, "my_fuzz_test", entries[0].FuzzTest)
+	assert.Equal(t, int32(1), entries[0].CorpusSize)
+	assert.Equal(t, int32(2), entries[1].CorpusSize)
+	assert.Equal(t, uint64(100), entries[1].TotalExecutions)
+	assert.False(t, entries[1].Timestamp.IsZero())
+}
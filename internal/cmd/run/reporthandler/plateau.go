@@ -0,0 +1,92 @@
+package reporthandler
+
+import (
+	"sync"
+	"time"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+// plateauDetector detects when the corpus hasn't grown for a given
+// duration, i.e. when the fuzzer doesn't find new coverage anymore. It
+// uses a timer which is reset whenever the corpus grows, because the
+// fuzzer reports metrics less and less often while it doesn't find new
+// coverage.
+type plateauDetector struct {
+	duration time.Duration
+	// onReached is called once when the plateau is reached, before
+	// the reached channel is closed
+	onReached func()
+
+	mu            sync.Mutex
+	maxCorpusSize int32
+	lastGrowthAt  time.Time
+	timer         *time.Timer
+	isReached     bool
+
+	reached chan struct{}
+}
+
+func newPlateauDetector(duration time.Duration, onReached func()) *plateauDetector {
+	return &plateauDetector{
+		duration:  duration,
+		onReached: onReached,
+		reached:   make(chan struct{}),
+	}
+}
+
+// update records the corpus size of the metric. The first call starts
+// the timer, which is reset whenever the corpus grew.
+func (d *plateauDetector) update(metric *report.FuzzingMetric) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.isReached {
+		return
+	}
+	if d.timer == nil {
+		d.maxCorpusSize = metric.CorpusSize
+		d.lastGrowthAt = time.Now()
+		d.timer = time.AfterFunc(d.duration, d.reach)
+		return
+	}
+	if metric.CorpusSize > d.maxCorpusSize {
+		d.maxCorpusSize = metric.CorpusSize
+		d.lastGrowthAt = time.Now()
+		d.timer.Reset(d.duration)
+	}
+}
+
+// reach is called by the timer. The corpus might have grown while the
+// timer fired, in which case the timer was already reset.
+func (d *plateauDetector) reach() {
+	d.mu.Lock()
+	if d.isReached || time.Since(d.lastGrowthAt) < d.duration {
+		d.mu.Unlock()
+		return
+	}
+	d.isReached = true
+	d.mu.Unlock()
+
+	if d.onReached != nil {
+		d.onReached()
+	}
+	close(d.reached)
+}
+
+// stop stops the timer, so that the plateau isn't reached after the
+// fuzzer stopped.
+func (d *plateauDetector) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// wasReached returns true if the plateau was reached.
+func (d *plateauDetector) wasReached() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.isReached
+}
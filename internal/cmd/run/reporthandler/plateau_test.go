@@ -0,0 +1,49 @@
+package reporthandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/report"
+)
+
+func TestPlateauDetector(t *testing.T) {
+	numReached := 0
+	d := newPlateauDetector(200*time.Millisecond, func() { numReached++ })
+	metric := func(corpusSize int32) *report.FuzzingMetric {
+		return &report.FuzzingMetric{CorpusSize: corpusSize}
+	}
+
+	start := time.Now()
+	d.update(metric(10))
+	time.Sleep(100 * time.Millisecond)
+	d.update(metric(10))
+	// The corpus grew, so the timer starts again
+	d.update(metric(11))
+	time.Sleep(150 * time.Millisecond)
+	assert.False(t, d.wasReached())
+
+	// The plateau is reached without further metrics, because the
+	// fuzzer doesn't necessarily report metrics while it doesn't find
+	// new coverage
+	select {
+	case <-d.reached:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "reached channel was not closed")
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.True(t, d.wasReached())
+	assert.Equal(t, 1, numReached)
+}
+
+func TestPlateauDetector_Stop(t *testing.T) {
+	d := newPlateauDetector(50*time.Millisecond, nil)
+	d.update(&report.FuzzingMetric{CorpusSize: 10})
+	d.stop()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, d.wasReached())
+}
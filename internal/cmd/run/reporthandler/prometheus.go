@@ -0,0 +1,96 @@
+package reporthandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// prometheusMetric is the description of a metric in the Prometheus
+// text exposition format.
+type prometheusMetric struct {
+	name       string
+	help       string
+	metricType string
+	value      func(*MetricsHistoryEntry) float64
+}
+
+// labelValueEscaper escapes label values as required by the Prometheus
+// text exposition format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// FormatPrometheusMetrics returns the final metrics of the runs of the
+// fuzz tests in the Prometheus text exposition format, labeled with the
+// fuzz test.
+func FormatPrometheusMetrics(entries ...*MetricsHistoryEntry) string {
+	metrics := []prometheusMetric{
+		{"cifuzz_executions_total", "Total number of executions of the fuzz test.", "counter",
+			func(e *MetricsHistoryEntry) float64 { return float64(e.TotalExecutions) }},
+		{"cifuzz_execs_per_second", "Average number of executions per second.", "gauge",
+			func(e *MetricsHistoryEntry) float64 { return float64(e.AverageExecsPerSec) }},
+		{"cifuzz_corpus_entries", "Number of entries in the corpus of the fuzz test.", "gauge",
+			func(e *MetricsHistoryEntry) float64 { return float64(e.CorpusEntries) }},
+		{"cifuzz_new_corpus_entries", "Number of corpus entries added during the run.", "gauge",
+			func(e *MetricsHistoryEntry) float64 { return float64(e.NewCorpusEntries) }},
+		{"cifuzz_findings_total", "Number of findings of the run.", "counter",
+			func(e *MetricsHistoryEntry) float64 { return float64(e.NumFindings) }},
+		{"cifuzz_run_duration_seconds", "Duration of the run in seconds.", "gauge",
+			func(e *MetricsHistoryEntry) float64 { return e.DurationSeconds }},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		// Each metric must only be described once, followed by the
+		// samples of all fuzz tests
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.metricType)
+		for _, entry := range entries {
+			labels := fmt.Sprintf(`{fuzz_test="%s"}`, labelValueEscaper.Replace(entry.FuzzTest))
+			fmt.Fprintf(&b, "%s%s %g\n", m.name, labels, m.value(entry))
+		}
+	}
+	return b.String()
+}
+
+// WritePrometheusMetrics writes the final metrics of the runs of the
+// fuzz tests in the Prometheus text exposition format to the file at
+// path. The file is replaced atomically, so that the textfile collector
+// of the node_exporter never reads a partially written file.
+func WritePrometheusMetrics(path string, entries ...*MetricsHistoryEntry) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// The temporary file must be in the same directory, because the
+	// rename is only atomic within a file system. The collector only
+	// reads files ending with ".prom", so it ignores the temporary file.
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(FormatPrometheusMetrics(entries...))
+	if err != nil {
+		tmpFile.Close()
+		return errors.WithStack(err)
+	}
+	err = tmpFile.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// os.CreateTemp creates the file with mode 0600, which the
+	// node_exporter might not be able to read
+	err = os.Chmod(tmpFile.Name(), 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmpFile.Name(), path))
+}
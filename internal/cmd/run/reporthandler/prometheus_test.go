@@ -0,0 +1,54 @@
+package reporthandler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cifuzz.prom")
+	entry := &MetricsHistoryEntry{
+		FuzzTest:           `my_"fuzz"_test`,
+		DurationSeconds:    1.5,
+		TotalExecutions:    1000,
+		AverageExecsPerSec: 500,
+		CorpusEntries:      10,
+		NewCorpusEntries:   2,
+		NumFindings:        1,
+	}
+
+	err := WritePrometheusMetrics(path, entry)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# TYPE cifuzz_executions_total counter\n")
+	assert.Contains(t, string(content), `cifuzz_executions_total{fuzz_test="my_\"fuzz\"_test"} 1000`+"\n")
+	assert.Contains(t, string(content), `cifuzz_execs_per_second{fuzz_test="my_\"fuzz\"_test"} 500`+"\n")
+	assert.Contains(t, string(content), `cifuzz_corpus_entries{fuzz_test="my_\"fuzz\"_test"} 10`+"\n")
+	assert.Contains(t, string(content), `cifuzz_findings_total{fuzz_test="my_\"fuzz\"_test"} 1`+"\n")
+	assert.Contains(t, string(content), `cifuzz_run_duration_seconds{fuzz_test="my_\"fuzz\"_test"} 1.5`+"\n")
+
+	// The temporary file was renamed
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFormatPrometheusMetrics_MultipleFuzzTests(t *testing.T) {
+	content := FormatPrometheusMetrics(
+		&MetricsHistoryEntry{FuzzTest: "a", TotalExecutions: 1},
+		&MetricsHistoryEntry{FuzzTest: "b", TotalExecutions: 2},
+	)
+
+	// Each metric is only described once
+	assert.Equal(t, 1, strings.Count(content, "# TYPE cifuzz_executions_total counter\n"))
+	assert.Contains(t, content, `cifuzz_executions_total{fuzz_test="a"} 1`+"\n")
+	assert.Contains(t, content, `cifuzz_executions_total{fuzz_test="b"} 2`+"\n")
+}
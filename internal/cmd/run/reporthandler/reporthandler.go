@@ -1,11 +1,14 @@
 package reporthandler
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"text/tabwriter"
 	"time"
 
@@ -15,7 +18,9 @@ import (
 	"github.com/pterm/pterm"
 	"golang.org/x/term"
 
+	"code-intelligence.com/cifuzz/internal/api"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler/metrics"
+	"code-intelligence.com/cifuzz/internal/config"
 	"code-intelligence.com/cifuzz/internal/names"
 	"code-intelligence.com/cifuzz/pkg/desktop"
 	"code-intelligence.com/cifuzz/pkg/finding"
@@ -27,13 +32,64 @@ import (
 )
 
 type ReportHandlerOptions struct {
-	ProjectDir           string
+	ProjectDir string
+	// The directory below which the findings are stored. Defaults to
+	// the project directory.
+	OutputRoot           string
 	GeneratedCorpusDir   string
 	ManagedSeedCorpusDir string
 	UserSeedCorpusDirs   []string
 	JSONOutput           io.Writer
 	PrinterOutput        io.Writer
 	SkipSavingFinding    bool
+	FindingWebhook       string
+	WebhookHeaders       []string
+	// The proxy used to send the findings to the webhook. If empty,
+	// the proxy is determined from the environment.
+	Proxy      string
+	StripPaths bool
+	// The directory which is stripped from the beginning of paths if
+	// StripPaths is set. Defaults to the project directory.
+	StripPathPrefix string
+	// The style of the generated finding names. Defaults to
+	// names.StyleTwoWord.
+	NameStyle names.Style
+	// The build system of the fuzz test, used to give build system
+	// specific advice when the fuzz test lacks debug info
+	BuildSystem string
+	// The indentation of the saved finding.json files. Defaults to
+	// finding.JSONIndentTwoSpace.
+	FindingJSONIndent finding.JSONIndent
+	// Sync the JSON output to disk after each report, so that it's not
+	// lost if cifuzz is killed during the run
+	SyncJSONOutput bool
+	// Write each report as compact JSON on a single line (JSON Lines)
+	// instead of pretty-printed JSON, so that consumers can parse the
+	// output line by line
+	JSONLines bool
+	// If set, a JSON line with the final metrics of the run is appended
+	// to this file, so that the metrics of many runs form a time series
+	MetricsHistoryFile string
+	// If set, a JSON line is appended to this file for each metric
+	// reported by the fuzzer, independent of the JSON output
+	MetricsFile string
+	// If set, the final metrics of the run are written to this file in
+	// the Prometheus text exposition format
+	PrometheusOutput string
+	// If set, a JSON summary of the run is written to this file after
+	// the run
+	SummaryFile string
+	// If set, the metrics are printed at most once per interval when
+	// the output is not a TTY
+	MetricsInterval time.Duration
+	// If set, the fuzzer is stopped when the corpus hasn't grown for
+	// this duration, see PlateauReached
+	StopOnPlateau time.Duration
+	// If true, the entries which the fuzzer added to the generated
+	// corpus are recorded, so that they can be written to the corpus
+	// index via WriteCorpusIndex. This requires libFuzzer to be run
+	// with -verbosity=2, which makes it report the paths of the entries.
+	CorpusIndex bool
 }
 
 type ReportHandler struct {
@@ -47,10 +103,22 @@ type ReportHandler struct {
 
 	LastMetrics  *report.FuzzingMetric
 	FirstMetrics *report.FuzzingMetric
+	// The final metrics of the run, set by PrintFinalMetrics
+	FinalMetrics *MetricsHistoryEntry
 	ErrorDetails []*finding.ErrorDetails
 
 	numSeedsAtInit uint
 
+	warnedMissingDebugInfo bool
+
+	webhookClient   *api.APIClient
+	pendingWebhooks sync.WaitGroup
+
+	metricsFile *os.File
+
+	plateau       *plateauDetector
+	corpusEntries map[string]*CorpusIndexEntry
+
 	FuzzTest string
 	Findings []*finding.Finding
 }
@@ -69,6 +137,9 @@ func NewReportHandler(fuzzTest string, options *ReportHandlerOptions) (*ReportHa
 	if options.PrinterOutput == nil {
 		h.PrinterOutput = io.Discard
 	}
+	if options.OutputRoot == "" {
+		h.OutputRoot = options.ProjectDir
+	}
 
 	// Use an updating printer if the output stream is a TTY
 	// and plain style is not enabled
@@ -80,7 +151,20 @@ func NewReportHandler(fuzzTest string, options *ReportHandlerOptions) (*ReportHa
 		}
 		h.usingUpdatingPrinter = true
 	} else {
-		h.printer = metrics.NewLinePrinter(h.PrinterOutput)
+		linePrinter := metrics.NewLinePrinter(h.PrinterOutput)
+		linePrinter.Interval = h.MetricsInterval
+		h.printer = linePrinter
+	}
+
+	if options.FindingWebhook != "" {
+		h.webhookClient = api.NewClient("")
+		h.webhookClient.Proxy = options.Proxy
+	}
+
+	if options.StopOnPlateau > 0 {
+		h.plateau = newPlateauDetector(options.StopOnPlateau, func() {
+			log.Infof("No new coverage was found for %s, stopping the fuzzer", h.StopOnPlateau)
+		})
 	}
 
 	return h, nil
@@ -102,6 +186,15 @@ func (h *ReportHandler) Handle(r *report.Report) error {
 		return nil
 	}
 
+	if r.CorpusEntry != nil {
+		// This report was only sent to record the corpus entry in the
+		// corpus index, the metric was reported separately
+		if h.CorpusIndex {
+			h.recordCorpusEntry(r.CorpusEntry)
+		}
+		return nil
+	}
+
 	if r.Status == report.RunStatusInitializing && !h.initStarted {
 		h.initStarted = true
 		h.numSeedsAtInit = r.NumSeeds
@@ -134,6 +227,19 @@ func (h *ReportHandler) Handle(r *report.Report) error {
 			h.FirstMetrics = r.Metric
 		}
 		h.printer.PrintMetrics(r.Metric)
+
+		if h.MetricsFile != "" {
+			err = h.writeMetricsFileEntry(r.Metric)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Only track the coverage after the seed inputs were run,
+		// because the corpus doesn't grow during initialization
+		if h.plateau != nil && h.initFinished {
+			h.plateau.update(r.Metric)
+		}
 	}
 
 	if r.Finding != nil {
@@ -163,11 +269,42 @@ func (h *ReportHandler) Handle(r *report.Report) error {
 	return nil
 }
 
+// PlateauReached returns a channel which is closed when the corpus
+// hasn't grown for the StopOnPlateau duration. If StopOnPlateau is not
+// set, the returned channel is never closed.
+func (h *ReportHandler) PlateauReached() <-chan struct{} {
+	if h.plateau == nil {
+		return nil
+	}
+	return h.plateau.reached
+}
+
+// ReachedPlateau returns true if the corpus didn't grow for the
+// StopOnPlateau duration.
+func (h *ReportHandler) ReachedPlateau() bool {
+	return h.plateau != nil && h.plateau.wasReached()
+}
+
+// Close stops detecting a plateau and closes the metrics file, which
+// must be done when the fuzzer stopped.
+func (h *ReportHandler) Close() error {
+	if h.plateau != nil {
+		h.plateau.stop()
+	}
+	return h.closeMetricsFile()
+}
+
 func (h *ReportHandler) writeJSONReport(r *report.Report) error {
 	var jsonString string
 	var err error
-	// Print with color if the output stream is a TTY
-	if file, ok := h.JSONOutput.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+	if h.JSONLines {
+		bytes, err := json.Marshal(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		jsonString = string(bytes)
+	} else if file, ok := h.JSONOutput.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		// Print with color if the output stream is a TTY
 		bytes, err := prettyjson.Marshal(r)
 		if err != nil {
 			return errors.WithStack(err)
@@ -180,6 +317,27 @@ func (h *ReportHandler) writeJSONReport(r *report.Report) error {
 		}
 	}
 	_, _ = fmt.Fprintln(h.JSONOutput, jsonString)
+	return h.flushJSONOutput()
+}
+
+// flushJSONOutput flushes the JSON output after each report, so that
+// consumers reading from a pipe or file see the reports while the
+// fuzzer is still running.
+func (h *ReportHandler) flushJSONOutput() error {
+	if flusher, ok := h.JSONOutput.(interface{ Flush() error }); ok {
+		err := flusher.Flush()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if file, ok := h.JSONOutput.(*os.File); ok && h.SyncJSONOutput {
+		err := file.Sync()
+		// Pipes and terminals don't support syncing, which is fine
+		// because their output is already visible to the consumer
+		if err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+			return errors.WithStack(err)
+		}
+	}
 	return nil
 }
 
@@ -212,7 +370,7 @@ func (h *ReportHandler) handleFinding(f *finding.Finding) error {
 	// crashing input which causes the crash again. We do want to
 	// produce a distinct new finding in that case.
 	nameSeed := append(stacktrace.EncodeStackTrace(f.StackTrace), f.InputData...)
-	f.Name = names.GetDeterministicName(nameSeed)
+	f.Name = names.GetDeterministicName(nameSeed, h.NameStyle)
 
 	if f.InputFile != "" && !h.SkipSavingFinding {
 		if h.ManagedSeedCorpusDir == "" {
@@ -224,7 +382,14 @@ func (h *ReportHandler) handleFinding(f *finding.Finding) error {
 			// the seed corpus directory.
 			return errors.New("finding before seed corpus directory was set")
 		}
-		err = f.CopyInputFileAndUpdateFinding(h.ProjectDir, h.ManagedSeedCorpusDir)
+		// If an output root was specified, the project directory
+		// might be read-only, so we add the crashing input to the
+		// generated corpus instead of the seed corpus
+		seedCorpusDir := h.ManagedSeedCorpusDir
+		if h.OutputRoot != h.ProjectDir && h.GeneratedCorpusDir != "" {
+			seedCorpusDir = h.GeneratedCorpusDir
+		}
+		err = f.CopyInputFileAndUpdateFinding(h.OutputRoot, seedCorpusDir)
 		if err != nil {
 			return err
 		}
@@ -232,21 +397,132 @@ func (h *ReportHandler) handleFinding(f *finding.Finding) error {
 
 	f.FuzzTest = h.FuzzTest
 
+	// Check for a suppression comment before the paths are stripped,
+	// because we need the path to read the source file
+	err = f.CheckSuppression(h.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	// Strip the paths after the name was generated, so that the name
+	// doesn't depend on the --strip-paths flag
+	if h.StripPaths {
+		prefix := h.StripPathPrefix
+		if prefix == "" {
+			prefix = h.ProjectDir
+		}
+		f.StripPaths(prefix)
+	}
+
 	// Do not mutate f after this call.
 	if !h.SkipSavingFinding {
-		err = f.Save(h.ProjectDir)
+		err = f.SaveWithIndent(h.OutputRoot, h.FindingJSONIndent)
 		if err != nil {
 			return err
 		}
 	}
 
+	if f.Suppressed {
+		msg := fmt.Sprintf("Suppressed finding %s via %q comment", f.Name, finding.SuppressionMarker)
+		if f.SuppressionReason != "" {
+			msg += ": " + f.SuppressionReason
+		}
+		log.Info(msg)
+		return nil
+	}
+
 	log.Finding(f.ShortDescriptionWithName())
+	h.warnIfMissingDebugInfo(f)
 
 	desktop.Notify("cifuzz finding", f.ShortDescriptionWithName())
 
+	if h.webhookClient != nil {
+		h.postFindingToWebhook(f)
+	}
+
 	return nil
 }
 
+// warnIfMissingDebugInfo prints a warning with advice on how to enable
+// debug info if the stack trace of the finding indicates that the fuzz
+// test was built without it. The warning is only printed once per run.
+func (h *ReportHandler) warnIfMissingDebugInfo(f *finding.Finding) {
+	if h.warnedMissingDebugInfo {
+		return
+	}
+	// Only C/C++ fuzz tests are built by the user's build configuration
+	if h.BuildSystem != config.BuildSystemCMake &&
+		h.BuildSystem != config.BuildSystemBazel &&
+		h.BuildSystem != config.BuildSystemOther {
+		return
+	}
+	if !stacktrace.MissingDebugInfo(f.Logs, f.StackTrace) {
+		return
+	}
+	h.warnedMissingDebugInfo = true
+
+	msg := `The stack trace of the finding lacks line numbers or function names,
+which indicates that the fuzz test was built without debug info.`
+	switch h.BuildSystem {
+	case config.BuildSystemOther:
+		msg += `
+Make sure that the build command passes $CFLAGS and $CXXFLAGS, which
+contain "-g", to the compiler, or add "-g" to the compiler flags of the
+fuzz test.`
+	case config.BuildSystemCMake:
+		msg += `
+Make sure that the compiler flags of the fuzz test (e.g. CMAKE_CXX_FLAGS)
+don't disable the debug info via "-g0" or strip the binary via "-s".`
+	case config.BuildSystemBazel:
+		msg += `
+Make sure that the bazel configuration doesn't disable the debug info
+(e.g. via "--copt=-g0") or strip the binary (e.g. via "--strip=always").`
+	}
+	log.Warn(msg)
+}
+
+// postFindingToWebhook sends the finding to the finding webhook in the
+// background, so that a slow or unreachable endpoint doesn't stall the
+// fuzzing run. Failures are only logged.
+func (h *ReportHandler) postFindingToWebhook(f *finding.Finding) {
+	// Marshal the finding right away, because it might be modified
+	// after it was handled, e.g. when it's enhanced with error details.
+	payload, err := json.Marshal(f)
+	if err != nil {
+		log.Warnf("Failed to send finding %s to webhook: %v", f.Name, err)
+		return
+	}
+
+	h.pendingWebhooks.Add(1)
+	go func() {
+		defer h.pendingWebhooks.Done()
+		err := h.webhookClient.PostToWebhook(h.FindingWebhook, h.WebhookHeaders, payload)
+		if err != nil {
+			log.Warnf("Failed to send finding %s to webhook: %v", f.Name, err)
+			return
+		}
+		log.Debugf("Sent finding %s to webhook %s", f.Name, h.FindingWebhook)
+	}()
+}
+
+// WaitForWebhooks blocks until all findings which are currently being
+// sent to the finding webhook were either delivered or timed out.
+func (h *ReportHandler) WaitForWebhooks() {
+	h.pendingWebhooks.Wait()
+}
+
+// UnsuppressedFindings returns the findings which were not suppressed
+// via a suppression comment in the source code.
+func (h *ReportHandler) UnsuppressedFindings() []*finding.Finding {
+	var findings []*finding.Finding
+	for _, f := range h.Findings {
+		if !f.Suppressed {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
 func (h *ReportHandler) PrintFindingInstruction() {
 	log.Note(`
 Use 'cifuzz finding <finding name>' for details on a finding.
@@ -319,11 +595,11 @@ func (h *ReportHandler) PrintFinalMetrics() error {
 	}
 
 	var averageExecsStr string
+	var averageExecs uint64
 
 	if h.FirstMetrics == nil {
 		averageExecsStr = metrics.NumberString("n/a")
 	} else {
-		var averageExecs uint64
 		metricsDuration := h.LastMetrics.Timestamp.Sub(h.FirstMetrics.Timestamp)
 		if metricsDuration.Milliseconds() == 0 {
 			// The first and last metrics are either the same or were
@@ -350,7 +626,7 @@ func (h *ReportHandler) PrintFinalMetrics() error {
 	lines := []string{
 		metrics.DescString("Execution time:\t") + metrics.NumberString(durationStr),
 		metrics.DescString("Average exec/s:\t") + averageExecsStr,
-		metrics.DescString("Findings:\t") + metrics.NumberString("%d", len(h.Findings)),
+		metrics.DescString("Findings:\t") + metrics.NumberString("%d", len(h.UnsuppressedFindings())),
 		metrics.DescString("Corpus entries:\t") + metrics.NumberString("%d", numCorpusEntries) +
 			metrics.DescString(" (+%s)", metrics.NumberString("%d", newCorpusEntries)),
 	}
@@ -367,9 +643,59 @@ func (h *ReportHandler) PrintFinalMetrics() error {
 		return errors.WithStack(err)
 	}
 
+	entry := &MetricsHistoryEntry{
+		Timestamp:          time.Now(),
+		FuzzTest:           h.FuzzTest,
+		DurationSeconds:    duration.Seconds(),
+		AverageExecsPerSec: averageExecs,
+		CorpusEntries:      numCorpusEntries,
+		NewCorpusEntries:   newCorpusEntries,
+		NumFindings:        len(h.UnsuppressedFindings()),
+	}
+	if h.LastMetrics != nil {
+		entry.TotalExecutions = h.LastMetrics.TotalExecutions
+	}
+	h.FinalMetrics = entry
+
+	if h.MetricsHistoryFile != "" {
+		err = AppendMetricsHistory(h.MetricsHistoryFile, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	if h.PrometheusOutput != "" {
+		err = WritePrometheusMetrics(h.PrometheusOutput, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	if h.SummaryFile != "" {
+		summary := NewRunSummary([]*FuzzTestSummary{h.FuzzTestSummary()})
+		err = WriteRunSummary(h.SummaryFile, summary)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// FuzzTestSummary returns the final metrics and the names of the
+// findings of the run, excluding suppressed findings. It returns nil if
+// PrintFinalMetrics wasn't called yet.
+func (h *ReportHandler) FuzzTestSummary() *FuzzTestSummary {
+	if h.FinalMetrics == nil {
+		return nil
+	}
+	summary := &FuzzTestSummary{MetricsHistoryEntry: h.FinalMetrics}
+	for _, f := range h.UnsuppressedFindings() {
+		summary.FindingNames = append(summary.FindingNames, f.Name)
+	}
+	return summary
+}
+
 func (h *ReportHandler) countCorpusEntries() (uint, error) {
 	var numSeeds uint
 	seedCorpusDirs := append(h.UserSeedCorpusDirs, h.ManagedSeedCorpusDir, h.GeneratedCorpusDir)
@@ -1,9 +1,15 @@
 package reporthandler
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +21,7 @@ import (
 	"code-intelligence.com/cifuzz/internal/testutil"
 	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
 	"code-intelligence.com/cifuzz/pkg/report"
 )
 
@@ -88,6 +95,27 @@ func TestReportHandler_Metrics(t *testing.T) {
 	checkOutput(t, printerOut, metrics.MetricsToString(metricsReport.Metric))
 }
 
+func TestReportHandler_FlushJSONOutput(t *testing.T) {
+	testDir := testutil.ChdirToTempDir(t, "report-handler-test-")
+	jsonFile, err := os.Create(filepath.Join(testDir, "output.json"))
+	require.NoError(t, err)
+	defer jsonFile.Close()
+	jsonOutput := bufio.NewWriter(jsonFile)
+	h, err := NewReportHandler("", &ReportHandlerOptions{
+		ProjectDir:     testDir,
+		JSONOutput:     jsonOutput,
+		SyncJSONOutput: true,
+	})
+	require.NoError(t, err)
+
+	err = h.Handle(&report.Report{Status: report.RunStatusRunning})
+	require.NoError(t, err)
+	assert.Zero(t, jsonOutput.Buffered())
+	content, err := os.ReadFile(jsonFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "RUNNING")
+}
+
 func TestReportHandler_Finding(t *testing.T) {
 	testDir := testutil.ChdirToTempDir(t, "report-handler-test-")
 	h, err := NewReportHandler("", &ReportHandlerOptions{ProjectDir: testDir, ManagedSeedCorpusDir: "seed_corpus"})
@@ -111,6 +139,35 @@ func TestReportHandler_Finding(t *testing.T) {
 	checkOutput(t, logOutput, expectedOutputs...)
 }
 
+func TestReportHandler_SuppressedFinding(t *testing.T) {
+	testDir := testutil.ChdirToTempDir(t, "report-handler-test-")
+	h, err := NewReportHandler("", &ReportHandlerOptions{ProjectDir: testDir, SkipSavingFinding: true})
+	require.NoError(t, err)
+
+	err = os.WriteFile("fuzz_test.c", []byte("// cifuzz:ignore known bug\nabort();\n"), 0o644)
+	require.NoError(t, err)
+
+	findingReport := &report.Report{
+		Status: report.RunStatusRunning,
+		Finding: &finding.Finding{
+			StackTrace: []*stacktrace.StackFrame{{SourceFile: "fuzz_test.c", Line: 2}},
+		},
+	}
+	err = h.Handle(findingReport)
+	require.NoError(t, err)
+
+	assert.True(t, findingReport.Finding.Suppressed)
+	assert.Len(t, h.Findings, 1)
+	assert.Empty(t, h.UnsuppressedFindings())
+	checkOutput(t, logOutput, "Suppressed finding", "known bug")
+
+	// Suppressed findings are not counted in the metrics
+	err = h.PrintFinalMetrics()
+	require.NoError(t, err)
+	assert.Zero(t, h.FinalMetrics.NumFindings)
+	assert.Empty(t, h.FuzzTestSummary().FindingNames)
+}
+
 func TestReportHandler_CorpusDirs(t *testing.T) {
 	h, err := NewReportHandler("", &ReportHandlerOptions{})
 	require.NoError(t, err)
@@ -154,6 +211,36 @@ func TestReportHandler_PrintJSON(t *testing.T) {
 	checkOutput(t, jsonOut, findingLogs...)
 }
 
+func TestReportHandler_JSONLines(t *testing.T) {
+	testDir := testutil.ChdirToTempDir(t, "report-handler-test-")
+	jsonOut := bytes.NewBuffer([]byte{})
+	h, err := NewReportHandler("", &ReportHandlerOptions{
+		ProjectDir: testDir,
+		JSONOutput: jsonOut,
+		JSONLines:  true,
+	})
+	require.NoError(t, err)
+
+	err = h.Handle(&report.Report{Status: report.RunStatusInitializing})
+	require.NoError(t, err)
+	err = h.Handle(&report.Report{
+		Status:  report.RunStatusRunning,
+		Finding: &finding.Finding{Logs: []string{"Oops", "The program crashed"}},
+	})
+	require.NoError(t, err)
+
+	// Each report is written as a single line of JSON
+	lines := strings.Split(strings.TrimSuffix(jsonOut.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var r report.Report
+		err = json.Unmarshal([]byte(line), &r)
+		require.NoError(t, err, line)
+	}
+	assert.Contains(t, lines[0], "INITIALIZING")
+	assert.Contains(t, lines[1], `"logs":["Oops","The program crashed"]`)
+}
+
 func TestReportHandler_GenerateName(t *testing.T) {
 	testDir := testutil.ChdirToTempDir(t, "report-handler-test-")
 	h, err := NewReportHandler("", &ReportHandlerOptions{ProjectDir: testDir})
@@ -172,6 +259,42 @@ func TestReportHandler_GenerateName(t *testing.T) {
 	assert.Equal(t, "adventurous_pangolin", findingReport.Finding.Name)
 }
 
+func TestReportHandler_FindingWebhook(t *testing.T) {
+	testDir := testutil.ChdirToTempDir(t, "report-handler-test-")
+
+	received := make(chan *finding.Finding, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		f := &finding.Finding{}
+		err := json.NewDecoder(r.Body).Decode(f)
+		assert.NoError(t, err)
+		received <- f
+	}))
+	defer server.Close()
+
+	h, err := NewReportHandler("my_fuzz_test", &ReportHandlerOptions{
+		ProjectDir:     testDir,
+		FindingWebhook: server.URL,
+		WebhookHeaders: []string{"Authorization: Bearer secret"},
+	})
+	require.NoError(t, err)
+
+	findingReport := &report.Report{
+		Status: report.RunStatusRunning,
+		Finding: &finding.Finding{
+			Logs:      []string{"Oops", "The program crashed"},
+			InputData: []byte("123"),
+		},
+	}
+	err = h.Handle(findingReport)
+	require.NoError(t, err)
+	h.WaitForWebhooks()
+
+	f := <-received
+	assert.Equal(t, findingReport.Finding.Name, f.Name)
+	assert.Equal(t, "my_fuzz_test", f.FuzzTest)
+}
+
 func checkOutput(t *testing.T, r io.Reader, s ...string) {
 	output, err := io.ReadAll(r)
 	require.NoError(t, err)
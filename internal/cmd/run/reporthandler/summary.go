@@ -0,0 +1,121 @@
+package reporthandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+)
+
+// RunSummary is the JSON summary of a run which is written to the file
+// specified via --summary-file. It contains the same final metrics as
+// the entries of the metrics history file and the names of the
+// findings. If multiple fuzz tests were run, the metrics are the totals
+// of all fuzz tests.
+type RunSummary struct {
+	*MetricsHistoryEntry
+	FindingNames []string `json:"finding_names"`
+	// The summaries of the individual fuzz tests of the run
+	FuzzTests []*FuzzTestSummary `json:"fuzz_tests"`
+}
+
+// FuzzTestSummary is the summary of a single fuzz test of a run.
+type FuzzTestSummary struct {
+	*MetricsHistoryEntry
+	FindingNames []string `json:"finding_names"`
+}
+
+// NewRunSummary returns the summary of a run of the given fuzz tests.
+// The fuzz test of the summary is only set if a single fuzz test was
+// run.
+func NewRunSummary(fuzzTests []*FuzzTestSummary) *RunSummary {
+	if len(fuzzTests) == 1 {
+		entry := *fuzzTests[0].MetricsHistoryEntry
+		return &RunSummary{
+			MetricsHistoryEntry: &entry,
+			FindingNames:        fuzzTests[0].FindingNames,
+			FuzzTests:           fuzzTests,
+		}
+	}
+
+	summary := &RunSummary{
+		MetricsHistoryEntry: &MetricsHistoryEntry{},
+		FuzzTests:           fuzzTests,
+	}
+	for _, fuzzTest := range fuzzTests {
+		if fuzzTest.Timestamp.After(summary.Timestamp) {
+			summary.Timestamp = fuzzTest.Timestamp
+		}
+		summary.DurationSeconds += fuzzTest.DurationSeconds
+		summary.TotalExecutions += fuzzTest.TotalExecutions
+		summary.CorpusEntries += fuzzTest.CorpusEntries
+		summary.NewCorpusEntries += fuzzTest.NewCorpusEntries
+		summary.NumFindings += fuzzTest.NumFindings
+		summary.FindingNames = append(summary.FindingNames, fuzzTest.FindingNames...)
+	}
+	if summary.DurationSeconds > 0 {
+		summary.AverageExecsPerSec = uint64(float64(summary.TotalExecutions) / summary.DurationSeconds)
+	}
+	return summary
+}
+
+// WriteRunSummary writes the summary as JSON to the file at path,
+// overwriting the file if it already exists.
+func WriteRunSummary(path string, summary *RunSummary) error {
+	// Write empty lists instead of null, which is easier to handle for
+	// consumers
+	if summary.FindingNames == nil {
+		summary.FindingNames = []string{}
+	}
+	if summary.FuzzTests == nil {
+		summary.FuzzTests = []*FuzzTestSummary{}
+	}
+	for _, fuzzTest := range summary.FuzzTests {
+		if fuzzTest.FindingNames == nil {
+			fuzzTest.FindingNames = []string{}
+		}
+	}
+	bytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.WriteFile(path, append(bytes, '\n'), 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// PrintFuzzTestSummaries prints a table with the findings, corpus
+// entries and average executions per second of each fuzz test, so that
+// it's visible which of the fuzz tests of a run found bugs or are slow.
+func PrintFuzzTestSummaries(fuzzTests []*FuzzTestSummary) error {
+	data := [][]string{
+		{"Fuzz Test", "Findings", "Corpus Entries", "Average exec/s"},
+	}
+	for _, fuzzTest := range fuzzTests {
+		data = append(data, []string{
+			fuzzTest.FuzzTest,
+			fmt.Sprint(fuzzTest.NumFindings),
+			fmt.Sprint(fuzzTest.CorpusEntries),
+			fmt.Sprint(fuzzTest.AverageExecsPerSec),
+		})
+	}
+	log.Print("\n")
+	tableString, err := pterm.DefaultTable.WithHasHeader().WithData(data).Srender()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	log.Print(tableString + "\n")
+	return nil
+}
@@ -0,0 +1,71 @@
+package reporthandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRunSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary", "cifuzz-summary.json")
+
+	err := WriteRunSummary(path, &RunSummary{
+		MetricsHistoryEntry: &MetricsHistoryEntry{FuzzTest: "my_fuzz_test", CorpusEntries: 3},
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var summary map[string]any
+	err = json.Unmarshal(content, &summary)
+	require.NoError(t, err)
+	// The metrics are flattened into the summary
+	assert.Equal(t, "my_fuzz_test", summary["fuzz_test"])
+	assert.Equal(t, float64(3), summary["corpus_entries"])
+	assert.Equal(t, []any{}, summary["finding_names"])
+}
+
+func TestNewRunSummary(t *testing.T) {
+	fuzzTests := []*FuzzTestSummary{
+		{
+			MetricsHistoryEntry: &MetricsHistoryEntry{FuzzTest: "a", DurationSeconds: 10, TotalExecutions: 1000, CorpusEntries: 3, NumFindings: 1},
+			FindingNames:        []string{"funny_frog"},
+		},
+		{
+			MetricsHistoryEntry: &MetricsHistoryEntry{FuzzTest: "b", DurationSeconds: 30, TotalExecutions: 3000, CorpusEntries: 5},
+		},
+	}
+
+	summary := NewRunSummary(fuzzTests)
+	assert.Equal(t, "", summary.FuzzTest)
+	assert.Equal(t, float64(40), summary.DurationSeconds)
+	assert.Equal(t, uint64(4000), summary.TotalExecutions)
+	assert.Equal(t, uint64(100), summary.AverageExecsPerSec)
+	assert.Equal(t, uint(8), summary.CorpusEntries)
+	assert.Equal(t, 1, summary.NumFindings)
+	assert.Equal(t, []string{"funny_frog"}, summary.FindingNames)
+
+	path := filepath.Join(t.TempDir(), "cifuzz-summary.json")
+	err := WriteRunSummary(path, summary)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written map[string]any
+	err = json.Unmarshal(content, &written)
+	require.NoError(t, err)
+	// The fuzz tests are listed individually
+	require.Len(t, written["fuzz_tests"], 2)
+	second := written["fuzz_tests"].([]any)[1].(map[string]any)
+	assert.Equal(t, "b", second["fuzz_test"])
+	assert.Equal(t, []any{}, second["finding_names"])
+
+	// The summary of a single fuzz test has the same metrics
+	summary = NewRunSummary(fuzzTests[:1])
+	assert.Equal(t, "a", summary.FuzzTest)
+	assert.Equal(t, uint(3), summary.CorpusEntries)
+	assert.Len(t, summary.FuzzTests, 1)
+}
@@ -10,9 +10,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"golang.org/x/term"
 
 	"code-intelligence.com/cifuzz/internal/api"
+	"code-intelligence.com/cifuzz/internal/build/java"
 	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
 	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
@@ -21,10 +23,12 @@ import (
 	"code-intelligence.com/cifuzz/internal/cmdutils/resolve"
 	"code-intelligence.com/cifuzz/internal/completion"
 	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
 	"code-intelligence.com/cifuzz/pkg/dialog"
 	"code-intelligence.com/cifuzz/pkg/finding"
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/report"
+	"code-intelligence.com/cifuzz/util/fileutil"
 	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
@@ -43,7 +47,7 @@ func New() *cobra.Command {
 	var bindFlags func()
 
 	cmd := &cobra.Command{
-		Use:   "run [flags] <fuzz test> [--] [<build system arg>...] ",
+		Use:   "run [flags] <fuzz test>|--all [--] [<build system arg>...] ",
 		Short: "Build and run a fuzz test",
 		Long: `This command builds and executes a fuzz test. The usage of this command
 depends on the build system configured for the project.
@@ -72,7 +76,7 @@ depends on the build system configured for the project.
     <fuzz test>.dict
 
   is used automatically if no other dictionary is specified
-  by using the --dict flag.
+  by using the --dict flag. Use --no-default-dict to disable this.
 
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Bazel") + `
   <fuzz test> is the name of the cc_fuzz_test target as defined in your
@@ -108,6 +112,11 @@ depends on the build system configured for the project.
 
   are used as a starting point for the fuzzing run.
 
+  With the --all flag, all fuzz tests of the project are run one after
+  another instead of a single fuzz test. For example:
+
+    cifuzz run --all --max-total-time 10m
+
 ` + pterm.Style{pterm.Reset, pterm.Bold}.Sprint("Node.js") + `
   <fuzz test> is a regex pattern that matches against all paths
   containing fuzz test files.
@@ -155,7 +164,7 @@ depends on the build system configured for the project.
     <fuzz test>.dict
 
   is used automatically if no other dictionary is specified
-  by using the --dict flag.
+  by using the --dict flag. Use --no-default-dict to disable this.
 
 `,
 		ValidArgsFunction: completion.ValidFuzzTests,
@@ -175,7 +184,14 @@ depends on the build system configured for the project.
 			} else {
 				lenFuzzTestArgs = len(args)
 			}
-			if lenFuzzTestArgs != 1 {
+			if viper.GetBool("all") {
+				// All fuzz tests of the project are run, so the fuzz
+				// tests are determined after the build
+				if lenFuzzTestArgs != 0 {
+					msg := fmt.Sprintf("No <fuzz test> argument can be provided when using --all, got %d", lenFuzzTestArgs)
+					return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+				}
+			} else if lenFuzzTestArgs != 1 {
 				msg := fmt.Sprintf("Exactly one <fuzz test> argument must be provided, got %d", lenFuzzTestArgs)
 				return cmdutils.WrapIncorrectUsageError(errors.New(msg))
 			}
@@ -185,7 +201,12 @@ depends on the build system configured for the project.
 				return err
 			}
 
-			if sliceutil.Contains(
+			if opts.All {
+				if opts.BuildSystem != config.BuildSystemMaven && opts.BuildSystem != config.BuildSystemGradle {
+					msg := "Flag \"all\" is only supported for build system types \"maven\" and \"gradle\""
+					return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+				}
+			} else if sliceutil.Contains(
 				[]string{config.BuildSystemMaven, config.BuildSystemGradle},
 				opts.BuildSystem,
 			) {
@@ -203,11 +224,13 @@ depends on the build system configured for the project.
 				}
 			}
 
-			fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath, args, opts.BuildSystem, opts.ProjectDir)
-			if err != nil {
-				return err
+			if !opts.All {
+				fuzzTests, err := resolve.FuzzTestArguments(opts.ResolveSourceFilePath && !opts.NoResolveSourcePath, args, opts.BuildSystem, opts.ProjectDir, opts.Offline)
+				if err != nil {
+					return err
+				}
+				opts.FuzzTest = fuzzTests[0]
 			}
-			opts.FuzzTest = fuzzTests[0]
 
 			opts.ArgsToPass = argsToPass
 
@@ -223,15 +246,27 @@ depends on the build system configured for the project.
 			opts.Stdout = cmd.OutOrStdout()
 			opts.Stderr = cmd.OutOrStderr()
 
+			err = opts.Validate()
+			if err != nil {
+				return err
+			}
+			opts.ApplyCIPreset()
+
 			if logging.ShouldLogBuildToFile() {
-				opts.BuildStdout, err = logging.BuildOutputToFile(opts.ProjectDir, []string{opts.FuzzTest})
+				fuzzTestNames := []string{opts.FuzzTest}
+				if opts.All {
+					// The builds of all fuzz tests are logged to the
+					// same file, named after the "all" suffix
+					fuzzTestNames = nil
+				}
+				opts.BuildStdout, err = logging.BuildOutputToFile(opts.OutputRoot, fuzzTestNames)
 				if err != nil {
 					return err
 				}
 				opts.BuildStderr = opts.BuildStdout
 			}
 
-			return opts.Validate()
+			return nil
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			var err error
@@ -240,8 +275,16 @@ depends on the build system configured for the project.
 				return err
 			}
 
+			if opts.FindingWebhook != "" {
+				err = api.ValidateWebhookURL(opts.FindingWebhook)
+				if err != nil {
+					return cmdutils.WrapIncorrectUsageError(err)
+				}
+			}
+
 			cmd := runCmd{Command: c, opts: opts}
 			cmd.apiClient = api.NewClient(opts.Server)
+			cmd.apiClient.Proxy = opts.Proxy
 			return cmd.run()
 		},
 	}
@@ -249,20 +292,61 @@ depends on the build system configured for the project.
 	// Note: If a flag should be configurable via cifuzz.yaml as well,
 	// bind it to viper in the PreRunE function.
 	funcs := []func(cmd *cobra.Command) func(){
+		cmdutils.AddAllFlag,
+		cmdutils.AddASanODRViolationFlag,
 		cmdutils.AddBuildCommandFlag,
+		cmdutils.AddBuildEnvFlag,
 		cmdutils.AddCleanCommandFlag,
 		cmdutils.AddBuildJobsFlag,
+		cmdutils.AddBuildMemoryLimitFlag,
 		cmdutils.AddBuildOnlyFlag,
+		cmdutils.AddCIFlag,
+		cmdutils.AddClassPathFlag,
+		cmdutils.AddCorpusIndexFlag,
+		cmdutils.AddCorpusOutputFlag,
+		cmdutils.AddDeterministicCorpusOrderFlag,
 		cmdutils.AddDictFlag,
+		cmdutils.AddDisplayNameFlag,
+		cmdutils.AddEngineFlag,
 		cmdutils.AddEngineArgFlag,
+		cmdutils.AddFailOnFlag,
+		cmdutils.AddFindingWebhookFlag,
 		cmdutils.AddInteractiveFlag,
+		cmdutils.AddJSONFlushFlag,
+		cmdutils.AddMaxRunsFlag,
+		cmdutils.AddMaxTotalTimeFlag,
+		cmdutils.AddMetricsFileFlag,
+		cmdutils.AddMetricsHistoryFileFlag,
+		cmdutils.AddMetricsIntervalFlag,
+		cmdutils.AddNativeLibPathFlag,
+		cmdutils.AddNoDefaultDictFlag,
+		cmdutils.AddNoResolveSourcePathFlag,
+		cmdutils.AddOfflineFlag,
+		cmdutils.AddOutputRootFlag,
+		cmdutils.AddPrintCommandFlag,
+		cmdutils.AddPrintFlagsFlag,
 		cmdutils.AddPrintJSONFlag,
 		cmdutils.AddProjectFlag,
 		cmdutils.AddProjectDirFlag,
+		cmdutils.AddPrometheusOutputFlag,
+		cmdutils.AddProxyFlag,
+		cmdutils.AddRefreshErrorDetailsFlag,
+		cmdutils.AddReproduceFlag,
+		cmdutils.AddRequireSeedsFlag,
+		cmdutils.AddRetryOnInfraFailureFlag,
+		cmdutils.AddRunnerBinaryFlag,
+		cmdutils.AddSanitizersFlag,
 		cmdutils.AddSeedCorpusFlag,
 		cmdutils.AddServerFlag,
+		cmdutils.AddStopOnPlateauFlag,
+		cmdutils.AddStripPathsFlag,
+		cmdutils.AddSummaryFileFlag,
+		cmdutils.AddSymbolizerFlag,
 		cmdutils.AddTimeoutFlag,
+		cmdutils.AddUnitTimeoutFlag,
 		cmdutils.AddUseSandboxFlag,
+		cmdutils.AddWarnOversizedSeedsFlag,
+		cmdutils.AddWebhookHeaderFlag,
 		cmdutils.AddResolveSourceFileFlag,
 	}
 	bindFlags = cmdutils.AddFlags(cmd, funcs...)
@@ -270,7 +354,7 @@ depends on the build system configured for the project.
 }
 
 func (c *runCmd) run() error {
-	errorDetails, token, err := auth.TryGetErrorDetailsAndToken(c.opts.Server)
+	errorDetails, token, err := auth.TryGetErrorDetailsAndToken(c.apiClient, c.opts.RefreshErrorDetails)
 	if err != nil {
 		return err
 	}
@@ -282,12 +366,104 @@ func (c *runCmd) run() error {
 	}
 	defer adapter.Cleanup()
 
-	err = adapter.CheckDependencies(c.opts.ProjectDir)
+	depStatuses, err := adapter.CheckDependencies(c.opts.ProjectDir)
+	if c.opts.PrintJSON {
+		jsonErr := dependencies.PrintJSON(c.OutOrStdout(), depStatuses)
+		if jsonErr != nil {
+			return jsonErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.opts.All {
+		return c.runAllFuzzTests(adapter, token)
+	}
+	return c.runFuzzTest(adapter, token)
+}
+
+// runAllFuzzTests runs all fuzz tests of the JVM project one after
+// another.
+func (c *runCmd) runAllFuzzTests(runAdapter adapter.Adapter, token string) error {
+	testDirs, err := java.TestDirs(c.opts.ProjectDir, c.opts.BuildSystem, c.opts.Offline)
+	if err != nil {
+		return err
+	}
+	fuzzTests, err := cmdutils.ListJVMFuzzTestsByRegex(testDirs, "")
 	if err != nil {
 		return err
 	}
+	if len(fuzzTests) == 0 {
+		log.Warn("No fuzz tests were found in the project")
+		return nil
+	}
+
+	allOpts := c.opts
+	defer func() { c.opts = allOpts }()
+	var summaries []*reporthandler.FuzzTestSummary
+	for i, fuzzTest := range fuzzTests {
+		log.Infof("Running %d/%d: %s", i+1, len(fuzzTests), pterm.Style{pterm.Reset, pterm.FgLightBlue}.Sprint(fuzzTest))
+
+		opts := *allOpts
+		opts.FuzzTest, opts.TargetMethod = cmdutils.SeparateTargetClassAndMethod(fuzzTest)
+		// Each fuzz test uses the seed corpus dirs specified by the
+		// user, the adapter adds the default seed corpus of the fuzz test
+		opts.SeedCorpusDirs = append([]string{}, allOpts.SeedCorpusDirs...)
+		// The summary and the Prometheus metrics of all fuzz tests are
+		// written after the last one, because each run would overwrite
+		// the files. The corpus index doesn't need to be aggregated,
+		// because each fuzz test has its own generated corpus and
+		// therefore its own index.
+		opts.SummaryFile = ""
+		opts.PrometheusOutput = ""
+		c.opts = &opts
+
+		c.reportHandler = nil
+		err = c.runFuzzTest(runAdapter, token)
+		if c.reportHandler != nil && c.reportHandler.FuzzTestSummary() != nil {
+			summaries = append(summaries, c.reportHandler.FuzzTestSummary())
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if len(summaries) > 0 {
+		printErr := reporthandler.PrintFuzzTestSummaries(summaries)
+		if printErr != nil {
+			return printErr
+		}
+		if allOpts.SummaryFile != "" {
+			writeErr := reporthandler.WriteRunSummary(allOpts.SummaryFile, reporthandler.NewRunSummary(summaries))
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+		if allOpts.PrometheusOutput != "" {
+			var entries []*reporthandler.MetricsHistoryEntry
+			for _, summary := range summaries {
+				entries = append(entries, summary.MetricsHistoryEntry)
+			}
+			writeErr := reporthandler.WritePrometheusMetrics(allOpts.PrometheusOutput, entries...)
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+	}
+	return err
+}
 
+// runFuzzTest runs the fuzz test specified in the options and handles
+// its findings.
+func (c *runCmd) runFuzzTest(adapter adapter.Adapter, token string) error {
+	var err error
 	c.reportHandler, err = adapter.Run(c.opts)
+	if c.reportHandler != nil {
+		// Give findings which are still being sent to the finding
+		// webhook a chance to be delivered before we exit.
+		defer c.reportHandler.WaitForWebhooks()
+	}
 	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) && c.opts.UseSandbox {
@@ -299,7 +475,17 @@ func (c *runCmd) run() error {
 	if c.reportHandler == nil && err == nil {
 		return nil
 	}
-	c.reportHandler.ErrorDetails = errorDetails
+	c.reportHandler.ErrorDetails = c.errorDetails
+
+	err = c.maybeExportCorpus()
+	if err != nil {
+		return err
+	}
+
+	err = c.maybeWriteCorpusIndex()
+	if err != nil {
+		return err
+	}
 
 	c.reportHandler.PrintCrashingInputNote()
 	err = c.reportHandler.PrintFinalMetrics()
@@ -307,6 +493,15 @@ func (c *runCmd) run() error {
 		return err
 	}
 
+	err = c.maybeUploadFindings(token)
+	if err != nil {
+		return err
+	}
+
+	return c.checkFailOn()
+}
+
+func (c *runCmd) maybeUploadFindings(token string) error {
 	// We need this check, otherwise we might hang forever in CI
 	if c.opts.Project == "" && !c.opts.Interactive {
 		log.Info("Skipping upload of findings because no project was specified and running in non-interactive mode.")
@@ -318,13 +513,59 @@ func (c *runCmd) run() error {
 	}
 
 	// check if there are findings that should be uploaded
-	if token != "" && len(c.reportHandler.Findings) > 0 {
-		err = c.uploadFindings(c.getFuzzTestNameForCampaignRun(), c.opts.BuildSystem, c.reportHandler.FirstMetrics, c.reportHandler.LastMetrics, token)
-		if err != nil {
-			return err
-		}
+	if token != "" && len(c.reportHandler.UnsuppressedFindings()) > 0 {
+		return c.uploadFindings(c.getFuzzTestNameForCampaignRun(), c.opts.BuildSystem, c.reportHandler.FirstMetrics, c.reportHandler.LastMetrics, token)
+	}
+	return nil
+}
+
+// maybeWriteCorpusIndex writes the coverage metadata of the entries
+// added to the generated corpus to the corpus index, if --corpus-index
+// was specified.
+func (c *runCmd) maybeWriteCorpusIndex() error {
+	if !c.opts.CorpusIndex {
+		return nil
+	}
+	if c.reportHandler.GeneratedCorpusDir == "" {
+		log.Warn("Not writing the corpus index because the location of the generated corpus is unknown")
+		return nil
 	}
+	path, err := c.reportHandler.WriteCorpusIndex()
+	if err != nil {
+		return errors.WithMessage(err, "Failed to write the corpus index")
+	}
+	log.Infof("Wrote the corpus index to %s", fileutil.PrettifyPath(path))
+	return nil
+}
+
+// maybeExportCorpus copies the inputs of the generated corpus to the
+// directory specified via --corpus-output.
+func (c *runCmd) maybeExportCorpus() error {
+	if c.opts.CorpusOutput == "" {
+		return nil
+	}
+	if c.reportHandler.GeneratedCorpusDir == "" {
+		log.Warn("Not exporting the generated corpus because its location is unknown")
+		return nil
+	}
+	numExported, err := cmdutils.ExportCorpus(c.reportHandler.GeneratedCorpusDir, c.opts.CorpusOutput)
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to export the generated corpus to %s", c.opts.CorpusOutput)
+	}
+	log.Infof("Exported %d inputs of the generated corpus to %s", numExported, fileutil.PrettifyPath(c.opts.CorpusOutput))
+	return nil
+}
 
+// checkFailOn returns an error if a finding of one of the kinds
+// specified via --fail-on was found. Suppressed findings are ignored.
+func (c *runCmd) checkFailOn() error {
+	for _, kind := range c.opts.FailOn {
+		for _, f := range c.reportHandler.UnsuppressedFindings() {
+			if f.IsKind(kind) {
+				return errors.Errorf("Found finding %s of kind %q, which was specified via --fail-on", f.Name, kind)
+			}
+		}
+	}
 	return nil
 }
 
@@ -372,32 +613,55 @@ Findings have *not* been uploaded. Please check the 'project' entry in your cifu
 	}
 
 	// create campaign run on server for selected project
-	campaignRunName, fuzzingRunName, err := c.apiClient.CreateCampaignRun(project, token, fuzzTarget, buildSystem, firstMetrics, lastMetrics)
+	campaignRunName, fuzzingRunName, err := c.apiClient.CreateCampaignRun(project, token, fuzzTarget, c.opts.DisplayName, buildSystem, firstMetrics, lastMetrics)
+	if err != nil {
+		return err
+	}
+
+	// The overrides were already validated in RunOptions.Validate
+	severityOverrides, err := finding.ParseSeverityOverrides(c.opts.SeverityOverrides)
 	if err != nil {
 		return err
 	}
 
-	// upload findings
-	for _, finding := range c.reportHandler.Findings {
+	// upload findings, except for the suppressed ones
+	findings := c.reportHandler.UnsuppressedFindings()
+	uploaded := make([]*api.UploadedFinding, 0, len(findings))
+	for _, finding := range findings {
 		if c.errorDetails != nil {
 			finding.EnhanceWithErrorDetails(c.errorDetails)
 		}
-		err = c.apiClient.UploadFinding(project, fuzzTarget, campaignRunName, fuzzingRunName, finding, token)
+		finding.ApplySeverityOverrides(severityOverrides)
+		uploadedFinding, err := c.apiClient.UploadFinding(project, fuzzTarget, c.opts.DisplayName, campaignRunName, fuzzingRunName, finding, token)
 		if err != nil {
 			return err
 		}
+		uploaded = append(uploaded, uploadedFinding)
 		// after a finding has been uploaded, we can delete the local copy
-		err = finding.Remove(c.opts.ProjectDir)
+		err = finding.Remove(c.opts.OutputRoot)
 		if err != nil {
 			return errors.WithMessage(err, fmt.Sprintf("Failed to remove finding %s", finding.Name))
 		}
 	}
-	log.Notef("Uploaded %d findings to CI Sense at: %s", len(c.reportHandler.Findings), c.opts.Server)
+	log.Notef("Uploaded %d findings to CI Sense at: %s", len(findings), c.opts.Server)
 	log.Infof("You can view the findings at %s/dashboard/%s/findings?origin=cli", c.opts.Server, campaignRunName)
+	printUploadedFindings(findings, uploaded)
 
 	return nil
 }
 
+// printUploadedFindings prints which remote finding each of the local
+// findings was stored as.
+func printUploadedFindings(findings []*finding.Finding, uploaded []*api.UploadedFinding) {
+	if len(uploaded) == 0 {
+		return
+	}
+	log.Info("Uploaded findings:")
+	for i, u := range uploaded {
+		log.Infof("  %s -> %s [%s]", findings[i].Name, u.DisplayName, u.Name)
+	}
+}
+
 func (c *runCmd) getFuzzTestNameForCampaignRun() string {
 	if c.opts.BuildSystem == config.BuildSystemMaven ||
 		c.opts.BuildSystem == config.BuildSystemGradle {
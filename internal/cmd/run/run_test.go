@@ -83,3 +83,19 @@ func TestVisualStudioMissing(t *testing.T) {
 	assert.Contains(t, stdErr,
 		fmt.Sprintf(dependencies.MessageVersion, "Visual Studio", dep.MinVersion.String(), version))
 }
+
+func TestAllFlag(t *testing.T) {
+	testutil.BootstrapExampleProjectForTest(t, "run-cmd-test", config.BuildSystemCMake)
+
+	// --all can't be combined with a fuzz test argument
+	_, _, err := cmdutils.ExecuteCommand(t, New(), os.Stdin, "--all", "my_fuzz_test")
+	require.Error(t, err)
+	var usageErr *cmdutils.IncorrectUsageError
+	assert.ErrorAs(t, err, &usageErr)
+
+	// --all is only supported for JVM projects
+	_, _, err = cmdutils.ExecuteCommand(t, New(), os.Stdin, "--all")
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+	assert.Contains(t, err.Error(), "is only supported for build system types")
+}
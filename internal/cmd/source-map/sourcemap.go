@@ -0,0 +1,181 @@
+package sourcemap
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"code-intelligence.com/cifuzz/internal/build/java"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
+	"code-intelligence.com/cifuzz/util/stringutil"
+)
+
+type options struct {
+	PrintJSON   bool   `mapstructure:"print-json"`
+	ProjectDir  string `mapstructure:"project-dir"`
+	BuildSystem string `mapstructure:"build-system"`
+}
+
+// FuzzTestSource maps a fuzz test to the source files in which it is
+// defined. The paths of the source files are relative to the project
+// directory and use forward slashes.
+type FuzzTestSource struct {
+	FuzzTest    string   `json:"fuzz_test"`
+	SourceFiles []string `json:"source_files"`
+}
+
+type sourceMapCmd struct {
+	*cobra.Command
+	opts *options
+}
+
+func New() *cobra.Command {
+	opts := &options{}
+	var bindFlags func()
+
+	cmd := &cobra.Command{
+		Use:   "source-map",
+		Short: "Print the source files of the fuzz tests in the project",
+		Long: `This command prints the source files in which the fuzz tests of the
+project are defined, e.g. to allow editor plugins to jump from a fuzz
+test name to its file.
+
+For CMake and Bazel projects, the fuzz tests are determined from the
+add_fuzz_test and cc_fuzz_test target definitions. For Maven and Gradle
+projects, the fuzz test classes are looked up in the source map of the
+project.`,
+		Example: "cifuzz source-map --json",
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Bind viper keys to flags. We can't do this in the New
+			// function, because that would re-bind viper keys which
+			// were bound to the flags of other commands before.
+			bindFlags()
+			return config.FindAndParseProjectConfig(opts)
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd := sourceMapCmd{Command: c, opts: opts}
+			return cmd.run()
+		},
+	}
+
+	bindFlags = cmdutils.AddFlags(cmd,
+		cmdutils.AddPrintJSONFlag,
+		cmdutils.AddProjectDirFlag,
+	)
+
+	return cmd
+}
+
+func (c *sourceMapCmd) run() error {
+	var fuzzTests []*FuzzTestSource
+	var err error
+	switch c.opts.BuildSystem {
+	case config.BuildSystemCMake:
+		fuzzTests, err = cmakeFuzzTestSources(c.opts.ProjectDir)
+	case config.BuildSystemBazel:
+		fuzzTests, err = bazelFuzzTestSources(c.opts.ProjectDir)
+	case config.BuildSystemMaven, config.BuildSystemGradle:
+		fuzzTests, err = jvmFuzzTestSources(c.opts.ProjectDir, c.opts.BuildSystem)
+	case config.BuildSystemNodeJS:
+		fuzzTests, err = nodeFuzzTestSources(c.opts.ProjectDir)
+	default:
+		err = errors.Errorf("The source map is not supported for build system type \"%s\"", c.opts.BuildSystem)
+	}
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(fuzzTests, func(i, j int) bool {
+		return fuzzTests[i].FuzzTest < fuzzTests[j].FuzzTest
+	})
+
+	if c.opts.PrintJSON {
+		// Print an empty list instead of null if there are no fuzz tests
+		if fuzzTests == nil {
+			fuzzTests = []*FuzzTestSource{}
+		}
+		s, err := stringutil.ToJSONString(fuzzTests)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(c.OutOrStdout(), s)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, fuzzTest := range fuzzTests {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", fuzzTest.FuzzTest, strings.Join(fuzzTest.SourceFiles, ", "))
+	}
+	return errors.WithStack(w.Flush())
+}
+
+// jvmFuzzTestSources looks up the source files of the JVM fuzz tests in
+// the source map of the project.
+func jvmFuzzTestSources(projectDir, buildSystem string) ([]*FuzzTestSource, error) {
+	testDirs, err := java.TestDirs(projectDir, buildSystem, false)
+	if err != nil {
+		return nil, err
+	}
+	sourceMap, err := sourcemap.CreateSourceMap(projectDir, testDirs)
+	if err != nil {
+		return nil, err
+	}
+	fuzzTests, err := cmdutils.ListJVMFuzzTestsByRegex(testDirs, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*FuzzTestSource
+	for _, fuzzTest := range fuzzTests {
+		className, _ := cmdutils.SeparateTargetClassAndMethod(fuzzTest)
+		var packageName string
+		if i := strings.LastIndex(className, "."); i != -1 {
+			packageName, className = className[:i], className[i+1:]
+		}
+
+		var sourceFiles []string
+		for _, sourceFile := range sourceMap.JavaPackages[packageName] {
+			base := filepath.Base(sourceFile)
+			if strings.TrimSuffix(base, filepath.Ext(base)) == className {
+				sourceFiles = append(sourceFiles, sourceFile)
+			}
+		}
+		res = append(res, &FuzzTestSource{FuzzTest: fuzzTest, SourceFiles: sourceFiles})
+	}
+	return res, nil
+}
+
+// nodeFuzzTestSources returns the Node.js fuzz test files of the
+// project.
+func nodeFuzzTestSources(projectDir string) ([]*FuzzTestSource, error) {
+	// use zglob to support globbing in windows
+	matches, err := zglob.Glob(filepath.Join(projectDir, "**", "*.fuzz.{js,ts}"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var res []*FuzzTestSource
+	for _, match := range matches {
+		relPath, err := filepath.Rel(projectDir, match)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		relPath = filepath.ToSlash(relPath)
+		if strings.HasPrefix(relPath, "node_modules/") || strings.Contains(relPath, "/node_modules/") {
+			continue
+		}
+		fuzzTest := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(match), ".js"), ".ts")
+		fuzzTest = strings.TrimSuffix(fuzzTest, ".fuzz")
+		res = append(res, &FuzzTestSource{FuzzTest: fuzzTest, SourceFiles: []string{relPath}})
+	}
+	return res, nil
+}
@@ -0,0 +1,214 @@
+package sourcemap
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/util/stringutil"
+)
+
+var (
+	cmakeFuzzTestPattern = regexp.MustCompile(`(?i)add_fuzz_test\s*\(([^)]*)\)`)
+	bazelFuzzTestPattern = regexp.MustCompile(`cc_fuzz_test\s*\(([^)]*)\)`)
+	bazelNamePattern     = regexp.MustCompile(`name\s*=\s*["']([^"']+)["']`)
+	bazelSrcsPattern     = regexp.MustCompile(`srcs\s*=\s*\[([^\]]*)\]`)
+	bazelStringPattern   = regexp.MustCompile(`["']([^"']+)["']`)
+
+	// The keywords of the multi-value arguments of the add_fuzz_test
+	// CMake function
+	cmakeKeywords = []string{"DEPENDENCIES", "INCLUDE_DIRS", "SOURCES"}
+)
+
+// cmakeFuzzTestSources returns the source files of the fuzz tests which
+// are defined via add_fuzz_test in the CMakeLists.txt files of the
+// project.
+func cmakeFuzzTestSources(projectDir string) ([]*FuzzTestSource, error) {
+	files, err := findFiles(projectDir, "CMakeLists.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*FuzzTestSource
+	for _, file := range files {
+		content, err := readWithoutComments(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range cmakeFuzzTestPattern.FindAllStringSubmatch(content, -1) {
+			name, sources := parseAddFuzzTestArgs(match[1])
+			if name == "" {
+				continue
+			}
+			sourceFiles, err := relSourceFiles(projectDir, filepath.Dir(file), sources)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, &FuzzTestSource{FuzzTest: name, SourceFiles: sourceFiles})
+		}
+	}
+	return res, nil
+}
+
+// parseAddFuzzTestArgs returns the name and the sources of an
+// add_fuzz_test call. The sources are either specified after the
+// SOURCES keyword or as the unparsed arguments after the name.
+func parseAddFuzzTestArgs(args string) (string, []string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	for i := range fields {
+		fields[i] = strings.Trim(fields[i], `"`)
+	}
+
+	var unparsed, sources []string
+	keyword := ""
+	for _, field := range fields[1:] {
+		if stringutil.Contains(cmakeKeywords, field) {
+			keyword = field
+			continue
+		}
+		switch keyword {
+		case "":
+			unparsed = append(unparsed, field)
+		case "SOURCES":
+			sources = append(sources, field)
+		}
+	}
+	if len(sources) == 0 {
+		sources = unparsed
+	}
+	return fields[0], sources
+}
+
+// bazelFuzzTestSources returns the source files of the fuzz tests which
+// are defined via cc_fuzz_test in the BUILD files of the project.
+func bazelFuzzTestSources(projectDir string) ([]*FuzzTestSource, error) {
+	files, err := findFiles(projectDir, "BUILD", "BUILD.bazel")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*FuzzTestSource
+	for _, file := range files {
+		content, err := readWithoutComments(file)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg, err := filepath.Rel(projectDir, filepath.Dir(file))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		pkg = filepath.ToSlash(pkg)
+		if pkg == "." {
+			pkg = ""
+		}
+
+		for _, match := range bazelFuzzTestPattern.FindAllStringSubmatch(content, -1) {
+			nameMatch := bazelNamePattern.FindStringSubmatch(match[1])
+			if nameMatch == nil {
+				continue
+			}
+
+			var sources []string
+			if srcsMatch := bazelSrcsPattern.FindStringSubmatch(match[1]); srcsMatch != nil {
+				for _, src := range bazelStringPattern.FindAllStringSubmatch(srcsMatch[1], -1) {
+					// Labels of other packages or repositories can't
+					// be resolved to a file without querying bazel
+					if strings.HasPrefix(src[1], "//") || strings.HasPrefix(src[1], "@") {
+						continue
+					}
+					sources = append(sources, strings.TrimPrefix(src[1], ":"))
+				}
+			}
+			sourceFiles, err := relSourceFiles(projectDir, filepath.Dir(file), sources)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, &FuzzTestSource{
+				FuzzTest:    "//" + pkg + ":" + nameMatch[1],
+				SourceFiles: sourceFiles,
+			})
+		}
+	}
+	return res, nil
+}
+
+// relSourceFiles returns the paths of the sources, which are relative
+// to the specified directory, relative to the project directory.
+// Sources which contain variables are skipped.
+func relSourceFiles(projectDir, dir string, sources []string) ([]string, error) {
+	var res []string
+	for _, source := range sources {
+		if strings.Contains(source, "${") || strings.Contains(source, "$<") {
+			continue
+		}
+		path := source
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		relPath, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res = append(res, filepath.ToSlash(relPath))
+	}
+	return res, nil
+}
+
+// findFiles returns the paths of the files with one of the specified
+// names in the project directory. Hidden directories, which contain
+// e.g. the build directories of cifuzz, are skipped.
+func findFiles(projectDir string, names ...string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != projectDir && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if stringutil.Contains(names, d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return files, nil
+}
+
+// readWithoutComments returns the content of the CMake or Bazel file
+// with comment lines removed.
+func readWithoutComments(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer file.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.Join(lines, "\n"), nil
+}
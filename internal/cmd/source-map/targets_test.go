@@ -0,0 +1,69 @@
+package sourcemap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(path, []byte(content), 0o644)
+	require.NoError(t, err)
+}
+
+func TestCMakeFuzzTestSources(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "CMakeLists.txt"), `
+add_subdirectory(fuzz)
+# add_fuzz_test(commented_out commented_out.cpp)
+`)
+	writeFile(t, filepath.Join(projectDir, "fuzz", "CMakeLists.txt"), `
+add_fuzz_test(my_fuzz_test my_fuzz_test.cpp)
+add_fuzz_test(other_fuzz_test
+  SOURCES other_fuzz_test.cpp "helper.cpp" ${GENERATED_SOURCE}
+  DEPENDENCIES exploreMe
+)
+`)
+	// Files in hidden directories like the build directory are ignored
+	writeFile(t, filepath.Join(projectDir, ".cifuzz-build", "CMakeLists.txt"), `add_fuzz_test(build_fuzz_test build.cpp)`)
+
+	fuzzTests, err := cmakeFuzzTestSources(projectDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*FuzzTestSource{
+		{FuzzTest: "my_fuzz_test", SourceFiles: []string{"fuzz/my_fuzz_test.cpp"}},
+		{FuzzTest: "other_fuzz_test", SourceFiles: []string{"fuzz/other_fuzz_test.cpp", "fuzz/helper.cpp"}},
+	}, fuzzTests)
+}
+
+func TestBazelFuzzTestSources(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "BUILD.bazel"), `
+cc_fuzz_test(
+    name = "root_fuzz_test",
+    srcs = ["root_fuzz_test.cpp"],
+)
+`)
+	writeFile(t, filepath.Join(projectDir, "src", "explore", "BUILD"), `
+cc_fuzz_test(
+    name = "explore_me_fuzz_test",
+    srcs = [
+        "explore_me_fuzz_test.cpp",
+        ":helper.h",
+        "//other:lib.cpp",
+    ],
+    deps = [":explore_me"],
+)
+`)
+
+	fuzzTests, err := bazelFuzzTestSources(projectDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*FuzzTestSource{
+		{FuzzTest: "//:root_fuzz_test", SourceFiles: []string{"root_fuzz_test.cpp"}},
+		{FuzzTest: "//src/explore:explore_me_fuzz_test", SourceFiles: []string{"src/explore/explore_me_fuzz_test.cpp", "src/explore/helper.h"}},
+	}, fuzzTests)
+}
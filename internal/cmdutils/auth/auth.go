@@ -41,13 +41,15 @@ func GetToken(server string) (string, error) {
 	return tokenstorage.Get(server)
 }
 
-func GetValidToken(server string) (string, error) {
-	token, err := GetToken(server)
+// GetValidToken returns the API access token for the server of the
+// API client if it's valid.
+func GetValidToken(apiClient *api.APIClient) (string, error) {
+	token, err := GetToken(apiClient.Server)
 	if err != nil {
 		return "", err
 	}
 
-	isValid, err := IsValidToken(server, token)
+	isValid, err := IsValidToken(apiClient, token)
 	if err != nil {
 		return "", err
 	}
@@ -58,22 +60,21 @@ func GetValidToken(server string) (string, error) {
 }
 
 // HasValidToken returns true if a valid API access token is configured
-// for the given server.
-func HasValidToken(server string) (bool, error) {
-	token, err := GetToken(server)
+// for the server of the API client.
+func HasValidToken(apiClient *api.APIClient) (bool, error) {
+	token, err := GetToken(apiClient.Server)
 	if err != nil {
 		return false, err
 	}
 
-	return IsValidToken(server, token)
+	return IsValidToken(apiClient, token)
 }
 
-func IsValidToken(server, token string) (bool, error) {
+func IsValidToken(apiClient *api.APIClient, token string) (bool, error) {
 	if token == "" {
 		return false, nil
 	}
 
-	apiClient := api.NewClient(server)
 	return apiClient.IsTokenValid(token)
 }
 
@@ -120,14 +121,13 @@ func StoreToken(server, token string) error {
 	return nil
 }
 
-func EnsureValidToken(server string) (string, error) {
+func EnsureValidToken(apiClient *api.APIClient) (string, error) {
+	server := apiClient.Server
 	token, err := GetToken(server)
 	if err != nil {
 		return "", err
 	}
 
-	apiClient := api.NewClient(server)
-
 	if token != "" {
 		isValid, err := apiClient.IsTokenValid(token)
 		if err != nil {
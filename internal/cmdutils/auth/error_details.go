@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/internal/api"
@@ -9,45 +11,86 @@ import (
 	"code-intelligence.com/cifuzz/pkg/messaging"
 )
 
-func getErrorDetailsAndToken(server string) ([]*finding.ErrorDetails, string, error) {
-	token, err := GetValidToken(server)
+func getErrorDetailsAndToken(apiClient *api.APIClient, refresh bool) ([]*finding.ErrorDetails, string, error) {
+	server := apiClient.Server
+	token, err := GetValidToken(apiClient)
 	if err != nil {
 		return nil, "", err
 	}
-	apiClient := api.NewClient(server)
+
+	// Use the cached error details unless they are outdated or the
+	// user requested to refresh them
+	if !refresh {
+		errorDetails, fetchedAt, err := cachedErrorDetails(server)
+		if err != nil {
+			log.Debugf("Failed to read cached error details: %v", err)
+		} else if errorDetails != nil && time.Since(fetchedAt) < errorDetailsCacheTTL {
+			log.Debugf("Using error details cached at %s", fetchedAt)
+			return errorDetails, token, nil
+		}
+	}
+
 	errorDetails, err := apiClient.GetErrorDetails(token)
-	return errorDetails, token, err
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = cacheErrorDetails(server, errorDetails)
+	if err != nil {
+		log.Debugf("Failed to cache error details: %v", err)
+	}
+	return errorDetails, token, nil
 }
 
-// TryGetErrorDetailsAndToken tries to get a valid token for the specified
-// server and use that to retrieve the error details from the server.
+// tryGetCachedErrorDetails returns the cached error details of the
+// server regardless of their age, which is used when there is no valid
+// token or the server can't be reached. If there are no cached error details, it prints a warning
+// that findings are not supplemented with error details.
+func tryGetCachedErrorDetails(server string) []*finding.ErrorDetails {
+	errorDetails, fetchedAt, err := cachedErrorDetails(server)
+	if err != nil {
+		log.Debugf("Failed to read cached error details: %v", err)
+	}
+	if errorDetails == nil {
+		log.Warn("Findings are not supplemented with error details from CI Sense")
+		return nil
+	}
+	log.Infof("Using error details from CI Sense cached at %s", fetchedAt.Format(time.RFC1123))
+	return errorDetails
+}
+
+// TryGetErrorDetailsAndToken tries to get a valid token for the server
+// of the API client and use that to retrieve the error details from the server.
 // It returns the error details and the token if successful.
+// The error details are cached locally and only fetched again if the
+// cache is outdated or refresh is true. If there is no valid token or
+// the server can't be reached, the cached error details are used.
 // If there is no valid token or the server is unreachable, it prints a
 // warning. Only unexpected errors are returned.
-func TryGetErrorDetailsAndToken(server string) ([]*finding.ErrorDetails, string, error) {
-	errorDetails, token, err := getErrorDetailsAndToken(server)
+func TryGetErrorDetailsAndToken(apiClient *api.APIClient, refresh bool) ([]*finding.ErrorDetails, string, error) {
+	errorDetails, token, err := getErrorDetailsAndToken(apiClient, refresh)
 
 	var connErr *api.ConnectionError
 	var apiErr *api.APIError
 	var noValidTokenError *NoValidTokenError
 	if errors.As(err, &noValidTokenError) {
 		// This error is returned by GetValidToken if there is no valid token.
+		// Error details cached while the user was still logged in can
+		// be used offline.
 		log.Infof(messaging.UsageWarning())
-		log.Warn("Findings are not supplemented with error details from CI Sense")
-		return nil, "", nil
+		return tryGetCachedErrorDetails(apiClient.Server), "", nil
 	}
 	if errors.As(err, &apiErr) {
 		// This error is returned by apiClient.GetErrorDetails if the API request
 		// fails.
-		log.Warnf("Failed to fetch error details: %v\nFindings are not supplemented with error details from CI Sense", apiErr.Error())
-		log.Infof("Continuing without access to error details from CI Sense")
-		return nil, "", nil
+		log.Warnf("Failed to fetch error details: %v", apiErr.Error())
+		return tryGetCachedErrorDetails(apiClient.Server), "", nil
 	}
 	if errors.As(err, &connErr) {
 		// This error is returned if either GetValidToken or apiClient.GetErrorDetails
 		// fail to connect to the server.
-		log.Warnf("Failed to connect to server: %v\nFindings are not supplemented with error details from CI Sense.", connErr)
-		return nil, "", nil
+		log.Warnf("Failed to connect to server: %v", connErr)
+		return tryGetCachedErrorDetails(apiClient.Server), "", nil
 	}
 	if err != nil {
 		return nil, "", err
@@ -59,7 +102,7 @@ func TryGetErrorDetailsAndToken(server string) ([]*finding.ErrorDetails, string,
 
 // TryGetErrorDetails does the same as TryGetErrorDetailsAndToken, but
 // only returns the error details.
-func TryGetErrorDetails(server string) ([]*finding.ErrorDetails, error) {
-	errorDetails, _, err := TryGetErrorDetailsAndToken(server)
+func TryGetErrorDetails(apiClient *api.APIClient, refresh bool) ([]*finding.ErrorDetails, error) {
+	errorDetails, _, err := TryGetErrorDetailsAndToken(apiClient, refresh)
 	return errorDetails, err
 }
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+// errorDetailsCacheTTL is the time after which the cached error details
+// of a server are fetched again
+const errorDetailsCacheTTL = 24 * time.Hour
+
+type errorDetailsCacheEntry struct {
+	FetchedAt    time.Time               `json:"fetched_at"`
+	ErrorDetails []*finding.ErrorDetails `json:"error_details"`
+}
+
+// errorDetailsCachePath returns the path of the file in which the error
+// details fetched from the servers are cached
+func errorDetailsCachePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Join(configDir, "cifuzz", "error_details_cache.json"), nil
+}
+
+func readErrorDetailsCache() (map[string]*errorDetailsCacheEntry, error) {
+	path, err := errorDetailsCachePath()
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]*errorDetailsCacheEntry{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cache := map[string]*errorDetailsCacheEntry{}
+	err = json.Unmarshal(bytes, &cache)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing error details cache %s", path)
+	}
+	return cache, nil
+}
+
+// cachedErrorDetails returns the cached error details of the server,
+// if any, and when they were fetched.
+func cachedErrorDetails(server string) ([]*finding.ErrorDetails, time.Time, error) {
+	cache, err := readErrorDetailsCache()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	entry, ok := cache[server]
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	return entry.ErrorDetails, entry.FetchedAt, nil
+}
+
+// cacheErrorDetails stores the error details fetched from the server in
+// the cache.
+func cacheErrorDetails(server string, errorDetails []*finding.ErrorDetails) error {
+	cache, err := readErrorDetailsCache()
+	if err != nil {
+		return err
+	}
+	cache[server] = &errorDetailsCacheEntry{
+		FetchedAt:    time.Now(),
+		ErrorDetails: errorDetails,
+	}
+
+	bytes, err := json.Marshal(cache)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	path, err := errorDetailsCachePath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// The error details are only available to authenticated users, so
+	// the cache is only readable by the current user
+	err = os.WriteFile(path, bytes, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/api"
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+func TestErrorDetailsCache(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("os.UserConfigDir only respects XDG_CONFIG_HOME on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	errorDetails, _, err := cachedErrorDetails("https://example.com")
+	require.NoError(t, err)
+	assert.Nil(t, errorDetails)
+
+	expected := []*finding.ErrorDetails{{ID: "heap_buffer_overflow", Name: "Heap Buffer Overflow"}}
+	err = cacheErrorDetails("https://example.com", expected)
+	require.NoError(t, err)
+	err = cacheErrorDetails("https://other.example.com", nil)
+	require.NoError(t, err)
+
+	errorDetails, fetchedAt, err := cachedErrorDetails("https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, expected, errorDetails)
+	assert.False(t, fetchedAt.IsZero())
+}
+
+func TestTryGetCachedErrorDetails(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("os.UserConfigDir only respects XDG_CONFIG_HOME on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Without cached error details, none are returned
+	assert.Nil(t, tryGetCachedErrorDetails("https://example.com"))
+
+	expected := []*finding.ErrorDetails{{ID: "heap_buffer_overflow", Name: "Heap Buffer Overflow"}}
+	err := cacheErrorDetails("https://example.com", expected)
+	require.NoError(t, err)
+	assert.Equal(t, expected, tryGetCachedErrorDetails("https://example.com"))
+}
+
+func TestTryGetErrorDetailsAndToken_NoValidToken(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("os.UserConfigDir only respects XDG_CONFIG_HOME on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("CIFUZZ_API_TOKEN", "")
+	apiClient := api.NewClient("https://no-token.example.com")
+
+	// Without a token and cached error details, none are returned
+	errorDetails, token, err := TryGetErrorDetailsAndToken(apiClient, false)
+	require.NoError(t, err)
+	assert.Nil(t, errorDetails)
+	assert.Empty(t, token)
+
+	// Without a token, the cached error details are returned
+	expected := []*finding.ErrorDetails{{ID: "heap_buffer_overflow", Name: "Heap Buffer Overflow"}}
+	err = cacheErrorDetails(apiClient.Server, expected)
+	require.NoError(t, err)
+	errorDetails, token, err = TryGetErrorDetailsAndToken(apiClient, false)
+	require.NoError(t, err)
+	assert.Equal(t, expected, errorDetails)
+	assert.Empty(t, token)
+}
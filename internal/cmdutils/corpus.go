@@ -0,0 +1,144 @@
+package cmdutils
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// StageCorpus copies the inputs of the corpus directories to the
+// staging directory with names which enforce a stable order,
+// independent of the order in which the file system lists them. The
+// inputs are sorted by the SHA1 of their content and named
+// "<index>-<sha1>", duplicate inputs are only staged once. It returns
+// the names of the staged inputs.
+func StageCorpus(dirs []string, stagingDir string) (map[string]bool, error) {
+	inputs := make(map[string]string)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			inputs[fmt.Sprintf("%x", sha1.Sum(content))] = path //nolint:gosec
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	hashes := make([]string, 0, len(inputs))
+	for hash := range inputs {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	staged := make(map[string]bool, len(hashes))
+	for i, hash := range hashes {
+		name := fmt.Sprintf("%06d-%s", i, hash)
+		content, err := os.ReadFile(inputs[hash])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = os.WriteFile(filepath.Join(stagingDir, name), content, 0o644)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		staged[name] = true
+	}
+	return staged, nil
+}
+
+// CopyNewInputs copies the inputs of the staging directory which were
+// not staged by StageCorpus, i.e. the ones added by the fuzzer, to the
+// destination directory.
+func CopyNewInputs(stagingDir string, staged map[string]bool, dstDir string) error {
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		if staged[entry.Name()] || !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(stagingDir, entry.Name()))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		err = os.WriteFile(filepath.Join(dstDir, entry.Name()), content, 0o644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// ExportCorpus copies the inputs of the corpus directory to the
+// destination directory, which is created if it doesn't exist. Inputs
+// in subdirectories are exported to the same relative paths, so that
+// inputs of the same name don't overwrite each other. Empty inputs are
+// skipped, because libFuzzer ignores them as well. It returns the
+// number of exported inputs.
+func ExportCorpus(corpusDir string, dstDir string) (int, error) {
+	err := os.MkdirAll(dstDir, 0o755)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	var numExported int
+	err = filepath.WalkDir(corpusDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if len(content) == 0 {
+			return nil
+		}
+		relPath, err := filepath.Rel(corpusDir, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dstDir, relPath)
+		err = os.MkdirAll(filepath.Dir(dstPath), 0o755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(dstPath, content, 0o644)
+		if err != nil {
+			return err
+		}
+		numExported++
+		return nil
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return numExported, nil
+}
@@ -0,0 +1,84 @@
+package cmdutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageCorpus(t *testing.T) {
+	seedDir := t.TempDir()
+	generatedDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(seedDir, "b"), []byte("bar"), 0o644)
+	require.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(seedDir, "nested"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(seedDir, "nested", "a"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	// Duplicate inputs are only staged once
+	err = os.WriteFile(filepath.Join(generatedDir, "c"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+
+	stagingDir := t.TempDir()
+	staged, err := StageCorpus([]string{seedDir, generatedDir, filepath.Join(seedDir, "does-not-exist")}, stagingDir)
+	require.NoError(t, err)
+
+	// The inputs are ordered by the SHA1 of their content
+	// sha1("foo") = 0beec7b5..., sha1("bar") = 62cdb702...
+	expected := []string{
+		"000000-0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33",
+		"000001-62cdb7020ff920e5aa642c3d4066950dd1f01f4d",
+	}
+	entries, err := os.ReadDir(stagingDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, expected, names)
+	assert.Equal(t, map[string]bool{expected[0]: true, expected[1]: true}, staged)
+
+	// Only the inputs added to the staging dir are copied back
+	err = os.WriteFile(filepath.Join(stagingDir, "new_input"), []byte("baz"), 0o644)
+	require.NoError(t, err)
+	err = CopyNewInputs(stagingDir, staged, generatedDir)
+	require.NoError(t, err)
+	entries, err = os.ReadDir(generatedDir)
+	require.NoError(t, err)
+	names = nil
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"c", "new_input"}, names)
+}
+
+func TestExportCorpus(t *testing.T) {
+	corpusDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(corpusDir, "a"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "b"), []byte("bar"), 0o644)
+	require.NoError(t, err)
+	// Inputs of the same name in subdirectories don't overwrite each other
+	err = os.MkdirAll(filepath.Join(corpusDir, "sub"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "sub", "a"), []byte("baz"), 0o644)
+	require.NoError(t, err)
+	// Empty inputs are skipped
+	err = os.WriteFile(filepath.Join(corpusDir, "empty"), nil, 0o644)
+	require.NoError(t, err)
+
+	dstDir := filepath.Join(t.TempDir(), "exported")
+	numExported, err := ExportCorpus(corpusDir, dstDir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, numExported)
+
+	for path, content := range map[string]string{"a": "foo", "b": "bar", filepath.Join("sub", "a"): "baz"} {
+		actual, err := os.ReadFile(filepath.Join(dstDir, path))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(actual))
+	}
+	assert.NoFileExists(t, filepath.Join(dstDir, "empty"))
+}
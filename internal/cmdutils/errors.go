@@ -92,6 +92,37 @@ func WrapCouldBeSandboxError(err error) error {
 	return &CouldBeSandboxError{err}
 }
 
+// CompilationError indicates that the build tool failed to compile
+// the fuzz test. When a CompilationError is handled, only a concise
+// message should be printed, because the build output was already
+// printed or is available in the build log.
+type CompilationError struct {
+	err      error
+	FuzzTest string
+}
+
+func (e CompilationError) Error() string {
+	if e.FuzzTest == "" {
+		return fmt.Sprintf("Compilation failed: %s", strings.TrimSpace(e.err.Error()))
+	}
+	return fmt.Sprintf("Compilation failed for %s: %s", e.FuzzTest, strings.TrimSpace(e.err.Error()))
+}
+
+func (e CompilationError) Unwrap() error {
+	return e.err
+}
+
+// WrapCompilationError wraps an existing error into a CompilationError
+// if it was caused by the build tool exiting with a non-zero exit code.
+// Other errors are returned unchanged.
+func WrapCompilationError(err error, fuzzTest string) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	return &CompilationError{err, fuzzTest}
+}
+
 // ExecError includes information about the exec.Cmd which failed in the
 // error message.
 type ExecError struct {
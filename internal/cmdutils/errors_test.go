@@ -0,0 +1,27 @@
+package cmdutils
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapCompilationError(t *testing.T) {
+	cmd := exec.Command("false")
+	err := cmd.Run()
+	require.Error(t, err)
+
+	err = WrapCompilationError(WrapExecError(errors.WithStack(err), cmd), "my_fuzz_test")
+	var compilationErr *CompilationError
+	require.ErrorAs(t, err, &compilationErr)
+	assert.Equal(t, "my_fuzz_test", compilationErr.FuzzTest)
+	assert.Contains(t, err.Error(), "Compilation failed for my_fuzz_test")
+
+	// Errors which were not caused by the build tool failing are
+	// not classified as compilation errors
+	err = WrapCompilationError(errors.New("some error"), "my_fuzz_test")
+	assert.False(t, errors.As(err, &compilationErr))
+}
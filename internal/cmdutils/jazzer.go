@@ -21,6 +21,22 @@ import (
 
 var jazzerFuzzTestRegex = regexp.MustCompile(`@FuzzTest|\sfuzzerTestOneInput\s*\(`)
 
+// jvmEntrypointDescription lists what we look for when searching a
+// class for fuzz tests.
+const jvmEntrypointDescription = `cifuzz looked for:
+  * methods annotated with @FuzzTest
+  * a method with the signature
+      public static void fuzzerTestOneInput(FuzzedDataProvider data)
+    or
+      public static void fuzzerTestOneInput(byte[] data)`
+
+// NewMissingJVMEntrypointError returns an error which names the class
+// without any fuzz test and explains which entrypoints were searched
+// for.
+func NewMissingJVMEntrypointError(className string) error {
+	return WrapIncorrectUsageError(errors.Errorf("No fuzz test found in class %s\n%s", className, jvmEntrypointDescription))
+}
+
 func JazzerSeedCorpus(targetClass string, projectDir string) string {
 	seedCorpus := targetClass + "Inputs"
 	path := strings.Split(seedCorpus, ".")
@@ -207,7 +223,7 @@ func ValidateJVMFuzzTest(fuzzTest string, targetMethod *string, deps []string) e
 	}
 
 	if len(fuzzTestsInTargetClass) == 0 {
-		return WrapIncorrectUsageError(errors.Errorf("No valid fuzz tests found in %s", fuzzTest))
+		return NewMissingJVMEntrypointError(fuzzTest)
 	}
 
 	if *targetMethod == "" {
@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -16,6 +17,13 @@ import (
 
 var buildLogPath string
 
+// The number of lines at the end of the build log which are printed
+// when the build fails. The full build log is available in the file.
+const buildLogTailLines = 50
+
+// The maximum length of a line printed from the end of the build log.
+const maxTailLineLength = 4096
+
 type BuildPrinter struct {
 	spinnerPrinter *log.SpinnerPrinter
 	output         io.Writer
@@ -72,19 +80,67 @@ func (p *BuildPrinter) printBuildLog() error {
 		return errors.WithStack(err)
 	}
 
-	_, err = fmt.Fprintln(p.output)
+	defer f.Close()
+
+	lines, truncated, err := tailLines(f, buildLogTailLines)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 
-	_, err = io.Copy(p.output, f)
+	_, err = fmt.Fprintln(p.output)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	for _, line := range lines {
+		_, err = fmt.Fprintln(p.output, line)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if truncated {
+		log.Info(fmt.Sprintf("Only the last %d lines of the build log are shown, the full log can be found here:\n%s\n", buildLogTailLines, buildLogPath))
+	}
+
 	return nil
 }
 
+// tailLines returns the last n lines read from r and whether any lines
+// were omitted. Lines longer than maxTailLineLength are cut off, so that
+// a single long line (e.g. minified code) doesn't fail the read.
+func tailLines(r io.Reader, n int) ([]string, bool, error) {
+	var lines []string
+	truncated := false
+	reader := bufio.NewReader(r)
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, errors.WithStack(err)
+		}
+		if len(line) <= maxTailLineLength {
+			line = append(line, chunk...)
+		}
+		if isPrefix {
+			continue
+		}
+		if len(line) > maxTailLineLength {
+			line = append(line[:maxTailLineLength], "..."...)
+		}
+		lines = append(lines, string(line))
+		line = line[:0]
+		if len(lines) > n {
+			lines = lines[1:]
+			truncated = true
+		}
+	}
+	return lines, truncated, nil
+}
+
 func BuildOutputToFile(projectDir string, fuzzTestNames []string) (io.Writer, error) {
 	logFile := fmt.Sprintf("build-%s.log", SuffixForLog(fuzzTestNames))
 	logDir, err := CreateLogDir(projectDir)
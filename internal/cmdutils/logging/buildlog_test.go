@@ -41,3 +41,22 @@ func TestOk(t *testing.T) {
 	require.NoError(t, err)
 	assert.FileExists(t, expected)
 }
+
+func TestTailLines(t *testing.T) {
+	lines, truncated, err := tailLines(strings.NewReader("a\nb\nc\n"), 5)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, []string{"a", "b", "c"}, lines)
+
+	lines, truncated, err = tailLines(strings.NewReader("a\nb\nc\nd\n"), 2)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, []string{"c", "d"}, lines)
+
+	// Lines longer than the buffer of a bufio.Scanner are cut off
+	longLine := strings.Repeat("x", 2*1024*1024)
+	lines, truncated, err = tailLines(strings.NewReader("a\n"+longLine+"\nb"), 5)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, []string{"a", longLine[:maxTailLineLength] + "...", "b"}, lines)
+}
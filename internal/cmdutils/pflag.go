@@ -63,6 +63,41 @@ func AddAdditionalFilesFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddAllFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("all", false,
+		"Run all fuzz tests of the project one after another instead of a single\n"+
+			"fuzz test. Only supported for build system types \"maven\" and \"gradle\".")
+	return func() {
+		ViperMustBindPFlag("all", cmd.Flags().Lookup("all"))
+	}
+}
+
+func AddASanODRViolationFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("asan-odr-violation", "",
+		"Set the ASan option detect_odr_violation to the specified `level` (0, 1 or 2).\n"+
+			"Use 0 to disable the detection of ODR violations, which can abort\n"+
+			"the fuzz test at startup in large C++ projects.")
+	return func() {
+		ViperMustBindPFlag("asan-odr-violation", cmd.Flags().Lookup("asan-odr-violation"))
+	}
+}
+
+func AddBadgeFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("badge", "",
+		"Write an SVG badge which shows the line coverage to the specified `file`,\n"+
+			"e.g. to display it in a README.")
+	return func() {
+		ViperMustBindPFlag("badge", cmd.Flags().Lookup("badge"))
+	}
+}
+
+func AddBadgeLabelFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("badge-label", "coverage", "The `label` of the badge written via --badge.")
+	return func() {
+		ViperMustBindPFlag("badge-label", cmd.Flags().Lookup("badge-label"))
+	}
+}
+
 func AddBranchFlag(cmd *cobra.Command) func() {
 	cmd.Flags().String("branch", "",
 		"Branch name to use in the bundle config.\n"+
@@ -80,6 +115,16 @@ func AddBuildCommandFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddBuildEnvFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringArray("build-env", nil,
+		"Set environment variable when building fuzz tests, e.g. '--build-env `VAR=value`'.\n"+
+			"This flag can be used multiple times.\n"+
+			"Only supported for CMake and Bazel projects.")
+	return func() {
+		ViperMustBindPFlag("build-env", cmd.Flags().Lookup("build-env"))
+	}
+}
+
 func AddCleanCommandFlag(cmd *cobra.Command) func() {
 	cmd.Flags().String("clean-command", "",
 		"The `command` to clean the fuzz test and its dependencies for other build systems.")
@@ -98,6 +143,18 @@ func AddBuildJobsFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddBuildMemoryLimitFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Uint("build-memory-limit", 0,
+		"Limit the memory in MB of the build. For CMake and other, the virtual memory of\n"+
+			"each build process is limited (only supported on Linux). For Maven and Gradle,\n"+
+			"the heap of the Maven JVM or Gradle daemon is limited. For Bazel, the memory\n"+
+			"available to local build actions is limited, which Bazel only uses to schedule\n"+
+			"actions. If omitted, the memory is not limited.")
+	return func() {
+		ViperMustBindPFlag("build-memory-limit", cmd.Flags().Lookup("build-memory-limit"))
+	}
+}
+
 func AddBuildOnlyFlag(cmd *cobra.Command) func() {
 	cmd.Flags().Bool("build-only", false,
 		"Only build the fuzz test and don't execute it.")
@@ -106,6 +163,28 @@ func AddBuildOnlyFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddCIFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("ci", false,
+		"Use output which is suited for CI logs: Disable colors and the updating\n"+
+			"metrics line, print the metrics every 30 seconds and write a JSON summary\n"+
+			"of the run to cifuzz-summary.json. Enabled by default if the CI environment\n"+
+			"variable is \"true\". The flags --plain, --metrics-interval and --summary-file\n"+
+			"take precedence over this preset.")
+	return func() {
+		ViperMustBindPFlag("ci", cmd.Flags().Lookup("ci"))
+	}
+}
+
+func AddClassPathFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringArray("classpath", nil,
+		"Append the `path` to the class path of the fuzz test, e.g. for jars which\n"+
+			"are not discovered as runtime dependencies. This flag can be used multiple times.\n"+
+			"Only supported for Maven and Gradle projects.")
+	return func() {
+		ViperMustBindPFlag("classpath", cmd.Flags().Lookup("classpath"))
+	}
+}
+
 func AddCommitFlag(cmd *cobra.Command) func() {
 	cmd.Flags().String("commit", "",
 		"Commit to use in the bundle config.\n"+
@@ -115,6 +194,50 @@ func AddCommitFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddCorpusIndexFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("corpus-index", false,
+		"Record the coverage (new edges and features) which each entry added to the\n"+
+			"generated corpus contributed in an index file next to the generated corpus\n"+
+			"directory (<corpus dir>.index.json). This runs libFuzzer with -verbosity=2,\n"+
+			"which makes it report the entries it adds.")
+	return func() {
+		ViperMustBindPFlag("corpus-index", cmd.Flags().Lookup("corpus-index"))
+	}
+}
+
+func AddCorpusOutputFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("corpus-output", "",
+		"Copy the inputs of the generated corpus to the specified directory\n"+
+			"after the fuzzing run finished, e.g. to archive the corpus for later reuse.\n"+
+			"Empty inputs are skipped.")
+	return func() {
+		ViperMustBindPFlag("corpus-output", cmd.Flags().Lookup("corpus-output"))
+	}
+}
+
+func AddCoveredFilesOutFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("covered-files-out", "",
+		"Write the source files of which at least one line was covered to the\n"+
+			"specified `file`, one file per line.")
+	return func() {
+		ViperMustBindPFlag("covered-files-out", cmd.Flags().Lookup("covered-files-out"))
+	}
+}
+
+func AddDeterministicCorpusOrderFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("deterministic-corpus-order", false,
+		"Copy the corpus inputs to a temporary directory with names which enforce a\n"+
+			"stable order before fuzzing, because libFuzzer processes the inputs in the\n"+
+			"order in which the file system lists them. Together with a fixed seed and\n"+
+			"number of runs (e.g. --engine-arg=-seed=1 --engine-arg=-runs=100000), this\n"+
+			"makes fuzzing runs reproducible. Note that copying the inputs can take a\n"+
+			"while for large corpora. Only supported for the libFuzzer engine and build\n"+
+			"system types \"cmake\", \"bazel\" and \"other\".")
+	return func() {
+		ViperMustBindPFlag("deterministic-corpus-order", cmd.Flags().Lookup("deterministic-corpus-order"))
+	}
+}
+
 func AddDictFlag(cmd *cobra.Command) func() {
 	// TODO(afl): Also link to https://github.com/AFLplusplus/AFLplusplus/blob/stable/dictionaries/README.md
 	cmd.Flags().String("dict", "",
@@ -126,6 +249,15 @@ func AddDictFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddDisplayNameFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("display-name", "",
+		"A human-friendly `name` of the fuzz test which is shown on the CI Sense dashboard\n"+
+			"instead of its technical identifier.")
+	return func() {
+		ViperMustBindPFlag("display-name", cmd.Flags().Lookup("display-name"))
+	}
+}
+
 func AddDockerImageFlagForContainerCommand(cmd *cobra.Command) func() {
 	// Default was originally set to "ubuntu:rolling", but this is not correct
 	// It will be set by the bundle command depending on the build system, unless user overrides it
@@ -152,11 +284,22 @@ By default, the image is chosen automatically based on the build system
 	}
 }
 
+func AddEngineFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("engine", "",
+		"The fuzzing `engine` to run the fuzz test with (libfuzzer or afl).\n"+
+			"The afl engine requires the fuzz test to be built with the AFL++ compilers\n"+
+			"and is only supported for build system types \"cmake\" and \"other\".\n"+
+			"By default, libFuzzer is used.")
+	return func() {
+		ViperMustBindPFlag("engine", cmd.Flags().Lookup("engine"))
+	}
+}
+
 func AddEngineArgFlag(cmd *cobra.Command) func() {
-	// TODO(afl): Also link to https://www.mankier.com/8/afl-fuzz
 	cmd.Flags().StringArray("engine-arg", nil,
 		"Command-line `argument` to pass to the fuzzing engine.\n"+
-			"See https://llvm.org/docs/LibFuzzer.html#options.\n"+
+			"See https://llvm.org/docs/LibFuzzer.html#options\n"+
+			"and https://www.mankier.com/8/afl-fuzz.\n"+
 			"This flag can be used multiple times.\n"+
 			"Not supported for Node.js projects.")
 	return func() {
@@ -174,6 +317,41 @@ func AddEnvFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddFailOnFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringArray("fail-on", nil,
+		"Exit with an error if a finding of the specified `kind` was found: any, oom\n"+
+			"(out-of-memory), timeout, crash (excluding out-of-memory and timeout), warning\n"+
+			"or runtime_error. This flag can be used multiple times.")
+	return func() {
+		ViperMustBindPFlag("fail-on", cmd.Flags().Lookup("fail-on"))
+	}
+}
+
+func AddFailUnderFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Float64("fail-under", 0,
+		"Fail if the total line coverage is below the specified `percent`.")
+	return func() {
+		ViperMustBindPFlag("fail-under", cmd.Flags().Lookup("fail-under"))
+	}
+}
+
+func AddFailUnderFileFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Float64("fail-under-file", 0,
+		"Fail if the line coverage of any file is below the specified `percent`.")
+	return func() {
+		ViperMustBindPFlag("fail-under-file", cmd.Flags().Lookup("fail-under-file"))
+	}
+}
+
+func AddFindingWebhookFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("finding-webhook", "",
+		"A `URL` to which each new finding is sent as JSON via a POST request\n"+
+			"as soon as it is found.")
+	return func() {
+		ViperMustBindPFlag("finding-webhook", cmd.Flags().Lookup("finding-webhook"))
+	}
+}
+
 func AddInteractiveFlag(cmd *cobra.Command) func() {
 	cmd.Flags().Bool("interactive", true, "Toggle interactive prompting in the terminal")
 	return func() {
@@ -181,6 +359,114 @@ func AddInteractiveFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddJSONFlushFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("json-flush", false,
+		"Sync the JSON output to disk after each report, so that it's not lost\n"+
+			"if cifuzz is killed during the run.")
+	return func() {
+		ViperMustBindPFlag("json-flush", cmd.Flags().Lookup("json-flush"))
+	}
+}
+
+func AddMaxRunsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Uint("max-runs", 0,
+		"Maximum number of fuzzing runs (i.e. executions of the fuzz test), which is\n"+
+			"passed to libFuzzer as -runs. The default is to run indefinitely.\n"+
+			"Not supported for build system type \"nodejs\" and the engine \"afl\".")
+	return func() {
+		ViperMustBindPFlag("max-runs", cmd.Flags().Lookup("max-runs"))
+	}
+}
+
+func AddMaxTotalTimeFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Duration("max-total-time", 0,
+		"Maximum total time to run the fuzz test, e.g. \"30m\", \"1h\", like libFuzzer's\n"+
+			"-max_total_time. This is an alias of --timeout, which can't be used together with it.")
+	return func() {
+		ViperMustBindPFlag("max-total-time", cmd.Flags().Lookup("max-total-time"))
+	}
+}
+
+func AddMetricsFileFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("metrics-file", "",
+		"Append each metric reported during the run (executions per second, total\n"+
+			"executions, corpus size and number of findings) as a JSON line to the `file`.\n"+
+			"This is independent of --json.")
+	return func() {
+		ViperMustBindPFlag("metrics-file", cmd.Flags().Lookup("metrics-file"))
+	}
+}
+
+func AddMetricsHistoryFileFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("metrics-history-file", "",
+		"Append a JSON line with the final metrics of the run (timestamp, fuzz test,\n"+
+			"duration, total executions, average exec/s, corpus entries and number of\n"+
+			"findings) to the specified file. Over many runs, the file forms a time\n"+
+			"series which shows the trend of the fuzzing effectiveness.")
+	return func() {
+		ViperMustBindPFlag("metrics-history-file", cmd.Flags().Lookup("metrics-history-file"))
+	}
+}
+
+func AddMetricsIntervalFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Duration("metrics-interval", 0,
+		"Print the fuzzing metrics at most once per `duration` (e.g. 30s) when the output\n"+
+			"is not a terminal. By default, all metrics updates are printed.")
+	return func() {
+		ViperMustBindPFlag("metrics-interval", cmd.Flags().Lookup("metrics-interval"))
+	}
+}
+
+func AddNativeLibPathFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringArray("native-lib-path", nil,
+		"Add the `dir` to the directories in which the JVM searches for native libraries\n"+
+			"(java.library.path), e.g. for fuzz tests which use JNI. This flag can be used\n"+
+			"multiple times. Only supported for Maven and Gradle projects.")
+	return func() {
+		ViperMustBindPFlag("native-lib-path", cmd.Flags().Lookup("native-lib-path"))
+	}
+}
+
+func AddNoDefaultDictFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("no-default-dict", false,
+		"Don't use the default dictionary <fuzz test>.dict of the fuzz test.\n"+
+			"A dictionary specified via --dict is still used.")
+	return func() {
+		ViperMustBindPFlag("no-default-dict", cmd.Flags().Lookup("no-default-dict"))
+	}
+}
+
+func AddNoResolveSourcePathFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("no-resolve-source-path", false,
+		"Treat the argument of the command literally as a fuzz test identifier, even if\n"+
+			"resolving source file paths was enabled via --resolve or the configuration.\n"+
+			"Use this if the source file path resolution produces a wrong fuzz test\n"+
+			"identifier, e.g. for symlinked source files.")
+	return func() {
+		ViperMustBindPFlag("no-resolve-source-path", cmd.Flags().Lookup("no-resolve-source-path"))
+	}
+}
+
+func AddOfflineFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("offline", false,
+		"Run Maven and Gradle in offline mode, which requires all dependencies\n"+
+			"to be available in the local repository or cache.")
+	return func() {
+		ViperMustBindPFlag("offline", cmd.Flags().Lookup("offline"))
+	}
+}
+
+func AddOutputRootFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("output-root", "",
+		"Write the files created by cifuzz (build directory, build logs, generated corpus\n"+
+			"and findings) below the specified `directory` instead of the project directory.\n"+
+			"This allows using cifuzz in a read-only checkout of the project.\n"+
+			"Not supported for Java and JavaScript projects.")
+	return func() {
+		ViperMustBindPFlag("output-root", cmd.Flags().Lookup("output-root"))
+	}
+}
+
 func AddPresetFlag(cmd *cobra.Command) func() {
 	cmd.Flags().String("preset", "", "Preset for a given environment to execute coverage with necessary flags.\n"+
 		"We recommend not using this flag with '--format' or '--output' because the preset will set these accordingly.\n"+
@@ -190,6 +476,24 @@ func AddPresetFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddPrintCommandFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("print-command", false,
+		"Print the command and environment used to run the fuzzer before it is started.\n"+
+			"Values of environment variables which look like secrets are redacted.")
+	return func() {
+		ViperMustBindPFlag("print-command", cmd.Flags().Lookup("print-command"))
+	}
+}
+
+func AddPrintFlagsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("print-flags", false,
+		"Print the compiler and linker flags (CFLAGS, CXXFLAGS, LDFLAGS and FUZZ_TEST_*)\n"+
+			"which are passed to the build command. Only applicable for build system type \"other\".")
+	return func() {
+		ViperMustBindPFlag("print-flags", cmd.Flags().Lookup("print-flags"))
+	}
+}
+
 func AddPrintJSONFlag(cmd *cobra.Command) func() {
 	cmd.Flags().Bool("json", false, "Print output as JSON")
 	return func() {
@@ -206,6 +510,54 @@ func AddProjectDirFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddPrometheusOutputFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("prometheus-output", "",
+		"Write the final metrics of the run in the Prometheus text exposition format\n"+
+			"to the `file`, e.g. for the textfile collector of the node_exporter.")
+	return func() {
+		ViperMustBindPFlag("prometheus-output", cmd.Flags().Lookup("prometheus-output"))
+	}
+}
+
+func AddProxyFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("proxy", "",
+		"The `URL` of the HTTP, HTTPS or SOCKS5 proxy used to connect to CI Sense,\n"+
+			"e.g. \"http://proxy.example.com:3128\". By default, the proxy is determined\n"+
+			"from the environment.")
+	return func() {
+		ViperMustBindPFlag("proxy", cmd.Flags().Lookup("proxy"))
+	}
+}
+
+func AddRefreshErrorDetailsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("refresh-error-details", false,
+		"Fetch the error details from CI Sense again instead of using the locally\n"+
+			"cached ones. The cached error details are refreshed once a day by default.")
+	return func() {
+		ViperMustBindPFlag("refresh-error-details", cmd.Flags().Lookup("refresh-error-details"))
+	}
+}
+
+func AddReproduceFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("reproduce", "",
+		"Execute the fuzz test once with the specified input `file` instead of fuzzing,\n"+
+			"e.g. to reproduce a crashing input sent by a teammate. The corpus directories\n"+
+			"and the timeout are not used. Not supported for the engine \"afl\" and build\n"+
+			"system type \"nodejs\".")
+	return func() {
+		ViperMustBindPFlag("reproduce", cmd.Flags().Lookup("reproduce"))
+	}
+}
+
+func AddRequireSeedsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("require-seeds", false,
+		"Abort before starting the fuzz test if none of the corpus directories\n"+
+			"contain a non-empty input, which usually indicates a misconfiguration in CI.")
+	return func() {
+		ViperMustBindPFlag("require-seeds", cmd.Flags().Lookup("require-seeds"))
+	}
+}
+
 func AddResolveSourceFileFlag(cmd *cobra.Command) func() {
 	cmd.Flags().BoolP("resolve", "r", false,
 		"Argument of the command is a path to a source file instead of a test identifier.\n"+
@@ -234,6 +586,43 @@ e.g. "my-project-c170bc17".`)
 	}
 }
 
+func AddRetryOnInfraFailureFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Uint("retry-on-infra-failure", 0,
+		"Retry the fuzzing run up to `N` times if the fuzzer exits unexpectedly without\n"+
+			"reporting a finding, e.g. because it was killed by the OOM killer. Runs which\n"+
+			"end with a finding are never retried.")
+	return func() {
+		ViperMustBindPFlag("retry-on-infra-failure", cmd.Flags().Lookup("retry-on-infra-failure"))
+	}
+}
+
+func AddRunnerBinaryFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("runner-binary", "",
+		"Run the fuzz test via the specified `binary`, which is executed with the\n"+
+			"path of the fuzz test and the libFuzzer arguments as arguments. This is\n"+
+			"an advanced option for running the fuzz test in a custom environment,\n"+
+			"for example in separate namespaces. Only supported for the libFuzzer\n"+
+			"engine and build system types \"cmake\" and \"other\".\n"+
+			"Can also be set via the CIFUZZ_LIBFUZZER_RUNNER environment variable.")
+	return func() {
+		ViperMustBindPFlag("runner-binary", cmd.Flags().Lookup("runner-binary"))
+		err := viper.BindEnv("runner-binary", "CIFUZZ_LIBFUZZER_RUNNER")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func AddSanitizersFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringSlice("sanitizers", nil,
+		"Comma-separated list of the `sanitizers` to build the fuzz test with, e.g.\n"+
+			"'--sanitizers address' for faster ASan-only runs (default \"address,undefined\").\n"+
+			"Only supported for CMake projects and build system type \"other\".")
+	return func() {
+		ViperMustBindPFlag("sanitizers", cmd.Flags().Lookup("sanitizers"))
+	}
+}
+
 func AddSeedCorpusFlag(cmd *cobra.Command) func() {
 	// TODO(afl): Also link to https://aflplus.plus/docs/fuzzing_in_depth/#a-collecting-inputs
 	cmd.Flags().StringArrayP("seed-corpus", "s", nil,
@@ -253,6 +642,57 @@ func AddServerFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddStopOnPlateauFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Duration("stop-on-plateau", 0,
+		"Stop the fuzzing run if no new coverage was found for the specified `duration`\n"+
+			"(e.g. 10m), i.e. when the corpus didn't grow anymore.")
+	return func() {
+		ViperMustBindPFlag("stop-on-plateau", cmd.Flags().Lookup("stop-on-plateau"))
+	}
+}
+
+func AddStripDebugFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("strip-debug", false,
+		"Strip the debug sections from the fuzzer executables and bundle them\n"+
+			"in a separate .debug file instead. The coverage executable keeps its\n"+
+			"debug information.")
+	return func() {
+		ViperMustBindPFlag("strip-debug", cmd.Flags().Lookup("strip-debug"))
+	}
+}
+
+func AddStripPathsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("strip-paths", false,
+		"Turn absolute paths below the project directory (or the directory specified\n"+
+			"via --strip-path-prefix) into relative paths in the findings and coverage\n"+
+			"reports, to avoid leaking the local directory layout when sharing them.")
+	cmd.Flags().String("strip-path-prefix", "",
+		"Directory which is stripped from the beginning of paths with --strip-paths.\n"+
+			"The default is the project directory.")
+	return func() {
+		ViperMustBindPFlag("strip-paths", cmd.Flags().Lookup("strip-paths"))
+		ViperMustBindPFlag("strip-path-prefix", cmd.Flags().Lookup("strip-path-prefix"))
+	}
+}
+
+func AddSummaryFileFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("summary-file", "",
+		"Write a JSON summary of the run (final metrics and names of the findings,\n"+
+			"in total and per fuzz test) to the specified `file`.")
+	return func() {
+		ViperMustBindPFlag("summary-file", cmd.Flags().Lookup("summary-file"))
+	}
+}
+
+func AddSymbolizerFlag(cmd *cobra.Command) func() {
+	cmd.Flags().String("symbolizer", "",
+		"Path to the llvm-symbolizer `executable` which the sanitizers use to symbolize\n"+
+			"stack traces. By default, the llvm-symbolizer found in the PATH is used.")
+	return func() {
+		ViperMustBindPFlag("symbolizer", cmd.Flags().Lookup("symbolizer"))
+	}
+}
+
 func AddTimeoutFlag(cmd *cobra.Command) func() {
 	cmd.Flags().Duration("timeout", 0,
 		"Maximum time to run the fuzz test, e.g. \"30m\", \"1h\". The default is to run indefinitely.")
@@ -261,6 +701,16 @@ func AddTimeoutFlag(cmd *cobra.Command) func() {
 	}
 }
 
+func AddUnitTimeoutFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Duration("unit-timeout", 0,
+		"Report a timeout finding if a single input runs longer than the specified\n"+
+			"`duration` (e.g. 10s), which is passed to libFuzzer as -timeout.\n"+
+			"Not supported for build system type \"nodejs\" and the engine \"afl\".")
+	return func() {
+		ViperMustBindPFlag("unit-timeout", cmd.Flags().Lookup("unit-timeout"))
+	}
+}
+
 func AddUseSandboxFlag(cmd *cobra.Command) func() {
 	cmd.Flags().Bool("use-sandbox", false,
 		"By default, fuzz tests are executed in a sandbox to prevent accidental damage to the system.\n"+
@@ -271,3 +721,22 @@ func AddUseSandboxFlag(cmd *cobra.Command) func() {
 		ViperMustBindPFlag("use-sandbox", cmd.Flags().Lookup("use-sandbox"))
 	}
 }
+
+func AddWarnOversizedSeedsFlag(cmd *cobra.Command) func() {
+	cmd.Flags().Bool("warn-oversized-seeds", false,
+		"Warn about inputs in the corpus directories which are larger than the\n"+
+			"-max_len passed via --engine-arg, because libFuzzer truncates them,\n"+
+			"which silently loses the coverage of the truncated part.")
+	return func() {
+		ViperMustBindPFlag("warn-oversized-seeds", cmd.Flags().Lookup("warn-oversized-seeds"))
+	}
+}
+
+func AddWebhookHeaderFlag(cmd *cobra.Command) func() {
+	cmd.Flags().StringArray("webhook-header", nil,
+		"Set an HTTP header on requests to the finding webhook, e.g. '--webhook-header \"`Name: value`\"'.\n"+
+			"This flag can be used multiple times.")
+	return func() {
+		ViperMustBindPFlag("webhook-headers", cmd.Flags().Lookup("webhook-header"))
+	}
+}
@@ -0,0 +1,241 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-zglob"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/build/java"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/java/sourcemap"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/sliceutil"
+)
+
+var (
+	cSourceExtensions   = []string{".c", ".cc", ".cpp", ".cxx", ".c++", ".h", ".hh", ".hpp", ".hxx", ".h++", ".inc", ".inl"}
+	jvmSourceExtensions = []string{".java", ".kt"}
+
+	cmakeBuildFilePattern = regexp.MustCompile(`^(CMakeLists\.txt|CMakePresets\.json|.*\.cmake)$`)
+	bazelBuildFilePattern = regexp.MustCompile(`^(BUILD|BUILD\.bazel|WORKSPACE|WORKSPACE\.bazel|MODULE\.bazel|\.bazelrc|.*\.bzl)$`)
+	jvmBuildFilePattern   = regexp.MustCompile(`^(pom\.xml|(build|settings)\.gradle(\.kts)?|gradle\.properties|.*\.versions\.toml)$`)
+)
+
+// ChangedFuzzTests returns the fuzz tests which are affected by the
+// given changed files. The second return value is false if the changes
+// can't be mapped to fuzz tests with certainty, for example because a
+// build file, a library source or any other file which is not a source
+// changed. In that case, all fuzz tests should be considered affected.
+// Maven and Gradle are run in offline mode if offline is set.
+func ChangedFuzzTests(changedFiles []string, buildSystem, projectDir string, offline bool) ([]string, bool, error) {
+	changedFiles = withoutCIFuzzOutput(changedFiles, projectDir)
+
+	switch buildSystem {
+	case config.BuildSystemCMake:
+		return changedCFuzzTests(changedFiles, buildSystem, projectDir, cmakeBuildFilePattern)
+	case config.BuildSystemBazel:
+		return changedCFuzzTests(changedFiles, buildSystem, projectDir, bazelBuildFilePattern)
+	case config.BuildSystemMaven, config.BuildSystemGradle:
+		sourceDirs, err := java.SourceDirs(projectDir, buildSystem, offline)
+		if err != nil {
+			return nil, false, err
+		}
+		testDirs, err := java.TestDirs(projectDir, buildSystem, offline)
+		if err != nil {
+			return nil, false, err
+		}
+		return changedJVMFuzzTests(changedFiles, projectDir, sourceDirs, testDirs)
+	default:
+		return nil, false, errors.Errorf("Determining the fuzz tests affected by changes is not supported for build system type %q", buildSystem)
+	}
+}
+
+// withoutCIFuzzOutput removes the files which cifuzz created in the
+// project dir, like build logs in .cifuzz-build, from the changed files,
+// because they are not inputs of the build and are often not ignored
+// by git.
+func withoutCIFuzzOutput(changedFiles []string, projectDir string) []string {
+	var files []string
+	for _, path := range changedFiles {
+		relPath, err := filepath.Rel(projectDir, path)
+		if err == nil && strings.HasPrefix(filepath.ToSlash(relPath), ".cifuzz-") {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files
+}
+
+// changedCFuzzTests maps changed C/C++ sources to the fuzz tests which
+// they are the fuzz test source of. Changes of any other file can
+// affect arbitrary fuzz tests.
+func changedCFuzzTests(changedFiles []string, buildSystem, projectDir string, buildFilePattern *regexp.Regexp) ([]string, bool, error) {
+	var fuzzTests []string
+	for _, path := range changedFiles {
+		if buildFilePattern.MatchString(filepath.Base(path)) {
+			log.Debugf("Build file %s changed, all fuzz tests are affected", path)
+			return nil, false, nil
+		}
+		if !sliceutil.Contains(cSourceExtensions, strings.ToLower(filepath.Ext(path))) {
+			log.Debugf("File %s is not a source file, all fuzz tests are affected", path)
+			return nil, false, nil
+		}
+		fuzzTest, err := resolve(path, buildSystem, projectDir, false)
+		if err != nil || fuzzTest == "" {
+			log.Debugf("Source file %s is not the source of a fuzz test, all fuzz tests are affected", path)
+			return nil, false, nil
+		}
+		fuzzTests = append(fuzzTests, fuzzTest)
+	}
+	return sliceutil.RemoveDuplicates(fuzzTests), true, nil
+}
+
+// changedJVMFuzzTests maps changed Java and Kotlin sources to the fuzz
+// test classes which depend on the package of a changed source, i.e.
+// which are part of it or import from it, directly or via other
+// packages of the project. The package of a source is looked up in the
+// source map of the project, so sources outside of the source and test
+// directories (or which were deleted) can't be mapped.
+func changedJVMFuzzTests(changedFiles []string, projectDir string, sourceDirs, testDirs []string) ([]string, bool, error) {
+	sourceMap, err := sourcemap.CreateSourceMap(projectDir, append(sourceDirs, testDirs...))
+	if err != nil {
+		return nil, false, err
+	}
+	packageOfSource := make(map[string]string)
+	for pkg, sources := range sourceMap.JavaPackages {
+		for _, source := range sources {
+			packageOfSource[source] = pkg
+		}
+	}
+
+	var changedPackages []string
+	for _, path := range changedFiles {
+		if jvmBuildFilePattern.MatchString(filepath.Base(path)) {
+			log.Debugf("Build file %s changed, all fuzz tests are affected", path)
+			return nil, false, nil
+		}
+		if !sliceutil.Contains(jvmSourceExtensions, filepath.Ext(path)) {
+			log.Debugf("File %s is not a source file, all fuzz tests are affected", path)
+			return nil, false, nil
+		}
+		relPath, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return nil, false, errors.WithStack(err)
+		}
+		pkg, ok := packageOfSource[filepath.ToSlash(relPath)]
+		if !ok {
+			log.Debugf("Source file %s is not contained in the source map, all fuzz tests are affected", path)
+			return nil, false, nil
+		}
+		changedPackages = append(changedPackages, pkg)
+	}
+	changedPackages = sliceutil.RemoveDuplicates(changedPackages)
+	if len(changedPackages) == 0 {
+		return nil, true, nil
+	}
+
+	affectedPackages, err := dependentPackages(projectDir, packageOfSource, changedPackages)
+	if err != nil {
+		return nil, false, err
+	}
+
+	affectedPatterns := packagePatterns(affectedPackages)
+	var fuzzTests []string
+	for _, testDir := range testDirs {
+		matches, err := zglob.Glob(filepath.Join(testDir, "**", "*.{java,kt}"))
+		if err != nil {
+			return nil, false, errors.WithStack(err)
+		}
+		for _, match := range matches {
+			fuzzTest, err := cmdutils.ConstructJVMFuzzTestIdentifier(match, testDir)
+			if err != nil {
+				return nil, false, err
+			}
+			if fuzzTest == "" {
+				continue
+			}
+			affected, err := dependsOnPackages(match, affectedPatterns)
+			if err != nil {
+				return nil, false, err
+			}
+			if affected {
+				fuzzTests = append(fuzzTests, fuzzTest)
+			}
+		}
+	}
+	return sliceutil.RemoveDuplicates(fuzzTests), true, nil
+}
+
+// dependentPackages returns the changed packages together with all
+// packages which depend on them transitively, i.e. which contain a
+// source which imports from a changed package or from a package which
+// depends on one. packageOfSource maps the sources, relative to the
+// project dir, to their packages.
+func dependentPackages(projectDir string, packageOfSource map[string]string, changedPackages []string) ([]string, error) {
+	affected := make(map[string]bool)
+	for _, pkg := range changedPackages {
+		affected[pkg] = true
+	}
+	packages := slices.Clone(changedPackages)
+	patterns := packagePatterns(packages)
+
+	// Add the packages of the sources which depend on the affected
+	// packages until no more packages are added
+	for added := true; added; {
+		added = false
+		for source, pkg := range packageOfSource {
+			if affected[pkg] {
+				continue
+			}
+			depends, err := dependsOnPackages(filepath.Join(projectDir, filepath.FromSlash(source)), patterns)
+			if err != nil {
+				return nil, err
+			}
+			if depends {
+				log.Debugf("Package %s is affected, because %s depends on a changed package", pkg, source)
+				affected[pkg] = true
+				packages = append(packages, pkg)
+				patterns = append(patterns, packagePattern(pkg))
+				added = true
+			}
+		}
+	}
+	return packages, nil
+}
+
+// packagePattern returns a regular expression which matches the
+// package declaration of the package and imports from it.
+func packagePattern(pkg string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pkg)
+	return regexp.MustCompile(`(?m)^\s*(package\s+` + quoted + `\s*;?\s*$|import\s+(static\s+)?` + quoted + `\.)`)
+}
+
+func packagePatterns(packages []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(packages))
+	for _, pkg := range packages {
+		patterns = append(patterns, packagePattern(pkg))
+	}
+	return patterns
+}
+
+// dependsOnPackages returns true if the source file is part of or
+// imports from any of the packages matched by the patterns, which are
+// created via packagePattern.
+func dependsOnPackages(path string, patterns []*regexp.Regexp) (bool, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	for _, pattern := range patterns {
+		if pattern.Match(bytes) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,136 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/internal/testutil"
+)
+
+func TestChangedJVMFuzzTests(t *testing.T) {
+	projectDir := testutil.MkdirTemp(t, "", "changed-fuzz-tests-")
+	sourceDir := filepath.Join(projectDir, "src", "main")
+	testDir := filepath.Join(projectDir, "src", "test")
+
+	writeFile := func(path, content string) string {
+		path = filepath.Join(projectDir, path)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(content), 0o644)
+		require.NoError(t, err)
+		return path
+	}
+	parser := writeFile("src/main/java/com/example/parser/Parser.java",
+		"package com.example.parser;\n\nimport com.example.core.Core;\n\npublic class Parser {}\n")
+	core := writeFile("src/main/java/com/example/core/Core.java",
+		"package com.example.core;\n\npublic class Core {}\n")
+	util := writeFile("src/main/java/com/example/util/Util.java",
+		"package com.example.util;\n\npublic class Util {}\n")
+	unused := writeFile("src/main/java/com/example/unused/Unused.java",
+		"package com.example.unused;\n\npublic class Unused {}\n")
+	parserFuzzTest := writeFile("src/test/java/com/example/fuzz/ParserFuzzTest.java", `package com.example.fuzz;
+
+import com.code_intelligence.jazzer.junit.FuzzTest;
+import com.example.parser.Parser;
+
+public class ParserFuzzTest {
+  @FuzzTest
+  void fuzz(byte[] data) {}
+}
+`)
+	writeFile("src/test/java/com/example/utilfuzz/UtilFuzzTest.java", `package com.example.utilfuzz;
+
+import com.code_intelligence.jazzer.junit.FuzzTest;
+import static com.example.util.Util.*;
+
+public class UtilFuzzTest {
+  @FuzzTest
+  void fuzz(byte[] data) {}
+}
+`)
+	helper := writeFile("src/test/java/com/example/fuzz/Helper.java",
+		"package com.example.fuzz;\n\npublic class Helper {}\n")
+	sourceDirs := []string{sourceDir}
+	testDirs := []string{testDir}
+
+	// A changed library source affects the fuzz tests importing it
+	fuzzTests, ok, err := changedJVMFuzzTests([]string{parser}, projectDir, sourceDirs, testDirs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"com.example.fuzz.ParserFuzzTest"}, fuzzTests)
+
+	fuzzTests, ok, err = changedJVMFuzzTests([]string{util}, projectDir, sourceDirs, testDirs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"com.example.utilfuzz.UtilFuzzTest"}, fuzzTests)
+
+	// A changed source affects the fuzz tests which depend on it
+	// transitively
+	fuzzTests, ok, err = changedJVMFuzzTests([]string{core}, projectDir, sourceDirs, testDirs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"com.example.fuzz.ParserFuzzTest"}, fuzzTests)
+
+	// A changed fuzz test or helper affects the fuzz tests in its package
+	fuzzTests, ok, err = changedJVMFuzzTests([]string{parserFuzzTest}, projectDir, sourceDirs, testDirs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"com.example.fuzz.ParserFuzzTest"}, fuzzTests)
+	fuzzTests, ok, err = changedJVMFuzzTests([]string{helper}, projectDir, sourceDirs, testDirs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"com.example.fuzz.ParserFuzzTest"}, fuzzTests)
+
+	// Sources which no fuzz test depends on don't affect any fuzz test
+	fuzzTests, ok, err = changedJVMFuzzTests([]string{unused}, projectDir, sourceDirs, testDirs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, fuzzTests)
+
+	// Build files, unknown sources and other files can't be mapped
+	for _, path := range []string{
+		filepath.Join(projectDir, "README.md"),
+		filepath.Join(projectDir, "src", "main", "resources", "config.properties"),
+		filepath.Join(projectDir, "pom.xml"),
+		filepath.Join(projectDir, "build.gradle.kts"),
+		filepath.Join(projectDir, "src", "main", "java", "com", "example", "Deleted.java"),
+	} {
+		_, ok, err = changedJVMFuzzTests([]string{parser, path}, projectDir, sourceDirs, testDirs)
+		require.NoError(t, err)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestChangedCMakeFuzzTests(t *testing.T) {
+	projectDir, err := filepath.Abs(filepath.Join("testdata", "cmake"))
+	require.NoError(t, err)
+
+	fuzzTests, ok, err := ChangedFuzzTests([]string{
+		filepath.Join(projectDir, "src", "fuzz_test_1", "fuzz_test.cpp"),
+	}, config.BuildSystemCMake, projectDir, false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"fuzz_test_1"}, fuzzTests)
+
+	// Files which are not sources can't be mapped
+	_, ok, err = ChangedFuzzTests([]string{
+		filepath.Join(projectDir, "src", "fuzz_test_1", "fuzz_test.cpp"),
+		filepath.Join(projectDir, "README.md"),
+	}, config.BuildSystemCMake, projectDir, false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// A source which is not the source of a fuzz test can't be mapped
+	_, ok, err = ChangedFuzzTests([]string{filepath.Join(projectDir, "src", "lib.cpp")}, config.BuildSystemCMake, projectDir, false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = ChangedFuzzTests([]string{filepath.Join(projectDir, "src", "CMakeLists.txt")}, config.BuildSystemCMake, projectDir, false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
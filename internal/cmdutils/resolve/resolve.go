@@ -25,8 +25,9 @@ import (
 var cmakeFuzzTestFileNamePattern = regexp.MustCompile(`add_fuzz_test\((?P<fuzzTest>[a-zA-Z0-9_.+=,@~-]+)\s(?P<file>[a-zA-Z0-9/\_.+=,@~-]+)\)`)
 
 // resolve determines the corresponding fuzz test name to a given source file.
-// The path has to be relative to the project directory.
-func resolve(path, buildSystem, projectDir string) (string, error) {
+// The path has to be relative to the project directory. Maven and Gradle
+// are run in offline mode if offline is set.
+func resolve(path, buildSystem, projectDir string, offline bool) (string, error) {
 	switch buildSystem {
 	case config.BuildSystemCMake:
 		cmakeLists, err := findAllCMakeLists(projectDir)
@@ -91,13 +92,13 @@ func resolve(path, buildSystem, projectDir string) (string, error) {
 		var testDirs []string
 		var err error
 		if buildSystem == config.BuildSystemMaven {
-			testDir, err := maven.GetTestDir(projectDir)
+			testDir, err := maven.GetTestDir(projectDir, offline)
 			if err != nil {
 				return "", err
 			}
 			testDirs = append(testDirs, testDir)
 		} else if buildSystem == config.BuildSystemGradle {
-			testDirs, err = gradle.GetTestSourceSets(projectDir)
+			testDirs, err = gradle.GetTestSourceSets(projectDir, offline)
 			if err != nil {
 				return "", err
 			}
@@ -189,11 +190,11 @@ func findAllCMakeLists(projectDir string) ([]string, error) {
 	return cmakeLists, errors.WithStack(err)
 }
 
-func FuzzTestArguments(resolveSourceFile bool, args []string, buildSystem, projectDir string) ([]string, error) {
+func FuzzTestArguments(resolveSourceFile bool, args []string, buildSystem, projectDir string, offline bool) ([]string, error) {
 	if resolveSourceFile {
 		var fuzzTests []string
 		for _, arg := range args {
-			fuzzTest, err := resolve(arg, buildSystem, projectDir)
+			fuzzTest, err := resolve(arg, buildSystem, projectDir, offline)
 			if err != nil {
 				return nil, errors.WithMessagef(err, "Failed to resolve source file %s", arg)
 			}
@@ -70,13 +70,13 @@ func testResolveBazel(t *testing.T, pwd string) {
 
 	// relative path
 	srcFile := filepath.Join("src", "fuzz_test_1", "fuzz_test.cpp")
-	resolved, err := resolve(srcFile, config.BuildSystemBazel, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemBazel, pwd, false)
 	require.NoError(t, err)
 	require.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemBazel, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemBazel, pwd, false)
 	require.NoError(t, err)
 	require.Equal(t, fuzzTestName, resolved)
 }
@@ -87,13 +87,13 @@ func testResolveCMake(t *testing.T, pwd string) {
 
 	// relative path
 	srcFile := filepath.Join("src", "fuzz_test_1", "fuzz_test.cpp")
-	resolved, err := resolve(srcFile, config.BuildSystemCMake, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemCMake, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemCMake, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemCMake, pwd, false)
 	require.NoError(t, err)
 	require.Equal(t, fuzzTestName, resolved)
 
@@ -102,13 +102,13 @@ func testResolveCMake(t *testing.T, pwd string) {
 
 	// relative path
 	srcFile = filepath.Join("src", "fuzz_test_2", "fuzz_test.cpp")
-	resolved, err = resolve(srcFile, config.BuildSystemCMake, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemCMake, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemCMake, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemCMake, pwd, false)
 	require.NoError(t, err)
 	require.Equal(t, fuzzTestName, resolved)
 }
@@ -119,26 +119,26 @@ func testResolveMaven(t *testing.T, pwd string) {
 	// Java file
 	// relative path
 	srcFile := filepath.Join("src", "test", "java", "com", "example", "fuzz_test_1", "FuzzTestCase.java")
-	resolved, err := resolve(srcFile, config.BuildSystemMaven, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemMaven, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// Kotlin file
 	// relative path
 	srcFile = filepath.Join("src", "test", "kotlin", "com", "example", "fuzz_test_1", "FuzzTestCase.kt")
-	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 }
@@ -149,26 +149,26 @@ func testResolveGradle(t *testing.T, pwd string) {
 	// Java file
 	// relative path
 	srcFile := filepath.Join("src", "test", "java", "com", "example", "fuzz_test_1", "FuzzTestCase.java")
-	resolved, err := resolve(srcFile, config.BuildSystemGradle, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemGradle, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// Kotlin file
 	// relative path
 	srcFile = filepath.Join("src", "test", "kotlin", "com", "example", "fuzz_test_1", "FuzzTestCase.kt")
-	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 }
@@ -181,12 +181,12 @@ func testResolveGradleWindowsPaths(t *testing.T, pwd string) {
 	fuzzTestName := "com.example.fuzz_test_1.FuzzTestCase"
 
 	srcFile := "src/test/java/com/example/fuzz_test_1/FuzzTestCase.java"
-	resolved, err := resolve(srcFile, config.BuildSystemGradle, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemGradle, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	srcFile = "src\\test\\java\\com\\example\\fuzz_test_1\\FuzzTestCase.java"
-	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemGradle, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 }
@@ -199,12 +199,12 @@ func testResolveMavenWindowsPaths(t *testing.T, pwd string) {
 	fuzzTestName := "com.example.fuzz_test_1.FuzzTestCase"
 
 	srcFile := "src/test/java/com/example/fuzz_test_1/FuzzTestCase.java"
-	resolved, err := resolve(srcFile, config.BuildSystemMaven, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemMaven, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	srcFile = "src\\test\\java\\com\\example\\fuzz_test_1\\FuzzTestCase.java"
-	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemMaven, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 }
@@ -214,13 +214,13 @@ func testResolveNodeJS(t *testing.T, pwd string) {
 
 	// relative path
 	srcFile := filepath.Join("src", "test", "FuzzTestCase.fuzz.js")
-	resolved, err := resolve(srcFile, config.BuildSystemNodeJS, pwd)
+	resolved, err := resolve(srcFile, config.BuildSystemNodeJS, pwd, false)
 	assert.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 
 	// absolute path
 	srcFile = filepath.Join(pwd, srcFile)
-	resolved, err = resolve(srcFile, config.BuildSystemNodeJS, pwd)
+	resolved, err = resolve(srcFile, config.BuildSystemNodeJS, pwd, false)
 	require.NoError(t, err)
 	assert.Equal(t, fuzzTestName, resolved)
 }
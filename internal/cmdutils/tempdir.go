@@ -0,0 +1,48 @@
+package cmdutils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// SetTempDir makes the directory specified via --temp-dir or
+// CIFUZZ_TMPDIR the default directory for temporary files, which is
+// used by os.MkdirTemp and os.CreateTemp (for example by the bundler
+// and the fuzzer runners) as well as by the processes started by
+// cifuzz.
+func SetTempDir() error {
+	tempDir := viper.GetString("temp-dir")
+	if tempDir == "" {
+		// Neither --temp-dir nor CIFUZZ_TMPDIR was set, use the
+		// system temp dir
+		return nil
+	}
+
+	tempDir, err := filepath.Abs(tempDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !fileutil.IsDir(tempDir) {
+		return WrapIncorrectUsageError(errors.Errorf("Temp directory %s does not exist or is not a directory", tempDir))
+	}
+
+	// os.TempDir uses $TMPDIR on Unix systems and %TMP% / %TEMP% on
+	// Windows
+	envVars := []string{"TMPDIR"}
+	if runtime.GOOS == "windows" {
+		envVars = []string{"TMP", "TEMP"}
+	}
+	for _, envVar := range envVars {
+		err = os.Setenv(envVar, tempDir)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
@@ -2,12 +2,43 @@ package cmdutils
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// The fuzzing engines which can be selected via --engine
+const (
+	EngineLibFuzzer = "libfuzzer"
+	EngineAFL       = "afl"
 )
 
+// ValidateEngine checks that the engine selected via --engine is
+// known and supported for the build system.
+func ValidateEngine(engine, buildSystem string) error {
+	if engine == "" {
+		return nil
+	}
+	if engine != EngineLibFuzzer && engine != EngineAFL {
+		msg := fmt.Sprintf("invalid argument %q for \"--engine\" flag: must be %q or %q", engine, EngineLibFuzzer, EngineAFL)
+		return WrapIncorrectUsageError(errors.New(msg))
+	}
+	if engine == EngineAFL &&
+		buildSystem != config.BuildSystemCMake &&
+		buildSystem != config.BuildSystemOther {
+		msg := "Engine \"afl\" is only supported for build system types \"cmake\" and \"other\""
+		return WrapIncorrectUsageError(errors.New(msg))
+	}
+	return nil
+}
+
 // ValidateCorpusDirs checks if the provided corpora exist and can be
 // accessed. It ensures that the paths are absolute.
 func ValidateCorpusDirs(dirs []string) ([]string, error) {
@@ -27,3 +58,245 @@ func ValidateCorpusDirs(dirs []string) ([]string, error) {
 	}
 	return dirs, nil
 }
+
+// ValidateSymbolizer checks if the provided llvm-symbolizer exists and
+// is a regular file. It returns the absolute path to it.
+func ValidateSymbolizer(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			msg := fmt.Sprintf("The symbolizer '%s' does not exist", path)
+			return "", WrapIncorrectUsageError(errors.New(msg))
+		}
+		return "", errors.WithStack(err)
+	}
+	if info.IsDir() {
+		msg := fmt.Sprintf("The symbolizer '%s' is a directory", path)
+		return "", WrapIncorrectUsageError(errors.New(msg))
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// ValidateRunnerBinary checks if the provided runner binary exists and
+// is an executable regular file. It returns the absolute path to it.
+func ValidateRunnerBinary(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			msg := fmt.Sprintf("The runner binary '%s' does not exist", path)
+			return "", WrapIncorrectUsageError(errors.New(msg))
+		}
+		return "", errors.WithStack(err)
+	}
+	if info.IsDir() {
+		msg := fmt.Sprintf("The runner binary '%s' is a directory", path)
+		return "", WrapIncorrectUsageError(errors.New(msg))
+	}
+	if info.Mode()&0o111 == 0 {
+		msg := fmt.Sprintf("The runner binary '%s' is not executable", path)
+		return "", WrapIncorrectUsageError(errors.New(msg))
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// ValidateReproduceInput checks if the provided input file exists and
+// is a regular file. It returns the absolute path to it.
+func ValidateReproduceInput(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			msg := fmt.Sprintf("The input '%s' does not exist", path)
+			return "", WrapIncorrectUsageError(errors.New(msg))
+		}
+		return "", errors.WithStack(err)
+	}
+	if !info.Mode().IsRegular() {
+		msg := fmt.Sprintf("The input '%s' is not a regular file", path)
+		return "", WrapIncorrectUsageError(errors.New(msg))
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// ValidateNativeLibPaths checks if the provided native library paths
+// are existing directories. It returns the absolute paths to them.
+func ValidateNativeLibPaths(paths []string) ([]string, error) {
+	var res []string
+	for _, p := range paths {
+		if !fileutil.IsDir(p) {
+			msg := fmt.Sprintf("The native library path '%s' is not a directory", p)
+			return nil, WrapIncorrectUsageError(errors.New(msg))
+		}
+		p, err := filepath.Abs(p)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// ValidateBuildEnv checks if the provided build environment variables
+// are of the form KEY=VALUE.
+func ValidateBuildEnv(buildEnv []string) error {
+	for _, e := range buildEnv {
+		key, _, found := strings.Cut(e, "=")
+		if !found || key == "" {
+			msg := fmt.Sprintf("invalid argument %q for \"--build-env\" flag: expected format \"KEY=VALUE\"", e)
+			return WrapIncorrectUsageError(errors.New(msg))
+		}
+	}
+	return nil
+}
+
+// ContainsNonEmptyInput returns true if any of the provided corpus
+// directories contains a non-empty file. Directories which don't exist
+// are ignored.
+func ContainsNonEmptyInput(dirs []string) (bool, error) {
+	errFound := errors.New("found non-empty input")
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Size() > 0 {
+				return errFound
+			}
+			return nil
+		})
+		if errors.Is(err, errFound) {
+			return true, nil
+		}
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+	}
+	return false, nil
+}
+
+// OversizedInputs returns the paths of the inputs in the directories
+// which are larger than maxLen bytes. Directories which don't exist are
+// ignored.
+func OversizedInputs(dirs []string, maxLen int) ([]string, error) {
+	var paths []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Size() > int64(maxLen) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return paths, nil
+}
+
+// ValidateClassPaths returns the absolute paths of the provided class
+// path entries. Entries which don't exist are skipped with a warning.
+func ValidateClassPaths(classPaths []string) ([]string, error) {
+	var res []string
+	for _, p := range classPaths {
+		_, err := os.Stat(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				log.Warnf("Ignoring class path entry '%s' which does not exist", p)
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+		p, err = filepath.Abs(p)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// ValidateOutputRoot creates the provided output root directory if it
+// doesn't exist yet and returns the absolute path to it. If no output
+// root is provided, the project directory is returned.
+func ValidateOutputRoot(outputRoot, projectDir string) (string, error) {
+	if outputRoot == "" {
+		return projectDir, nil
+	}
+	outputRoot, err := filepath.Abs(outputRoot)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	err = os.MkdirAll(outputRoot, 0o755)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to create output root %s", outputRoot)
+	}
+	return outputRoot, nil
+}
+
+// ValidateStripPathPrefix returns the absolute path of the provided
+// prefix which is stripped from paths in the reports. If no prefix is
+// provided, the project directory is returned.
+func ValidateStripPathPrefix(prefix, projectDir string) (string, error) {
+	if prefix == "" {
+		return projectDir, nil
+	}
+	prefix, err := filepath.Abs(prefix)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return prefix, nil
+}
+
+// RebaseOnOutputRoot returns the path below the output root which
+// corresponds to the specified path below the project directory. Paths
+// which are not below the project directory are returned unchanged.
+func RebaseOnOutputRoot(path, projectDir, outputRoot string) string {
+	if outputRoot == projectDir {
+		return path
+	}
+	relPath, err := filepath.Rel(projectDir, path)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.Join(outputRoot, relPath)
+}
@@ -0,0 +1,204 @@
+package cmdutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/config"
+)
+
+func TestValidateSymbolizer(t *testing.T) {
+	dir := t.TempDir()
+	symbolizer := filepath.Join(dir, "llvm-symbolizer")
+	err := os.WriteFile(symbolizer, nil, 0o755)
+	require.NoError(t, err)
+
+	path, err := ValidateSymbolizer(symbolizer)
+	require.NoError(t, err)
+	assert.Equal(t, symbolizer, path)
+
+	_, err = ValidateSymbolizer(filepath.Join(dir, "does-not-exist"))
+	require.Error(t, err)
+	var usageErr *IncorrectUsageError
+	assert.ErrorAs(t, err, &usageErr)
+
+	_, err = ValidateSymbolizer(dir)
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+}
+
+func TestValidateRunnerBinary(t *testing.T) {
+	dir := t.TempDir()
+	runner := filepath.Join(dir, "runner")
+	err := os.WriteFile(runner, nil, 0o755)
+	require.NoError(t, err)
+
+	path, err := ValidateRunnerBinary(runner)
+	require.NoError(t, err)
+	assert.Equal(t, runner, path)
+
+	var usageErr *IncorrectUsageError
+	_, err = ValidateRunnerBinary(filepath.Join(dir, "does-not-exist"))
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+
+	_, err = ValidateRunnerBinary(dir)
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+
+	notExecutable := filepath.Join(dir, "not-executable")
+	err = os.WriteFile(notExecutable, nil, 0o644)
+	require.NoError(t, err)
+	_, err = ValidateRunnerBinary(notExecutable)
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+}
+
+func TestValidateReproduceInput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "crash-123")
+	err := os.WriteFile(input, []byte("crash"), 0o644)
+	require.NoError(t, err)
+
+	path, err := ValidateReproduceInput(input)
+	require.NoError(t, err)
+	assert.Equal(t, input, path)
+
+	var usageErr *IncorrectUsageError
+	_, err = ValidateReproduceInput(filepath.Join(dir, "does-not-exist"))
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+
+	_, err = ValidateReproduceInput(dir)
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &usageErr)
+}
+
+func TestValidateNativeLibPaths(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := ValidateNativeLibPaths([]string{dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir}, paths)
+
+	_, err = ValidateNativeLibPaths([]string{dir, filepath.Join(dir, "does-not-exist")})
+	require.Error(t, err)
+	var usageErr *IncorrectUsageError
+	assert.ErrorAs(t, err, &usageErr)
+}
+
+func TestValidateBuildEnv(t *testing.T) {
+	require.NoError(t, ValidateBuildEnv([]string{"CC=/my/clang", "EMPTY="}))
+
+	err := ValidateBuildEnv([]string{"CC"})
+	require.Error(t, err)
+	var usageErr *IncorrectUsageError
+	assert.ErrorAs(t, err, &usageErr)
+
+	require.Error(t, ValidateBuildEnv([]string{"=value"}))
+}
+
+func TestValidateClassPaths(t *testing.T) {
+	dir := t.TempDir()
+	jar := filepath.Join(dir, "helper.jar")
+	err := os.WriteFile(jar, nil, 0o644)
+	require.NoError(t, err)
+
+	classPaths, err := ValidateClassPaths([]string{jar, filepath.Join(dir, "does-not-exist.jar"), dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{jar, dir}, classPaths)
+}
+
+func TestContainsNonEmptyInput(t *testing.T) {
+	emptyDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(emptyDir, "empty"), nil, 0o644)
+	require.NoError(t, err)
+
+	corpusDir := t.TempDir()
+	err = os.MkdirAll(filepath.Join(corpusDir, "sub"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "sub", "input"), []byte("seed"), 0o644)
+	require.NoError(t, err)
+
+	found, err := ContainsNonEmptyInput([]string{emptyDir, filepath.Join(emptyDir, "does-not-exist"), ""})
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	found, err = ContainsNonEmptyInput([]string{emptyDir, corpusDir})
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestOversizedInputs(t *testing.T) {
+	corpusDir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(corpusDir, "sub"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "small"), []byte("seed"), 0o644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "sub", "large"), []byte("large seed"), 0o644)
+	require.NoError(t, err)
+
+	paths, err := OversizedInputs([]string{corpusDir, filepath.Join(corpusDir, "does-not-exist"), ""}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(corpusDir, "sub", "large")}, paths)
+
+	paths, err = OversizedInputs([]string{corpusDir}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+func TestValidateOutputRoot(t *testing.T) {
+	projectDir := t.TempDir()
+
+	outputRoot, err := ValidateOutputRoot("", projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, projectDir, outputRoot)
+
+	dir := filepath.Join(t.TempDir(), "out")
+	outputRoot, err = ValidateOutputRoot(dir, projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, outputRoot)
+	assert.DirExists(t, dir)
+}
+
+func TestValidateStripPathPrefix(t *testing.T) {
+	projectDir := t.TempDir()
+
+	prefix, err := ValidateStripPathPrefix("", projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, projectDir, prefix)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	prefix, err = ValidateStripPathPrefix("src", projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cwd, "src"), prefix)
+}
+
+func TestValidateEngine(t *testing.T) {
+	require.NoError(t, ValidateEngine("", config.BuildSystemMaven))
+	require.NoError(t, ValidateEngine(EngineLibFuzzer, config.BuildSystemBazel))
+	require.NoError(t, ValidateEngine(EngineAFL, config.BuildSystemCMake))
+	require.Error(t, ValidateEngine(EngineAFL, config.BuildSystemBazel))
+	require.Error(t, ValidateEngine("honggfuzz", config.BuildSystemCMake))
+}
+
+func TestRebaseOnOutputRoot(t *testing.T) {
+	projectDir := string(filepath.Separator) + "project"
+	outputRoot := string(filepath.Separator) + "out"
+
+	assert.Equal(t,
+		filepath.Join(outputRoot, ".cifuzz-corpus", "my_fuzz_test"),
+		RebaseOnOutputRoot(filepath.Join(projectDir, ".cifuzz-corpus", "my_fuzz_test"), projectDir, outputRoot))
+	// Paths outside of the project directory are not changed
+	assert.Equal(t,
+		filepath.Join(string(filepath.Separator)+"tmp", "corpus"),
+		RebaseOnOutputRoot(filepath.Join(string(filepath.Separator)+"tmp", "corpus"), projectDir, outputRoot))
+	// Without an output root, the path is not changed
+	assert.Equal(t,
+		filepath.Join(projectDir, "corpus"),
+		RebaseOnOutputRoot(filepath.Join(projectDir, "corpus"), projectDir, projectDir))
+}
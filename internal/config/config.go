@@ -3,11 +3,13 @@ package config
 import (
 	_ "embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -66,7 +68,7 @@ const AllowUnsupportedPlatformsEnv = "CIFUZZ_ALLOW_UNSUPPORTED_PLATFORMS"
 var projectConfigTemplate string
 
 // CreateProjectConfig creates a new project config in the given directory
-func CreateProjectConfig(configDir string, server string, project string) (string, error) {
+func CreateProjectConfig(configDir string, server string, project string, buildSystem string) (string, error) {
 	// try to open the target file, returns error if already exists
 	configpath := filepath.Join(configDir, ProjectConfigFile)
 	f, err := os.OpenFile(configpath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
@@ -88,10 +90,12 @@ func CreateProjectConfig(configDir string, server string, project string) (strin
 		LastUpdated string
 		Server      string
 		Project     string
+		BuildSystem string
 	}{
 		time.Now().Format("2006-01-02"),
 		server,
 		project,
+		buildSystem,
 	}
 
 	// parse the template and write it to config file
@@ -279,6 +283,34 @@ func FindConfigDir() (string, error) {
 	return dir, nil
 }
 
+// FindProjectDirs returns the sorted directories below the specified
+// root directory (including the root directory itself) which contain a
+// cifuzz.yaml. Hidden directories, like the .cifuzz-build directory,
+// are not searched.
+func FindProjectDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == ProjectConfigFile {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
 func EnsureProjectEntry(configContent string, project string) string {
 	// check if there is already a project entry (with or without a comment)
 	re := regexp.MustCompile(`(?m)^#*\s*project:.*$`)
@@ -32,7 +32,7 @@ func TestCreateProjectConfig(t *testing.T) {
 	require.NoError(t, err)
 	defer fileutil.Cleanup(projectDir)
 
-	path, err := CreateProjectConfig(projectDir, "", "")
+	path, err := CreateProjectConfig(projectDir, "", "", "")
 	assert.NoError(t, err)
 	expectedPath := filepath.Join(projectDir, ProjectConfigFile)
 	assert.Equal(t, expectedPath, path)
@@ -58,7 +58,7 @@ func TestCreateProjectConfig_WithServerAndProject(t *testing.T) {
 	require.NoError(t, err)
 	defer fileutil.Cleanup(projectDir)
 
-	path, err := CreateProjectConfig(projectDir, "https://foo.bar", "my-project")
+	path, err := CreateProjectConfig(projectDir, "https://foo.bar", "my-project", "")
 	assert.NoError(t, err)
 	expectedPath := filepath.Join(projectDir, ProjectConfigFile)
 	assert.Equal(t, expectedPath, path)
@@ -87,7 +87,7 @@ func TestCreateProjectConfig_NoPerm(t *testing.T) {
 	err = acl.Chmod(projectDir, 0o555)
 	require.NoError(t, err)
 
-	path, err := CreateProjectConfig(projectDir, "", "")
+	path, err := CreateProjectConfig(projectDir, "", "", "")
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, os.ErrPermission)
 	assert.Empty(t, path)
@@ -108,7 +108,7 @@ func TestCreateProjectConfig_Exists(t *testing.T) {
 	err = os.WriteFile(existingPath, []byte{}, 0o644)
 	require.NoError(t, err)
 
-	path, err := CreateProjectConfig(filepath.Dir(existingPath), "", "")
+	path, err := CreateProjectConfig(filepath.Dir(existingPath), "", "", "")
 	assert.Error(t, err)
 	// check if path of the existing config is return and the error indicates it too
 	assert.ErrorIs(t, err, os.ErrExist)
@@ -330,3 +330,19 @@ project: myProject
 		})
 	}
 }
+
+func TestFindProjectDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b/nested", "c", ".cifuzz-build/d"} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0o755)
+		require.NoError(t, err)
+	}
+	for _, dir := range []string{"a", "b/nested", ".cifuzz-build/d"} {
+		err := os.WriteFile(filepath.Join(root, dir, ProjectConfigFile), nil, 0o644)
+		require.NoError(t, err)
+	}
+
+	dirs, err := FindProjectDirs(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "a"), filepath.Join(root, "b", "nested")}, dirs)
+}
@@ -32,9 +32,19 @@ type dockerfileConfig struct {
 	Base        string
 }
 
+// ImageBuildOptions allows to customize how the image is built from
+// the bundle.
+type ImageBuildOptions struct {
+	// Dockerfile is used instead of the default Dockerfile if set.
+	Dockerfile string
+	// ContextDir is a directory whose contents are added to the build
+	// context, so that they can be used by a custom Dockerfile.
+	ContextDir string
+}
+
 // BuildImageFromBundle creates an image based on an existing bundle.
-func BuildImageFromBundle(bundlePath string) (string, error) {
-	buildContextDir, err := prepareBuildContext(bundlePath)
+func BuildImageFromBundle(bundlePath string, opts *ImageBuildOptions) (string, error) {
+	buildContextDir, err := prepareBuildContext(bundlePath, opts)
 	if err != nil {
 		return "", err
 	}
@@ -82,13 +92,22 @@ func UploadImage(imageID string, registry string) error {
 
 // prepareBuildContext takes a existing artifact bundle, extracts it
 // and adds needed files/information.
-func prepareBuildContext(bundlePath string) (string, error) {
+func prepareBuildContext(bundlePath string, opts *ImageBuildOptions) (string, error) {
 	// extract bundle to a temporary directory
 	buildContextDir, err := os.MkdirTemp("", "bundle-extract")
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
 
+	// Copy the custom build context first, so that files from the
+	// bundle take precedence over files of the same name.
+	if opts.ContextDir != "" {
+		err = copy.Copy(opts.ContextDir, buildContextDir)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to copy build context %s", opts.ContextDir)
+		}
+	}
+
 	err = archive.Extract(bundlePath, buildContextDir)
 	if err != nil {
 		return "", errors.WithMessagef(err, "Failed to extract bundle to %s", buildContextDir)
@@ -103,9 +122,17 @@ func prepareBuildContext(bundlePath string) (string, error) {
 
 	// add additional files needed for the image
 	// eg. build instructions and cifuzz executables
-	err = createDockerfile(filepath.Join(buildContextDir, "Dockerfile"), metadata.Docker)
-	if err != nil {
-		return "", err
+	dockerfilePath := filepath.Join(buildContextDir, "Dockerfile")
+	if opts.Dockerfile != "" {
+		err = copy.Copy(opts.Dockerfile, dockerfilePath)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed to copy Dockerfile %s", opts.Dockerfile)
+		}
+	} else {
+		err = createDockerfile(dockerfilePath, metadata.Docker)
+		if err != nil {
+			return "", err
+		}
 	}
 	err = copyCifuzz(buildContextDir)
 	if err != nil {
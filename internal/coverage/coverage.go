@@ -6,11 +6,40 @@ const FormatHTML = "html"
 const FormatLCOV = "lcov"
 const FormatJacocoXML = "jacocoxml"
 
+const FormatCobertura = "cobertura"
+
+// FormatJUnit is not a coverage report format but a JUnit XML report
+// which contains the results of the coverage threshold checks
+const FormatJUnit = "junit"
+
+// FormatSARIF is a SARIF 2.1.0 log which contains the coverage summary
+// of each file, to be ingested by tools which consume SARIF
+const FormatSARIF = "sarif"
+
+// FormatSonarQube is the generic test coverage format of SonarQube
+const FormatSonarQube = "sonarqube"
+
+// ReportFileName returns the name of the report of the given format
+// when reports of multiple formats are written to the same directory.
+// For HTML reports, it's the name of a directory.
+func ReportFileName(format string) string {
+	switch format {
+	case FormatLCOV:
+		return "coverage.lcov"
+	case FormatCobertura:
+		return "coverage.cobertura.xml"
+	case FormatSonarQube:
+		return "coverage.sonarqube.xml"
+	default:
+		return format
+	}
+}
+
 var ValidOutputFormats = map[string][]string{
-	config.BuildSystemCMake:  {FormatHTML, FormatLCOV},
-	config.BuildSystemBazel:  {FormatHTML, FormatLCOV},
-	config.BuildSystemOther:  {FormatHTML, FormatLCOV},
-	config.BuildSystemMaven:  {FormatHTML, FormatLCOV, FormatJacocoXML},
-	config.BuildSystemGradle: {FormatHTML, FormatLCOV, FormatJacocoXML},
-	config.BuildSystemNodeJS: {FormatHTML, FormatLCOV},
+	config.BuildSystemCMake:  {FormatHTML, FormatLCOV, FormatCobertura, FormatSonarQube, FormatJUnit, FormatSARIF},
+	config.BuildSystemBazel:  {FormatHTML, FormatLCOV, FormatCobertura, FormatSonarQube, FormatJUnit, FormatSARIF},
+	config.BuildSystemOther:  {FormatHTML, FormatLCOV, FormatCobertura, FormatSonarQube, FormatJUnit, FormatSARIF},
+	config.BuildSystemMaven:  {FormatHTML, FormatLCOV, FormatJacocoXML, FormatCobertura, FormatSonarQube, FormatJUnit, FormatSARIF},
+	config.BuildSystemGradle: {FormatHTML, FormatLCOV, FormatJacocoXML, FormatCobertura, FormatSonarQube, FormatJUnit, FormatSARIF},
+	config.BuildSystemNodeJS: {FormatHTML, FormatLCOV, FormatCobertura, FormatSonarQube, FormatJUnit},
 }
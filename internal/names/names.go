@@ -3,7 +3,10 @@ package names
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"math/rand"
+
+	"github.com/pkg/errors"
 )
 
 // these lists of names and adjectives are (partly) copied from:
@@ -478,14 +481,61 @@ var (
 	}
 )
 
+// Style determines the format of the names generated by
+// GetDeterministicName.
+type Style string
+
+const (
+	// StyleTwoWord generates names like 'focused_turing'
+	StyleTwoWord Style = "two-word"
+	// StyleThreeWord generates names like 'brave_focused_turing', which
+	// makes collisions a lot less likely
+	StyleThreeWord Style = "three-word"
+	// StyleHash generates names from a short hash of the seed, like
+	// 'f3a1b2c4d5e6', which are practically unique
+	StyleHash Style = "hash"
+)
+
+// hashNameLength is the number of hex digits of the hash used for names
+// of the hash style
+const hashNameLength = 12
+
+var Styles = []Style{StyleTwoWord, StyleThreeWord, StyleHash}
+
+// ParseStyle parses the name style configured by the user. An empty
+// string results in the default style, which is StyleTwoWord.
+func ParseStyle(s string) (Style, error) {
+	if s == "" {
+		return StyleTwoWord, nil
+	}
+	for _, style := range Styles {
+		if Style(s) == style {
+			return style, nil
+		}
+	}
+	return "", errors.Errorf("invalid name style %q: must be one of %s, %s or %s", s, StyleTwoWord, StyleThreeWord, StyleHash)
+}
+
 // GetDeterministicName generates a name from the list of adjectives and
 // surnames from Docker's namesgenerator package, formatted as
-// "adjective_surname". For example 'focused_turing'.
+// "adjective_surname" (for example 'focused_turing') or, for the
+// three-word style, "adjective_adjective_surname". For the hash style,
+// the name is a short hex encoded hash instead.
 // The name is chosen deterministically based on the specified seed.
-func GetDeterministicName(seedValue []byte) string {
+func GetDeterministicName(seedValue []byte, style Style) string {
 	hash := sha256.New()
 	hash.Write(seedValue)
-	source := rand.NewSource(int64(binary.BigEndian.Uint64(hash.Sum(nil))))
+	sum := hash.Sum(nil)
+
+	if style == StyleHash {
+		return hex.EncodeToString(sum)[:hashNameLength]
+	}
+
+	source := rand.NewSource(int64(binary.BigEndian.Uint64(sum)))
 	r := rand.New(source)
-	return left[r.Intn(len(left))] + "_" + right[r.Intn(len(right))]
+	name := left[r.Intn(len(left))] + "_" + right[r.Intn(len(right))]
+	if style == StyleThreeWord {
+		name = left[r.Intn(len(left))] + "_" + name
+	}
+	return name
 }
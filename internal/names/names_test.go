@@ -4,10 +4,36 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeterminsticName(t *testing.T) {
-	assert.Equal(t, "gracious_camel", GetDeterministicName([]byte("fc75")))
-	assert.Equal(t, "obnoxious_tortoise", GetDeterministicName([]byte("fc7598c04e2ffdc36c3ff70428fd98912ffb07a8")))
-	assert.Equal(t, "observing_deer", GetDeterministicName([]byte("")))
+	assert.Equal(t, "gracious_camel", GetDeterministicName([]byte("fc75"), StyleTwoWord))
+	assert.Equal(t, "obnoxious_tortoise", GetDeterministicName([]byte("fc7598c04e2ffdc36c3ff70428fd98912ffb07a8"), StyleTwoWord))
+	assert.Equal(t, "observing_deer", GetDeterministicName([]byte(""), StyleTwoWord))
+}
+
+func TestDeterministicName_Styles(t *testing.T) {
+	seed := []byte("fc75")
+
+	// The three-word names extend the two-word names, so that findings
+	// keep a recognizable name when the style is changed
+	assert.Regexp(t, `^[a-z]+_gracious_camel$`, GetDeterministicName(seed, StyleThreeWord))
+	assert.Equal(t, GetDeterministicName(seed, StyleThreeWord), GetDeterministicName(seed, StyleThreeWord))
+
+	assert.Regexp(t, `^[0-9a-f]{12}$`, GetDeterministicName(seed, StyleHash))
+	assert.NotEqual(t, GetDeterministicName(seed, StyleHash), GetDeterministicName([]byte("fc76"), StyleHash))
+}
+
+func TestParseStyle(t *testing.T) {
+	style, err := ParseStyle("")
+	require.NoError(t, err)
+	assert.Equal(t, StyleTwoWord, style)
+
+	style, err = ParseStyle("hash")
+	require.NoError(t, err)
+	assert.Equal(t, StyleHash, style)
+
+	_, err = ParseStyle("four-word")
+	require.Error(t, err)
 }
@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/internal/config"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// ErrMissingInput is returned by Replayer.Reproduces if the crashing
+// input of the finding does not exist
+var ErrMissingInput = errors.New("The crashing input of the finding does not exist")
+
+// Replayer executes fuzz tests once with the crashing inputs of
+// findings. Each fuzz test is only built once.
+type Replayer struct {
+	opts    *adapter.RunOptions
+	adapter adapter.Adapter
+}
+
+// CheckBuildSystem returns an error if replaying findings is not
+// supported for the build system.
+func CheckBuildSystem(buildSystem string) error {
+	if buildSystem != config.BuildSystemCMake &&
+		buildSystem != config.BuildSystemBazel &&
+		buildSystem != config.BuildSystemOther {
+		msg := fmt.Sprintf("Replaying findings is not supported for build system type \"%s\"", buildSystem)
+		return cmdutils.WrapIncorrectUsageError(errors.New(msg))
+	}
+	return nil
+}
+
+// NewReplayer returns a Replayer which builds and executes the fuzz
+// tests with the specified run options. Cleanup must be called when
+// it's no longer used.
+func NewReplayer(opts *adapter.RunOptions) (*Replayer, error) {
+	runAdapter, err := adapter.NewAdapter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = runAdapter.CheckDependencies(opts.ProjectDir)
+	if err != nil {
+		runAdapter.Cleanup()
+		return nil, err
+	}
+
+	return &Replayer{opts: opts, adapter: runAdapter}, nil
+}
+
+// Reproduces builds the fuzz test of the finding unless it was built
+// before, executes it once with the crashing input of the finding and
+// returns whether it still triggers a finding. If the crashing input
+// does not exist, ErrMissingInput is returned.
+func (r *Replayer) Reproduces(f *finding.Finding) (bool, error) {
+	if f.InputFile == "" {
+		return false, errors.WithStack(ErrMissingInput)
+	}
+	inputFile := f.InputFile
+	if !filepath.IsAbs(inputFile) {
+		inputFile = filepath.Join(r.opts.ProjectDir, inputFile)
+	}
+	exists, err := fileutil.Exists(inputFile)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errors.WithStack(ErrMissingInput)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "cifuzz-replay-")
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tmpDir)
+
+	// The crashing input is the only input in the seed corpus. An
+	// empty directory is used as the generated corpus, so that the
+	// generated corpus of the fuzz test is not executed.
+	seedCorpusDir := filepath.Join(tmpDir, "seeds")
+	err = copy.Copy(inputFile, filepath.Join(seedCorpusDir, filepath.Base(inputFile)))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	opts := *r.opts
+	opts.FuzzTest = f.FuzzTest
+	opts.Replay = true
+	opts.SeedCorpusDirs = []string{seedCorpusDir}
+	opts.ReplayCorpusDir = filepath.Join(tmpDir, "corpus")
+	opts.EngineArgs = append(append([]string{}, r.opts.EngineArgs...), "-runs=0")
+	opts.Timeout = 0
+
+	reportHandler, err := r.adapter.Run(&opts)
+	if err != nil {
+		return false, err
+	}
+	return len(reportHandler.Findings) > 0, nil
+}
+
+// Cleanup removes the temporary files of the builds.
+func (r *Replayer) Cleanup() {
+	r.adapter.Cleanup()
+}
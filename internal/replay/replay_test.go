@@ -0,0 +1,74 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/internal/cmd/run/adapter"
+	"code-intelligence.com/cifuzz/internal/cmd/run/reporthandler"
+	"code-intelligence.com/cifuzz/pkg/dependencies"
+	"code-intelligence.com/cifuzz/pkg/finding"
+)
+
+// fakeAdapter reports a finding if the seed corpus contains an input
+// named "crash"
+type fakeAdapter struct {
+	runs []*adapter.RunOptions
+}
+
+func (a *fakeAdapter) CheckDependencies(string) ([]*dependencies.Status, error) { return nil, nil }
+
+func (a *fakeAdapter) Run(opts *adapter.RunOptions) (*reporthandler.ReportHandler, error) {
+	a.runs = append(a.runs, opts)
+	h := &reporthandler.ReportHandler{}
+	for _, dir := range opts.SeedCorpusDirs {
+		if _, err := os.Stat(filepath.Join(dir, "crash")); err == nil {
+			h.Findings = append(h.Findings, &finding.Finding{Name: "new_finding"})
+		}
+	}
+	return h, nil
+}
+
+func (a *fakeAdapter) Cleanup() {}
+
+func TestReproduces(t *testing.T) {
+	projectDir := t.TempDir()
+	for _, name := range []string{"crash", "fixed"} {
+		err := os.WriteFile(filepath.Join(projectDir, name), []byte(name), 0o644)
+		require.NoError(t, err)
+	}
+
+	runAdapter := &fakeAdapter{}
+	r := &Replayer{
+		opts:    &adapter.RunOptions{ProjectDir: projectDir, EngineArgs: []string{"-seed=1"}},
+		adapter: runAdapter,
+	}
+
+	// Relative input paths are resolved against the project directory
+	reproduces, err := r.Reproduces(&finding.Finding{Name: "a", FuzzTest: "my_fuzz_test", InputFile: "crash"})
+	require.NoError(t, err)
+	assert.True(t, reproduces)
+
+	reproduces, err = r.Reproduces(&finding.Finding{Name: "b", FuzzTest: "my_fuzz_test", InputFile: filepath.Join(projectDir, "fixed")})
+	require.NoError(t, err)
+	assert.False(t, reproduces)
+
+	require.Len(t, runAdapter.runs, 2)
+	opts := runAdapter.runs[0]
+	assert.True(t, opts.Replay)
+	assert.Equal(t, "my_fuzz_test", opts.FuzzTest)
+	assert.Equal(t, []string{"-seed=1", "-runs=0"}, opts.EngineArgs)
+	// The engine args of the options are not modified
+	assert.Equal(t, []string{"-seed=1"}, r.opts.EngineArgs)
+
+	// Findings without a crashing input are not replayed
+	_, err = r.Reproduces(&finding.Finding{Name: "c", FuzzTest: "my_fuzz_test", InputFile: "missing"})
+	require.ErrorIs(t, err, ErrMissingInput)
+	_, err = r.Reproduces(&finding.Finding{Name: "d", FuzzTest: "my_fuzz_test"})
+	require.ErrorIs(t, err, ErrMissingInput)
+	assert.Len(t, runAdapter.runs, 2)
+}
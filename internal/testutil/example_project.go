@@ -39,7 +39,7 @@ func BootstrapEmptyProject(t *testing.T, prefix string) string {
 	projectDir := MkdirTemp(t, "", prefix)
 
 	// Create an empty config file
-	_, err := config.CreateProjectConfig(projectDir, "", "")
+	_, err := config.CreateProjectConfig(projectDir, "", "", "")
 	require.NoError(t, err)
 
 	return projectDir
@@ -2,12 +2,14 @@ package dependencies
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/pkg/log"
 	"code-intelligence.com/cifuzz/pkg/runfiles"
+	"code-intelligence.com/cifuzz/util/stringutil"
 )
 
 var errDeps = errors.New(`unable to run command due to missing/invalid dependencies.
@@ -53,20 +55,48 @@ type Dependency struct {
 	Installed  func(*Dependency, string) bool
 }
 
-// Compares MinVersion against GetVersion
-func (dep *Dependency) checkVersion(projectDir string) bool {
+// Status is the machine-readable result of checking a single
+// dependency
+type Status struct {
+	Key   Key  `json:"key"`
+	Found bool `json:"found"`
+	// Version is the detected version, it's empty if the dependency was
+	// not found or the version could not be determined
+	Version string `json:"version,omitempty"`
+	// MinVersion is the minimum required version, it's empty if any
+	// version is accepted
+	MinVersion string `json:"min_version,omitempty"`
+	OK         bool   `json:"ok"`
+}
+
+// PrintJSON prints the statuses of the checked dependencies as JSON
+// to w, so that scripts can act on missing dependencies
+func PrintJSON(w io.Writer, statuses []*Status) error {
+	s, err := stringutil.ToJSONString(struct {
+		Dependencies []*Status `json:"dependencies"`
+	}{statuses})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, s)
+	return errors.WithStack(err)
+}
+
+// Compares MinVersion against GetVersion and returns the current
+// version, if it could be determined
+func (dep *Dependency) checkVersion(projectDir string) (*semver.Version, bool) {
 	currentVersion, err := dep.GetVersion(dep, projectDir)
 	if err != nil {
 		log.Warnf("Unable to get current version for %s, message: %v", dep.Key, err)
 		// we want to be lenient if we were not able to extract the version
-		return true
+		return nil, true
 	}
 
 	if currentVersion.Compare(&dep.MinVersion) == -1 {
 		log.Warnf(MessageVersion, dep.Key, dep.MinVersion.String(), currentVersion.String())
-		return false
+		return currentVersion, false
 	}
-	return true
+	return currentVersion, true
 }
 
 // helper to easily check against functions from the runfiles.RunfilesFinder interface
@@ -78,14 +108,16 @@ func (dep *Dependency) checkFinder(finderFunc func() (string, error)) bool {
 	return true
 }
 
-// Check iterates of a list of dependencies and checks if they are fulfilled
-func Check(keys []Key, projectDir string) error {
-	err := check(keys, deps, runfiles.Finder, projectDir)
+// Check iterates of a list of dependencies and checks if they are fulfilled.
+// The status of each dependency is returned, also if an error is
+// returned because any of them is not fulfilled.
+func Check(keys []Key, projectDir string) ([]*Status, error) {
+	statuses, err := check(keys, deps, runfiles.Finder, projectDir)
 	if err != nil {
-		return errors.WithMessage(err, "Invalid dependencies")
+		return statuses, errors.WithMessage(err, "Invalid dependencies")
 	}
 
-	return nil
+	return statuses, nil
 }
 
 func Version(key Key, projectDir string) (*semver.Version, error) {
@@ -98,8 +130,9 @@ func Version(key Key, projectDir string) (*semver.Version, error) {
 	return dep.GetVersion(dep, projectDir)
 }
 
-func check(keys []Key, deps Dependencies, finder runfiles.RunfilesFinder, projectDir string) error {
+func check(keys []Key, deps Dependencies, finder runfiles.RunfilesFinder, projectDir string) ([]*Status, error) {
 	allFine := true
+	var statuses []*Status
 	for _, key := range keys {
 		dep, found := deps[key]
 		if !found {
@@ -108,26 +141,37 @@ func check(keys []Key, deps Dependencies, finder runfiles.RunfilesFinder, projec
 
 		dep.finder = finder
 
+		status := &Status{Key: dep.Key}
+		if !dep.MinVersion.Equal(semver.MustParse("0.0.0")) {
+			status.MinVersion = dep.MinVersion.String()
+		}
+		statuses = append(statuses, status)
+
 		if !dep.Installed(dep, projectDir) {
 			log.Warnf(MessageMissing, dep.Key)
 			allFine = false
 			continue
 		}
+		status.Found = true
 
-		if dep.MinVersion.Equal(semver.MustParse("0.0.0")) {
+		if status.MinVersion == "" {
 			log.Debugf("Checking dependency: %s ", dep.Key)
 		} else {
 			log.Debugf("Checking dependency: %s version >= %s", dep.Key, dep.MinVersion.String())
 		}
 
-		if !dep.checkVersion(projectDir) {
+		currentVersion, ok := dep.checkVersion(projectDir)
+		if currentVersion != nil {
+			status.Version = currentVersion.String()
+		}
+		status.OK = ok
+		if !ok {
 			allFine = false
 		}
-
 	}
 
 	if !allFine {
-		return errDeps
+		return statuses, errDeps
 	}
-	return nil
+	return statuses, nil
 }
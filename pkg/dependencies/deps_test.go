@@ -1,6 +1,7 @@
 package dependencies
 
 import (
+	"bytes"
 	"errors"
 	"path/filepath"
 	"testing"
@@ -23,7 +24,7 @@ func TestCheck(t *testing.T) {
 	finder := &mocks.RunfilesFinderMock{}
 	finder.On("CMakePath").Return("cmake", nil)
 
-	err := check(keys, deps, finder, "")
+	_, err := check(keys, deps, finder, "")
 	require.NoError(t, err)
 }
 
@@ -34,7 +35,7 @@ func TestCheck_NotInstalled(t *testing.T) {
 	finder := &mocks.RunfilesFinderMock{}
 	finder.On("CMakePath").Return("", errors.New("missing-error"))
 
-	err := check(keys, deps, finder, "")
+	_, err := check(keys, deps, finder, "")
 	require.Error(t, err)
 }
 
@@ -51,10 +52,46 @@ func TestCheck_WrongVersion(t *testing.T) {
 	finder := &mocks.RunfilesFinderMock{}
 	finder.On("CMakePath").Return("cmake", nil)
 
-	err := check(keys, deps, finder, "")
+	_, err := check(keys, deps, finder, "")
 	require.Error(t, err)
 }
 
+func TestCheck_Statuses(t *testing.T) {
+	keys := []Key{CMake, LLVMSymbolizer}
+	deps := getDeps(keys)
+
+	dep := deps[CMake]
+	dep.GetVersion = func(d *Dependency, _ string) (*semver.Version, error) {
+		return semver.MustParse("1.0.0"), nil
+	}
+
+	finder := &mocks.RunfilesFinderMock{}
+	finder.On("CMakePath").Return("cmake", nil)
+	finder.On("LLVMSymbolizerPath").Return("", errors.New("missing-error"))
+
+	statuses, err := check(keys, deps, finder, "")
+	require.Error(t, err)
+
+	require.Len(t, statuses, 2)
+	require.Equal(t, &Status{
+		Key:        CMake,
+		Found:      true,
+		Version:    "1.0.0",
+		MinVersion: deps[CMake].MinVersion.String(),
+		OK:         false,
+	}, statuses[0])
+	require.Equal(t, LLVMSymbolizer, statuses[1].Key)
+	require.False(t, statuses[1].Found)
+	require.False(t, statuses[1].OK)
+}
+
+func TestPrintJSON(t *testing.T) {
+	var out bytes.Buffer
+	err := PrintJSON(&out, []*Status{{Key: CMake, Found: true, Version: "3.16.0", OK: true}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"dependencies":[{"key":"cmake","found":true,"version":"3.16.0","ok":true}]}`, out.String())
+}
+
 func TestCheck_ShortVersion(t *testing.T) {
 	keys := []Key{CMake}
 	deps := getDeps(keys)
@@ -68,7 +105,7 @@ func TestCheck_ShortVersion(t *testing.T) {
 	finder := &mocks.RunfilesFinderMock{}
 	finder.On("CMakePath").Return("cmake", nil)
 
-	err := check(keys, deps, finder, "")
+	_, err := check(keys, deps, finder, "")
 	require.NoError(t, err)
 }
 
@@ -85,7 +122,7 @@ func TestCheck_UnableToGetVersion(t *testing.T) {
 	finder := &mocks.RunfilesFinderMock{}
 	finder.On("CMakePath").Return("cmake", nil)
 
-	err := check(keys, deps, finder, "")
+	_, err := check(keys, deps, finder, "")
 	require.NoError(t, err)
 }
 
@@ -101,17 +138,17 @@ func TestCheck_SpecialCaseGradle(t *testing.T) {
 	// no gradle and no gradlew
 	finder := &mocks.RunfilesFinderMock{}
 	finder.On("GradlePath").Return("", errors.New("missing-error"))
-	err := check(keys, deps, finder, "")
+	_, err := check(keys, deps, finder, "")
 	require.Error(t, err)
 
 	// gradle but no gradlew
 	finder = &mocks.RunfilesFinderMock{}
 	finder.On("GradlePath").Return("gradle", nil)
-	err = check(keys, deps, finder, "")
+	_, err = check(keys, deps, finder, "")
 	require.NoError(t, err)
 
 	// no gradle but gradlew in project dir
 	finder = &mocks.RunfilesFinderMock{}
-	err = check(keys, deps, finder, filepath.Join("testdata", "gradle"))
+	_, err = check(keys, deps, finder, filepath.Join("testdata", "gradle"))
 	require.NoError(t, err)
 }
@@ -0,0 +1,126 @@
+package finding
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/archiveutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// FileWriter writes files to an archive, like the archive writers of
+// the bundler.
+type FileWriter interface {
+	WriteFile(archivePath string, sourcePath string) error
+}
+
+// Export writes all local findings of the project to the archive
+// writer w. The files are written below the findings directory, so
+// that the archive can be imported into another project via Import.
+// It returns the number of exported findings.
+func Export(projectDir string, w FileWriter) (int, error) {
+	findingsDir := filepath.Join(projectDir, nameFindingsDir)
+	entries, err := os.ReadDir(findingsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, errors.WithStack(err)
+	}
+
+	var count int
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		findingDir := filepath.Join(findingsDir, e.Name())
+		files, err := os.ReadDir(findingDir)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		for _, file := range files {
+			// The lock file is only used to synchronize cifuzz
+			// processes and doesn't belong to the finding
+			if file.Name() == lockFile || file.IsDir() {
+				continue
+			}
+			err = w.WriteFile(filepath.Join(nameFindingsDir, e.Name(), file.Name()), filepath.Join(findingDir, file.Name()))
+			if err != nil {
+				return 0, err
+			}
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Import extracts the findings from a gzip-compressed tar archive
+// created by Export into the findings directory of the project.
+// Each finding is validated before it is imported. Findings which
+// already exist in the project are skipped. It returns the imported
+// findings.
+func Import(projectDir, archivePath string) ([]*Finding, error) {
+	tempDir, err := os.MkdirTemp("", "cifuzz-findings-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(tempDir)
+
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer archiveFile.Close()
+	gr, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read findings archive %s", archivePath)
+	}
+	defer gr.Close()
+	err = archiveutil.Untar(gr, tempDir)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Failed to extract findings archive %s", archivePath)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(tempDir, nameFindingsDir))
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf("%s is not a findings archive: it doesn't contain a %s directory", archivePath, nameFindingsDir)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Validate all findings before importing any of them, so that an
+	// invalid archive doesn't leave the project in a partial state
+	var findings []*Finding
+	for _, e := range entries {
+		f, err := LoadFinding(tempDir, e.Name(), nil)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid finding %q in archive %s", e.Name(), archivePath)
+		}
+		if f.Name != e.Name() {
+			return nil, errors.Errorf("Invalid finding %q in archive %s: name doesn't match directory", e.Name(), archivePath)
+		}
+		findings = append(findings, f)
+	}
+
+	var imported []*Finding
+	for _, f := range findings {
+		exists, err := f.Exists(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			log.Warnf("Skipping finding %s, it already exists in the project", f.Name)
+			continue
+		}
+		err = copy.Copy(filepath.Join(tempDir, nameFindingsDir, f.Name), filepath.Join(projectDir, nameFindingsDir, f.Name))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		imported = append(imported, f)
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,76 @@
+package finding
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Category is a more specific classification of a finding than its
+// ErrorType. Unlike the ErrorType, it's not part of the protobuf
+// representation, so categories can be added without breaking the
+// parsing of reports.
+type Category string
+
+const (
+	// CategoryOutOfMemory are findings for which the fuzz test exceeded
+	// the memory limit of the fuzzer, which are resource exhaustion
+	// bugs instead of memory safety bugs
+	CategoryOutOfMemory Category = "out-of-memory"
+	// CategoryTimeout are findings for which a single input ran longer
+	// than the timeout of the fuzzer
+	CategoryTimeout Category = "timeout"
+)
+
+// FindingKinds are the kinds of findings which can be specified via
+// the --fail-on flag
+var FindingKinds = []string{"any", "oom", "timeout", "crash", "warning", "runtime_error"}
+
+// ValidateFindingKinds returns an error if any of the kinds is not one
+// of FindingKinds.
+func ValidateFindingKinds(kinds []string) error {
+	for _, kind := range kinds {
+		valid := false
+		for _, k := range FindingKinds {
+			if strings.EqualFold(kind, k) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.Errorf("invalid finding kind %q: must be one of %s", kind, strings.Join(FindingKinds, ", "))
+		}
+	}
+	return nil
+}
+
+// IsOutOfMemory returns true if the fuzz test exceeded the memory limit
+// of the fuzzer.
+func (f *Finding) IsOutOfMemory() bool {
+	return f.Category == CategoryOutOfMemory
+}
+
+// IsTimeout returns true if a single input of the fuzz test ran longer
+// than the timeout of the fuzzer.
+func (f *Finding) IsTimeout() bool {
+	return f.Category == CategoryTimeout
+}
+
+// IsKind returns true if the finding is of the specified kind, which
+// is one of FindingKinds. Out-of-memory and timeout findings are
+// crashes, but they are only matched by "oom" and "timeout"
+// respectively and "any" to allow handling them separately from memory
+// safety bugs.
+func (f *Finding) IsKind(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "any":
+		return true
+	case "oom":
+		return f.IsOutOfMemory()
+	case "timeout":
+		return f.IsTimeout()
+	case "crash":
+		return f.Type == ErrorTypeCrash && !f.IsOutOfMemory() && !f.IsTimeout()
+	}
+	return strings.EqualFold(string(f.Type), kind)
+}
@@ -0,0 +1,33 @@
+package finding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinding_IsKind(t *testing.T) {
+	oom := &Finding{Type: ErrorTypeCrash, Details: "out-of-memory (malloc(2147483648))", Category: CategoryOutOfMemory}
+	crash := &Finding{Type: ErrorTypeCrash, Details: "heap-buffer-overflow on address 0x1234"}
+	timeout := &Finding{Type: ErrorTypeCrash, Details: "timeout after 1 seconds", Category: CategoryTimeout}
+	warning := &Finding{Type: ErrorTypeWarning, Details: "Slow input detected"}
+
+	assert.True(t, oom.IsKind("oom"))
+	assert.True(t, oom.IsKind("any"))
+	assert.False(t, oom.IsKind("crash"))
+	assert.True(t, crash.IsKind("crash"))
+	assert.False(t, crash.IsKind("oom"))
+	assert.True(t, timeout.IsKind("timeout"))
+	assert.False(t, timeout.IsKind("crash"))
+	assert.False(t, crash.IsKind("timeout"))
+	assert.True(t, warning.IsKind("warning"))
+	assert.False(t, warning.IsKind("runtime_error"))
+
+	assert.Equal(t, "out-of-memory", oom.ShortDescription())
+	assert.Equal(t, "heap buffer overflow", crash.ShortDescription())
+	assert.Equal(t, "timeout", timeout.ShortDescription())
+
+	require.NoError(t, ValidateFindingKinds([]string{"oom", "RUNTIME_ERROR"}))
+	require.Error(t, ValidateFindingKinds([]string{"leak"}))
+}
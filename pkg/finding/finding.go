@@ -42,6 +42,24 @@ type Finding struct {
 	CreatedAt  time.Time                `json:"created_at,omitempty"`
 	InputFile  string                   `json:"input_file,omitempty"`
 	StackTrace []*stacktrace.StackFrame `json:"stack_trace,omitempty"`
+	// The SHA1 of the corpus entry which libFuzzer mutated to produce
+	// the crashing input, if it was reported. libFuzzer names corpus
+	// entries after their SHA1, so this identifies the file in the
+	// corpus directory.
+	ProvenanceInput string `json:"provenance_input,omitempty"`
+	// A more specific classification of the finding than its type, if
+	// it could be determined
+	Category Category `json:"category,omitempty"`
+	// The last lines of output printed by the fuzz target itself before
+	// the crash. In contrast to Logs, this doesn't contain the output
+	// of the fuzzer.
+	TargetOutput []string `json:"target_output,omitempty"`
+	// Suppressed is true if the crashing source line was annotated with
+	// a suppression comment (see SuppressionMarker). Suppressed
+	// findings are saved, but they are not uploaded and don't cause
+	// the run to fail.
+	Suppressed        bool   `json:"suppressed,omitempty"`
+	SuppressionReason string `json:"suppression_reason,omitempty"`
 
 	seedPath string
 
@@ -120,7 +138,20 @@ func (f *Finding) Exists(projectDir string) (bool, error) {
 }
 
 func (f *Finding) Save(projectDir string) error {
-	findingDir := filepath.Join(projectDir, nameFindingsDir, f.Name)
+	return f.SaveWithIndent(projectDir, JSONIndentTwoSpace)
+}
+
+// SaveWithIndent saves the finding with the specified indentation of
+// its finding.json file.
+func (f *Finding) SaveWithIndent(projectDir string, indent JSONIndent) error {
+	return f.SaveToDirWithIndent(filepath.Join(projectDir, nameFindingsDir), indent)
+}
+
+// SaveToDirWithIndent does the same as SaveWithIndent, but saves the
+// finding to the specified findings directory instead of the one in
+// the project directory.
+func (f *Finding) SaveToDirWithIndent(findingsDir string, indent JSONIndent) error {
+	findingDir := filepath.Join(findingsDir, f.Name)
 	jsonPath := filepath.Join(findingDir, nameJSONFile)
 
 	err := os.MkdirAll(findingDir, 0o755)
@@ -128,7 +159,7 @@ func (f *Finding) Save(projectDir string) error {
 		return errors.WithStack(err)
 	}
 
-	err = f.saveJSON(jsonPath)
+	err = f.saveJSON(jsonPath, indent)
 	if err != nil {
 		return err
 	}
@@ -136,10 +167,10 @@ func (f *Finding) Save(projectDir string) error {
 	return nil
 }
 
-func (f *Finding) saveJSON(jsonPath string) error {
-	bytes, err := json.MarshalIndent(f, "", "  ")
+func (f *Finding) saveJSON(jsonPath string, indent JSONIndent) error {
+	bytes, err := indent.marshal(f)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 
 	if err := os.WriteFile(jsonPath, bytes, 0o644); err != nil {
@@ -150,7 +181,14 @@ func (f *Finding) saveJSON(jsonPath string) error {
 }
 
 func (f *Finding) Remove(projectDir string) error {
-	findingDir := filepath.Join(projectDir, nameFindingsDir, f.Name)
+	return f.RemoveFromDir(filepath.Join(projectDir, nameFindingsDir))
+}
+
+// RemoveFromDir does the same as Remove, but removes the finding from
+// the specified findings directory instead of the one in the project
+// directory.
+func (f *Finding) RemoveFromDir(findingsDir string) error {
+	findingDir := filepath.Join(findingsDir, f.Name)
 	err := os.RemoveAll(findingDir)
 	if err != nil {
 		return errors.WithStack(err)
@@ -244,6 +282,21 @@ func (f *Finding) copyInputFile(projectDir, seedCorpusDir string) error {
 	return nil
 }
 
+// StripPaths turns the absolute paths below the prefix directory in
+// the logs, the stack trace and the input file of the finding into paths
+// relative to the prefix directory, so that the finding doesn't leak
+// the local directory layout when it's shared.
+func (f *Finding) StripPaths(prefix string) {
+	for i, line := range f.Logs {
+		f.Logs[i] = fileutil.StripPathPrefix(line, prefix)
+	}
+	for _, frame := range f.StackTrace {
+		frame.SourceFile = fileutil.StripPathPrefix(frame.SourceFile, prefix)
+	}
+	f.InputFile = fileutil.StripPathPrefix(f.InputFile, prefix)
+	f.Details = fileutil.StripPathPrefix(f.Details, prefix)
+}
+
 func (f *Finding) SourceLocation() string {
 	if f.StackTrace != nil && len(f.StackTrace) > 0 {
 		stackFrame := f.StackTrace[0]
@@ -274,6 +327,10 @@ func (f *Finding) ShortDescriptionColumns() []string {
 	switch f.Type {
 	case ErrorTypeCrash:
 		switch {
+		case f.IsOutOfMemory():
+			errorType = string(CategoryOutOfMemory)
+		case f.IsTimeout():
+			errorType = string(CategoryTimeout)
 		case f.Details == "detected memory leaks":
 			// Special vulnerabilities
 			errorType = f.Details
@@ -307,10 +364,22 @@ func (f *Finding) ShortDescriptionColumns() []string {
 	return columns
 }
 
+// FindingsDir returns the directory in which the findings of the
+// project are stored.
+func FindingsDir(projectDir string) string {
+	return filepath.Join(projectDir, nameFindingsDir)
+}
+
 // LocalFindings parses the JSON files of all findings and returns the
 // result.
 func LocalFindings(projectDir string, errorDetails []*ErrorDetails) ([]*Finding, error) {
-	findingsDir := filepath.Join(projectDir, nameFindingsDir)
+	return LocalFindingsFromDir(filepath.Join(projectDir, nameFindingsDir), errorDetails)
+}
+
+// LocalFindingsFromDir does the same as LocalFindings, but reads the
+// findings from the specified findings directory instead of the one in
+// the project directory.
+func LocalFindingsFromDir(findingsDir string, errorDetails []*ErrorDetails) ([]*Finding, error) {
 	entries, err := os.ReadDir(findingsDir)
 	if os.IsNotExist(err) {
 		return []*Finding{}, nil
@@ -321,7 +390,7 @@ func LocalFindings(projectDir string, errorDetails []*ErrorDetails) ([]*Finding,
 
 	var res []*Finding
 	for _, e := range entries {
-		f, err := LoadFinding(projectDir, e.Name(), errorDetails)
+		f, err := LoadFindingFromDir(findingsDir, e.Name(), errorDetails)
 		if err != nil {
 			return nil, err
 		}
@@ -341,7 +410,14 @@ func LocalFindings(projectDir string, errorDetails []*ErrorDetails) ([]*Finding,
 // If the specified finding does not exist, a NotExistError is returned.
 // If the user is logged in, the error details are added to the finding.
 func LoadFinding(projectDir, findingName string, errorDetails []*ErrorDetails) (*Finding, error) {
-	findingDir := filepath.Join(projectDir, nameFindingsDir, findingName)
+	return LoadFindingFromDir(filepath.Join(projectDir, nameFindingsDir), findingName, errorDetails)
+}
+
+// LoadFindingFromDir does the same as LoadFinding, but reads the
+// finding from the specified findings directory instead of the one in
+// the project directory.
+func LoadFindingFromDir(findingsDir, findingName string, errorDetails []*ErrorDetails) (*Finding, error) {
+	findingDir := filepath.Join(findingsDir, findingName)
 	jsonPath := filepath.Join(findingDir, nameJSONFile)
 	bytes, err := os.ReadFile(jsonPath)
 	if os.IsNotExist(err) {
@@ -362,6 +438,46 @@ func LoadFinding(projectDir, findingName string, errorDetails []*ErrorDetails) (
 	return &f, nil
 }
 
+// ValidateFindingsDir checks that the specified directory has the
+// layout of a findings directory, i.e. that it only contains
+// directories with a finding.json file. If the directory contains a
+// .cifuzz-findings directory (e.g. because it's a project directory),
+// the path of that directory is returned.
+func ValidateFindingsDir(dir string) (string, error) {
+	fileInfo, err := os.Stat(dir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if !fileInfo.IsDir() {
+		return "", errors.Errorf("%s is not a directory", dir)
+	}
+
+	exists, err := fileutil.Exists(filepath.Join(dir, nameFindingsDir))
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		dir = filepath.Join(dir, nameFindingsDir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, e := range entries {
+		exists, err := fileutil.Exists(filepath.Join(dir, e.Name(), nameJSONFile))
+		if err != nil {
+			return "", err
+		}
+		if !e.IsDir() || !exists {
+			return "", errors.Errorf("%s is not a findings directory: %s doesn't contain a %s file",
+				dir, e.Name(), nameJSONFile)
+		}
+	}
+
+	return dir, nil
+}
+
 // EnhanceWithErrorDetails adds more details to the finding by parsing the
 // error details file.
 func (f *Finding) EnhanceWithErrorDetails(errorDetails []*ErrorDetails) {
@@ -44,6 +44,32 @@ func TestFinding_Save_LoadFinding(t *testing.T) {
 	require.Equal(t, expectedJSON, actualJSON)
 }
 
+func TestFinding_SaveWithIndent(t *testing.T) {
+	testDir := testutil.ChdirToTempDir(t, "finding-test-")
+	finding := testFinding()
+	jsonPath := filepath.Join(testDir, nameFindingsDir, finding.Name, nameJSONFile)
+
+	err := finding.SaveWithIndent(testDir, JSONIndentCompact)
+	require.NoError(t, err)
+	bytes, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bytes), "\n")
+
+	err = finding.SaveWithIndent(testDir, JSONIndentTab)
+	require.NoError(t, err)
+	bytes, err = os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(bytes), "\n\t\"name\": ")
+
+	// The finding can be loaded regardless of the indentation
+	loadedFinding, err := LoadFinding(testDir, finding.Name, nil)
+	require.NoError(t, err)
+	assert.Equal(t, finding.Name, loadedFinding.Name)
+
+	_, err = ParseJSONIndent("four-space")
+	require.Error(t, err)
+}
+
 func TestFinding_MoveInputFile(t *testing.T) {
 	var err error
 	testBaseDir := testutil.ChdirToTempDir(t, "finding-test-")
@@ -91,6 +117,62 @@ func TestGetLocalFindings(t *testing.T) {
 	require.Equal(t, finding, findings[0])
 }
 
+func TestValidateFindingsDir(t *testing.T) {
+	projectDir := t.TempDir()
+	finding := testFinding()
+	err := finding.Save(projectDir)
+	require.NoError(t, err)
+	findingsDir := filepath.Join(projectDir, nameFindingsDir)
+
+	// The findings directory itself and its parent directory are valid
+	dir, err := ValidateFindingsDir(findingsDir)
+	require.NoError(t, err)
+	assert.Equal(t, findingsDir, dir)
+	dir, err = ValidateFindingsDir(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, findingsDir, dir)
+
+	findings, err := LocalFindingsFromDir(dir, nil)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, finding, findings[0])
+
+	// A directory containing other files is not a findings directory
+	err = os.WriteFile(filepath.Join(findingsDir, "foo"), nil, 0o644)
+	require.NoError(t, err)
+	_, err = ValidateFindingsDir(findingsDir)
+	require.Error(t, err)
+
+	_, err = ValidateFindingsDir(filepath.Join(projectDir, "does-not-exist"))
+	require.Error(t, err)
+}
+
+// fileWriter records the files written to the archive
+type fileWriter map[string]string
+
+func (w fileWriter) WriteFile(archivePath string, sourcePath string) error {
+	w[archivePath] = sourcePath
+	return nil
+}
+
+func TestExport(t *testing.T) {
+	projectDir := testutil.MkdirTemp(t, "", "finding-test-")
+
+	finding := testFinding()
+	err := finding.Save(projectDir)
+	require.NoError(t, err)
+	findingDir := filepath.Join(projectDir, nameFindingsDir, finding.Name)
+	err = os.WriteFile(filepath.Join(findingDir, nameCrashingInput), []byte("input"), 0o644)
+	require.NoError(t, err)
+
+	w := fileWriter{}
+	count, err := Export(projectDir, w)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, filepath.Join(findingDir, nameCrashingInput), w[filepath.Join(nameFindingsDir, finding.Name, nameCrashingInput)])
+	require.Contains(t, w, filepath.Join(nameFindingsDir, finding.Name, nameJSONFile))
+}
+
 func testFinding() *Finding {
 	return &Finding{
 		Origin: "Local",
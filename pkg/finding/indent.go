@@ -0,0 +1,52 @@
+package finding
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// JSONIndent is the indentation of the saved finding.json files
+type JSONIndent string
+
+const (
+	// JSONIndentTwoSpace indents nested fields by two spaces
+	JSONIndentTwoSpace JSONIndent = "two-space"
+	// JSONIndentTab indents nested fields by a tab
+	JSONIndentTab JSONIndent = "tab"
+	// JSONIndentCompact writes the finding on a single line, which
+	// minimizes the size of the file
+	JSONIndentCompact JSONIndent = "compact"
+)
+
+var JSONIndents = []JSONIndent{JSONIndentTwoSpace, JSONIndentTab, JSONIndentCompact}
+
+// ParseJSONIndent parses the indentation configured by the user. An
+// empty string results in the default indentation, which is
+// JSONIndentTwoSpace.
+func ParseJSONIndent(s string) (JSONIndent, error) {
+	if s == "" {
+		return JSONIndentTwoSpace, nil
+	}
+	for _, indent := range JSONIndents {
+		if JSONIndent(s) == indent {
+			return indent, nil
+		}
+	}
+	return "", errors.Errorf("invalid finding JSON indentation %q: must be one of %s, %s or %s",
+		s, JSONIndentTwoSpace, JSONIndentTab, JSONIndentCompact)
+}
+
+func (i JSONIndent) marshal(v any) ([]byte, error) {
+	var bytes []byte
+	var err error
+	switch i {
+	case JSONIndentCompact:
+		bytes, err = json.Marshal(v)
+	case JSONIndentTab:
+		bytes, err = json.MarshalIndent(v, "", "\t")
+	default:
+		bytes, err = json.MarshalIndent(v, "", "  ")
+	}
+	return bytes, errors.WithStack(err)
+}
@@ -0,0 +1,108 @@
+package finding
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The name of the test suite of findings which don't specify the fuzz
+// test which produced them
+const junitUnknownFuzzTest = "unknown"
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitReport returns a JUnit XML report with a failed test case for
+// each of the findings, grouped into a test suite per fuzz test. This
+// allows CI systems which display JUnit reports to show the findings
+// as failed tests.
+func JUnitReport(findings []*Finding) ([]byte, error) {
+	var suiteNames []string
+	suites := make(map[string]*junitTestSuite)
+	for _, f := range findings {
+		suiteName := f.FuzzTest
+		if suiteName == "" {
+			suiteName = junitUnknownFuzzTest
+		}
+		suite, ok := suites[suiteName]
+		if !ok {
+			suite = &junitTestSuite{Name: suiteName}
+			suites[suiteName] = suite
+			suiteNames = append(suiteNames, suiteName)
+		}
+
+		suite.Tests++
+		suite.Failures++
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      f.Name,
+			ClassName: suiteName,
+			Failure: &junitFailure{
+				Message: f.ShortDescription(),
+				Type:    string(f.Type),
+				Text:    junitFailureText(f),
+			},
+		})
+	}
+
+	// Sort the test suites, so that the report is deterministic. The
+	// test cases keep the order of the findings.
+	sort.Strings(suiteNames)
+	report := junitTestSuites{TestSuites: []junitTestSuite{}}
+	for _, name := range suiteNames {
+		report.TestSuites = append(report.TestSuites, *suites[name])
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func junitFailureText(f *Finding) string {
+	text := fmt.Sprintf("%s\nLocation: %s\n", f.Details, f.SourceLocation())
+	if len(f.Logs) > 0 {
+		text += "\n" + strings.Join(f.Logs, "\n") + "\n"
+	}
+	return text
+}
+
+// WriteJUnitReport writes the report returned by JUnitReport to the
+// specified path.
+func WriteJUnitReport(path string, findings []*Finding) error {
+	report, err := JUnitReport(findings)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, report, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package finding
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+)
+
+func TestJUnitReport(t *testing.T) {
+	findings := []*Finding{
+		{
+			Name:     "second_finding",
+			Type:     ErrorTypeCrash,
+			Details:  "heap-buffer-overflow on address 0x1",
+			Logs:     []string{"==1==ERROR: AddressSanitizer: heap-buffer-overflow", "READ of size 4"},
+			FuzzTest: "parser_fuzz_test",
+			StackTrace: []*stacktrace.StackFrame{
+				{Function: "parse", SourceFile: "src/parser.cpp", Line: 12, Column: 3},
+			},
+		},
+		{
+			Name:     "first_finding",
+			Type:     ErrorTypeRuntimeError,
+			Details:  "undefined behavior: <overflow>",
+			FuzzTest: "lexer_fuzz_test",
+		},
+		{
+			Name:     "third_finding",
+			Type:     ErrorTypeCrash,
+			Details:  "timeout",
+			FuzzTest: "parser_fuzz_test",
+		},
+	}
+
+	report, err := JUnitReport(findings)
+	require.NoError(t, err)
+	s := string(report)
+	assert.Contains(t, s, `<testsuite name="lexer_fuzz_test" tests="1" failures="1">`)
+	assert.Contains(t, s, `<testsuite name="parser_fuzz_test" tests="2" failures="2">`)
+	assert.Contains(t, s, `<testcase name="second_finding" classname="parser_fuzz_test">`)
+	// Line breaks in the failure text are escaped by encoding/xml
+	assert.Contains(t, s, `<failure message="heap buffer overflow in parse (src/parser.cpp:12:3)" type="CRASH">`+
+		`heap-buffer-overflow on address 0x1&#xA;Location: src/parser.cpp:12:3&#xA;&#xA;`+
+		`==1==ERROR: AddressSanitizer: heap-buffer-overflow&#xA;READ of size 4&#xA;</failure>`)
+	assert.Contains(t, s, `undefined behavior: &lt;overflow&gt;&#xA;Location: n/a&#xA;</failure>`)
+	// The test suites are sorted by name, the test cases keep their order
+	assert.Less(t, strings.Index(s, "lexer_fuzz_test"), strings.Index(s, "parser_fuzz_test"))
+	assert.Less(t, strings.Index(s, "second_finding"), strings.Index(s, "third_finding"))
+
+	path := filepath.Join(t.TempDir(), "findings.xml")
+	err = WriteJUnitReport(path, findings)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, report, content)
+}
@@ -0,0 +1,115 @@
+package finding
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The scores which are used for severity overrides which only specify
+// a level. These are the lower bounds of the CVSS v3 ratings.
+var severityLevelScores = map[SeverityLevel]float32{
+	SeverityLevelCritical: 9.0,
+	SeverityLevelHigh:     7.0,
+	SeverityLevelMedium:   4.0,
+	SeverityLevelLow:      0.1,
+}
+
+// ParseSeverity parses a severity which is either specified as a level
+// (critical, high, medium or low) or as a score between 0 and 10.
+func ParseSeverity(s string) (*Severity, error) {
+	level := SeverityLevel(strings.ToUpper(strings.TrimSpace(s)))
+	if score, ok := severityLevelScores[level]; ok {
+		return &Severity{Level: level, Score: score}, nil
+	}
+
+	score, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	if err != nil || score < 0 || score > 10 {
+		return nil, errors.Errorf("invalid severity %q: must be one of critical, high, medium, low or a score between 0 and 10", s)
+	}
+	return &Severity{Level: severityLevelForScore(float32(score)), Score: float32(score)}, nil
+}
+
+// ParseSeverityLevel parses a severity level (critical, high, medium or
+// low) case-insensitively.
+func ParseSeverityLevel(s string) (SeverityLevel, error) {
+	level := SeverityLevel(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := severityLevelScores[level]; !ok {
+		return "", errors.Errorf("invalid severity level %q: must be one of critical, high, medium or low", s)
+	}
+	return level, nil
+}
+
+// AtLeast returns true if the severity is of the specified level or a
+// higher one. Severities without a known level are compared by their
+// score.
+func (s *Severity) AtLeast(level SeverityLevel) bool {
+	// The level of findings from CI Sense is not upper case
+	ownLevel := SeverityLevel(strings.ToUpper(string(s.Level)))
+	if _, ok := severityLevelScores[ownLevel]; !ok {
+		ownLevel = severityLevelForScore(s.Score)
+	}
+	return severityLevelScores[ownLevel] >= severityLevelScores[level]
+}
+
+func severityLevelForScore(score float32) SeverityLevel {
+	switch {
+	case score >= severityLevelScores[SeverityLevelCritical]:
+		return SeverityLevelCritical
+	case score >= severityLevelScores[SeverityLevelHigh]:
+		return SeverityLevelHigh
+	case score >= severityLevelScores[SeverityLevelMedium]:
+		return SeverityLevelMedium
+	default:
+		return SeverityLevelLow
+	}
+}
+
+// ParseSeverityOverrides parses the severity overrides configured by
+// the user, which map an error details ID or a finding type to a
+// severity.
+func ParseSeverityOverrides(overrides map[string]string) (map[string]*Severity, error) {
+	res := make(map[string]*Severity, len(overrides))
+	for key, value := range overrides {
+		severity, err := ParseSeverity(value)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid severity override for %q", key)
+		}
+		// Viper lowercases all keys, so we match them case-insensitively
+		res[strings.ToLower(key)] = severity
+	}
+	return res, nil
+}
+
+// ApplySeverityOverrides sets the severity of the finding to the one
+// configured by the user for its error details ID or, if there is none,
+// for its finding type. User overrides take precedence over the
+// severity from the error details, so this must be called after
+// EnhanceWithErrorDetails. Findings without a matching override keep
+// their severity.
+func (f *Finding) ApplySeverityOverrides(overrides map[string]*Severity) {
+	if len(overrides) == 0 {
+		return
+	}
+
+	var severity *Severity
+	if f.MoreDetails != nil && f.MoreDetails.ID != "" {
+		severity = overrides[strings.ToLower(f.MoreDetails.ID)]
+	}
+	if severity == nil {
+		severity = overrides[strings.ToLower(string(f.Type))]
+	}
+	if severity == nil {
+		return
+	}
+
+	// The error details are shared between findings, so we copy them
+	// instead of modifying them
+	var details ErrorDetails
+	if f.MoreDetails != nil {
+		details = *f.MoreDetails
+	}
+	details.Severity = severity
+	f.MoreDetails = &details
+}
@@ -0,0 +1,71 @@
+package finding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity("critical")
+	require.NoError(t, err)
+	assert.Equal(t, &Severity{Level: SeverityLevelCritical, Score: 9.0}, severity)
+
+	severity, err = ParseSeverity("7.5")
+	require.NoError(t, err)
+	assert.Equal(t, &Severity{Level: SeverityLevelHigh, Score: 7.5}, severity)
+
+	_, err = ParseSeverity("urgent")
+	require.Error(t, err)
+	_, err = ParseSeverity("11")
+	require.Error(t, err)
+}
+
+func TestSeverity_AtLeast(t *testing.T) {
+	level, err := ParseSeverityLevel("High")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityLevelHigh, level)
+	_, err = ParseSeverityLevel("7.5")
+	require.Error(t, err)
+
+	assert.True(t, (&Severity{Level: SeverityLevelCritical, Score: 9.5}).AtLeast(SeverityLevelHigh))
+	assert.True(t, (&Severity{Level: "High", Score: 7.0}).AtLeast(SeverityLevelHigh))
+	assert.False(t, (&Severity{Level: "Medium", Score: 5.0}).AtLeast(SeverityLevelHigh))
+	// Severities without a level are compared by their score
+	assert.True(t, (&Severity{Score: 8.0}).AtLeast(SeverityLevelHigh))
+	assert.False(t, (&Severity{Score: 2.0}).AtLeast(SeverityLevelMedium))
+}
+
+func TestApplySeverityOverrides(t *testing.T) {
+	overrides, err := ParseSeverityOverrides(map[string]string{
+		"undefined_behavior": "critical",
+		"warning":            "low",
+	})
+	require.NoError(t, err)
+
+	catalogDetails := &ErrorDetails{
+		ID:       "undefined_behavior",
+		Name:     "Undefined Behavior",
+		Severity: &Severity{Level: SeverityLevelMedium, Score: 5.0},
+	}
+
+	// The override for the error details ID wins over the catalog
+	f := &Finding{Type: ErrorTypeRuntimeError, MoreDetails: catalogDetails}
+	f.ApplySeverityOverrides(overrides)
+	assert.Equal(t, SeverityLevelCritical, f.MoreDetails.Severity.Level)
+	assert.Equal(t, "Undefined Behavior", f.MoreDetails.Name)
+	// The shared error details are not modified
+	assert.Equal(t, SeverityLevelMedium, catalogDetails.Severity.Level)
+
+	// Findings without error details are matched by type
+	f = &Finding{Type: ErrorTypeWarning}
+	f.ApplySeverityOverrides(overrides)
+	require.NotNil(t, f.MoreDetails)
+	assert.Equal(t, SeverityLevelLow, f.MoreDetails.Severity.Level)
+
+	// Findings without a matching override keep their severity
+	f = &Finding{Type: ErrorTypeCrash, MoreDetails: &ErrorDetails{ID: "heap_buffer_overflow", Severity: &Severity{Level: SeverityLevelHigh, Score: 8.0}}}
+	f.ApplySeverityOverrides(overrides)
+	assert.Equal(t, SeverityLevelHigh, f.MoreDetails.Severity.Level)
+}
@@ -0,0 +1,109 @@
+package finding
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SuppressionMarker is the marker of a comment which suppresses
+// findings whose top stack frame points at the line of the comment or
+// the line below it, for example:
+//
+//	// cifuzz:ignore the parser is expected to abort on invalid input
+//	abort(); /* cifuzz:ignore the parser is expected to abort */
+const SuppressionMarker = "cifuzz:ignore"
+
+// CheckSuppression marks the finding as suppressed if the source line
+// of its top stack frame, or the line above it, contains a suppression
+// comment. Relative source file paths are resolved against the project
+// directory. Findings without a stack trace or whose source file can't
+// be found are never suppressed.
+func (f *Finding) CheckSuppression(projectDir string) error {
+	if len(f.StackTrace) == 0 || f.StackTrace[0].SourceFile == "" || f.StackTrace[0].Line == 0 {
+		return nil
+	}
+	frame := f.StackTrace[0]
+
+	path := frame.SourceFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var lineNumber uint32
+	for scanner.Scan() {
+		lineNumber++
+		if lineNumber < frame.Line-1 {
+			continue
+		}
+		if lineNumber > frame.Line {
+			break
+		}
+		_, reason, found := strings.Cut(scanner.Text(), SuppressionMarker)
+		if found {
+			f.Suppressed = true
+			// The reason of a block comment ends at the end of the comment
+			reason, _, _ = strings.Cut(reason, "*/")
+			f.SuppressionReason = strings.TrimSpace(reason)
+			return nil
+		}
+	}
+	return errors.WithStack(scanner.Err())
+}
+
+// AddSuppression appends a suppression comment with the given reason
+// to the source line of the top stack frame of the finding and marks
+// the finding as suppressed. A block comment is used, so that the
+// comment is valid in all supported languages and no lines are shifted.
+// Relative source file paths are resolved against the project
+// directory.
+func (f *Finding) AddSuppression(projectDir, reason string) error {
+	if len(f.StackTrace) == 0 || f.StackTrace[0].SourceFile == "" || f.StackTrace[0].Line == 0 {
+		return errors.Errorf("Finding %s doesn't have a source location", f.Name)
+	}
+	frame := f.StackTrace[0]
+
+	path := frame.SourceFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if int(frame.Line) > len(lines) {
+		return errors.Errorf("Source file %s doesn't have line %d", frame.SourceFile, frame.Line)
+	}
+	// The reason must not terminate the block comment early
+	reason = strings.TrimSpace(strings.ReplaceAll(reason, "*/", "* /"))
+	comment := " /* " + strings.TrimSpace(SuppressionMarker+" "+reason) + " */"
+	line := lines[frame.Line-1]
+	code := strings.TrimRight(line, "\r\n")
+	lines[frame.Line-1] = code + comment + line[len(code):]
+
+	err = os.WriteFile(path, []byte(strings.Join(lines, "")), info.Mode())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	f.Suppressed = true
+	f.SuppressionReason = reason
+	return nil
+}
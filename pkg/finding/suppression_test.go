@@ -0,0 +1,86 @@
+package finding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code-intelligence.com/cifuzz/pkg/parser/libfuzzer/stacktrace"
+)
+
+func TestFinding_CheckSuppression(t *testing.T) {
+	projectDir := t.TempDir()
+	source := `void parse(const char *data) {
+  // cifuzz:ignore aborting on invalid input is expected
+  abort();
+  free(data);
+  crash(); // cifuzz:ignore
+  free(data);
+  exit(1); /* cifuzz:ignore exiting is expected */ return;
+}
+`
+	err := os.WriteFile(filepath.Join(projectDir, "parser.c"), []byte(source), 0o644)
+	require.NoError(t, err)
+
+	tests := []struct {
+		sourceFile string
+		line       uint32
+		suppressed bool
+		reason     string
+	}{
+		// The comment is on the line above the crashing line
+		{"parser.c", 3, true, "aborting on invalid input is expected"},
+		// The comment is on the crashing line itself
+		{filepath.Join(projectDir, "parser.c"), 5, true, ""},
+		// The reason of a block comment ends at the end of the comment
+		{"parser.c", 7, true, "exiting is expected"},
+		// The comment is two lines above the crashing line
+		{"parser.c", 4, false, ""},
+		{"does-not-exist.c", 3, false, ""},
+	}
+	for _, tt := range tests {
+		f := &Finding{StackTrace: []*stacktrace.StackFrame{{SourceFile: tt.sourceFile, Line: tt.line}}}
+		err = f.CheckSuppression(projectDir)
+		require.NoError(t, err)
+		assert.Equal(t, tt.suppressed, f.Suppressed, "%s:%d", tt.sourceFile, tt.line)
+		assert.Equal(t, tt.reason, f.SuppressionReason, "%s:%d", tt.sourceFile, tt.line)
+	}
+
+	// Findings without a stack trace are never suppressed
+	f := &Finding{}
+	err = f.CheckSuppression(projectDir)
+	require.NoError(t, err)
+	assert.False(t, f.Suppressed)
+}
+
+func TestFinding_AddSuppression(t *testing.T) {
+	projectDir := t.TempDir()
+	source := "void parse(const char *data) {\r\n  abort();\r\n}\r\n"
+	err := os.WriteFile(filepath.Join(projectDir, "parser.c"), []byte(source), 0o644)
+	require.NoError(t, err)
+
+	f := &Finding{StackTrace: []*stacktrace.StackFrame{{SourceFile: "parser.c", Line: 2}}}
+	err = f.AddSuppression(projectDir, "false positive")
+	require.NoError(t, err)
+	assert.True(t, f.Suppressed)
+	// No lines are inserted, so the line of the finding doesn't change
+	assert.Equal(t, uint32(2), f.StackTrace[0].Line)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, "parser.c"))
+	require.NoError(t, err)
+	assert.Equal(t, "void parse(const char *data) {\r\n  abort(); /* cifuzz:ignore false positive */\r\n}\r\n", string(content))
+
+	// The inserted comment is recognized as a suppression
+	f = &Finding{StackTrace: []*stacktrace.StackFrame{{SourceFile: "parser.c", Line: 2}}}
+	err = f.CheckSuppression(projectDir)
+	require.NoError(t, err)
+	assert.True(t, f.Suppressed)
+	assert.Equal(t, "false positive", f.SuppressionReason)
+
+	// Findings without a source location can't be suppressed
+	err = (&Finding{}).AddSuppression(projectDir, "false positive")
+	require.Error(t, err)
+}
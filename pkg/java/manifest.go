@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -71,9 +72,19 @@ func writeManifest(entries map[string]string, target io.Writer) error {
 	return nil
 }
 
+// entriesToString formats the entries as manifest headers. The headers
+// are sorted by name, so that the manifest doesn't depend on the random
+// iteration order of the map.
 func entriesToString(entries map[string]string) (string, error) {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
 	var content strings.Builder
-	for k, v := range entries {
+	for _, k := range keys {
+		v := entries[k]
 		// headers are not allowed to be wrapped, so without the ": "
 		// separator a header is not allowed to be longer than 70 bytes
 		if len(k) > 70 {
@@ -72,6 +72,21 @@ func TestEntriesToString_EOF(t *testing.T) {
 	assert.Equal(t, "\n", result[len(result)-1:])
 }
 
+func TestEntriesToString_Sorted(t *testing.T) {
+	entries := map[string]string{
+		"Jazzer-Target-Method": "fuzz",
+		"Fuzz-Target-Class":    "com.example.FuzzTest",
+		"Jazzer-Fuzz-Target":   "com.example.FuzzTest",
+	}
+	for i := 0; i < 10; i++ {
+		result, err := entriesToString(entries)
+		require.NoError(t, err)
+		assert.Equal(t, "Fuzz-Target-Class: com.example.FuzzTest\n"+
+			"Jazzer-Fuzz-Target: com.example.FuzzTest\n"+
+			"Jazzer-Target-Method: fuzz\n", result)
+	}
+}
+
 func TestEntriesToString_Empty(t *testing.T) {
 	entries := map[string]string{}
 	result, err := entriesToString(entries)
@@ -72,3 +72,15 @@ func UpdateCurrentSpinnerPrinter(msg string) {
 		activeSpinnerPrinter.UpdateText(msg)
 	}
 }
+
+func SpinnerPrinterActive() bool {
+	return activeSpinnerPrinter != nil
+}
+
+// SpinnerPrinterText returns the text of the active spinner, if any
+func SpinnerPrinterText() string {
+	if activeSpinnerPrinter == nil {
+		return ""
+	}
+	return activeSpinnerPrinter.Text
+}
@@ -1,6 +1,10 @@
 package options
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"strings"
+)
 
 const (
 	JazzerMainClass string = "com.code_intelligence.jazzer.Jazzer"
@@ -20,6 +24,12 @@ const (
 	JazzerTargetMethodManifest      string = "Jazzer-Target-Method"
 )
 
+// JavaLibraryPathFlag returns the JVM argument which sets the
+// directories in which the JVM searches for native libraries.
+func JavaLibraryPathFlag(paths []string) string {
+	return "-Djava.library.path=" + strings.Join(paths, string(os.PathListSeparator))
+}
+
 func JazzerTargetClassFlag(value string) string {
 	return JazzerTargetClass + "=" + value
 }
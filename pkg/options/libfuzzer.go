@@ -1,9 +1,23 @@
 package options
 
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 const (
-	LibFuzzerMaxTotalTime   string = "-max_total_time"
-	LibFuzzerDictionary     string = "-dict"
-	LibFuzzerArtifactPrefix string = "-artifact_prefix"
+	LibFuzzerMaxTotalTime    string = "-max_total_time"
+	LibFuzzerDictionary      string = "-dict"
+	LibFuzzerArtifactPrefix  string = "-artifact_prefix"
+	LibFuzzerMaxLen          string = "-max_len"
+	LibFuzzerRuns            string = "-runs"
+	LibFuzzerMerge           string = "-merge"
+	LibFuzzerTimeout         string = "-timeout"
+	LibFuzzerVerbosity       string = "-verbosity"
+	LibFuzzerErrorExitCode   string = "-error_exitcode"
+	LibFuzzerTimeoutExitCode string = "-timeout_exitcode"
 )
 
 func LibFuzzerMaxTotalTimeFlag(value string) string {
@@ -17,3 +31,53 @@ func LibFuzzerDictionaryFlag(value string) string {
 func LibFuzzerArtifactPrefixFlag(value string) string {
 	return LibFuzzerArtifactPrefix + "=" + value
 }
+
+func LibFuzzerRunsFlag(value string) string {
+	return LibFuzzerRuns + "=" + value
+}
+
+func LibFuzzerMergeFlag(value string) string {
+	return LibFuzzerMerge + "=" + value
+}
+
+func LibFuzzerTimeoutFlag(value string) string {
+	return LibFuzzerTimeout + "=" + value
+}
+
+func LibFuzzerVerbosityFlag(value string) string {
+	return LibFuzzerVerbosity + "=" + value
+}
+
+func LibFuzzerTimeoutExitCodeFlag(value string) string {
+	return LibFuzzerTimeoutExitCode + "=" + value
+}
+
+// ParseLibFuzzerMaxLen returns the value of the last -max_len flag in the
+// libFuzzer arguments, or 0 if the flag is not set (which is also
+// libFuzzer's default).
+func ParseLibFuzzerMaxLen(args []string) (int, error) {
+	return parseLastNonNegativeIntFlag(args, LibFuzzerMaxLen, 0)
+}
+
+// ParseLibFuzzerExitCode returns the value of the last exit code flag
+// with the specified name (e.g. -timeout_exitcode) in the libFuzzer
+// arguments, or defaultValue if the flag is not set.
+func ParseLibFuzzerExitCode(args []string, flag string, defaultValue int) (int, error) {
+	return parseLastNonNegativeIntFlag(args, flag, defaultValue)
+}
+
+func parseLastNonNegativeIntFlag(args []string, flag string, defaultValue int) (int, error) {
+	result := defaultValue
+	for _, arg := range args {
+		value, found := strings.CutPrefix(arg, flag+"=")
+		if !found {
+			continue
+		}
+		var err error
+		result, err = strconv.Atoi(value)
+		if err != nil || result < 0 {
+			return 0, errors.Errorf("invalid value %q for libFuzzer flag %s", value, flag)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package coverage
+
+import (
+	"fmt"
+	"html"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// The badge colors of shields.io for the coverage thresholds, starting
+// with the highest threshold
+var badgeColors = []struct {
+	threshold float64
+	color     string
+}{
+	{90, "#4c1"},    // brightgreen
+	{75, "#97ca00"}, // green
+	{60, "#a4a61d"}, // yellowgreen
+	{40, "#dfb317"}, // yellow
+	{20, "#fe7d37"}, // orange
+	{0, "#e05d44"},  // red
+}
+
+const badgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">
+  <title>%[3]s: %[4]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="%[1]d" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[2]d" height="20" fill="#555"/>
+    <rect x="%[2]d" width="%[5]d" height="20" fill="%[6]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text>
+    <text x="%[7]d" y="14">%[3]s</text>
+    <text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text>
+    <text x="%[8]d" y="14">%[4]s</text>
+  </g>
+</svg>
+`
+
+// LineCoverage returns the percentage of the lines which were hit
+func (cs *Summary) LineCoverage() float64 {
+	return cs.Total.LineCoverage()
+}
+
+// Badge returns an SVG badge in the style of shields.io which shows the
+// line coverage, colored by coverage thresholds.
+func (cs *Summary) Badge(label string) string {
+	percent := cs.LineCoverage()
+	value := fmt.Sprintf("%.1f%%", percent)
+
+	var color string
+	for _, c := range badgeColors {
+		if percent >= c.threshold {
+			color = c.color
+			break
+		}
+	}
+
+	// Approximate the text width, which would require the font metrics
+	// to be computed exactly
+	labelWidth := textWidth(label)
+	valueWidth := textWidth(value)
+	return fmt.Sprintf(badgeTemplate,
+		labelWidth+valueWidth,
+		labelWidth,
+		html.EscapeString(label),
+		html.EscapeString(value),
+		valueWidth,
+		color,
+		labelWidth/2,
+		labelWidth+valueWidth/2,
+	)
+}
+
+// WriteBadge writes the SVG badge returned by Badge to the specified
+// path.
+func (cs *Summary) WriteBadge(path, label string) error {
+	err := os.WriteFile(path, []byte(cs.Badge(label)), 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func textWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
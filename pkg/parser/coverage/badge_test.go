@@ -0,0 +1,31 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary_Badge(t *testing.T) {
+	testCases := map[string]struct {
+		linesHit   int
+		linesFound int
+		percent    string
+		color      string
+	}{
+		"full":    {linesHit: 10, linesFound: 10, percent: "100.0%", color: "#4c1"},
+		"medium":  {linesHit: 2, linesFound: 3, percent: "66.7%", color: "#a4a61d"},
+		"low":     {linesHit: 1, linesFound: 10, percent: "10.0%", color: "#e05d44"},
+		"nothing": {linesHit: 0, linesFound: 0, percent: "100.0%", color: "#4c1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			summary := &Summary{Total: Overview{LinesHit: tc.linesHit, LinesFound: tc.linesFound}}
+			badge := summary.Badge("fuzz <coverage>")
+			assert.Contains(t, badge, ">"+tc.percent+"<")
+			assert.Contains(t, badge, `fill="`+tc.color+`"`)
+			assert.Contains(t, badge, "fuzz &lt;coverage&gt;")
+		})
+	}
+}
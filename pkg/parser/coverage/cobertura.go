@@ -0,0 +1,219 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        string             `xml:"line-rate,attr"`
+	BranchRate      string             `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      int                `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Complexity int              `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Complexity int             `xml:"complexity,attr"`
+	Methods    struct{}        `xml:"methods"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int    `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// coberturaCounts are the line and branch counts of a class, package
+// or the whole report
+type coberturaCounts struct {
+	linesCovered, linesValid       int
+	branchesCovered, branchesValid int
+}
+
+func (c *coberturaCounts) add(o coberturaCounts) {
+	c.linesCovered += o.linesCovered
+	c.linesValid += o.linesValid
+	c.branchesCovered += o.branchesCovered
+	c.branchesValid += o.branchesValid
+}
+
+func (c *coberturaCounts) lineRate() string {
+	return coberturaRate(c.linesCovered, c.linesValid)
+}
+
+func (c *coberturaCounts) branchRate() string {
+	return coberturaRate(c.branchesCovered, c.branchesValid)
+}
+
+func coberturaRate(covered, valid int) string {
+	if valid == 0 {
+		return "1"
+	}
+	return fmt.Sprintf("%.4f", float64(covered)/float64(valid))
+}
+
+// coberturaPackageName returns the name of the package of the source
+// files in dir. The files directly in the source directory are put in
+// a package named after the source directory.
+func coberturaPackageName(dir, sourceDir string) string {
+	dir = strings.TrimLeft(dir, "/")
+	if dir != "" && dir != "." {
+		return strings.ReplaceAll(dir, "/", ".")
+	}
+	name := filepath.Base(sourceDir)
+	if name == "." || name == string(filepath.Separator) {
+		return "default"
+	}
+	return name
+}
+
+// CoberturaReport converts the report into a Cobertura XML report, as
+// supported e.g. by GitLab to display the coverage in merge requests.
+// The report should contain a single section per source file, which is
+// ensured by merging it via MergeLCOVReports. Each source file becomes
+// a class of the package of its directory. The file names are made
+// relative to the source directory.
+func (r *LCOVReport) CoberturaReport(sourceDir string) ([]byte, error) {
+	packages := make(map[string]*coberturaPackage)
+	packageCounts := make(map[string]*coberturaCounts)
+	var total coberturaCounts
+
+	for _, sf := range r.SourceFiles {
+		filename := sf.Name
+		if filepath.IsAbs(filename) {
+			if rel, err := filepath.Rel(sourceDir, filename); err == nil && !strings.HasPrefix(rel, "..") {
+				filename = rel
+			}
+		}
+		filename = filepath.ToSlash(filename)
+
+		class, counts := coberturaClassFromSourceFile(sf, filename)
+
+		packageName := coberturaPackageName(path.Dir(filename), sourceDir)
+		pkg, ok := packages[packageName]
+		if !ok {
+			pkg = &coberturaPackage{Name: packageName}
+			packages[packageName] = pkg
+			packageCounts[packageName] = &coberturaCounts{}
+		}
+		pkg.Classes = append(pkg.Classes, class)
+		packageCounts[packageName].add(counts)
+		total.add(counts)
+	}
+
+	report := coberturaCoverage{
+		LineRate:        total.lineRate(),
+		BranchRate:      total.branchRate(),
+		LinesCovered:    total.linesCovered,
+		LinesValid:      total.linesValid,
+		BranchesCovered: total.branchesCovered,
+		BranchesValid:   total.branchesValid,
+		Timestamp:       time.Now().UnixMilli(),
+		Sources:         []string{filepath.ToSlash(sourceDir)},
+	}
+
+	var packageNames []string
+	for name := range packages {
+		packageNames = append(packageNames, name)
+	}
+	sort.Strings(packageNames)
+	for _, name := range packageNames {
+		pkg := packages[name]
+		pkg.LineRate = packageCounts[name].lineRate()
+		pkg.BranchRate = packageCounts[name].branchRate()
+		sort.Slice(pkg.Classes, func(i, j int) bool {
+			return pkg.Classes[i].Filename < pkg.Classes[j].Filename
+		})
+		report.Packages = append(report.Packages, *pkg)
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func coberturaClassFromSourceFile(sf *SourceFile, filename string) (coberturaClass, coberturaCounts) {
+	var counts coberturaCounts
+
+	type branchCount struct{ covered, valid int }
+	branches := make(map[int]*branchCount)
+	for _, b := range sf.BranchInformation {
+		bc, ok := branches[b.Line]
+		if !ok {
+			bc = &branchCount{}
+			branches[b.Line] = bc
+		}
+		bc.valid++
+		if b.Executions > 0 {
+			bc.covered++
+		}
+	}
+
+	class := coberturaClass{
+		Name:     strings.ReplaceAll(strings.TrimSuffix(filename, path.Ext(filename)), "/", "."),
+		Filename: filename,
+	}
+	for _, l := range sf.LineInformation {
+		line := coberturaLine{Number: l.Number, Hits: l.Executions}
+		counts.linesValid++
+		if l.Executions > 0 {
+			counts.linesCovered++
+		}
+		if bc, ok := branches[l.Number]; ok {
+			line.Branch = true
+			line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", bc.covered*100/bc.valid, bc.covered, bc.valid)
+			counts.branchesValid += bc.valid
+			counts.branchesCovered += bc.covered
+		}
+		class.Lines = append(class.Lines, line)
+	}
+	class.LineRate = counts.lineRate()
+	class.BranchRate = counts.branchRate()
+	return class, counts
+}
+
+// WriteCoberturaReport writes the report returned by CoberturaReport to
+// the specified path.
+func (r *LCOVReport) WriteCoberturaReport(path, sourceDir string) error {
+	report, err := r.CoberturaReport(sourceDir)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, report, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeLCOVReports(t *testing.T) {
+	report1, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(`TN:fuzz_test_1
+SF:/project/src/parser.cpp
+FN:3,parse
+FNDA:2,parse
+DA:3,2
+DA:4,0
+BRDA:4,0,0,2
+BRDA:4,0,1,-
+end_of_record
+`))
+	require.NoError(t, err)
+	report2, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(`TN:fuzz_test_2
+SF:/project/src/parser.cpp
+FN:3,parse
+FNDA:1,parse
+DA:3,1
+DA:4,1
+BRDA:4,0,0,-
+BRDA:4,0,1,1
+end_of_record
+SF:/project/src/lexer.cpp
+DA:1,0
+end_of_record
+`))
+	require.NoError(t, err)
+
+	merged := MergeLCOVReports(report1, report2)
+	require.Len(t, merged.SourceFiles, 2)
+
+	lexer := merged.SourceFiles[0]
+	assert.Equal(t, "/project/src/lexer.cpp", lexer.Name)
+	assert.Equal(t, Overview{LinesFound: 1}, lexer.Overview)
+
+	parser := merged.SourceFiles[1]
+	assert.Equal(t, "/project/src/parser.cpp", parser.Name)
+	assert.Empty(t, parser.TestName)
+	assert.Equal(t, []Line{{Number: 3, Executions: 3}, {Number: 4, Executions: 1}}, parser.LineInformation)
+	assert.Equal(t, []FunctionExecution{{Name: "parse", Executions: 3}}, parser.FunctionExecutions)
+	assert.Equal(t, Overview{
+		FunctionsFound: 1,
+		FunctionsHit:   1,
+		LinesFound:     2,
+		LinesHit:       2,
+		BranchesFound:  2,
+		BranchesHit:    2,
+	}, parser.Overview)
+}
+
+func TestLCOVReport_CoberturaReport(t *testing.T) {
+	report, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(`SF:/project/src/parser.cpp
+DA:3,2
+DA:4,0
+BRDA:3,0,0,2
+BRDA:3,0,1,-
+end_of_record
+SF:/project/src/lexer.cpp
+DA:1,1
+end_of_record
+SF:/project/main.cpp
+DA:1,0
+end_of_record
+`))
+	require.NoError(t, err)
+
+	out, err := MergeLCOVReports(report).CoberturaReport("/project")
+	require.NoError(t, err)
+	xml := string(out)
+
+	assert.Contains(t, xml, `<coverage line-rate="0.5000" branch-rate="0.5000" lines-covered="2" lines-valid="4" branches-covered="1" branches-valid="2"`)
+	assert.Contains(t, xml, `<source>/project</source>`)
+	assert.Contains(t, xml, `<package name="project" line-rate="0.0000" branch-rate="1"`)
+	assert.Contains(t, xml, `<package name="src" line-rate="0.6667" branch-rate="0.5000"`)
+	assert.Contains(t, xml, `<class name="src.parser" filename="src/parser.cpp" line-rate="0.5000" branch-rate="0.5000"`)
+	assert.Contains(t, xml, `<line number="3" hits="2" branch="true" condition-coverage="50% (1/2)"></line>`)
+	assert.Contains(t, xml, `<line number="4" hits="0" branch="false"></line>`)
+}
+
+func TestCoberturaPackageName(t *testing.T) {
+	assert.Equal(t, "src.parser", coberturaPackageName("src/parser", "/project"))
+	assert.Equal(t, "project", coberturaPackageName(".", "/project"))
+	assert.Equal(t, "usr.include", coberturaPackageName("/usr/include", "/project"))
+	assert.Equal(t, "default", coberturaPackageName(".", "/"))
+}
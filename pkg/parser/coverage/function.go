@@ -0,0 +1,172 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+
+	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// FunctionCoverage describes how often a single function was executed.
+type FunctionCoverage struct {
+	Filename   string
+	Name       string
+	Line       int
+	Executions int
+}
+
+// FilterFunctions returns the coverage of all functions in the report
+// whose name matches the given pattern, based on the FN and FNDA
+// records of the report.
+func (r *LCOVReport) FilterFunctions(pattern *regexp.Regexp) []*FunctionCoverage {
+	var functions []*FunctionCoverage
+	for _, sf := range r.SourceFiles {
+		executions := make(map[string]int)
+		for _, fe := range sf.FunctionExecutions {
+			executions[fe.Name] += fe.Executions
+		}
+
+		for _, f := range sf.FunctionInformation {
+			if !pattern.MatchString(f.Name) {
+				continue
+			}
+			functions = append(functions, &FunctionCoverage{
+				Filename:   sf.Name,
+				Name:       f.Name,
+				Line:       f.Line,
+				Executions: executions[f.Name],
+			})
+		}
+	}
+	return functions
+}
+
+// FilterByFunctions returns a report which only contains the functions
+// whose name matches the given pattern and the lines and branches
+// within them. A function is assumed to extend from its first line to
+// the line before the next function of the source file, because the
+// FN records don't contain the end line. Source files without matching
+// functions are dropped and the overview counts are computed from the
+// remaining records, so that the summary of the returned report only
+// describes the matching functions.
+func (r *LCOVReport) FilterByFunctions(pattern *regexp.Regexp) *LCOVReport {
+	filtered := &LCOVReport{}
+	for _, sf := range r.SourceFiles {
+		starts := make([]int, 0, len(sf.FunctionInformation))
+		for _, f := range sf.FunctionInformation {
+			starts = append(starts, f.Line)
+		}
+		sort.Ints(starts)
+
+		result := &SourceFile{TestName: sf.TestName, Name: sf.Name}
+		matching := make(map[string]bool)
+		// The line ranges [start, end) of the matching functions, an
+		// end of 0 means the end of the file
+		var ranges [][2]int
+		for _, f := range sf.FunctionInformation {
+			if !pattern.MatchString(f.Name) {
+				continue
+			}
+			matching[f.Name] = true
+			result.FunctionInformation = append(result.FunctionInformation, f)
+			end := 0
+			if i := sort.SearchInts(starts, f.Line+1); i < len(starts) {
+				end = starts[i]
+			}
+			ranges = append(ranges, [2]int{f.Line, end})
+		}
+		if len(result.FunctionInformation) == 0 {
+			continue
+		}
+		inMatchingFunction := func(line int) bool {
+			for _, r := range ranges {
+				if line >= r[0] && (r[1] == 0 || line < r[1]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		executions := make(map[string]int)
+		for _, fe := range sf.FunctionExecutions {
+			if matching[fe.Name] {
+				result.FunctionExecutions = append(result.FunctionExecutions, fe)
+				executions[fe.Name] += fe.Executions
+			}
+		}
+		result.FunctionsFound = len(result.FunctionInformation)
+		for _, f := range result.FunctionInformation {
+			if executions[f.Name] > 0 {
+				result.FunctionsHit++
+			}
+		}
+
+		for _, l := range sf.LineInformation {
+			if !inMatchingFunction(l.Number) {
+				continue
+			}
+			result.LineInformation = append(result.LineInformation, l)
+			result.LinesFound++
+			if l.Executions > 0 {
+				result.LinesHit++
+			}
+		}
+
+		for _, b := range sf.BranchInformation {
+			if !inMatchingFunction(b.Line) {
+				continue
+			}
+			result.BranchInformation = append(result.BranchInformation, b)
+			result.BranchesFound++
+			if b.Executions > 0 {
+				result.BranchesHit++
+			}
+		}
+
+		filtered.SourceFiles = append(filtered.SourceFiles, result)
+	}
+	return filtered
+}
+
+// FilterLCOVByFunctions returns the lcov report restricted to the
+// functions matching the pattern, see LCOVReport.FilterByFunctions.
+func FilterLCOVByFunctions(report string, pattern *regexp.Regexp) (string, error) {
+	lcovReport, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(report))
+	if err != nil {
+		return "", err
+	}
+	return lcovReport.FilterByFunctions(pattern).String(), nil
+}
+
+// PrintFunctionTable prints the coverage of the given functions, which
+// were selected via the given pattern.
+func PrintFunctionTable(functions []*FunctionCoverage, pattern *regexp.Regexp, writer io.Writer) {
+	log.Print("\n")
+	if len(functions) == 0 {
+		log.Warnf("No functions matching %q found in the coverage report", pattern.String())
+		return
+	}
+
+	tableData := pterm.TableData{{"Function", "File", "Line", "Executions"}}
+	for _, f := range functions {
+		tableData = append(tableData, []string{
+			f.Name,
+			fileutil.PrettifyPath(f.Filename),
+			fmt.Sprintf("%d", f.Line),
+			fmt.Sprintf("%d", f.Executions),
+		})
+	}
+	table := pterm.DefaultTable.WithWriter(writer).WithHasHeader().WithData(tableData)
+
+	log.Successf("Coverage of functions matching %q:\n", pattern.String())
+	if err := table.Render(); err != nil {
+		log.Errorf(err, "Unable to print function coverage table: %v", err)
+	}
+	log.Print("\n")
+}
@@ -0,0 +1,99 @@
+package coverage
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLCOVReport_FilterFunctions(t *testing.T) {
+	lcov := `SF:/src/explore_me.cpp
+FN:5,exploreMe
+FN:20,exploreOther
+FN:30,helper
+FNDA:12,exploreMe
+FNDA:0,exploreOther
+FNDA:3,helper
+FNF:3
+FNH:2
+end_of_record
+SF:/src/other.cpp
+FN:2,exploreMore
+FNDA:1,exploreMore
+FNF:1
+FNH:1
+end_of_record
+`
+	report, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(lcov))
+	require.NoError(t, err)
+
+	functions := report.FilterFunctions(regexp.MustCompile("^explore"))
+	assert.Equal(t, []*FunctionCoverage{
+		{Filename: "/src/explore_me.cpp", Name: "exploreMe", Line: 5, Executions: 12},
+		{Filename: "/src/explore_me.cpp", Name: "exploreOther", Line: 20, Executions: 0},
+		{Filename: "/src/other.cpp", Name: "exploreMore", Line: 2, Executions: 1},
+	}, functions)
+
+	functions = report.FilterFunctions(regexp.MustCompile("doesNotExist"))
+	assert.Empty(t, functions)
+}
+
+func TestLCOVReport_FilterByFunctions(t *testing.T) {
+	lcov := `SF:/src/explore_me.cpp
+FN:5,exploreMe
+FN:20,helper
+FNDA:12,exploreMe
+FNDA:3,helper
+FNF:2
+FNH:2
+DA:5,12
+DA:6,0
+DA:21,3
+LF:3
+LH:2
+BRDA:6,0,0,1
+BRDA:6,0,1,-
+BRDA:22,0,0,1
+BRF:3
+BRH:2
+end_of_record
+SF:/src/other.cpp
+FN:2,other
+FNDA:1,other
+FNF:1
+FNH:1
+DA:2,1
+LF:1
+LH:1
+end_of_record
+`
+	report, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(lcov))
+	require.NoError(t, err)
+
+	filtered := report.FilterByFunctions(regexp.MustCompile("^explore"))
+	require.Len(t, filtered.SourceFiles, 1)
+	sf := filtered.SourceFiles[0]
+	assert.Equal(t, "/src/explore_me.cpp", sf.Name)
+	assert.Equal(t, []Function{{Name: "exploreMe", Line: 5}}, sf.FunctionInformation)
+	assert.Equal(t, []Line{{Number: 5, Executions: 12}, {Number: 6, Executions: 0}}, sf.LineInformation)
+	assert.Len(t, sf.BranchInformation, 2)
+	assert.Equal(t, Overview{
+		FunctionsFound: 1,
+		FunctionsHit:   1,
+		LinesFound:     2,
+		LinesHit:       1,
+		BranchesFound:  2,
+		BranchesHit:    1,
+	}, sf.Overview)
+
+	summary := filtered.Summary()
+	assert.Equal(t, 2, summary.Total.LinesFound)
+
+	// The filtered report can be parsed again
+	reparsed, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(filtered.String()))
+	require.NoError(t, err)
+	assert.Equal(t, sf.Overview, reparsed.SourceFiles[0].Overview)
+}
@@ -0,0 +1,100 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// HTMLIndexEntry is a fuzz test listed in the HTML index of the
+// coverage reports of multiple fuzz tests.
+type HTMLIndexEntry struct {
+	FuzzTest string
+	// The path of the directory of the HTML report of the fuzz test,
+	// relative to the directory of the index
+	ReportDir string
+	Summary   *Summary
+}
+
+var htmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Coverage Reports</title>
+  <style>
+    body { font-family: sans-serif; margin: 2em; }
+    table { border-collapse: collapse; }
+    th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; }
+    th { background: #eee; }
+    td.number { text-align: right; }
+  </style>
+</head>
+<body>
+  <h1>Coverage Reports</h1>
+  <table>
+    <tr>
+      <th>Fuzz Test</th>
+      <th>Line Coverage</th>
+      <th>Lines Hit/Found</th>
+      <th>Functions Hit/Found</th>
+      <th>Branches Hit/Found</th>
+    </tr>
+{{- range .}}
+    <tr>
+      <td><a href="{{.Link}}">{{.FuzzTest}}</a></td>
+      <td class="number">{{.LineCoverage}}</td>
+      <td class="number">{{.Lines}}</td>
+      <td class="number">{{.Functions}}</td>
+      <td class="number">{{.Branches}}</td>
+    </tr>
+{{- end}}
+  </table>
+</body>
+</html>
+`))
+
+// WriteHTMLIndex writes an HTML page to path which lists the fuzz tests
+// with their coverage and links to their HTML reports.
+func WriteHTMLIndex(path string, entries []*HTMLIndexEntry) error {
+	type row struct {
+		FuzzTest     string
+		Link         string
+		LineCoverage string
+		Lines        string
+		Functions    string
+		Branches     string
+	}
+	var rows []row
+	for _, entry := range entries {
+		r := row{
+			FuzzTest:     entry.FuzzTest,
+			Link:         filepath.ToSlash(filepath.Join(entry.ReportDir, "index.html")),
+			LineCoverage: "n/a",
+			Lines:        "n/a",
+			Functions:    "n/a",
+			Branches:     "n/a",
+		}
+		if entry.Summary != nil {
+			total := entry.Summary.Total
+			r.LineCoverage = fmt.Sprintf("%.1f%%", total.LineCoverage())
+			r.Lines = fmt.Sprintf("%d / %d", total.LinesHit, total.LinesFound)
+			r.Functions = fmt.Sprintf("%d / %d", total.FunctionsHit, total.FunctionsFound)
+			r.Branches = fmt.Sprintf("%d / %d", total.BranchesHit, total.BranchesFound)
+		}
+		rows = append(rows, r)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = htmlIndexTemplate.Execute(f, rows)
+	if err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(f.Close())
+}
@@ -0,0 +1,33 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTMLIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.html")
+	err := WriteHTMLIndex(path, []*HTMLIndexEntry{
+		{
+			FuzzTest:  "com.example.<FuzzTest>",
+			ReportDir: "com.example.FuzzTest",
+			Summary:   &Summary{Total: Overview{LinesHit: 1, LinesFound: 4, FunctionsHit: 1, FunctionsFound: 2}},
+		},
+		{FuzzTest: "other_fuzz_test", ReportDir: "other_fuzz_test"},
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	index := string(content)
+	assert.Contains(t, index, `<a href="com.example.FuzzTest/index.html">com.example.&lt;FuzzTest&gt;</a>`)
+	assert.Contains(t, index, "25.0%")
+	assert.Contains(t, index, "1 / 4")
+	assert.Contains(t, index, "1 / 2")
+	assert.Contains(t, index, `<a href="other_fuzz_test/index.html">other_fuzz_test</a>`)
+	assert.Contains(t, index, "n/a")
+}
@@ -0,0 +1,116 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// ThresholdCheck is the result of comparing the line coverage of the
+// whole project or of a single file with a threshold.
+type ThresholdCheck struct {
+	// Name describes what was checked, e.g. "total" or the path of the
+	// file
+	Name      string
+	Coverage  float64
+	Threshold float64
+}
+
+func (c *ThresholdCheck) Passed() bool {
+	return c.Coverage >= c.Threshold
+}
+
+func (c *ThresholdCheck) String() string {
+	return fmt.Sprintf("line coverage of %s is %.1f%% (threshold %.1f%%)", c.Name, c.Coverage, c.Threshold)
+}
+
+// CheckThresholds compares the total line coverage with failUnder and,
+// if failUnderFile is not 0, the line coverage of each file with
+// failUnderFile.
+func (cs *Summary) CheckThresholds(failUnder, failUnderFile float64) []*ThresholdCheck {
+	checks := []*ThresholdCheck{{
+		Name:      "total",
+		Coverage:  cs.LineCoverage(),
+		Threshold: failUnder,
+	}}
+	if failUnderFile == 0 {
+		return checks
+	}
+	for _, file := range cs.Files {
+		checks = append(checks, &ThresholdCheck{
+			Name:      fileutil.PrettifyPath(file.Filename),
+			Coverage:  file.Coverage.LineCoverage(),
+			Threshold: failUnderFile,
+		})
+	}
+	return checks
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// JUnitReport returns a JUnit XML report with a test case for each of
+// the checks, which fails if the coverage is below the threshold. This
+// allows CI systems which only display JUnit reports to show the
+// results of the coverage gate.
+func JUnitReport(suiteName string, checks []*ThresholdCheck) ([]byte, error) {
+	suite := junitTestSuite{Name: suiteName, Tests: len(checks)}
+	for _, check := range checks {
+		testCase := junitTestCase{
+			Name:      check.Name,
+			ClassName: suiteName,
+		}
+		if !check.Passed() {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("Line coverage %.1f%% is below the threshold of %.1f%%", check.Coverage, check.Threshold),
+				Type:    "CoverageThreshold",
+			}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	out, err := xml.MarshalIndent(junitTestSuites{TestSuites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// WriteJUnitReport writes the report returned by JUnitReport to the
+// specified path.
+func WriteJUnitReport(path, suiteName string, checks []*ThresholdCheck) error {
+	report, err := JUnitReport(suiteName, checks)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, report, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_CheckThresholds(t *testing.T) {
+	summary := &Summary{
+		Total: Overview{LinesHit: 6, LinesFound: 10},
+		Files: []*FileCoverage{
+			{Filename: "a.cpp", Coverage: Overview{LinesHit: 5, LinesFound: 5}},
+			{Filename: "b.cpp", Coverage: Overview{LinesHit: 1, LinesFound: 5}},
+		},
+	}
+
+	checks := summary.CheckThresholds(50, 0)
+	require.Len(t, checks, 1)
+	assert.Equal(t, "total", checks[0].Name)
+	assert.True(t, checks[0].Passed())
+
+	checks = summary.CheckThresholds(70, 50)
+	require.Len(t, checks, 3)
+	assert.False(t, checks[0].Passed())
+	assert.Equal(t, "a.cpp", checks[1].Name)
+	assert.True(t, checks[1].Passed())
+	assert.Equal(t, "b.cpp", checks[2].Name)
+	assert.False(t, checks[2].Passed())
+}
+
+func TestJUnitReport(t *testing.T) {
+	checks := []*ThresholdCheck{
+		{Name: "total", Coverage: 80, Threshold: 75},
+		{Name: "src/<parser>.cpp", Coverage: 20, Threshold: 50},
+	}
+
+	report, err := JUnitReport("my_fuzz_test", checks)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), `<testsuite name="my_fuzz_test" tests="2" failures="1">`)
+	assert.Contains(t, string(report), `<testcase name="total" classname="my_fuzz_test"></testcase>`)
+	assert.Contains(t, string(report), `<testcase name="src/&lt;parser&gt;.cpp" classname="my_fuzz_test">`)
+	assert.Contains(t, string(report), `<failure message="Line coverage 20.0% is below the threshold of 50.0%" type="CoverageThreshold"></failure>`)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -14,13 +15,17 @@ import (
 	"code-intelligence.com/cifuzz/pkg/log"
 )
 
-// TODO: do we need TN:<test name>? Only for merged reports?
+var invalidTestNameCharsRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
 
 type LCOVReport struct {
 	SourceFiles []*SourceFile
 }
 
 type SourceFile struct {
+	// TestName is the name of the test which produced the coverage of
+	// this section. Merged reports can contain sections of different
+	// tests.
+	TestName            string
 	Name                string
 	FunctionInformation []Function
 	FunctionExecutions  []FunctionExecution
@@ -60,6 +65,14 @@ type Overview struct {
 	BranchesHit    int
 }
 
+// LineCoverage returns the percentage of the lines which were hit
+func (o *Overview) LineCoverage() float64 {
+	if o.LinesFound == 0 {
+		return 100
+	}
+	return float64(o.LinesHit) * 100 / float64(o.LinesFound)
+}
+
 func (r *LCOVReport) WriteLCOVReportToFile(file string) error {
 	if r.SourceFiles == nil || len(r.SourceFiles) == 0 {
 		log.Debug("LCOV report is empty, no file created")
@@ -79,58 +92,214 @@ func (r *LCOVReport) WriteLCOVReportToFile(file string) error {
 	defer f.Close()
 
 	for _, sf := range r.SourceFiles {
-		// SF:<absolute path to the source file>
-		s := fmt.Sprintf("SF:%s\n", sf.Name)
+		_, err = f.WriteString(sf.lcovRecords())
+		if err != nil {
+			return errors.Wrapf(err, "Failed to write to file '%s'", file)
+		}
+	}
+
+	log.Debugf("Successfully wrote lcov report to %s", file)
+	return nil
+}
+
+// String returns the report in the lcov format.
+func (r *LCOVReport) String() string {
+	var b strings.Builder
+	for _, sf := range r.SourceFiles {
+		b.WriteString(sf.lcovRecords())
+	}
+	return b.String()
+}
+
+// lcovRecords returns the lcov records of the source file section.
+func (sf *SourceFile) lcovRecords() string {
+	var s string
+	if sf.TestName != "" {
+		// TN:<test name>
+		s += fmt.Sprintf("TN:%s\n", sf.TestName)
+	}
+	// SF:<absolute path to the source file>
+	s += fmt.Sprintf("SF:%s\n", sf.Name)
 
-		// Function Coverage
-		for _, f := range sf.FunctionInformation {
-			// FN:<line number of function start>,<function name>
-			s += fmt.Sprintf("FN:%d,%s\n", f.Line, f.Name)
+	// Function Coverage
+	for _, f := range sf.FunctionInformation {
+		// FN:<line number of function start>,<function name>
+		s += fmt.Sprintf("FN:%d,%s\n", f.Line, f.Name)
+	}
+	for _, f := range sf.FunctionExecutions {
+		// FNDA:<execution count>,<function name>
+		s += fmt.Sprintf("FNDA:%d,%s\n", f.Executions, f.Name)
+	}
+	// FNF:<number of functions found>
+	s += fmt.Sprintf("FNF:%d\n", sf.FunctionsFound)
+	// FNH:<number of function hit>
+	s += fmt.Sprintf("FNH:%d\n", sf.FunctionsHit)
+
+	// Line Coverage
+	for _, l := range sf.LineInformation {
+		// DA:<line number>,<execution count>[,<checksum>]
+		s += fmt.Sprintf("DA:%d,%d\n", l.Number, l.Executions)
+	}
+	// LF:<number of instrumented lines>
+	s += fmt.Sprintf("LF:%d\n", sf.LinesFound)
+	// LH:<number of lines with a non-zero execution count>
+	s += fmt.Sprintf("LH:%d\n", sf.LinesHit)
+
+	// Branch coverage
+	for _, b := range sf.BranchInformation {
+		if b.Executions == 0 {
+			// BRDA:<line number>,<block number>,<branch number>,<taken>
+			s += fmt.Sprintf("BRDA:%d,0,%d,-\n", b.Line, b.Number)
+		} else {
+			s += fmt.Sprintf("BRDA:%d,0,%d,%d\n", b.Line, b.Number, b.Executions)
 		}
-		for _, f := range sf.FunctionExecutions {
-			// FNDA:<execution count>,<function name>
-			s += fmt.Sprintf("FNDA:%d,%s\n", f.Executions, f.Name)
+	}
+	// BRF:<number of branches found>
+	s += fmt.Sprintf("BRF:%d\n", sf.BranchesFound)
+	// BRH:<number of branches hit>
+	s += fmt.Sprintf("BRH:%d\n", sf.BranchesHit)
+
+	// Necessary to signal end of sourcefile section
+	s += fmt.Sprintf("end_of_record\n")
+	return s
+}
+
+// SetTestName sets the test name of all sections of the report which
+// don't have a test name yet.
+func (r *LCOVReport) SetTestName(testName string) {
+	testName = SanitizeTestName(testName)
+	for _, sf := range r.SourceFiles {
+		if sf.TestName == "" {
+			sf.TestName = testName
 		}
-		// FNF:<number of functions found>
-		s += fmt.Sprintf("FNF:%d\n", sf.FunctionsFound)
-		// FNH:<number of function hit>
-		s += fmt.Sprintf("FNH:%d\n", sf.FunctionsHit)
-
-		// Line Coverage
-		for _, l := range sf.LineInformation {
-			// DA:<line number>,<execution count>[,<checksum>]
-			s += fmt.Sprintf("DA:%d,%d\n", l.Number, l.Executions)
+	}
+}
+
+// SanitizeTestName turns the name of a fuzz test into a valid LCOV test
+// name, which may only consist of letters, digits and underscores.
+func SanitizeTestName(testName string) string {
+	return invalidTestNameCharsRegex.ReplaceAllString(testName, "_")
+}
+
+// AddTestName adds a TN record with the specified test name to all
+// sections of the lcov report which don't have a test name yet.
+// Sections which already have a test name, e.g. in merged reports, are
+// left unchanged.
+func AddTestName(report string, testName string) string {
+	testNameRecord := "TN:" + SanitizeTestName(testName)
+
+	lines := strings.SplitAfter(report, "\n")
+	var b strings.Builder
+	hasTestName := false
+	for _, line := range lines {
+		record := strings.TrimRight(line, "\r\n")
+		switch {
+		case record == "TN:":
+			// Replace the empty test name
+			line = testNameRecord + line[len(record):]
+			hasTestName = true
+		case strings.HasPrefix(record, "TN:"):
+			hasTestName = true
+		case strings.HasPrefix(record, "SF:") && !hasTestName:
+			b.WriteString(testNameRecord + "\n")
+			hasTestName = true
+		case record == "end_of_record":
+			hasTestName = false
 		}
-		// LF:<number of instrumented lines>
-		s += fmt.Sprintf("LF:%d\n", sf.LinesFound)
-		// LH:<number of lines with a non-zero execution count>
-		s += fmt.Sprintf("LH:%d\n", sf.LinesHit)
-
-		// Branch coverage
-		for _, b := range sf.BranchInformation {
-			if b.Executions == 0 {
-				// BRDA:<line number>,<block number>,<branch number>,<taken>
-				s += fmt.Sprintf("BRDA:%d,0,%d,-\n", b.Line, b.Number)
-			} else {
-				s += fmt.Sprintf("BRDA:%d,0,%d,%d\n", b.Line, b.Number, b.Executions)
-			}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// SourceFilesInReport returns the paths of the source files contained
+// in the lcov report.
+func SourceFilesInReport(report string) []string {
+	var sourceFiles []string
+	for _, line := range strings.Split(report, "\n") {
+		record := strings.TrimRight(line, "\r")
+		if strings.HasPrefix(record, "SF:") {
+			sourceFiles = append(sourceFiles, strings.TrimPrefix(record, "SF:"))
 		}
-		// BRF:<number of branches found>
-		s += fmt.Sprintf("BRF:%d\n", sf.BranchesFound)
-		// BRH:<number of branches hit>
-		s += fmt.Sprintf("BRH:%d\n", sf.BranchesHit)
+	}
+	return sourceFiles
+}
 
-		// Necessary to signal end of sourcefile section
-		s += fmt.Sprintf("end_of_record\n")
+// AddUncoveredFiles appends a section with zero coverage to the lcov
+// report for each of the source files which is not contained in the
+// report yet. Each line of those files which may contain executable
+// code is reported as an instrumented line which was never hit.
+func AddUncoveredFiles(report string, sourceFiles []string) (string, error) {
+	covered := make(map[string]bool)
+	for _, sourceFile := range SourceFilesInReport(report) {
+		covered[sourceFile] = true
+	}
 
-		_, err = f.WriteString(s)
+	var b strings.Builder
+	b.WriteString(report)
+	if report != "" && !strings.HasSuffix(report, "\n") {
+		b.WriteString("\n")
+	}
+	for _, sourceFile := range sourceFiles {
+		if covered[sourceFile] {
+			continue
+		}
+		content, err := os.ReadFile(sourceFile)
 		if err != nil {
-			return errors.Wrapf(err, "Failed to write to file '%s'", file)
+			return "", errors.WithStack(err)
+		}
+
+		b.WriteString("SF:" + sourceFile + "\n")
+		var linesFound int
+		for _, line := range executableLines(string(content)) {
+			b.WriteString(fmt.Sprintf("DA:%d,0\n", line))
+			linesFound++
 		}
+		b.WriteString(fmt.Sprintf("LF:%d\nLH:0\nend_of_record\n", linesFound))
 	}
+	return b.String(), nil
+}
 
-	log.Debugf("Successfully wrote lcov report to %s", file)
-	return nil
+// executableLines returns the numbers of the lines of the C/C++ source
+// which may contain executable code. Blank lines, comments, preprocessor
+// directives and lines which only contain braces are skipped.
+func executableLines(source string) []int {
+	var lines []int
+	var inBlockComment bool
+	for i, line := range strings.Split(source, "\n") {
+		var code strings.Builder
+		rest := line
+		for rest != "" {
+			if inBlockComment {
+				_, after, found := strings.Cut(rest, "*/")
+				if !found {
+					break
+				}
+				inBlockComment = false
+				rest = after
+				continue
+			}
+			lineComment := strings.Index(rest, "//")
+			blockComment := strings.Index(rest, "/*")
+			if blockComment != -1 && (lineComment == -1 || blockComment < lineComment) {
+				code.WriteString(rest[:blockComment])
+				inBlockComment = true
+				rest = rest[blockComment+2:]
+				continue
+			}
+			if lineComment != -1 {
+				rest = rest[:lineComment]
+			}
+			code.WriteString(rest)
+			break
+		}
+
+		trimmed := strings.TrimSpace(code.String())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.Trim(trimmed, "{}; \t") == "" {
+			continue
+		}
+		lines = append(lines, i+1)
+	}
+	return lines
 }
 
 func ParseLCOVFileIntoLCOVReport(in io.Reader) (*LCOVReport, error) {
@@ -156,6 +325,9 @@ func ParseLCOVFileIntoLCOVReport(in io.Reader) (*LCOVReport, error) {
 		}
 
 		switch prefix {
+		case "TN":
+			currentSourceFile.TestName = v
+
 		case "SF":
 			currentSourceFile.Name = v
 
@@ -290,16 +462,20 @@ func ParseLCOVFileIntoLCOVReport(in io.Reader) (*LCOVReport, error) {
 // into the `Summary` struct. It will print the summary in verbose mode
 // in JSON format if possible.
 func ParseLCOVReportIntoSummary(in io.Reader) (*Summary, error) {
-	summary := &Summary{
-		Total: Overview{},
-	}
-
 	report, err := ParseLCOVFileIntoLCOVReport(in)
 	if err != nil {
 		return nil, err
 	}
+	return report.Summary(), nil
+}
+
+// Summary returns the coverage summary of the report
+func (r *LCOVReport) Summary() *Summary {
+	summary := &Summary{
+		Total: Overview{},
+	}
 
-	for _, sf := range report.SourceFiles {
+	for _, sf := range r.SourceFiles {
 		currentFile := &FileCoverage{
 			Filename: sf.Name,
 			Coverage: sf.Overview,
@@ -324,5 +500,5 @@ func ParseLCOVReportIntoSummary(in io.Reader) (*Summary, error) {
 		log.Debugf("Successfully created coverage summary: %s", string(out))
 	}
 
-	return summary, nil
+	return summary
 }
@@ -233,3 +233,83 @@ func TestParseLCOVIntoSummary_EmptyReport(t *testing.T) {
 	assert.Empty(t, summary.Total.LinesFound, "summary shouldn't have any found lines")
 	assert.Empty(t, summary.Total.FunctionsFound, "summary shouldn't have any found functions")
 }
+
+func TestWriteLCOVReportToFile_TestName(t *testing.T) {
+	report := LCOVReport{
+		SourceFiles: []*SourceFile{
+			{Name: "foo.cpp"},
+			{Name: "bar.cpp", TestName: "other_test"},
+		},
+	}
+	report.SetTestName("src/my-fuzz-test")
+
+	tempDir := testutil.MkdirTemp(t, "", "lcov-test")
+	lcovPath := filepath.Join(tempDir, "report.lcov")
+	err := report.WriteLCOVReportToFile(lcovPath)
+	require.NoError(t, err)
+
+	file, err := os.Open(lcovPath)
+	require.NoError(t, err)
+	defer file.Close()
+	parsedReport, err := ParseLCOVFileIntoLCOVReport(file)
+	require.NoError(t, err)
+	require.Len(t, parsedReport.SourceFiles, 2)
+	assert.Equal(t, "src_my_fuzz_test", parsedReport.SourceFiles[0].TestName)
+	assert.Equal(t, "other_test", parsedReport.SourceFiles[1].TestName)
+}
+
+func TestAddTestName(t *testing.T) {
+	report := `SF:foo.cpp
+LF:1
+end_of_record
+TN:
+SF:bar.cpp
+end_of_record
+TN:other_test
+SF:baz.cpp
+end_of_record
+`
+	expectedReport := `TN:my_fuzz_test
+SF:foo.cpp
+LF:1
+end_of_record
+TN:my_fuzz_test
+SF:bar.cpp
+end_of_record
+TN:other_test
+SF:baz.cpp
+end_of_record
+`
+	assert.Equal(t, expectedReport, AddTestName(report, "my_fuzz_test"))
+}
+
+func TestAddUncoveredFiles(t *testing.T) {
+	dir := t.TempDir()
+	coveredFile := filepath.Join(dir, "covered.cpp")
+	uncoveredFile := filepath.Join(dir, "uncovered.cpp")
+	source := `#include <stdio.h>
+
+// Returns one
+int foo() {
+  /* Comments spanning
+     multiple lines */
+  int i = 1; /* comment */
+  return i;
+}
+`
+	err := os.WriteFile(uncoveredFile, []byte(source), 0o644)
+	require.NoError(t, err)
+
+	report := "SF:" + coveredFile + "\nDA:1,3\nLF:1\nLH:1\nend_of_record\n"
+	expectedReport := report +
+		"SF:" + uncoveredFile + "\nDA:4,0\nDA:7,0\nDA:8,0\nLF:3\nLH:0\nend_of_record\n"
+	actualReport, err := AddUncoveredFiles(report, []string{coveredFile, uncoveredFile})
+	require.NoError(t, err)
+	assert.Equal(t, expectedReport, actualReport)
+
+	summary, err := ParseLCOVReportIntoSummary(strings.NewReader(actualReport))
+	require.NoError(t, err)
+	require.Len(t, summary.Files, 2)
+	assert.Equal(t, 0, summary.Files[1].Coverage.LinesHit)
+	assert.Equal(t, 3, summary.Files[1].Coverage.LinesFound)
+}
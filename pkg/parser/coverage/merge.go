@@ -0,0 +1,137 @@
+package coverage
+
+import (
+	"sort"
+)
+
+type branchKey struct {
+	line   int
+	block  int
+	number int
+}
+
+// MergeLCOVReports merges the reports into a single report with one
+// section per source file. The execution counts of lines, functions and
+// branches which are contained in multiple reports, e.g. because they
+// were executed by multiple fuzz tests, are summed up and the overview
+// of each source file is recomputed. The test names of the sections are
+// dropped, because a merged section combines the coverage of multiple
+// tests. If the reports disagree on the lines of a source file, e.g.
+// because they were created from different revisions, the merged
+// section contains the union of the lines, so it extends up to the
+// highest line number seen in any of the reports.
+func MergeLCOVReports(reports ...*LCOVReport) *LCOVReport {
+	type mergedFile struct {
+		functionLines map[string]int
+		functionExecs map[string]int
+		lineExecs     map[int]int
+		branchExecs   map[branchKey]int
+	}
+
+	files := make(map[string]*mergedFile)
+	for _, report := range reports {
+		for _, sf := range report.SourceFiles {
+			f, ok := files[sf.Name]
+			if !ok {
+				f = &mergedFile{
+					functionLines: make(map[string]int),
+					functionExecs: make(map[string]int),
+					lineExecs:     make(map[int]int),
+					branchExecs:   make(map[branchKey]int),
+				}
+				files[sf.Name] = f
+			}
+			for _, fn := range sf.FunctionInformation {
+				f.functionLines[fn.Name] = fn.Line
+			}
+			for _, fn := range sf.FunctionExecutions {
+				f.functionExecs[fn.Name] += fn.Executions
+			}
+			for _, l := range sf.LineInformation {
+				f.lineExecs[l.Number] += l.Executions
+			}
+			for _, b := range sf.BranchInformation {
+				f.branchExecs[branchKey{b.Line, b.Block, b.Number}] += b.Executions
+			}
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	merged := &LCOVReport{}
+	for _, name := range names {
+		f := files[name]
+		sf := &SourceFile{Name: name}
+
+		// Functions can be reported via FN and/or FNDA records
+		functionNames := make(map[string]bool)
+		for fn := range f.functionLines {
+			functionNames[fn] = true
+		}
+		for fn := range f.functionExecs {
+			functionNames[fn] = true
+		}
+		var sortedFunctions []string
+		for fn := range functionNames {
+			sortedFunctions = append(sortedFunctions, fn)
+		}
+		sort.Slice(sortedFunctions, func(i, j int) bool {
+			li, lj := f.functionLines[sortedFunctions[i]], f.functionLines[sortedFunctions[j]]
+			if li != lj {
+				return li < lj
+			}
+			return sortedFunctions[i] < sortedFunctions[j]
+		})
+		for _, fn := range sortedFunctions {
+			if line, ok := f.functionLines[fn]; ok {
+				sf.FunctionInformation = append(sf.FunctionInformation, Function{Name: fn, Line: line})
+			}
+			sf.FunctionExecutions = append(sf.FunctionExecutions, FunctionExecution{Name: fn, Executions: f.functionExecs[fn]})
+			sf.FunctionsFound++
+			if f.functionExecs[fn] > 0 {
+				sf.FunctionsHit++
+			}
+		}
+
+		for line, execs := range f.lineExecs {
+			sf.LineInformation = append(sf.LineInformation, Line{Number: line, Executions: execs})
+			sf.LinesFound++
+			if execs > 0 {
+				sf.LinesHit++
+			}
+		}
+		sort.Slice(sf.LineInformation, func(i, j int) bool {
+			return sf.LineInformation[i].Number < sf.LineInformation[j].Number
+		})
+
+		for key, execs := range f.branchExecs {
+			sf.BranchInformation = append(sf.BranchInformation, Branch{
+				Line:       key.line,
+				Block:      key.block,
+				Number:     key.number,
+				Executions: execs,
+			})
+			sf.BranchesFound++
+			if execs > 0 {
+				sf.BranchesHit++
+			}
+		}
+		sort.Slice(sf.BranchInformation, func(i, j int) bool {
+			a, b := sf.BranchInformation[i], sf.BranchInformation[j]
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			if a.Block != b.Block {
+				return a.Block < b.Block
+			}
+			return a.Number < b.Number
+		})
+
+		merged.SourceFiles = append(merged.SourceFiles, sf)
+	}
+	return merged
+}
@@ -0,0 +1,88 @@
+package coverage
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// InputCoverage is the coverage of a single corpus input, compared to
+// the coverage of all other inputs of the corpus
+type InputCoverage struct {
+	Input string `json:"input"`
+	// CoveredLines is the number of lines executed by the input
+	CoveredLines int `json:"covered_lines"`
+	// UniqueLines are the lines, per source file, which are executed by
+	// this input but by no other input of the corpus
+	UniqueLines map[string][]int `json:"unique_lines"`
+}
+
+// Redundant returns true if all lines executed by the input are also
+// executed by other inputs of the corpus.
+func (c *InputCoverage) Redundant() bool {
+	return len(c.UniqueLines) == 0
+}
+
+// PerInputCoverage computes the coverage of each input from the report
+// of running only that input. reports[i] must be the report of
+// inputs[i].
+func PerInputCoverage(inputs []string, reports []*LCOVReport) []*InputCoverage {
+	type line struct {
+		file   string
+		number int
+	}
+
+	coveredLines := make([][]line, len(reports))
+	numInputs := make(map[line]int)
+	for i, report := range reports {
+		// A source file can be contained in multiple sections of the
+		// report, so the lines are deduplicated first
+		seen := make(map[line]bool)
+		for _, sf := range report.SourceFiles {
+			for _, l := range sf.LineInformation {
+				key := line{sf.Name, l.Number}
+				if l.Executions == 0 || seen[key] {
+					continue
+				}
+				seen[key] = true
+				coveredLines[i] = append(coveredLines[i], key)
+				numInputs[key]++
+			}
+		}
+	}
+
+	var res []*InputCoverage
+	for i, input := range inputs {
+		c := &InputCoverage{
+			Input:        input,
+			CoveredLines: len(coveredLines[i]),
+			UniqueLines:  make(map[string][]int),
+		}
+		for _, l := range coveredLines[i] {
+			if numInputs[l] == 1 {
+				c.UniqueLines[l.file] = append(c.UniqueLines[l.file], l.number)
+			}
+		}
+		for _, lines := range c.UniqueLines {
+			sort.Ints(lines)
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// WritePerInputCoverage writes the coverage of the inputs as JSON to
+// the specified path.
+func WritePerInputCoverage(path string, inputs []*InputCoverage) error {
+	out, err := json.MarshalIndent(inputs, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.WriteFile(path, append(out, '\n'), 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerInputCoverage(t *testing.T) {
+	var reports []*LCOVReport
+	for _, lcov := range []string{`SF:/project/parser.cpp
+DA:1,1
+DA:2,3
+DA:3,0
+end_of_record
+`, `SF:/project/parser.cpp
+DA:1,2
+DA:2,0
+DA:3,1
+end_of_record
+SF:/project/lexer.cpp
+DA:7,1
+end_of_record
+`, `SF:/project/parser.cpp
+DA:1,1
+DA:2,0
+DA:3,0
+end_of_record
+`} {
+		report, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(lcov))
+		require.NoError(t, err)
+		reports = append(reports, report)
+	}
+
+	inputs := PerInputCoverage([]string{"a", "b", "c"}, reports)
+	require.Len(t, inputs, 3)
+
+	assert.Equal(t, 2, inputs[0].CoveredLines)
+	assert.Equal(t, map[string][]int{"/project/parser.cpp": {2}}, inputs[0].UniqueLines)
+	assert.False(t, inputs[0].Redundant())
+
+	assert.Equal(t, 3, inputs[1].CoveredLines)
+	assert.Equal(t, map[string][]int{"/project/parser.cpp": {3}, "/project/lexer.cpp": {7}}, inputs[1].UniqueLines)
+
+	assert.Equal(t, 1, inputs[2].CoveredLines)
+	assert.True(t, inputs[2].Redundant())
+}
@@ -0,0 +1,184 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// The ID of the rule which all results of the report refer to
+	sarifRuleID = "coverage"
+	// The base ID which relative artifact locations are resolved against
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+	Properties         *sarifCoverageProperties         `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                   `json:"ruleId"`
+	Level      string                   `json:"level"`
+	Message    sarifMessage             `json:"message"`
+	Locations  []sarifLocation          `json:"locations"`
+	Properties *sarifCoverageProperties `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifCoverageProperties struct {
+	LineCoverage   float64 `json:"lineCoverage"`
+	LinesFound     int     `json:"linesFound"`
+	LinesHit       int     `json:"linesHit"`
+	FunctionsFound int     `json:"functionsFound"`
+	FunctionsHit   int     `json:"functionsHit"`
+	BranchesFound  int     `json:"branchesFound"`
+	BranchesHit    int     `json:"branchesHit"`
+}
+
+func newSarifCoverageProperties(o *Overview) *sarifCoverageProperties {
+	return &sarifCoverageProperties{
+		LineCoverage:   o.LineCoverage(),
+		LinesFound:     o.LinesFound,
+		LinesHit:       o.LinesHit,
+		FunctionsFound: o.FunctionsFound,
+		FunctionsHit:   o.FunctionsHit,
+		BranchesFound:  o.BranchesFound,
+		BranchesHit:    o.BranchesHit,
+	}
+}
+
+// SARIFReport returns a SARIF 2.1.0 log with a result for each file of
+// the summary, which contains its coverage. Paths of files in the
+// project directory are made relative to the %SRCROOT% base ID, so
+// that tools can resolve them against their checkout. If omitSrcRoot
+// is true, the absolute path of the project directory is not included
+// as the value of the %SRCROOT% base ID.
+func SARIFReport(summary *Summary, projectDir string, omitSrcRoot bool) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "cifuzz",
+			InformationURI: "https://github.com/CodeIntelligenceTesting/cifuzz",
+			Rules: []sarifRule{{
+				ID:               sarifRuleID,
+				ShortDescription: sarifMessage{Text: "Code coverage of the fuzz tests"},
+			}},
+		}},
+		Results:    []sarifResult{},
+		Properties: newSarifCoverageProperties(&summary.Total),
+	}
+	if projectDir != "" && !omitSrcRoot {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifSrcRoot: {URI: fileURI(projectDir) + "/"},
+		}
+	}
+
+	for _, file := range summary.Files {
+		c := file.Coverage
+		run.Results = append(run.Results, sarifResult{
+			RuleID: sarifRuleID,
+			Level:  "note",
+			Message: sarifMessage{Text: fmt.Sprintf("Line coverage: %d / %d (%.1f%%), function coverage: %d / %d, branch coverage: %d / %d",
+				c.LinesHit, c.LinesFound, c.LineCoverage(), c.FunctionsHit, c.FunctionsFound, c.BranchesHit, c.BranchesFound)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifactLocation(file.Filename, projectDir)},
+			}},
+			Properties: newSarifCoverageProperties(&c),
+		})
+	}
+
+	out, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append(out, '\n'), nil
+}
+
+// WriteSARIFReport writes the report returned by SARIFReport to the
+// specified path.
+func WriteSARIFReport(path string, summary *Summary, projectDir string, omitSrcRoot bool) error {
+	report, err := SARIFReport(summary, projectDir, omitSrcRoot)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, report, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func artifactLocation(filename, projectDir string) sarifArtifactLocation {
+	if !filepath.IsAbs(filename) {
+		// Java source files are reported relative to the source
+		// directories, which we can't resolve here
+		return sarifArtifactLocation{URI: filepath.ToSlash(filename)}
+	}
+	if projectDir != "" {
+		rel, err := filepath.Rel(projectDir, filename)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifSrcRoot}
+		}
+	}
+	return sarifArtifactLocation{URI: fileURI(filename)}
+}
+
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths like C:/foo
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
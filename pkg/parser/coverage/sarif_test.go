@@ -0,0 +1,48 @@
+package coverage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSARIFReport(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "project")
+	summary := &Summary{
+		Total: Overview{LinesHit: 6, LinesFound: 10, FunctionsHit: 1, FunctionsFound: 2},
+		Files: []*FileCoverage{
+			{Filename: filepath.Join(projectDir, "src", "parser.cpp"), Coverage: Overview{LinesHit: 5, LinesFound: 5}},
+			{Filename: "com/example/App.java", Coverage: Overview{LinesHit: 1, LinesFound: 5}},
+		},
+	}
+
+	out, err := SARIFReport(summary, projectDir, false)
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(out, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "cifuzz", run.Tool.Driver.Name)
+	assert.Equal(t, 60.0, run.Properties.LineCoverage)
+	require.Len(t, run.Results, 2)
+
+	assert.Equal(t, sarifArtifactLocation{URI: "src/parser.cpp", URIBaseID: "%SRCROOT%"},
+		run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation)
+	assert.Equal(t, "Line coverage: 5 / 5 (100.0%), function coverage: 0 / 0, branch coverage: 0 / 0", run.Results[0].Message.Text)
+
+	assert.Equal(t, sarifArtifactLocation{URI: "com/example/App.java"},
+		run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation)
+	assert.Equal(t, 20.0, run.Results[1].Properties.LineCoverage)
+
+	// The project directory is only included as the %SRCROOT% base ID
+	// if it's not omitted
+	require.Contains(t, run.OriginalURIBaseIDs, "%SRCROOT%")
+	out, err = SARIFReport(summary, projectDir, true)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), filepath.ToSlash(projectDir))
+}
@@ -0,0 +1,95 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type sonarQubeCoverage struct {
+	XMLName xml.Name        `xml:"coverage"`
+	Version string          `xml:"version,attr"`
+	Files   []sonarQubeFile `xml:"file"`
+}
+
+type sonarQubeFile struct {
+	Path  string          `xml:"path,attr"`
+	Lines []sonarQubeLine `xml:"lineToCover"`
+}
+
+type sonarQubeLine struct {
+	LineNumber      int  `xml:"lineNumber,attr"`
+	Covered         bool `xml:"covered,attr"`
+	BranchesToCover int  `xml:"branchesToCover,attr,omitempty"`
+	CoveredBranches int  `xml:"coveredBranches,attr,omitempty"`
+}
+
+// SonarQubeReport converts the report into the generic test coverage
+// format of SonarQube. The report should contain a single section per
+// source file, which is ensured by merging it via MergeLCOVReports. The
+// file names are made relative to the source directory, which should be
+// the base directory of the SonarQube project.
+func (r *LCOVReport) SonarQubeReport(sourceDir string) ([]byte, error) {
+	report := sonarQubeCoverage{Version: "1"}
+
+	for _, sf := range r.SourceFiles {
+		filename := sf.Name
+		if filepath.IsAbs(filename) {
+			if rel, err := filepath.Rel(sourceDir, filename); err == nil && !strings.HasPrefix(rel, "..") {
+				filename = rel
+			}
+		}
+
+		type branchCount struct{ covered, valid int }
+		branches := make(map[int]*branchCount)
+		for _, b := range sf.BranchInformation {
+			bc, ok := branches[b.Line]
+			if !ok {
+				bc = &branchCount{}
+				branches[b.Line] = bc
+			}
+			bc.valid++
+			if b.Executions > 0 {
+				bc.covered++
+			}
+		}
+
+		file := sonarQubeFile{Path: filepath.ToSlash(filename)}
+		for _, l := range sf.LineInformation {
+			line := sonarQubeLine{LineNumber: l.Number, Covered: l.Executions > 0}
+			if bc, ok := branches[l.Number]; ok {
+				line.BranchesToCover = bc.valid
+				line.CoveredBranches = bc.covered
+			}
+			file.Lines = append(file.Lines, line)
+		}
+		report.Files = append(report.Files, file)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// WriteSonarQubeReport writes the report returned by SonarQubeReport to
+// the specified path.
+func (r *LCOVReport) WriteSonarQubeReport(path, sourceDir string) error {
+	report, err := r.SonarQubeReport(sourceDir)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, report, 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
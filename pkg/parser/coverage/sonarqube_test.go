@@ -0,0 +1,35 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLCOVReport_SonarQubeReport(t *testing.T) {
+	report, err := ParseLCOVFileIntoLCOVReport(strings.NewReader(`SF:/project/src/parser.cpp
+DA:3,2
+DA:4,0
+BRDA:3,0,0,2
+BRDA:3,0,1,-
+end_of_record
+SF:/other/lexer.cpp
+DA:1,1
+end_of_record
+`))
+	require.NoError(t, err)
+
+	out, err := MergeLCOVReports(report).SonarQubeReport("/project")
+	require.NoError(t, err)
+	xml := string(out)
+
+	assert.Contains(t, xml, `<coverage version="1">`)
+	assert.Contains(t, xml, `<file path="src/parser.cpp">`)
+	// Files outside of the source directory keep their absolute path
+	assert.Contains(t, xml, `<file path="/other/lexer.cpp">`)
+	assert.Contains(t, xml, `<lineToCover lineNumber="3" covered="true" branchesToCover="2" coveredBranches="1"></lineToCover>`)
+	assert.Contains(t, xml, `<lineToCover lineNumber="4" covered="false"></lineToCover>`)
+	assert.Less(t, strings.Index(xml, "/other/lexer.cpp"), strings.Index(xml, "src/parser.cpp"))
+}
@@ -0,0 +1,45 @@
+package coverage
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+// StripPathsInReport turns the absolute paths below the prefix
+// directory in the report at reportPath into paths relative to the
+// prefix directory. If reportPath is a directory, like the one of an
+// HTML report, all files in it are processed. Binary files are skipped.
+func StripPathsInReport(reportPath string, prefix string) error {
+	err := filepath.WalkDir(reportPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(content, 0) != -1 {
+			return nil
+		}
+		stripped := fileutil.StripPathPrefix(string(content), prefix)
+		if stripped == string(content) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(stripped), info.Mode().Perm())
+	})
+	return errors.WithStack(err)
+}
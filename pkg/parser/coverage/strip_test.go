@@ -0,0 +1,47 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripPathsInReport(t *testing.T) {
+	projectDir := filepath.Join(string(filepath.Separator)+"home", "user", "project")
+	reportDir := t.TempDir()
+
+	lcovPath := filepath.Join(reportDir, "report.lcov")
+	lcov := "SF:" + filepath.Join(projectDir, "src", "foo.cpp") + "\nend_of_record\n" +
+		"SF:" + filepath.Join(string(filepath.Separator)+"usr", "include", "bar.h") + "\nend_of_record\n"
+	require.NoError(t, os.WriteFile(lcovPath, []byte(lcov), 0o644))
+
+	htmlPath := filepath.Join(reportDir, "html", "index.html")
+	require.NoError(t, os.MkdirAll(filepath.Dir(htmlPath), 0o755))
+	html := "<td>" + filepath.Join(projectDir, "src") + string(filepath.Separator) + "</td>"
+	require.NoError(t, os.WriteFile(htmlPath, []byte(html), 0o644))
+
+	// Binary files are not modified
+	binaryPath := filepath.Join(reportDir, "html", "image.png")
+	binary := append([]byte{0}, []byte(filepath.Join(projectDir, "src", "foo.cpp"))...)
+	require.NoError(t, os.WriteFile(binaryPath, binary, 0o644))
+
+	err := StripPathsInReport(reportDir, projectDir)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(lcovPath)
+	require.NoError(t, err)
+	expectedLCOV := "SF:" + filepath.Join("src", "foo.cpp") + "\nend_of_record\n" +
+		"SF:" + filepath.Join(string(filepath.Separator)+"usr", "include", "bar.h") + "\nend_of_record\n"
+	assert.Equal(t, expectedLCOV, string(content))
+
+	content, err = os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Equal(t, "<td>src"+string(filepath.Separator)+"</td>", string(content))
+
+	content, err = os.ReadFile(binaryPath)
+	require.NoError(t, err)
+	assert.Equal(t, binary, content)
+}
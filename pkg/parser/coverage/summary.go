@@ -3,7 +3,11 @@ package coverage
 import (
 	"fmt"
 	"io"
+	"os"
+	"sort"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 
 	"code-intelligence.com/cifuzz/pkg/log"
@@ -60,3 +64,34 @@ func (cs *Summary) PrintTable(writer io.Writer) {
 	}
 	log.Print("\n")
 }
+
+// CoveredFiles returns the sorted names of the files of which at least
+// one line was executed.
+func (cs *Summary) CoveredFiles() []string {
+	covered := make(map[string]bool)
+	for _, file := range cs.Files {
+		if file.Coverage.LinesHit > 0 {
+			covered[file.Filename] = true
+		}
+	}
+	files := make([]string, 0, len(covered))
+	for file := range covered {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// WriteCoveredFiles writes the files returned by CoveredFiles to the
+// specified path, one file per line.
+func (cs *Summary) WriteCoveredFiles(path string) error {
+	var content strings.Builder
+	for _, file := range cs.CoveredFiles() {
+		content.WriteString(file + "\n")
+	}
+	err := os.WriteFile(path, []byte(content.String()), 0o644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
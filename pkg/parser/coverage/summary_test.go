@@ -3,6 +3,7 @@ package coverage
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -38,3 +39,33 @@ end_of_record
 	assert.Contains(t, out, "0 / 0 (100.0%)")
 	assert.Contains(t, out, "3 / 22")
 }
+
+func TestSummary_CoveredFiles(t *testing.T) {
+	report := `SF:foo.cpp
+DA:1,0
+DA:2,3
+LF:2
+LH:1
+end_of_record
+SF:bar.cpp
+DA:1,0
+LF:1
+LH:0
+end_of_record
+SF:baz.cpp
+DA:1,1
+LF:1
+LH:1
+end_of_record
+`
+	summary, err := ParseLCOVReportIntoSummary(strings.NewReader(report))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baz.cpp", "foo.cpp"}, summary.CoveredFiles())
+
+	path := filepath.Join(t.TempDir(), "files.txt")
+	err = summary.WriteCoveredFiles(path)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "baz.cpp\nfoo.cpp\n", string(content))
+}
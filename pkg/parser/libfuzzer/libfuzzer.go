@@ -68,8 +68,16 @@ var (
 	// #670	REDUCE cov: 13 ft: 15 corp: 4/5b lim: 8 exec/s: 0 rss: 31Mb L: 1/2 MS: 2 CopyPart-EraseBytes-
 	statsPattern = regexp.MustCompile(
 		`#(?P<total_execs>\d+)\s+(?P<status>\S*)\s+(cov:\s+(?P<edges>\d+)\s+)?ft:\s+(?P<features>\d+)\s+corp:\s+(?P<corpus_size>\d+)/.*exec/s:\s+(?P<executions_per_second>\d+)\s+`)
+	// Example for matching string (only printed with -verbosity=2):
+	// Written 4 bytes to .cifuzz-corpus/my_fuzz_test/a94a8fe5ccb19ba61c4c0873d391e987982fbbd3
+	corpusEntryPattern = regexp.MustCompile(
+		`^Written (?P<size>\d+) bytes to (?P<path>.+)$`)
 	testInputFilePattern = regexp.MustCompile(
 		`Test unit written to\s*(?P<test_input_file>.*)`)
+	// Example for matching string:
+	// MS: 1 ChangeBit-; base unit: adc83b19e793491b1c6ea0fd8b46cd9f32e592fc
+	baseUnitPattern = regexp.MustCompile(
+		`base unit: (?P<base_unit>[0-9a-f]{40})`)
 	slowInputPattern = regexp.MustCompile(
 		`\s*Slowest unit: (?P<duration>\d+) s.*`)
 	goPanicPattern = regexp.MustCompile(`^panic:\s+\S+`)
@@ -103,6 +111,12 @@ type parser struct {
 	lastFeatures       int       // Last features reported by Libfuzzer
 	lastNewEdgeTime    time.Time // Timestamp representing the point when the last new edge was reported
 	lastEdges          int       // Last edges reported by Libfuzzer
+
+	// The metric of the last stats line
+	lastMetric *report.FuzzingMetric
+	// The corpus entry of the last NEW or REDUCE stats line, which is
+	// sent once libFuzzer reports the path the entry was written to
+	pendingCorpusEntry *report.CorpusEntry
 }
 
 type Options struct {
@@ -115,6 +129,9 @@ type Options struct {
 	// The directory to which paths in the stack trace are made relative to
 	ProjectDir string
 	SourceMap  *sourcemap.SourceMap
+	// If set, the lines which were printed by the fuzz target itself
+	// are stored in TargetOutput and attached to findings
+	TargetOutput *TargetOutputBuffer
 }
 
 func NewLibfuzzerOutputParser(options *Options) *parser {
@@ -218,8 +235,14 @@ func (p *parser) parseLine(ctx context.Context, line string) error {
 		}
 	}
 
-	metric := p.parseAsFuzzingMetric(line)
+	metric, status := p.parseAsFuzzingMetric(line)
 	if metric != nil {
+		p.pendingCorpusEntry = nil
+		if status == "NEW" || status == "REDUCE" {
+			p.pendingCorpusEntry = newCorpusEntry(p.lastMetric, metric, status == "REDUCE")
+		}
+		p.lastMetric = metric
+
 		r := &report.Report{Metric: metric}
 		if p.initFinished {
 			r.Status = report.RunStatusRunning
@@ -253,6 +276,14 @@ func (p *parser) parseLine(ctx context.Context, line string) error {
 		return nil
 	}
 
+	if p.pendingCorpusEntry != nil {
+		entry, ok := p.parseAsCorpusEntry(line)
+		if ok {
+			p.pendingCorpusEntry = nil
+			return p.sendReport(ctx, &report.Report{CorpusEntry: entry})
+		}
+	}
+
 	finding := p.parseAsNewFinding(line)
 
 	if finding != nil && !p.libFuzzerErrorFollowingGoPanic(finding) {
@@ -272,12 +303,24 @@ func (p *parser) parseLine(ctx context.Context, line string) error {
 		return nil
 	}
 
+	if p.pendingFinding == nil && p.TargetOutput != nil && !isFuzzerOutputLine(line) {
+		// The line was printed by the fuzz target before a crash
+		p.TargetOutput.AddLine(line)
+	}
+
 	if p.pendingFinding != nil {
 		// The line is not a metrics line and doesn't mark a new finding,
 		// so we append it to the pending finding (unless it's filtered)
 		if !minijail.IsIgnoredLine(line) && !p.foundBeginningOfJestReport {
 			p.pendingFinding.Logs = append(p.pendingFinding.Logs, line)
 		}
+
+		// Store the corpus entry which was mutated to produce the
+		// crashing input, if libFuzzer reported it
+		baseUnit, ok := parseAsBaseUnit(line)
+		if ok {
+			p.pendingFinding.ProvenanceInput = baseUnit
+		}
 	}
 
 	// Check if the line contains the path to the test input file (which
@@ -353,6 +396,16 @@ func parseAsTestInputFilePath(logLine string) (string, bool) {
 	return "", false
 }
 
+func parseAsBaseUnit(logLine string) (string, bool) {
+	result, found := regexutil.FindNamedGroupsMatch(baseUnitPattern, logLine)
+	// libFuzzer reports a base unit of all zeros if the input was not
+	// produced by mutating a corpus entry
+	if found && strings.Trim(result["base_unit"], "0") != "" {
+		return result["base_unit"], true
+	}
+	return "", false
+}
+
 func (p *parser) parseAsGoFinding(line string) *finding.Finding {
 	if _, found := regexutil.FindNamedGroupsMatch(goPanicPattern, line); found {
 		return &finding.Finding{
@@ -375,9 +428,10 @@ func (p *parser) parseAsLibfuzzerFinding(line string) *finding.Finding {
 	result, found := regexutil.FindNamedGroupsMatch(libfuzzerTimeoutErrorPattern, line)
 	if found {
 		return &finding.Finding{
-			Type:    finding.ErrorTypeCrash, // aka Vulnerability
-			Details: fmt.Sprintf("timeout after %s seconds", result["timeout_seconds"]),
-			Logs:    []string{line},
+			Type:     finding.ErrorTypeCrash, // aka Vulnerability
+			Details:  fmt.Sprintf("timeout after %s seconds", result["timeout_seconds"]),
+			Category: finding.CategoryTimeout,
+			Logs:     []string{line},
 		}
 	}
 
@@ -400,11 +454,15 @@ func (p *parser) parseAsLibfuzzerFinding(line string) *finding.Finding {
 			return nil
 		}
 
-		return &finding.Finding{
+		f := &finding.Finding{
 			Type:    finding.ErrorTypeCrash, // aka Vulnerability
 			Details: result["error_type"],
 			Logs:    []string{line},
 		}
+		if strings.HasPrefix(result["error_type"], "out-of-memory") {
+			f.Category = finding.CategoryOutOfMemory
+		}
+		return f
 	}
 
 	return nil
@@ -490,15 +548,18 @@ func (p *parser) parseAsJazzerJSFinding(line string) *finding.Finding {
 	return nil
 }
 
-func (p *parser) parseAsFuzzingMetric(line string) *report.FuzzingMetric {
+// parseAsFuzzingMetric parses a libFuzzer stats line. It returns the
+// metric and the status of the line (e.g. "NEW" or "pulse"), or nil if
+// the line is not a stats line.
+func (p *parser) parseAsFuzzingMetric(line string) (*report.FuzzingMetric, string) {
 	if result, found := regexutil.FindNamedGroupsMatch(statsPattern, line); found {
 		totalExecs, err := strconv.ParseUint(result["total_execs"], 10, 64)
 		if err != nil {
-			return nil
+			return nil, ""
 		}
 		features, err := strconv.Atoi(result["features"])
 		if err != nil {
-			return nil
+			return nil, ""
 		}
 
 		var edges int
@@ -507,17 +568,17 @@ func (p *parser) parseAsFuzzingMetric(line string) *report.FuzzingMetric {
 		} else {
 			edges, err = strconv.Atoi(result["edges"])
 			if err != nil {
-				return nil
+				return nil, ""
 			}
 		}
 
 		execsPerSec, err := strconv.Atoi(result["executions_per_second"])
 		if err != nil {
-			return nil
+			return nil, ""
 		}
 		corpusSize, err := strconv.Atoi(result["corpus_size"])
 		if err != nil {
-			return nil
+			return nil, ""
 		}
 		now := time.Now()
 		var secondsSinceLastFeature uint64
@@ -553,9 +614,40 @@ func (p *parser) parseAsFuzzingMetric(line string) *report.FuzzingMetric {
 			TotalExecutions:         totalExecs,
 			SecondsSinceLastFeature: secondsSinceLastFeature,
 			SecondsSinceLastEdge:    secondsSinceLastEdge,
-		}
+		}, result["status"]
 	}
-	return nil
+	return nil, ""
+}
+
+// newCorpusEntry returns the corpus entry of a NEW or REDUCE stats line
+// with the coverage increase compared to the previous stats line. The
+// path and size are set once libFuzzer reports where it wrote the entry.
+func newCorpusEntry(previous, metric *report.FuzzingMetric, reduced bool) *report.CorpusEntry {
+	entry := &report.CorpusEntry{Reduced: reduced, Metric: metric}
+	if previous == nil {
+		entry.NewEdges, entry.NewFeatures = metric.Edges, metric.Features
+	} else {
+		entry.NewEdges = max(metric.Edges-previous.Edges, 0)
+		entry.NewFeatures = max(metric.Features-previous.Features, 0)
+	}
+	return entry
+}
+
+// parseAsCorpusEntry completes the pending corpus entry if the line
+// reports the path the entry was written to.
+func (p *parser) parseAsCorpusEntry(line string) (*report.CorpusEntry, bool) {
+	result, found := regexutil.FindNamedGroupsMatch(corpusEntryPattern, line)
+	if !found {
+		return nil, false
+	}
+	size, err := strconv.ParseInt(result["size"], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	entry := p.pendingCorpusEntry
+	entry.Path = result["path"]
+	entry.Size = size
+	return entry, true
 }
 
 func parseAsSlowInput(log string) *finding.Finding {
@@ -645,6 +737,10 @@ func (p *parser) finalizeAndSendPendingFinding(ctx context.Context) error {
 		ID: errorid.ForFinding(p.pendingFinding),
 	}
 
+	if p.TargetOutput != nil {
+		p.pendingFinding.TargetOutput = p.TargetOutput.Lines()
+	}
+
 	err = p.sendFinding(ctx, p.pendingFinding)
 	if err != nil {
 		return err
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -749,10 +750,12 @@ SUMMARY: libFuzzer: timeout`,
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeCrash,
-						Details:   "timeout after 1 seconds",
-						InputData: testInput,
-						InputFile: testInputFile.Name(),
+						Type:            finding.ErrorTypeCrash,
+						Details:         "timeout after 1 seconds",
+						Category:        finding.CategoryTimeout,
+						InputData:       testInput,
+						InputFile:       testInputFile.Name(),
+						ProvenanceInput: "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc",
 						Logs: []string{
 							"ALARM: working on the last Unit for 1 seconds",
 							"       and the timeout value is 1 (use -timeout=N to change)",
@@ -804,10 +807,11 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeWarning,
-						Details:   "Error: Crash!",
-						InputData: testInput,
-						InputFile: testInputFile.Name(),
+						Type:            finding.ErrorTypeWarning,
+						Details:         "Error: Crash!",
+						InputData:       testInput,
+						InputFile:       testInputFile.Name(),
+						ProvenanceInput: "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc",
 						Logs: []string{
 							"==126471== Uncaught Exception: Error: Crash!",
 							"    at unhandled-exception/UnhandledException.fuzz.js:3:9",
@@ -845,10 +849,11 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeWarning,
-						Details:   "Command Injection",
-						InputData: testInput,
-						InputFile: testInputFile.Name(),
+						Type:            finding.ErrorTypeWarning,
+						Details:         "Command Injection",
+						InputData:       testInput,
+						InputFile:       testInputFile.Name(),
+						ProvenanceInput: "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc",
 						Logs: []string{
 							"==126471== Command Injection in exec(); called with 'jazzer'",
 							"    at command-injection/CommandInjection.fuzz.js:3:9",
@@ -886,10 +891,11 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeWarning,
-						Details:   "Path Traversal",
-						InputData: testInput,
-						InputFile: testInputFile.Name(),
+						Type:            finding.ErrorTypeWarning,
+						Details:         "Path Traversal",
+						InputData:       testInput,
+						InputFile:       testInputFile.Name(),
+						ProvenanceInput: "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc",
 						Logs: []string{
 							"==126471== Path Traversal in openSync(): called with 'jazzer'",
 							"    at path-traversal/PathTraversal.fuzz.js:3:9",
@@ -926,10 +932,11 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeWarning,
-						Details:   "Prototype Pollution",
-						InputData: testInput,
-						InputFile: testInputFile.Name(),
+						Type:            finding.ErrorTypeWarning,
+						Details:         "Prototype Pollution",
+						InputData:       testInput,
+						InputFile:       testInputFile.Name(),
+						ProvenanceInput: "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc",
 						Logs: []string{
 							"==126471== Prototype Pollution: Prototype of Object changed.",
 							`MS: 4 InsertByte-CMP-ShuffleBytes-CMP- DE: "\000\000"-"Fuzz"-; base unit: adc83b19e793491b1c6ea0fd8b46cd9f32e592fc`,
@@ -957,10 +964,12 @@ Base64: RnV6eg==`, testInputFile.Name()),
 				{
 					Status: report.RunStatusRunning,
 					Finding: &finding.Finding{
-						Type:      finding.ErrorTypeCrash,
-						Details:   "timeout after 1 seconds",
-						InputData: testInput,
-						InputFile: testInputFile.Name(),
+						Type:            finding.ErrorTypeCrash,
+						Details:         "timeout after 1 seconds",
+						Category:        finding.CategoryTimeout,
+						InputData:       testInput,
+						InputFile:       testInputFile.Name(),
+						ProvenanceInput: "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc",
 						Logs: []string{
 							"ALARM: working on the last Unit for 1 seconds",
 							"       and the timeout value is 1 (use -timeout=N to change)",
@@ -1043,6 +1052,7 @@ func TestBufferOverflowCrashLogs(t *testing.T) {
 	assertCorrectCrashesParsing(t,
 		"global-buffer-overflow on address 0x00",
 		"global_buffer_overflow",
+		"",
 		expectedCrashFile.Name(),
 		testInput,
 		[]string{
@@ -1065,6 +1075,7 @@ func TestOOMCrashLogs(t *testing.T) {
 	assertCorrectCrashesParsing(t,
 		"out-of-memory (used: 251Mb; limit: 250Mb)",
 		"out_of_memory",
+		finding.CategoryOutOfMemory,
 		expectedCrashFile.Name(),
 		testInput,
 		[]string{
@@ -1075,7 +1086,7 @@ func TestOOMCrashLogs(t *testing.T) {
 		})
 }
 
-func assertCorrectCrashesParsing(t *testing.T, errorDetails, errorID, crashFile string, crashingInput []byte, logs []string) {
+func assertCorrectCrashesParsing(t *testing.T, errorDetails, errorID string, category finding.Category, crashFile string, crashingInput []byte, logs []string) {
 	expectedReports := []*report.Report{
 		{
 			Status: report.RunStatusRunning,
@@ -1088,6 +1099,7 @@ func assertCorrectCrashesParsing(t *testing.T, errorDetails, errorID, crashFile
 				MoreDetails: &finding.ErrorDetails{
 					ID: errorID,
 				},
+				Category: category,
 			},
 		},
 	}
@@ -1141,3 +1153,94 @@ func removeTimestamps(r *report.Report) {
 		r.Metric.Timestamp = time.Time{}
 	}
 }
+
+func TestParseAsBaseUnit(t *testing.T) {
+	baseUnit, ok := parseAsBaseUnit("MS: 1 ChangeBit-; base unit: adc83b19e793491b1c6ea0fd8b46cd9f32e592fc")
+	require.True(t, ok)
+	assert.Equal(t, "adc83b19e793491b1c6ea0fd8b46cd9f32e592fc", baseUnit)
+
+	_, ok = parseAsBaseUnit("MS: 0 ; base unit: 0000000000000000000000000000000000000000")
+	assert.False(t, ok)
+
+	_, ok = parseAsBaseUnit("==8141==ERROR: AddressSanitizer: global-buffer-overflow on address 0x00")
+	assert.False(t, ok)
+}
+
+func TestCorpusEntries(t *testing.T) {
+	reporter := NewLibfuzzerOutputParser(nil)
+	reportsCh := make(chan *report.Report, maxBufferedReports)
+	input := strings.Join([]string{
+		"#2\tINITED cov: 10 ft: 20 corp: 1/1b exec/s: 0 rss: 26Mb",
+		"#5\tNEW    cov: 12 ft: 25 corp: 2/5b lim: 4 exec/s: 0 rss: 26Mb L: 4/4 MS: 1 ChangeBit-",
+		"Written 4 bytes to corpus/a94a8fe5ccb19ba61c4c0873d391e987982fbbd3",
+		"#9\tREDUCE cov: 12 ft: 25 corp: 2/4b lim: 4 exec/s: 0 rss: 26Mb L: 3/3 MS: 1 EraseBytes-",
+		"Written 3 bytes to corpus/62cdb7020ff920e5aa642c3d4066950dd1f01f4d",
+		"#16\tpulse  cov: 12 ft: 25 corp: 2/4b lim: 4 exec/s: 0 rss: 26Mb",
+		// Only lines following a NEW or REDUCE line are corpus entries
+		"Written 5 bytes to corpus/2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+	}, "\n")
+	err := reporter.Parse(context.Background(), strings.NewReader(input), reportsCh)
+	require.NoError(t, err)
+
+	var entries []*report.CorpusEntry
+	for r := range reportsCh {
+		if r.CorpusEntry != nil {
+			entries = append(entries, r.CorpusEntry)
+		}
+	}
+	require.Len(t, entries, 2)
+	assert.Equal(t, "corpus/a94a8fe5ccb19ba61c4c0873d391e987982fbbd3", entries[0].Path)
+	assert.Equal(t, int64(4), entries[0].Size)
+	assert.False(t, entries[0].Reduced)
+	assert.Equal(t, int32(2), entries[0].NewEdges)
+	assert.Equal(t, int32(5), entries[0].NewFeatures)
+	assert.Equal(t, int32(12), entries[0].Metric.Edges)
+	assert.Equal(t, "corpus/62cdb7020ff920e5aa642c3d4066950dd1f01f4d", entries[1].Path)
+	assert.Equal(t, int64(3), entries[1].Size)
+	assert.True(t, entries[1].Reduced)
+	assert.Equal(t, int32(0), entries[1].NewEdges)
+	assert.Equal(t, int32(0), entries[1].NewFeatures)
+}
+
+func TestTargetOutput(t *testing.T) {
+	targetOutput := NewTargetOutputBuffer()
+	// Output printed by the fuzz target to stdout
+	_, err := io.WriteString(targetOutput, "stdout line\npartial")
+	require.NoError(t, err)
+
+	reporter := NewLibfuzzerOutputParser(&Options{TargetOutput: targetOutput})
+	reporter.initFinished = true
+	reportsCh := make(chan *report.Report, maxBufferedReports)
+	input := strings.Join([]string{
+		"INFO: Seed: 1234",
+		"#2\tINITED cov: 2 ft: 2 corp: 1/1b exec/s: 0 rss: 26Mb",
+		"stderr line",
+		"==8141==ERROR: AddressSanitizer: heap-use-after-free on address 0x00",
+		"error info 1",
+	}, "\n")
+	err = reporter.Parse(context.Background(), strings.NewReader(input), reportsCh)
+	require.NoError(t, err)
+
+	var f *finding.Finding
+	for r := range reportsCh {
+		if r.Finding != nil {
+			f = r.Finding
+		}
+	}
+	require.NotNil(t, f)
+	assert.Equal(t, []string{"stdout line", "stderr line", "partial"}, f.TargetOutput)
+	assert.Equal(t, []string{
+		"==8141==ERROR: AddressSanitizer: heap-use-after-free on address 0x00",
+		"error info 1",
+	}, f.Logs)
+}
+
+func TestTargetOutputBuffer_MaxLines(t *testing.T) {
+	b := NewTargetOutputBuffer()
+	for i := 0; i < MaxTargetOutputLines+10; i++ {
+		b.AddLine(strconv.Itoa(i))
+	}
+	lines := b.Lines()
+	require.Len(t, lines, MaxTargetOutputLines)
+	assert.Equal(t, "10", lines[0])
+}
@@ -0,0 +1,37 @@
+package stacktrace
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matches stack frames which were symbolized without source locations,
+// e.g. "#1 0x55d3 in LLVMFuzzerTestOneInput (/path/to/fuzz_test+0x55d3)"
+// or "#1 0x55d3 (/path/to/fuzz_test+0x55d3)"
+var frameWithoutSourceLocationPattern = regexp.MustCompile(`#\d+\s+0x[a-fA-F0-9]+\s+(in\s+\S+\s+)?\(\S+\+0x[a-fA-F0-9]+\)`)
+
+// MissingDebugInfo returns true if the stack trace of a libFuzzer
+// finding indicates that the fuzz test was built without debug info,
+// i.e. if any of the parsed stack frames lacks a line number or a
+// function name, or if the frame of the fuzz test function in the logs
+// has no source location.
+//
+// Frames of system libraries commonly have no source locations, so
+// only the frame of the fuzz test function is checked in the logs.
+func MissingDebugInfo(logs []string, frames []*StackFrame) bool {
+	for _, frame := range frames {
+		if frame.Line == 0 || frame.Function == "" || frame.Function == "??" {
+			return true
+		}
+	}
+
+	for _, line := range logs {
+		if !frameWithoutSourceLocationPattern.MatchString(line) {
+			continue
+		}
+		if strings.Contains(line, " LLVMFuzzerTestOneInput") {
+			return true
+		}
+	}
+	return false
+}
@@ -276,3 +276,41 @@ func TestEncodeStackTrace(t *testing.T) {
 	// should countain (27 chars + 15 separators)
 	assert.Len(t, result, 42)
 }
+
+func TestMissingDebugInfo(t *testing.T) {
+	testCases := map[string]struct {
+		logs     []string
+		frames   []*StackFrame
+		expected bool
+	}{
+		"with debug info": {
+			logs: []string{
+				"#0 0x55d3 in parse /src/parser.cpp:12:3",
+				"#1 0x55f0 in LLVMFuzzerTestOneInputNoReturn /src/parser_fuzz_test.cpp:8:3",
+				"#2 0x7f12 in __libc_start_main (/lib/x86_64-linux-gnu/libc.so.6+0x29d8f)",
+			},
+			frames: []*StackFrame{
+				{SourceFile: "src/parser.cpp", Line: 12, Column: 3, Function: "parse"},
+				{SourceFile: "src/parser_fuzz_test.cpp", Line: 8, Column: 3, Function: "LLVMFuzzerTestOneInputNoReturn"},
+			},
+			expected: false,
+		},
+		"fuzz test frame without source location": {
+			logs: []string{
+				"#0 0x55d3 in parse (/build/parser_fuzz_test+0x55d3)",
+				"#1 0x55f0 in LLVMFuzzerTestOneInput (/build/parser_fuzz_test+0x55f0)",
+			},
+			expected: true,
+		},
+		"frame without line number": {
+			frames:   []*StackFrame{{SourceFile: "src/parser.cpp", Function: "parse"}},
+			expected: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MissingDebugInfo(tc.logs, tc.frames))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package libfuzzer
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// MaxTargetOutputLines is the number of lines of the fuzz target's own
+// output which are stored in a finding
+const MaxTargetOutputLines = 100
+
+// Prefixes of lines which are printed by libFuzzer itself and are
+// therefore not part of the fuzz target's output
+var fuzzerOutputPrefixes = []string{
+	"INFO: ",
+	"WARNING: ",
+	"MS: ",
+	"#",
+	"Done ",
+	"Running: ",
+	"Executed ",
+	"Dictionary: ",
+	"base unit: ",
+	"stat::",
+	"==",
+}
+
+// TargetOutputBuffer stores the last lines printed by the fuzz target
+// itself. It is written to concurrently by the runner (which writes the
+// stdout of the fuzz target) and the parser (which writes the lines
+// of stderr which were not printed by libFuzzer).
+type TargetOutputBuffer struct {
+	mutex   sync.Mutex
+	lines   []string
+	partial []byte
+}
+
+func NewTargetOutputBuffer() *TargetOutputBuffer {
+	return &TargetOutputBuffer{}
+}
+
+// Write implements io.Writer. Incomplete lines are buffered until the
+// newline is written.
+func (b *TargetOutputBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		b.addLine(string(b.partial[:i]))
+		b.partial = b.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// AddLine adds a complete line to the buffer
+func (b *TargetOutputBuffer) AddLine(line string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.addLine(line)
+}
+
+func (b *TargetOutputBuffer) addLine(line string) {
+	b.lines = append(b.lines, strings.TrimSuffix(line, "\r"))
+	if len(b.lines) > MaxTargetOutputLines {
+		b.lines = b.lines[len(b.lines)-MaxTargetOutputLines:]
+	}
+}
+
+// Lines returns the stored lines, including a trailing incomplete line
+func (b *TargetOutputBuffer) Lines() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	lines := append([]string{}, b.lines...)
+	if len(b.partial) > 0 {
+		lines = append(lines, string(b.partial))
+	}
+	if len(lines) > MaxTargetOutputLines {
+		lines = lines[len(lines)-MaxTargetOutputLines:]
+	}
+	return lines
+}
+
+func isFuzzerOutputLine(line string) bool {
+	for _, prefix := range fuzzerOutputPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
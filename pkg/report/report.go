@@ -17,6 +17,22 @@ type Report struct {
 	NumSeeds        uint             `json:"num_seeds,omitempty"`
 	SeedCorpus      string           `json:"seed_corpus,omitempty"`
 	GeneratedCorpus string           `json:"generated_corpus,omitempty"`
+	CorpusEntry     *CorpusEntry     `json:"corpus_entry,omitempty"`
+}
+
+// CorpusEntry is an input which the fuzzer added to the generated
+// corpus, either because it covered new features (NEW) or because it
+// covers the features of an existing input with a smaller size
+// (REDUCE).
+type CorpusEntry struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Reduced bool   `json:"reduced,omitempty"`
+	// The edges and features which were new when the entry was added
+	NewEdges    int32 `json:"new_edges"`
+	NewFeatures int32 `json:"new_features"`
+	// The metric which the fuzzer reported when it added the entry
+	Metric *FuzzingMetric `json:"metric,omitempty"`
 }
 
 func (x *Report) GetFinding() *finding.Finding {
@@ -0,0 +1,484 @@
+package afl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"code-intelligence.com/cifuzz/internal/cmdutils"
+	"code-intelligence.com/cifuzz/pkg/finding"
+	"code-intelligence.com/cifuzz/pkg/log"
+	libfuzzer_parser "code-intelligence.com/cifuzz/pkg/parser/libfuzzer"
+	"code-intelligence.com/cifuzz/pkg/report"
+	fuzzer_runner "code-intelligence.com/cifuzz/pkg/runner"
+	"code-intelligence.com/cifuzz/util/envutil"
+	"code-intelligence.com/cifuzz/util/executil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+)
+
+const (
+	// The interval in which the crashes directory of AFL++ is checked
+	// for new crashing inputs
+	pollInterval = time.Second
+	// The time we give a crashing input to reproduce the crash
+	reproduceTimeout = time.Minute
+	// ExitGracePeriod is the time we give afl-fuzz to exit after the
+	// timeout passed via -V was exceeded.
+	ExitGracePeriod = time.Second * 10
+)
+
+// binaryMarkers are symbols which are only contained in binaries which
+// were instrumented by the AFL++ compilers.
+var binaryMarkers = [][]byte{
+	[]byte("__AFL_SHM_ID"),
+	[]byte("__afl_area_ptr"),
+}
+
+type RunnerOptions struct {
+	EngineArgs         []string
+	EnvVars            []string
+	FuzzTarget         string
+	GeneratedCorpusDir string
+	KeepColor          bool
+	LibraryDirs        []string
+	LogOutput          io.Writer
+	ProjectDir         string
+	ReportHandler      report.Handler
+	SeedCorpusDirs     []string
+	Timeout            time.Duration
+	Verbose            bool
+	// If true, the fuzzer command and environment are printed before
+	// the fuzzer is started, regardless of the verbosity
+	PrintCommand bool
+	// The path to the llvm-symbolizer which the sanitizers use to
+	// symbolize the stack traces of reproduced crashes. If empty, the
+	// llvm-symbolizer found in the runfiles is used.
+	Symbolizer string
+	// The directory in which the crashing inputs are stored. The output
+	// directory of afl-fuzz is removed when the run finishes, so the
+	// findings refer to the copies in this directory. If empty, a
+	// temporary directory is created, which is not removed.
+	CrashesDir string
+}
+
+func (options *RunnerOptions) ValidateOptions() error {
+	if options.LogOutput == nil {
+		options.LogOutput = os.Stderr
+	}
+	return nil
+}
+
+type Runner struct {
+	*RunnerOptions
+
+	started chan struct{}
+	cmd     *executil.Cmd
+	// The crashing inputs which were already reproduced and reported
+	reported map[string]bool
+}
+
+func NewRunner(options *RunnerOptions) *Runner {
+	return &Runner{
+		RunnerOptions: options,
+		started:       make(chan struct{}, 1),
+		reported:      make(map[string]bool),
+	}
+}
+
+// IsAFLBinary returns true if the executable was instrumented by one of
+// the AFL++ compilers.
+func IsAFLBinary(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	for _, marker := range binaryMarkers {
+		if bytes.Contains(content, marker) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	err := r.ValidateOptions()
+	if err != nil {
+		return err
+	}
+
+	aflFuzz, err := exec.LookPath("afl-fuzz")
+	if err != nil {
+		return errors.New("afl-fuzz not found in PATH, please install AFL++ to use the AFL++ engine")
+	}
+
+	// AFL++ only supports a single input directory, so we collect the
+	// seeds and the generated corpus in a temporary directory
+	inputDir, err := os.MkdirTemp("", "afl-in-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(inputDir)
+	err = r.collectInputs(inputDir)
+	if err != nil {
+		return err
+	}
+
+	outputDir, err := os.MkdirTemp("", "afl-out-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fileutil.Cleanup(outputDir)
+
+	args := []string{aflFuzz, "-i", inputDir, "-o", outputDir}
+	// Tell afl-fuzz to exit after the timeout
+	if r.Timeout > 0 {
+		args = append(args, "-V", strconv.FormatInt(int64(r.Timeout.Seconds()), 10))
+	}
+	// Add user-specified afl-fuzz options
+	args = append(args, r.EngineArgs...)
+	args = append(args, "--", r.FuzzTarget, "@@")
+
+	env, err := r.FuzzerEnvironment()
+	if err != nil {
+		return err
+	}
+
+	var cmdCtx context.Context
+	var cancelCmdCtx context.CancelFunc
+	if r.Timeout > 0 {
+		cmdCtx, cancelCmdCtx = context.WithTimeout(ctx, r.Timeout+ExitGracePeriod)
+	} else {
+		cmdCtx, cancelCmdCtx = context.WithCancel(ctx)
+	}
+	defer cancelCmdCtx()
+	r.cmd = executil.CommandContext(cmdCtx, args[0], args[1:]...)
+	r.cmd.Env, err = envutil.Copy(os.Environ(), env)
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	if r.Verbose {
+		ptermWriter := log.NewPTermWriter(r.LogOutput)
+		r.cmd.Stdout = ptermWriter
+		r.cmd.Stderr = ptermWriter
+	} else {
+		r.cmd.Stdout = &output
+		r.cmd.Stderr = &output
+	}
+
+	if r.PrintCommand {
+		log.Printf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, envutil.RedactSecrets(env)))
+	} else {
+		log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, env))
+	}
+	err = r.cmd.Start()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r.started <- struct{}{}
+
+	// AFL++ uses the "default" instance name if none was specified
+	crashesDir := filepath.Join(outputDir, "default", "crashes")
+
+	routines, routinesCtx := errgroup.WithContext(ctx)
+	waitDone := make(chan struct{})
+	routines.Go(func() error {
+		defer close(waitDone)
+		err := r.cmd.Wait()
+		if err == nil || r.cmd.TerminatedAfterContextDone() {
+			return nil
+		}
+		if !r.Verbose {
+			log.Print(output.String())
+		}
+		return cmdutils.WrapExecError(errors.WithStack(err), r.cmd.Cmd)
+	})
+
+	// Report crashing inputs while afl-fuzz is running
+	routines.Go(func() error {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-routinesCtx.Done():
+				return nil
+			case <-waitDone:
+				// Report the crashes which were found since the last check
+				return r.reportNewCrashes(ctx, crashesDir)
+			case <-ticker.C:
+				err := r.reportNewCrashes(routinesCtx, crashesDir)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+
+	err = routines.Wait()
+	if err != nil {
+		// nolint: wrapcheck
+		return err
+	}
+
+	// Store the queue of afl-fuzz in the generated corpus, so that the
+	// inputs are used by subsequent runs
+	return r.copyQueue(filepath.Join(outputDir, "default", "queue"))
+}
+
+func (r *Runner) collectInputs(inputDir string) error {
+	var n int
+	for _, dir := range append([]string{r.GeneratedCorpusDir}, r.SeedCorpusDirs...) {
+		files, err := inputFiles(dir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			err = os.WriteFile(filepath.Join(inputDir, strconv.Itoa(n)), content, 0o644)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			n++
+		}
+	}
+
+	// afl-fuzz refuses to start with an empty input directory
+	if n == 0 {
+		err := os.WriteFile(filepath.Join(inputDir, "empty"), []byte("\n"), 0o644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) copyQueue(queueDir string) error {
+	if r.GeneratedCorpusDir == "" {
+		return nil
+	}
+	files, err := inputFiles(queueDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		// Use the same naming scheme as libFuzzer, so that inputs which
+		// are already contained in the corpus are not duplicated
+		path := filepath.Join(r.GeneratedCorpusDir, fmt.Sprintf("%x", sha1.Sum(content)))
+		err = os.WriteFile(path, content, 0o644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// inputFiles returns the regular files in the directory, sorted by
+// name. If the directory does not exist, no files are returned.
+func inputFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var files []string
+	for _, entry := range entries {
+		// AFL++ stores a README.txt in the crashes directory
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), "README") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (r *Runner) reportNewCrashes(ctx context.Context, crashesDir string) error {
+	files, err := inputFiles(crashesDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if r.reported[file] {
+			continue
+		}
+		r.reported[file] = true
+
+		f, err := r.reproduceCrash(ctx, file)
+		if err != nil {
+			return err
+		}
+		err = r.ReportHandler.Handle(&report.Report{
+			Status:  report.RunStatusRunning,
+			Finding: f,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reproduceCrash runs the fuzz target on the crashing input to obtain
+// the sanitizer output, which AFL++ doesn't provide, and parses it
+// into a finding.
+func (r *Runner) reproduceCrash(ctx context.Context, inputFile string) (*finding.Finding, error) {
+	inputData, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	env, err := r.ReproducerEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, reproduceTimeout)
+	defer cancel()
+	cmd := executil.CommandContext(cmdCtx, r.FuzzTarget, inputFile)
+	cmd.Env, err = envutil.Copy(os.Environ(), env)
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	targetOutput := libfuzzer_parser.NewTargetOutputBuffer()
+	cmd.Stdout = targetOutput
+	cmd.Stderr = &output
+	log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(cmd.Args, env))
+	// The command is expected to fail, the output tells us why
+	_ = cmd.Run()
+
+	parser := libfuzzer_parser.NewLibfuzzerOutputParser(&libfuzzer_parser.Options{
+		KeepColor:    r.KeepColor,
+		ProjectDir:   r.ProjectDir,
+		TargetOutput: targetOutput,
+	})
+	reportsCh := make(chan *report.Report, 100)
+	err = parser.Parse(ctx, bytes.NewReader(output.Bytes()), reportsCh)
+	if err != nil {
+		return nil, err
+	}
+
+	var f *finding.Finding
+	for report := range reportsCh {
+		if report.Finding != nil && f == nil {
+			f = report.Finding
+		}
+	}
+	if f == nil {
+		// The crash is not reproducible outside of afl-fuzz or the
+		// target crashed without sanitizer output
+		f = &finding.Finding{
+			Type:         finding.ErrorTypeCrash,
+			Details:      "Crash reported by AFL++",
+			Logs:         strings.Split(strings.TrimRight(output.String(), "\n"), "\n"),
+			TargetOutput: targetOutput.Lines(),
+		}
+	}
+	f.InputData = inputData
+	f.InputFile, err = r.storeCrashingInput(inputData)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// storeCrashingInput copies the crashing input to the crashes directory,
+// because the output directory of afl-fuzz is removed when the run
+// finishes. The input is named like the crash files of libFuzzer, since
+// the names AFL++ uses contain colons, which are not valid in file
+// names on Windows.
+func (r *Runner) storeCrashingInput(inputData []byte) (string, error) {
+	var err error
+	if r.CrashesDir == "" {
+		r.CrashesDir, err = os.MkdirTemp("", "afl-crashes-")
+	} else {
+		err = os.MkdirAll(r.CrashesDir, 0o755)
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	path := filepath.Join(r.CrashesDir, fmt.Sprintf("crash-%x", sha1.Sum(inputData)))
+	err = os.WriteFile(path, inputData, 0o644)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// FuzzerEnvironment returns the environment in which afl-fuzz is run.
+// We don't set any sanitizer options here, because afl-fuzz requires
+// specific values which it sets itself.
+func (r *Runner) FuzzerEnvironment() ([]string, error) {
+	env, err := envutil.Setenv(nil, "AFL_NO_UI", "1")
+	if err != nil {
+		return nil, err
+	}
+	env, err = fuzzer_runner.SetLDLibraryPath(env, r.LibraryDirs)
+	if err != nil {
+		return nil, err
+	}
+	return fuzzer_runner.AddEnvFlags(env, r.EnvVars)
+}
+
+// ReproducerEnvironment returns the environment in which crashing
+// inputs are reproduced to obtain symbolized stack traces.
+func (r *Runner) ReproducerEnvironment() ([]string, error) {
+	env, err := fuzzer_runner.FuzzerEnvironment(r.Symbolizer)
+	if err != nil {
+		return nil, err
+	}
+	env, err = fuzzer_runner.SetLDLibraryPath(env, r.LibraryDirs)
+	if err != nil {
+		return nil, err
+	}
+	env, err = fuzzer_runner.AddEnvFlags(env, r.EnvVars)
+	if err != nil {
+		return nil, err
+	}
+	env, err = fuzzer_runner.SetCommonUBSANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+	env, err = fuzzer_runner.SetCommonASANOptions(env)
+	if err != nil {
+		return nil, err
+	}
+	return fuzzer_runner.SetASANOptions(env, nil, map[string]string{"abort_on_error": "0"})
+}
+
+func (r *Runner) Cleanup(ctx context.Context) {
+	// Wait until the command has been started, else we can't terminate it
+	select {
+	case <-ctx.Done():
+		return
+	case <-r.started:
+		err := r.cmd.TerminateProcessGroup()
+		if err != nil {
+			log.Error(err)
+		}
+	}
+}
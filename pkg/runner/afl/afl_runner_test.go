@@ -0,0 +1,60 @@
+package afl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAFLBinary(t *testing.T) {
+	dir := t.TempDir()
+
+	aflBinary := filepath.Join(dir, "afl_fuzz_test")
+	err := os.WriteFile(aflBinary, []byte("\x7fELF\x00__afl_area_ptr\x00"), 0o755)
+	require.NoError(t, err)
+	isAFLBinary, err := IsAFLBinary(aflBinary)
+	require.NoError(t, err)
+	assert.True(t, isAFLBinary)
+
+	libfuzzerBinary := filepath.Join(dir, "libfuzzer_fuzz_test")
+	err = os.WriteFile(libfuzzerBinary, []byte("\x7fELF\x00LLVMFuzzerTestOneInput\x00"), 0o755)
+	require.NoError(t, err)
+	isAFLBinary, err = IsAFLBinary(libfuzzerBinary)
+	require.NoError(t, err)
+	assert.False(t, isAFLBinary)
+}
+
+func TestInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"README.txt", "id:000001,sig:06", "id:000000,sig:11"} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".state"), 0o755))
+
+	files, err := inputFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "id:000000,sig:11"),
+		filepath.Join(dir, "id:000001,sig:06"),
+	}, files)
+
+	files, err = inputFiles(filepath.Join(dir, "does-not-exist"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestStoreCrashingInput(t *testing.T) {
+	crashesDir := filepath.Join(t.TempDir(), "crashes")
+	r := NewRunner(&RunnerOptions{CrashesDir: crashesDir})
+
+	path, err := r.storeCrashingInput([]byte("crash"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(crashesDir, "crash-2fc7f1452374b6e341d67717f032abbe0da0f4a6"), path)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "crash", string(content))
+}
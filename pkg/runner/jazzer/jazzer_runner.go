@@ -31,6 +31,9 @@ type RunnerOptions struct {
 	TargetMethod                  string
 	ClassPaths                    []string
 	InstrumentationPackageFilters []string
+	// Directories which contain native libraries loaded by the fuzz
+	// test via JNI
+	NativeLibPaths []string
 }
 
 func (options *RunnerOptions) ValidateOptions() error {
@@ -101,6 +104,10 @@ func (r *Runner) Run(ctx context.Context) error {
 		"-XX:+EnableDynamicAgentLoading",
 	)
 
+	if len(r.NativeLibPaths) > 0 {
+		args = append(args, options.JavaLibraryPathFlag(r.NativeLibPaths))
+	}
+
 	// Jazzer main class
 	args = append(args, options.JazzerMainClass)
 
@@ -158,6 +165,14 @@ func (r *Runner) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if len(r.NativeLibPaths) > 0 {
+		// Native libraries loaded by the fuzz test might depend on
+		// other libraries from the same directories
+		env, err = fuzzer_runner.SetNativeLibraryPath(env, r.NativeLibPaths)
+		if err != nil {
+			return err
+		}
+	}
 
 	return r.RunLibfuzzerAndReport(ctx, args, env)
 }
@@ -35,5 +35,7 @@ func TestMain(m *testing.M) {
 	}
 
 	defer fileutil.Cleanup(baseTempDir)
+	// Removes the installer lock file from the project dir
+	defer builder.Cleanup()
 	m.Run()
 }
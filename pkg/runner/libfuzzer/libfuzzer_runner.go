@@ -61,6 +61,21 @@ type RunnerOptions struct {
 	CoverageBinary      string
 	CoverageLibraryDirs []string
 	CoverageOutputPath  string
+	// If true, the fuzzer command and environment are printed before
+	// the fuzzer is started, regardless of the verbosity
+	PrintCommand bool
+	// The path to the llvm-symbolizer which the sanitizers use to
+	// symbolize stack traces. If empty, the llvm-symbolizer found
+	// in the runfiles is used.
+	Symbolizer string
+	// The value of the ASan option detect_odr_violation, which is set
+	// to "0" to disable the detection of ODR violations. If empty, the
+	// value from ASAN_OPTIONS or ASan's default is used.
+	DetectODRViolation string
+	// The path to a binary which is executed with the path to the fuzz
+	// target and the libFuzzer arguments instead of executing the fuzz
+	// target directly. If empty, the fuzz target is executed directly.
+	RunnerBinary string
 }
 
 func (options *RunnerOptions) ValidateOptions() error {
@@ -80,6 +95,17 @@ func (options *RunnerOptions) ValidateOptions() error {
 		if err != nil {
 			return errors.WithStack(err)
 		}
+
+		if options.RunnerBinary != "" {
+			options.RunnerBinary, err = filepath.EvalSymlinks(options.RunnerBinary)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			options.RunnerBinary, err = filepath.Abs(options.RunnerBinary)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
 	}
 
 	if options.LogOutput == nil {
@@ -115,6 +141,11 @@ func (r *Runner) Run(ctx context.Context) error {
 
 	args := []string{r.FuzzTarget}
 
+	// Execute the fuzz target via the user-specified runner binary
+	if r.RunnerBinary != "" {
+		args = []string{r.RunnerBinary, r.FuzzTarget}
+	}
+
 	// Tell libfuzzer to exit after the timeout
 	timeoutSeconds := strconv.FormatInt(int64(r.Timeout.Seconds()), 10)
 	args = append(args, options.LibFuzzerMaxTotalTimeFlag(timeoutSeconds))
@@ -161,6 +192,11 @@ func (r *Runner) Run(ctx context.Context) error {
 			{Source: r.GeneratedCorpusDir, Writable: minijail.ReadWrite},
 		}
 
+		// The runner binary must be accessible
+		if r.RunnerBinary != "" {
+			bindings = append(bindings, &minijail.Binding{Source: r.RunnerBinary})
+		}
+
 		for _, dir := range r.ReadOnlyBindings {
 			bindings = append(bindings, &minijail.Binding{Source: dir})
 		}
@@ -212,6 +248,10 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 		return err
 	}
 
+	// The stdout of the fuzz target is stored separately, because
+	// libFuzzer only prints to stderr
+	targetOutput := libfuzzer_parser.NewTargetOutputBuffer()
+
 	var stderrPipe io.ReadCloser
 	if r.Verbose {
 		// Print the command's stdout and stderr via pterm to avoid that
@@ -221,7 +261,7 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 		// stderr, which is what we want, because we only want reports
 		// printed to stdout.
 		ptermWriter := log.NewPTermWriter(r.LogOutput)
-		r.cmd.Stdout = ptermWriter
+		r.cmd.Stdout = io.MultiWriter(ptermWriter, targetOutput)
 
 		// Write the command's stderr to both a pipe and the pterm
 		// writer which prints it to stderr, so that we can parse the
@@ -247,9 +287,14 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 		if err != nil {
 			return err
 		}
+		r.cmd.Stdout = targetOutput
 	}
 
-	log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, env))
+	if r.PrintCommand {
+		log.Printf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, envutil.RedactSecrets(env)))
+	} else {
+		log.Debugf("Command: %s", envutil.QuotedCommandWithEnv(r.cmd.Args, env))
+	}
 	err = r.cmd.Start()
 	if err != nil {
 		return err
@@ -270,6 +315,7 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 		StartupOutputWriter: startupOutputWriter,
 		ProjectDir:          r.ProjectDir,
 		SourceMap:           r.SourceMap,
+		TargetOutput:        targetOutput,
 	})
 	reportsCh := make(chan *report.Report, MaxBufferedReports)
 
@@ -312,7 +358,7 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 				return err
 			}
 
-			if !IsExpectedExitError(err) {
+			if !IsExpectedExitError(err, r.EngineArgs) {
 				// Print the stderr output of the fuzzer up to the point where
 				// it has been successfully initialized to provide users with
 				// the context of this abnormal exit even without verbose mode.
@@ -369,7 +415,7 @@ func (r *Runner) RunLibfuzzerAndReport(ctx context.Context, args []string, env [
 }
 
 func (r *Runner) FuzzerEnvironment() ([]string, error) {
-	env, err := fuzzer_runner.FuzzerEnvironment()
+	env, err := fuzzer_runner.FuzzerEnvironment(r.Symbolizer)
 	if err != nil {
 		return nil, err
 	}
@@ -416,6 +462,9 @@ func (r *Runner) FuzzerEnvironment() ([]string, error) {
 		// we are setting this explicitly to false
 		"abort_on_error": "0",
 	}
+	if r.DetectODRViolation != "" {
+		overrideOptions["detect_odr_violation"] = r.DetectODRViolation
+	}
 	env, err = fuzzer_runner.SetASANOptions(env, nil, overrideOptions)
 	if err != nil {
 		return nil, err
@@ -447,17 +496,32 @@ func sendReports(handler report.Handler, reportsCh <-chan *report.Report) error
 	return nil
 }
 
-func IsExpectedExitError(err error) bool {
-	expectedExitCodes := []int{
-		fuzzer_runner.SanitizerErrorExitCode,
-		fuzzer_runner.LibFuzzerErrorExitCode,
-		fuzzer_runner.LibFuzzerOOMExitCode,
-		fuzzer_runner.LibFuzzerTimeoutExitCode,
-	}
-
+// IsExpectedExitError returns true if the error is caused by libFuzzer
+// exiting with one of the exit codes it uses when it found an issue.
+// The exit codes configured via the -error_exitcode and
+// -timeout_exitcode flags in the engine args are taken into account.
+func IsExpectedExitError(err error, engineArgs []string) bool {
 	var exitErr *exec.ExitError
 	if !errors.As(err, &exitErr) {
 		return false
 	}
-	return sliceutil.Contains(expectedExitCodes, exitErr.ExitCode())
+	return sliceutil.Contains(expectedExitCodes(engineArgs), exitErr.ExitCode())
+}
+
+func expectedExitCodes(engineArgs []string) []int {
+	codes := []int{
+		fuzzer_runner.SanitizerErrorExitCode,
+		fuzzer_runner.LibFuzzerOOMExitCode,
+	}
+	// Invalid values are rejected by libFuzzer itself, so the defaults
+	// are used in that case
+	errorExitCode, err := options.ParseLibFuzzerExitCode(engineArgs, options.LibFuzzerErrorExitCode, fuzzer_runner.LibFuzzerErrorExitCode)
+	if err != nil {
+		errorExitCode = fuzzer_runner.LibFuzzerErrorExitCode
+	}
+	timeoutExitCode, err := options.ParseLibFuzzerExitCode(engineArgs, options.LibFuzzerTimeoutExitCode, fuzzer_runner.LibFuzzerTimeoutExitCode)
+	if err != nil {
+		timeoutExitCode = fuzzer_runner.LibFuzzerTimeoutExitCode
+	}
+	return append(codes, errorExitCode, timeoutExitCode)
 }
@@ -0,0 +1,31 @@
+package libfuzzer
+
+import (
+	"os/exec"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsExpectedExitError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	exitError := func(code int) error {
+		return exec.Command("sh", "-c", "exit "+strconv.Itoa(code)).Run()
+	}
+
+	// The default exit codes of libFuzzer
+	assert.True(t, IsExpectedExitError(exitError(70), nil))
+	assert.True(t, IsExpectedExitError(exitError(77), nil))
+	assert.False(t, IsExpectedExitError(exitError(3), nil))
+
+	// Exit codes configured via the engine args replace the defaults
+	args := []string{"-timeout_exitcode=3", "-error_exitcode=4"}
+	assert.True(t, IsExpectedExitError(exitError(3), args))
+	assert.True(t, IsExpectedExitError(exitError(4), args))
+	assert.False(t, IsExpectedExitError(exitError(70), args))
+	assert.False(t, IsExpectedExitError(exitError(77), args))
+}
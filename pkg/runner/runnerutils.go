@@ -3,6 +3,7 @@ package runner
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -178,16 +179,22 @@ func AddEnvFlags(env []string, envVars []string) ([]string, error) {
 	return env, nil
 }
 
-func FuzzerEnvironment() ([]string, error) {
+// FuzzerEnvironment returns the environment variables needed to run
+// a libFuzzer fuzz test. If symbolizerPath is empty, the
+// llvm-symbolizer found via the runfiles finder is used.
+func FuzzerEnvironment(symbolizerPath string) ([]string, error) {
 	var err error
 
 	var env []string
 
 	// Tell the address sanitizer where it can find llvm-symbolizer.
 	// See https://clang.llvm.org/docs/AddressSanitizer.html#symbolizing-the-reports
-	llvmSymbolizerPath, err := runfiles.Finder.LLVMSymbolizerPath()
-	if err != nil {
-		return nil, err
+	llvmSymbolizerPath := symbolizerPath
+	if llvmSymbolizerPath == "" {
+		llvmSymbolizerPath, err = runfiles.Finder.LLVMSymbolizerPath()
+		if err != nil {
+			return nil, err
+		}
 	}
 	// Resolve the path to the llvm-symbolizer to ensure that the path
 	// can be accessed inside the sandbox
@@ -226,6 +233,25 @@ func SetLDLibraryPath(env []string, libraryDirs []string) ([]string, error) {
 	return envutil.Setenv(env, "LD_LIBRARY_PATH", libDirsList)
 }
 
+// SetNativeLibraryPath adds the library dirs to the environment variable
+// which the dynamic linker of the platform uses to find shared
+// libraries, keeping the directories which are already set in env or
+// the current process environment.
+func SetNativeLibraryPath(env []string, libraryDirs []string) ([]string, error) {
+	key := "LD_LIBRARY_PATH"
+	switch runtime.GOOS {
+	case "darwin":
+		key = "DYLD_LIBRARY_PATH"
+	case "windows":
+		key = "PATH"
+	}
+	value := envutil.Getenv(env, key)
+	if value == "" {
+		value = os.Getenv(key)
+	}
+	return envutil.Setenv(env, key, envutil.AppendToPathList(value, libraryDirs...))
+}
+
 func sanitizerOptionsColorValue() string {
 	// Colorize sanitizer reports if cifuzz itself is running in an interactive
 	// terminal. Since we redirect unstructured output from fuzzer binaries to
@@ -1,14 +1,24 @@
 package vcs
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"code-intelligence.com/cifuzz/internal/bundler/archive"
 	"code-intelligence.com/cifuzz/internal/cmdutils"
 	"code-intelligence.com/cifuzz/pkg/log"
+	"code-intelligence.com/cifuzz/util/archiveutil"
+	"code-intelligence.com/cifuzz/util/fileutil"
+	"code-intelligence.com/cifuzz/util/sliceutil"
 )
 
 // GitCommit returns the full SHA of the current commit if the working directory is contained in a Git repository.
@@ -78,3 +88,225 @@ func CodeRevision() *archive.CodeRevision {
 
 	return revision
 }
+
+// BlameInfo describes the commit which last changed a line of a file.
+type BlameInfo struct {
+	Commit     string
+	Author     string
+	AuthorMail string
+	Date       time.Time
+	Summary    string
+}
+
+// IsCommitted returns false if the line was changed in the working
+// tree but not committed yet.
+func (b *BlameInfo) IsCommitted() bool {
+	return strings.Trim(b.Commit, "0") != ""
+}
+
+// GitBlame returns the commit which last changed the specified line of
+// the file. It returns an error if the file is not contained in a Git
+// repository.
+func GitBlame(path string, line uint32) (*BlameInfo, error) {
+	cmd := exec.Command("git", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseGitBlame(string(out))
+}
+
+// parseGitBlame parses the output of `git blame --porcelain` for a
+// single line.
+func parseGitBlame(out string) (*BlameInfo, error) {
+	lines := strings.Split(out, "\n")
+	fields := strings.Fields(lines[0])
+	if len(fields) == 0 {
+		return nil, errors.Errorf("Unexpected output of git blame: %q", out)
+	}
+
+	info := &BlameInfo{Commit: fields[0]}
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "\t") {
+			// The content of the line follows the headers
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			info.Author = value
+		case "author-mail":
+			info.AuthorMail = strings.Trim(value, "<>")
+		case "author-time":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			info.Date = time.Unix(seconds, 0)
+		case "summary":
+			info.Summary = value
+		}
+	}
+	return info, nil
+}
+
+// GitExportDir writes the files of the directory as they were committed
+// at the specified ref to the destination directory. It returns an
+// error if the directory is not contained in a Git repository or was
+// not under version control at the ref. The directory doesn't have to
+// exist in the working tree.
+func GitExportDir(dir, ref, dest string) error {
+	root, treePath, err := gitTreePath(dir)
+	if err != nil {
+		return errors.WithMessagef(err, "Failed to get the content of %s at git ref %q", dir, ref)
+	}
+
+	// Archiving the tree of the directory instead of the commit
+	// produces paths relative to the directory. The archive is
+	// streamed to the destination instead of being buffered, because
+	// corpora can be large.
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "archive", "--format=tar", ref+":"+treePath)
+	cmd.Dir = root
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	untarErr := archiveutil.Untar(stdout, dest)
+	if untarErr != nil {
+		_ = cmd.Process.Kill()
+	} else {
+		// Consume the padding after the end of the archive, else git
+		// might fail to write it
+		_, untarErr = io.Copy(io.Discard, stdout)
+	}
+	err = cmd.Wait()
+	if err != nil && stderr.Len() > 0 {
+		return errors.Errorf("Failed to get the content of %s at git ref %q, make sure that it's under version control: %s",
+			dir, ref, strings.TrimSpace(stderr.String()))
+	}
+	if untarErr != nil {
+		return errors.WithMessagef(untarErr, "Failed to extract the content of %s at git ref %q", dir, ref)
+	}
+	return errors.WithStack(err)
+}
+
+// GitDirExists returns whether the directory was under version control
+// at the specified ref. It returns an error if the directory is not
+// contained in a Git repository or the ref doesn't exist.
+func GitDirExists(dir, ref string) (bool, error) {
+	root, treePath, err := gitTreePath(dir)
+	if err != nil {
+		return false, err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "rev-parse", "--verify", ref+"^{commit}")
+	cmd.Dir = root
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return false, errors.Errorf("Failed to look up %s at git ref %q: %s", dir, ref, strings.TrimSpace(stderr.String()))
+	}
+	// The object type is only printed if the path exists at the ref
+	cmd = exec.Command("git", "cat-file", "-t", ref+":"+treePath)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return false, nil
+	}
+	return strings.TrimSpace(string(out)) == "tree", nil
+}
+
+// gitTreePath returns the root of the Git repository which contains dir
+// and the path of dir relative to it, which is needed to refer to the
+// tree of the directory at a ref. If dir doesn't exist, the repository
+// is determined via its closest existing parent directory.
+func gitTreePath(dir string) (root string, treePath string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+	existingDir := dir
+	for {
+		exists, err := fileutil.Exists(existingDir)
+		if err != nil {
+			return "", "", err
+		}
+		if exists || filepath.Dir(existingDir) == existingDir {
+			break
+		}
+		existingDir = filepath.Dir(existingDir)
+	}
+	missingPath, err := filepath.Rel(existingDir, dir)
+	if err != nil {
+		return "", "", errors.WithStack(err)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel", "--show-prefix")
+	cmd.Dir = existingDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", errors.Errorf("%s is not contained in a Git repository: %s", dir, strings.TrimSpace(stderr.String()))
+	}
+	lines := strings.Split(string(out), "\n")
+	if len(lines) < 2 {
+		return "", "", errors.Errorf("Unexpected output of git rev-parse: %q", out)
+	}
+	root = lines[0]
+	treePath = strings.TrimSuffix(path.Join(lines[1], filepath.ToSlash(missingPath)), "/")
+	if treePath == "." {
+		treePath = ""
+	}
+	return root, treePath, nil
+}
+
+// GitChangedFiles returns the absolute paths of the files in the
+// repository containing dir which changed since the merge base of the
+// specified ref and HEAD, like `git diff ref...HEAD`, so that changes
+// which were made on ref after the branches diverged are not included.
+// The result also includes uncommitted changes, deleted files and
+// untracked files which are not ignored.
+func GitChangedFiles(dir, ref string) ([]string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("Failed to determine the files changed since git ref %q, %s is not contained in a Git repository: %s",
+			ref, dir, strings.TrimSpace(stderr.String()))
+	}
+	root := strings.TrimSpace(string(out))
+
+	var changedFiles []string
+	for _, args := range [][]string{
+		{"diff", "--name-only", "-z", ref + "...HEAD", "--"},
+		{"diff", "--name-only", "-z", "HEAD", "--"},
+		{"ls-files", "--others", "--exclude-standard", "-z"},
+	} {
+		stderr.Reset()
+		cmd = exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Stderr = &stderr
+		out, err = cmd.Output()
+		if err != nil {
+			return nil, errors.Errorf("Failed to determine the files changed since git ref %q: %s",
+				ref, strings.TrimSpace(stderr.String()))
+		}
+		for _, path := range strings.Split(string(out), "\x00") {
+			if path != "" {
+				changedFiles = append(changedFiles, filepath.Join(root, filepath.FromSlash(path)))
+			}
+		}
+	}
+	// Files with committed and uncommitted changes are reported twice
+	return sliceutil.RemoveDuplicates(changedFiles), nil
+}
@@ -92,6 +92,105 @@ func TestCodeRevision_NoRepo(t *testing.T) {
 	require.Nil(t, revision)
 }
 
+func TestGitBlame(t *testing.T) {
+	repo := createGitRepoWithCommits(t)
+	err := os.MkdirAll(filepath.Join(repo, "src"), 0o755)
+	require.NoError(t, err)
+	sourceFile := filepath.Join(repo, "src", "parser.cpp")
+	err = os.WriteFile(sourceFile, []byte("int a;\nint b;\n"), 0o644)
+	require.NoError(t, err)
+	runGit(t, repo, "add", "src/parser.cpp")
+	runGit(t, repo, "commit", "-m", "Add parser")
+
+	info, err := vcs.GitBlame(sourceFile, 2)
+	require.NoError(t, err)
+	assert.True(t, info.IsCommitted())
+	assert.Len(t, info.Commit, 40)
+	assert.Equal(t, "Your Name", info.Author)
+	assert.Equal(t, "you@example.com", info.AuthorMail)
+	assert.Equal(t, "Add parser", info.Summary)
+	assert.False(t, info.Date.IsZero())
+
+	// Lines which were changed in the working tree are not committed
+	err = os.WriteFile(sourceFile, []byte("int a;\nint c;\n"), 0o644)
+	require.NoError(t, err)
+	info, err = vcs.GitBlame(sourceFile, 2)
+	require.NoError(t, err)
+	assert.False(t, info.IsCommitted())
+
+	// Files outside of a Git repository can't be blamed
+	otherFile := filepath.Join(testutil.MkdirTemp(t, "", "git-blame-"), "file.cpp")
+	err = os.WriteFile(otherFile, []byte("int a;\n"), 0o644)
+	require.NoError(t, err)
+	_, err = vcs.GitBlame(otherFile, 1)
+	require.Error(t, err)
+}
+
+func TestGitExportDir(t *testing.T) {
+	repo := createGitRepoWithCommits(t)
+	corpusDir := filepath.Join(repo, "corpus")
+	err := os.MkdirAll(corpusDir, 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "input1"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	runGit(t, repo, "add", "corpus")
+	runGit(t, repo, "commit", "-m", "Add corpus")
+	runGit(t, repo, "tag", "v1")
+
+	err = os.WriteFile(filepath.Join(corpusDir, "input2"), []byte("bar"), 0o644)
+	require.NoError(t, err)
+	runGit(t, repo, "add", "corpus")
+	runGit(t, repo, "commit", "-m", "Extend corpus")
+
+	// Only the inputs which were committed at the ref are exported
+	dest := testutil.MkdirTemp(t, "", "git-export-")
+	err = vcs.GitExportDir(corpusDir, "v1", dest)
+	require.NoError(t, err)
+	entries, err := os.ReadDir(dest)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "input1", entries[0].Name())
+
+	// The corpus didn't exist in the first commit
+	err = vcs.GitExportDir(corpusDir, "HEAD~2", testutil.MkdirTemp(t, "", "git-export-"))
+	require.Error(t, err)
+
+	// Directories which were removed from the working tree can still
+	// be exported
+	runGit(t, repo, "rm", "-r", "corpus")
+	dest = testutil.MkdirTemp(t, "", "git-export-")
+	err = vcs.GitExportDir(corpusDir, "HEAD", dest)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dest, "input1"))
+	assert.FileExists(t, filepath.Join(dest, "input2"))
+}
+
+func TestGitDirExists(t *testing.T) {
+	repo := createGitRepoWithCommits(t)
+	corpusDir := filepath.Join(repo, "corpus")
+	err := os.MkdirAll(corpusDir, 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(corpusDir, "input1"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	runGit(t, repo, "add", "corpus")
+	runGit(t, repo, "commit", "-m", "Add corpus")
+
+	exists, err := vcs.GitDirExists(corpusDir, "HEAD")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = vcs.GitDirExists(corpusDir, "HEAD~1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = vcs.GitDirExists(filepath.Join(repo, "does-not-exist"), "HEAD")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = vcs.GitDirExists(corpusDir, "no-such-ref")
+	require.Error(t, err)
+}
+
 func createGitRepoWithCommits(t *testing.T) string {
 	t.Helper()
 
@@ -125,3 +224,49 @@ func runGit(t *testing.T, repo string, args ...string) {
 	err := cmd.Run()
 	require.NoError(t, err)
 }
+
+func TestGitChangedFiles(t *testing.T) {
+	repo := createGitRepoWithCommits(t)
+	runGit(t, repo, "tag", "v1")
+	// Git returns the paths relative to the resolved repository root
+	repo, err := filepath.EvalSymlinks(repo)
+	require.NoError(t, err)
+
+	changed, err := vcs.GitChangedFiles(repo, "v1")
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	// A committed, an uncommitted and an untracked change
+	err = os.WriteFile(filepath.Join(repo, "committed_file"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	runGit(t, repo, "add", "committed_file")
+	runGit(t, repo, "commit", "-m", "Third commit")
+	err = os.WriteFile(filepath.Join(repo, "other_file"), []byte("bar"), 0o644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(repo, "untracked_file"), []byte("baz"), 0o644)
+	require.NoError(t, err)
+
+	changed, err = vcs.GitChangedFiles(repo, "v1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(repo, "committed_file"),
+		filepath.Join(repo, "other_file"),
+		filepath.Join(repo, "untracked_file"),
+	}, changed)
+
+	// Changes on the ref after the branches diverged are not included
+	runGit(t, repo, "add", "other_file", "untracked_file")
+	runGit(t, repo, "commit", "-m", "Fourth commit")
+	runGit(t, repo, "checkout", "-b", "feature", "v1")
+	runGit(t, repo, "tag", "-d", "v1")
+	err = os.WriteFile(filepath.Join(repo, "feature_file"), []byte("foo"), 0o644)
+	require.NoError(t, err)
+	runGit(t, repo, "add", "feature_file")
+	runGit(t, repo, "commit", "-m", "Feature commit")
+	changed, err = vcs.GitChangedFiles(repo, "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(repo, "feature_file")}, changed)
+
+	_, err = vcs.GitChangedFiles(repo, "does-not-exist")
+	require.Error(t, err)
+}
@@ -22,7 +22,9 @@ func UntarFile(source, dest string) error {
 	return Untar(file, dest)
 }
 
-// Untar extracts a tar archive to a destination directory
+// Untar extracts a tar archive to a destination directory. It returns
+// an error if the archive contains entries which would be extracted
+// outside of the destination directory.
 func Untar(r io.Reader, dest string) error {
 	hardlinks := make(map[string]string)
 	tr := tar.NewReader(r)
@@ -36,20 +38,25 @@ func Untar(r io.Reader, dest string) error {
 			return errors.WithStack(err)
 		}
 
+		var path string
+		path, err = extractionPath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+
 		switch header.Typeflag {
 		case tar.TypeDir:
-			err = os.MkdirAll(filepath.Join(dest, header.Name), 0755)
+			err = os.MkdirAll(path, 0755)
 			if err != nil {
 				return errors.WithStack(err)
 			}
 		case tar.TypeReg:
-			filePath := filepath.Join(dest, header.Name)
-			err = os.MkdirAll(filepath.Dir(filePath), 0755)
+			err = os.MkdirAll(filepath.Dir(path), 0755)
 			if err != nil {
 				return errors.WithStack(err)
 			}
 			var file *os.File
-			file, err = os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
+			file, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
 			if err != nil {
 				return errors.WithStack(err)
 			}
@@ -63,9 +70,12 @@ func Untar(r io.Reader, dest string) error {
 			// already exist, which is not necessarily the case yet, so
 			// we store the link and target paths and create the hard
 			// links after all other files were extracted
-			targetpath := filepath.Join(dest, header.Linkname)
-			linkpath := filepath.Join(dest, header.Name)
-			hardlinks[linkpath] = targetpath
+			var targetpath string
+			targetpath, err = extractionPath(dest, header.Linkname)
+			if err != nil {
+				return err
+			}
+			hardlinks[path] = targetpath
 		default:
 			return errors.Errorf("unsupported file type: %d", header.Typeflag)
 		}
@@ -82,6 +92,22 @@ func Untar(r io.Reader, dest string) error {
 	return nil
 }
 
+// extractionPath returns the path to which the archive entry with the
+// given name is extracted. To prevent path traversal ("zip slip")
+// attacks, it returns an error if the path is outside of dest, i.e. if
+// the name is absolute or escapes dest via "..".
+func extractionPath(dest, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return "", errors.Errorf("illegal absolute file path in archive: %s", name)
+	}
+	path := filepath.Join(dest, name)
+	relPath, err := filepath.Rel(filepath.Clean(dest), path)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
+		return "", errors.Errorf("illegal file path in archive: %s", name)
+	}
+	return path, nil
+}
+
 // Unzip extracts a ZIP archive to a destination directory
 // Based on: https://stackoverflow.com/a/24792688/2804197
 // Original author: https://stackoverflow.com/users/1316499/astockwell
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -116,6 +117,24 @@ func QuotedEnv(env []string) []string {
 	return quotedEnv
 }
 
+// secretEnvKeyPattern matches the names of environment variables which
+// commonly contain secrets
+var secretEnvKeyPattern = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|API_?KEY|AUTH)`)
+
+// RedactSecrets returns a copy of env in which the values of variables
+// whose names indicate that they contain secrets are replaced.
+func RedactSecrets(env []string) []string {
+	var redacted []string
+	for _, e := range env {
+		key, _, found := strings.Cut(e, "=")
+		if found && secretEnvKeyPattern.MatchString(key) {
+			e = key + "=<redacted>"
+		}
+		redacted = append(redacted, e)
+	}
+	return redacted
+}
+
 // QuotedCommandWithEnv returns a string which can be executed in a
 // shell to run the specified command with the specified environment
 // variables. Useful for debug output to be able to run commands manually.
@@ -71,3 +71,8 @@ func TestGetEnvWithPathSubstring(t *testing.T) {
 	res = GetEnvWithPathSubstring(env, "foo", "foo")
 	require.Equal(t, "", res)
 }
+
+func TestRedactSecrets(t *testing.T) {
+	env := []string{"NO_CIFUZZ=1", "GITHUB_TOKEN=abc", "db_password=def", "MY_API_KEY=ghi", "EMPTY="}
+	require.Equal(t, []string{"NO_CIFUZZ=1", "GITHUB_TOKEN=<redacted>", "db_password=<redacted>", "MY_API_KEY=<redacted>", "EMPTY="}, RedactSecrets(env))
+}
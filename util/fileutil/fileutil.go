@@ -84,6 +84,34 @@ func PrettifyPath(path string) string {
 	return rel
 }
 
+// pathStart matches the beginning of a string or line or a character
+// which can't be part of a path, i.e. the position where a path can
+// start. pathEnd matches the position where a path ends.
+const (
+	pathStart = `(?m)(^|[^\w.~/\\-])`
+	pathEnd   = `($|[^\w.~/\\-])`
+)
+
+// StripPathPrefix removes the directory dir from the beginning of all
+// paths below dir which occur in s, turning them into paths relative
+// to dir. Occurrences of dir itself are replaced by ".". Occurrences
+// of dir in the middle of a path are not affected. If dir contains
+// symlinks, paths with the resolved directory are stripped as well.
+func StripPathPrefix(s string, dir string) string {
+	dirs := []string{filepath.Clean(dir)}
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err == nil && resolvedDir != dirs[0] {
+		dirs = append(dirs, resolvedDir)
+	}
+	for _, d := range dirs {
+		re := regexp.MustCompile(pathStart + regexp.QuoteMeta(d+string(filepath.Separator)))
+		s = re.ReplaceAllString(s, "${1}")
+		re = regexp.MustCompile(pathStart + regexp.QuoteMeta(d) + pathEnd)
+		s = re.ReplaceAllString(s, "${1}.${2}")
+	}
+	return s
+}
+
 // IsBelow returns true if and only if path lies below or is the path root.
 // path and root must be either both absolute or both relative.
 func IsBelow(path string, root string) (bool, error) {
@@ -27,6 +27,19 @@ func TestPrettifyPath(t *testing.T) {
 	assert.Equal(t, filepath.Join("..some", "dir"), PrettifyPath(filepath.Join(cwd, "..some", "dir")))
 }
 
+func TestStripPathPrefix(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator)+"home", "user", "project")
+	s := "Crash in " + filepath.Join(dir, "src", "foo.c") + ":12, see " + filepath.Join(string(filepath.Separator)+"tmp", "bar")
+	expected := "Crash in " + filepath.Join("src", "foo.c") + ":12, see " + filepath.Join(string(filepath.Separator)+"tmp", "bar")
+	assert.Equal(t, expected, StripPathPrefix(s, dir))
+	// The directory itself is replaced by "."
+	assert.Equal(t, "<source>.</source>", StripPathPrefix("<source>"+dir+"</source>", dir))
+	// Only leading path prefixes are stripped
+	s = filepath.Join(string(filepath.Separator)+"mnt", dir, "foo.c") + "\n" + filepath.Join(dir, "bar.c")
+	expected = filepath.Join(string(filepath.Separator)+"mnt", dir, "foo.c") + "\n" + "bar.c"
+	assert.Equal(t, expected, StripPathPrefix(s, dir))
+}
+
 func TestIsBelow(t *testing.T) {
 	isBelow, err := IsBelow(filepath.Join("dir1", "dir2", "file"), filepath.Join("dir1", "dir2"))
 	assert.NoError(t, err)